@@ -0,0 +1,183 @@
+// Package address parses, validates and normalizes the recipients of the
+// messages the tools compose, so that mistakes are reported before Mail.app
+// opens a window rather than showing up as broken drafts.
+//
+// Addresses are normalized as follows:
+//   - display names are decoded from RFC 2047, unquoted, put into Unicode
+//     NFC and their white space is collapsed; a name that only repeats the
+//     address is dropped,
+//   - domains are converted to their lower case ASCII form, so an
+//     internationalized domain such as münchen.de becomes xn--mnchen-3ya.de.
+//
+// The local part is kept as given.
+package address
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/mail"
+	"strings"
+
+	"golang.org/x/net/idna"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Address is a normalized mailbox.
+type Address struct {
+	Name  string `json:"name,omitempty"`
+	Email string `json:"email"`
+}
+
+// String formats the address as Mail.app accepts it for a recipient, e.g.
+// "Bob Smith <bob@example.com>" or `"Smith, Bob" <bob@example.com>`. Unlike
+// mail.Address.String, names are not encoded.
+func (a Address) String() string {
+	if a.Name == "" {
+		return a.Email
+	}
+	name := a.Name
+	if strings.ContainsAny(name, `()<>[]:;@\,."`) {
+		name = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(name) + `"`
+	}
+	return name + " <" + a.Email + ">"
+}
+
+// key identifies an address when removing duplicates. Local parts are case
+// sensitive by the standard, but not in practice.
+func (a Address) key() string {
+	return strings.ToLower(a.Email)
+}
+
+var parser = &mail.AddressParser{WordDecoder: &mime.WordDecoder{}}
+
+// ParseList parses and normalizes an RFC 5322 address list such as
+// `bob@example.com, "Smith, Alice" <alice@example.com>`. Group syntax is
+// flattened into the group's mailboxes.
+func ParseList(value string) ([]Address, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, errors.New("empty address")
+	}
+	list, err := parser.ParseList(value)
+	if err != nil {
+		return nil, parseError(value, err)
+	}
+	addresses := make([]Address, 0, len(list))
+	for _, a := range list {
+		normalized, err := Normalize(a.Name, a.Address)
+		if err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, normalized)
+	}
+	return addresses, nil
+}
+
+// parseError explains why value is not a valid address list, pointing out
+// common mistakes.
+func parseError(value string, err error) error {
+	reason := strings.TrimPrefix(err.Error(), "mail: ")
+	switch {
+	case !strings.Contains(value, "@"):
+		reason = "no @ in address"
+	case strings.Count(value, "<") != strings.Count(value, ">"):
+		reason = "unbalanced angle brackets, use Name <address>"
+	case strings.Contains(value, "<") && strings.Count(value, "@") == 1 && strings.Contains(value, ","):
+		reason = `display name contains a comma, quote it as "Last, First" <address>`
+	}
+	return fmt.Errorf("invalid address %q: %s", value, reason)
+}
+
+// Normalize validates the address email with the display name name and
+// returns it normalized.
+func Normalize(name, email string) (Address, error) {
+	at := strings.LastIndexByte(email, '@')
+	if at <= 0 || at == len(email)-1 {
+		return Address{}, fmt.Errorf("invalid address %q: missing local part or domain", email)
+	}
+	domain, err := NormalizeDomain(email[at+1:])
+	if err != nil {
+		return Address{}, fmt.Errorf("invalid address %q: %w", email, err)
+	}
+	email = email[:at+1] + domain
+
+	name = strings.Join(strings.Fields(norm.NFC.String(name)), " ")
+	if strings.EqualFold(name, email) || strings.EqualFold(name, "<"+email+">") {
+		name = ""
+	}
+	return Address{Name: name, Email: email}, nil
+}
+
+// NormalizeDomain returns the lower case ASCII form of a domain, which must
+// have at least two labels.
+func NormalizeDomain(domain string) (string, error) {
+	ascii, err := idna.Lookup.ToASCII(strings.TrimSuffix(domain, "."))
+	if err != nil {
+		return "", fmt.Errorf("invalid domain %q", domain)
+	}
+	labels := strings.Split(ascii, ".")
+	if len(labels) < 2 {
+		return "", fmt.Errorf("domain %q has no top-level domain", domain)
+	}
+	if strings.Trim(labels[len(labels)-1], "0123456789") == "" {
+		return "", fmt.Errorf("invalid top-level domain in %q", domain)
+	}
+	return ascii, nil
+}
+
+// Recipients are the normalized recipients of a message.
+type Recipients struct {
+	To  []Address `json:"to,omitempty"`
+	Cc  []Address `json:"cc,omitempty"`
+	Bcc []Address `json:"bcc,omitempty"`
+
+	// Duplicates are the addresses that were removed because they were
+	// given before, in the same or an earlier field.
+	Duplicates []Address `json:"duplicates,omitempty"`
+}
+
+// ParseRecipients parses the To, Cc and Bcc recipients of a message. Each
+// element may be a single address or an address list. An address that is
+// given more than once is kept where it is given first, looking at To, Cc
+// and Bcc in this order. All invalid elements are reported in one error.
+func ParseRecipients(to, cc, bcc []string) (Recipients, error) {
+	var r Recipients
+	var problems []string
+	seen := map[string]bool{}
+	parse := func(field string, values []string) []Address {
+		addresses := []Address{}
+		for _, value := range values {
+			list, err := ParseList(value)
+			if err != nil {
+				problems = append(problems, field+": "+err.Error())
+				continue
+			}
+			for _, a := range list {
+				if seen[a.key()] {
+					r.Duplicates = append(r.Duplicates, a)
+					continue
+				}
+				seen[a.key()] = true
+				addresses = append(addresses, a)
+			}
+		}
+		return addresses
+	}
+	r.To = parse("to", to)
+	r.Cc = parse("cc", cc)
+	r.Bcc = parse("bcc", bcc)
+	if len(problems) > 0 {
+		return Recipients{}, fmt.Errorf("invalid recipients: %s", strings.Join(problems, "; "))
+	}
+	return r, nil
+}
+
+// Strings formats addresses with Address.String.
+func Strings(addresses []Address) []string {
+	out := make([]string, len(addresses))
+	for i, a := range addresses {
+		out[i] = a.String()
+	}
+	return out
+}
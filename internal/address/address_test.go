@@ -0,0 +1,130 @@
+package address
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseList(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    []Address
+		wantErr string
+	}{
+		{
+			name:  "plain address",
+			value: " bob@Example.COM ",
+			want:  []Address{{Email: "bob@example.com"}},
+		},
+		{
+			name:  "display name with extra white space",
+			value: "  Bob   Smith <bob@example.com>",
+			want:  []Address{{Name: "Bob Smith", Email: "bob@example.com"}},
+		},
+		{
+			name:  "list with quoted name",
+			value: `"Smith, Alice" <alice@example.com>, bob@example.com`,
+			want:  []Address{{Name: "Smith, Alice", Email: "alice@example.com"}, {Email: "bob@example.com"}},
+		},
+		{
+			name:  "encoded name",
+			value: "=?UTF-8?Q?J=C3=BCrgen?= <juergen@example.com>",
+			want:  []Address{{Name: "Jürgen", Email: "juergen@example.com"}},
+		},
+		{
+			name:  "name repeating the address",
+			value: `"Bob@example.com" <bob@example.com>`,
+			want:  []Address{{Email: "bob@example.com"}},
+		},
+		{
+			name:  "internationalized domain",
+			value: "Jürgen <juergen@MÜNCHEN.de>",
+			want:  []Address{{Name: "Jürgen", Email: "juergen@xn--mnchen-3ya.de"}},
+		},
+		{
+			name:  "group",
+			value: "Team: alice@example.com, bob@example.com;",
+			want:  []Address{{Email: "alice@example.com"}, {Email: "bob@example.com"}},
+		},
+		{name: "empty", value: " ", wantErr: "empty"},
+		{name: "missing at", value: "bob.example.com", wantErr: "no @"},
+		{name: "unclosed angle bracket", value: "Bob <bob@example.com", wantErr: "angle brackets"},
+		{name: "unquoted comma in name", value: "Smith, Bob <bob@example.com>", wantErr: "quote it"},
+		{name: "missing top-level domain", value: "bob@localhost", wantErr: "top-level domain"},
+		{name: "numeric top-level domain", value: "bob@10.0.0.1", wantErr: "top-level domain"},
+		{name: "invalid domain", value: "bob@-example.com", wantErr: "invalid domain"},
+		{name: "double at", value: "bob@@example.com", wantErr: "invalid address"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseList(tt.value)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("ParseList(%q) = %v, %v, want error %q", tt.value, got, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ParseList(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddress_String(t *testing.T) {
+	tests := []struct {
+		address Address
+		want    string
+	}{
+		{Address{Email: "bob@example.com"}, "bob@example.com"},
+		{Address{Name: "Bob Smith", Email: "bob@example.com"}, "Bob Smith <bob@example.com>"},
+		{Address{Name: "Jürgen", Email: "juergen@example.com"}, "Jürgen <juergen@example.com>"},
+		{Address{Name: `Smith, Bob "B."`, Email: "bob@example.com"}, `"Smith, Bob \"B.\"" <bob@example.com>`},
+	}
+	for _, tt := range tests {
+		got := tt.address.String()
+		if got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+		if parsed, err := ParseList(got); err != nil || len(parsed) != 1 || parsed[0] != tt.address {
+			t.Errorf("ParseList(%q) = %v, %v, want the address back", got, parsed, err)
+		}
+	}
+}
+
+func TestParseRecipients(t *testing.T) {
+	r, err := ParseRecipients(
+		[]string{"Bob <bob@example.com>, alice@example.com"},
+		[]string{"BOB@example.com", "carol@example.com"},
+		[]string{"carol@example.com", "dave@example.com"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := Strings(r.To); !slices.Equal(got, []string{"Bob <bob@example.com>", "alice@example.com"}) {
+		t.Errorf("To = %v", got)
+	}
+	if got := Strings(r.Cc); !slices.Equal(got, []string{"carol@example.com"}) {
+		t.Errorf("Cc = %v, want the duplicate of a To recipient removed", got)
+	}
+	if got := Strings(r.Bcc); !slices.Equal(got, []string{"dave@example.com"}) {
+		t.Errorf("Bcc = %v, want the duplicate of a Cc recipient removed", got)
+	}
+	if len(r.Duplicates) != 2 {
+		t.Errorf("Duplicates = %v, want 2", r.Duplicates)
+	}
+
+	_, err = ParseRecipients([]string{"bob@example"}, nil, []string{"alice@example.com", "Smith, Carol <carol@example.com>"})
+	if err == nil || !strings.Contains(err.Error(), "to: ") || !strings.Contains(err.Error(), "bcc: ") {
+		t.Errorf("ParseRecipients() error = %v, want the invalid To and Bcc recipients", err)
+	}
+
+	if r, err := ParseRecipients(nil, []string{}, nil); err != nil || r.To == nil || len(r.Cc) != 0 {
+		t.Errorf("ParseRecipients() = %+v, %v, want empty lists", r, err)
+	}
+}
@@ -0,0 +1,99 @@
+// Package body extracts the text and HTML bodies of raw RFC 5322 messages,
+// as Mail.app reports them in the message source.
+package body
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+
+	"golang.org/x/net/html/charset"
+)
+
+// maxDepth limits the nesting of multipart bodies.
+const maxDepth = 20
+
+// Parts are the bodies of a message, decoded to UTF-8 with LF line endings.
+type Parts struct {
+	Text string // the first text/plain part
+	HTML string // the first text/html part
+}
+
+// Parse returns the first text/plain and text/html parts of a message.
+// Attachments and attached messages are skipped.
+func Parse(source string) (Parts, error) {
+	msg, err := mail.ReadMessage(strings.NewReader(source))
+	if err != nil {
+		return Parts{}, fmt.Errorf("failed to parse message: %w", err)
+	}
+	var p Parts
+	if err := p.walk(textproto.MIMEHeader(msg.Header), msg.Body, 0); err != nil {
+		return Parts{}, err
+	}
+	return p, nil
+}
+
+func (p *Parts) walk(h textproto.MIMEHeader, r io.Reader, depth int) error {
+	mediaType, params, err := mime.ParseMediaType(h.Get("Content-Type"))
+	if err != nil {
+		// RFC 2045: the default is plain US-ASCII text
+		mediaType, params = "text/plain", map[string]string{}
+	}
+	if disposition, _, err := mime.ParseMediaType(h.Get("Content-Disposition")); err == nil && disposition == "attachment" {
+		return nil
+	}
+
+	switch {
+	case strings.HasPrefix(mediaType, "multipart/"):
+		if depth >= maxDepth || params["boundary"] == "" {
+			return nil
+		}
+		mr := multipart.NewReader(r, params["boundary"])
+		for {
+			part, err := mr.NextRawPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read multipart body: %w", err)
+			}
+			if err := p.walk(part.Header, part, depth+1); err != nil {
+				return err
+			}
+		}
+	case mediaType == "text/plain" && p.Text == "":
+		p.Text, err = decode(h, params, r)
+	case mediaType == "text/html" && p.HTML == "":
+		p.HTML, err = decode(h, params, r)
+	}
+	return err
+}
+
+// decode undoes the transfer encoding and converts the charset of a text part.
+func decode(h textproto.MIMEHeader, params map[string]string, r io.Reader) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(h.Get("Content-Transfer-Encoding"))) {
+	case "base64":
+		r = base64.NewDecoder(base64.StdEncoding, r)
+	case "quoted-printable":
+		r = quotedprintable.NewReader(r)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode body: %w", err)
+	}
+	if cs := strings.ToLower(params["charset"]); cs != "" && cs != "utf-8" && cs != "us-ascii" {
+		// Unknown charsets are returned as they are
+		if cr, err := charset.NewReaderLabel(cs, strings.NewReader(string(data))); err == nil {
+			if converted, err := io.ReadAll(cr); err == nil {
+				data = converted
+			}
+		}
+	}
+	return strings.ReplaceAll(string(data), "\r\n", "\n"), nil
+}
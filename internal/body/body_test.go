@@ -0,0 +1,94 @@
+package body
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		wantText string
+		wantHTML string
+	}{
+		{
+			name:     "plain text without content type",
+			source:   "Subject: Hi\r\n\r\nHello\r\nWorld\r\n",
+			wantText: "Hello\nWorld\n",
+		},
+		{
+			name:     "html only",
+			source:   "Content-Type: text/html; charset=utf-8\n\n<p>Hello</p>",
+			wantHTML: "<p>Hello</p>",
+		},
+		{
+			name: "alternative with transfer encodings",
+			source: strings.Join([]string{
+				`Content-Type: multipart/alternative; boundary="b1"`,
+				``,
+				`--b1`,
+				`Content-Type: text/plain; charset=iso-8859-1`,
+				`Content-Transfer-Encoding: quoted-printable`,
+				``,
+				`Gr=FC=DFe, a long line that is soft=`,
+				` wrapped`,
+				`--b1`,
+				`Content-Type: text/html; charset=utf-8`,
+				`Content-Transfer-Encoding: base64`,
+				``,
+				`PHA+R3LDvMOfZTwvcD4=`,
+				`--b1--`,
+			}, "\r\n"),
+			wantText: "Grüße, a long line that is soft wrapped",
+			wantHTML: "<p>Grüße</p>",
+		},
+		{
+			name: "nested multipart skips attachments",
+			source: strings.Join([]string{
+				`Content-Type: multipart/mixed; boundary=outer`,
+				``,
+				`--outer`,
+				`Content-Type: multipart/related; boundary=inner`,
+				``,
+				`--inner`,
+				`Content-Type: text/html; charset=windows-1252`,
+				``,
+				"\x93quoted\x94",
+				`--inner`,
+				`Content-Type: image/png`,
+				``,
+				`png`,
+				`--inner--`,
+				`--outer`,
+				`Content-Type: text/plain`,
+				`Content-Disposition: attachment; filename="notes.txt"`,
+				``,
+				`not the body`,
+				`--outer--`,
+			}, "\n"),
+			wantHTML: "“quoted”",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.source)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got.Text != tt.wantText {
+				t.Errorf("Text = %q, want %q", got.Text, tt.wantText)
+			}
+			if got.HTML != tt.wantHTML {
+				t.Errorf("HTML = %q, want %q", got.HTML, tt.wantHTML)
+			}
+		})
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	if _, err := Parse("no header"); err == nil {
+		t.Error("Parse() of a message without header succeeded, want error")
+	}
+}
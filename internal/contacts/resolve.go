@@ -0,0 +1,337 @@
+// Package contacts resolves the names people go by, e.g. "Maria from
+// finance", to email addresses. It draws on vCards and on the correspondents
+// of recently seen mail, ranks the candidates and keeps the evidence behind
+// each, so that an ambiguous name can be put to a choice instead of being
+// guessed.
+//
+// A candidate must match the query by name, nickname or the local part of its
+// address. Other words of the query may match the organization, title,
+// categories or domain instead. Candidates mail has been exchanged with
+// recently rank higher.
+package contacts
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/dastrobu/mail-mcp/internal/address"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Correspondent is an address seen in recent mail.
+type Correspondent struct {
+	Name     string    // display name, may be empty
+	Email    string    // address
+	Received int       // number of messages received from the address
+	Sent     int       // number of messages sent to the address
+	Last     time.Time // date of the latest of these messages
+}
+
+// Statuses of a Resolution
+const (
+	Resolved  = "resolved"
+	Ambiguous = "ambiguous"
+	NotFound  = "not_found"
+)
+
+// Resolution is the outcome of resolving a name.
+type Resolution struct {
+	Query      string      `json:"query"`
+	Status     string      `json:"status"`
+	Address    string      `json:"address,omitempty"` // the address the name resolved to
+	Candidates []Candidate `json:"candidates"`
+}
+
+// Candidate is an address a name may refer to.
+type Candidate struct {
+	Name     string   `json:"name,omitempty"`
+	Email    string   `json:"email"`
+	Address  string   `json:"address"` // name and email as the compose tools accept them
+	Score    int      `json:"score"`
+	Sources  []string `json:"sources"`
+	Evidence []string `json:"evidence"`
+
+	person    string // identifies the person, who may have several addresses
+	activity  int
+	unmatched int // words of the query the candidate does not match
+}
+
+// Resolver resolves names.
+type Resolver struct {
+	Contacts       []Contact
+	Correspondents []Correspondent
+	Now            time.Time // reference for recent mail, the current time if zero
+}
+
+// Scores of matching words of the query
+const (
+	scoreName      = 30 // a word of the name
+	scoreNickname  = 25
+	scoreNamePart  = 20 // the beginning of a word of the name, e.g. Max for Maximilian
+	scoreFullName  = 20 // bonus if the query is the name
+	scoreOrg       = 15 // organization, title or category
+	scoreEmail     = 10 // local part or domain of the address
+	scoreUnmatched = -10
+	scoreContact   = 10 // the address is in a vCard
+	scoreSecondary = -5 // not the preferred address of a vCard
+	scoreRecent    = 5  // bonus for mail within recentDays
+	maxActivity    = 10 // bonus for mail, one point per two messages
+
+	// margin is the lead the best candidate needs over the candidates of
+	// other people for a name to resolve.
+	margin = 20
+
+	recentDays = 30
+)
+
+// stopWords are ignored in queries, e.g. "from" in "Maria from finance".
+var stopWords = []string{"from", "at", "in", "of", "the", "and"}
+
+// entry is an address with what is known about it.
+type entry struct {
+	person        string
+	name          string
+	email         string
+	contact       *Contact
+	secondary     bool
+	correspondent *Correspondent
+}
+
+// entries merges the vCards and correspondents by address. Correspondents
+// with the name of a vCard are taken to be the same person.
+func (r *Resolver) entries() []*entry {
+	var entries []*entry
+	byEmail := map[string]*entry{}
+	persons := map[string]string{}
+	for i := range r.Contacts {
+		c := &r.Contacts[i]
+		person := fmt.Sprintf("vcard:%d", i)
+		if c.Name != "" {
+			persons[nameKey(c.Name)] = person
+		}
+		for n, email := range c.Emails {
+			a, err := address.Normalize(c.Name, email)
+			if err != nil || byEmail[strings.ToLower(a.Email)] != nil {
+				continue
+			}
+			e := &entry{person: person, name: a.Name, email: a.Email, contact: c, secondary: n > 0}
+			byEmail[strings.ToLower(a.Email)] = e
+			entries = append(entries, e)
+		}
+	}
+	for i := range r.Correspondents {
+		c := &r.Correspondents[i]
+		a, err := address.Normalize(c.Name, c.Email)
+		if err != nil {
+			continue
+		}
+		key := strings.ToLower(a.Email)
+		if e := byEmail[key]; e != nil {
+			e.correspondent = c
+			if e.name == "" {
+				e.name = a.Name
+			}
+			continue
+		}
+		person, ok := persons[nameKey(a.Name)]
+		if !ok || a.Name == "" {
+			person = "mail:" + key
+		}
+		e := &entry{person: person, name: a.Name, email: a.Email, correspondent: c}
+		byEmail[key] = e
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// Resolve resolves query to an address. It resolves if the best candidate
+// matches every word of the query and is ahead of the candidates of other
+// people by a clear margin; otherwise the candidates are a choice. At most
+// limit candidates are returned, all if limit is not positive.
+func (r *Resolver) Resolve(query string, limit int) Resolution {
+	res := Resolution{Query: query, Status: NotFound, Candidates: []Candidate{}}
+	words := slices.DeleteFunc(tokens(query), func(w string) bool { return slices.Contains(stopWords, w) })
+	if len(words) == 0 {
+		return res
+	}
+	now := r.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	var candidates []Candidate
+	for _, e := range r.entries() {
+		if c, ok := e.match(words, now); ok {
+			candidates = append(candidates, c)
+		}
+	}
+	if len(candidates) == 0 {
+		return res
+	}
+	slices.SortStableFunc(candidates, func(a, b Candidate) int {
+		return cmp.Or(
+			cmp.Compare(b.Score, a.Score),
+			cmp.Compare(b.activity, a.activity),
+			cmp.Compare(a.Name, b.Name),
+			cmp.Compare(a.Email, b.Email),
+		)
+	})
+
+	best := candidates[0]
+	res.Status = Resolved
+	if best.unmatched > 0 {
+		res.Status = Ambiguous
+	}
+	for _, c := range candidates[1:] {
+		if c.person != best.person && best.Score-c.Score < margin {
+			res.Status = Ambiguous
+			break
+		}
+	}
+	if res.Status == Resolved {
+		res.Address = best.Address
+	}
+	if limit > 0 && len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	res.Candidates = candidates
+	return res
+}
+
+// match scores the entry for the words of a query. Entries that match
+// neither by name nor by address are no candidates.
+func (e *entry) match(words []string, now time.Time) (Candidate, bool) {
+	c := Candidate{
+		Name:     e.name,
+		Email:    e.email,
+		Address:  address.Address{Name: e.name, Email: e.email}.String(),
+		Sources:  []string{},
+		Evidence: []string{},
+		person:   e.person,
+	}
+	local, domain, _ := strings.Cut(e.email, "@")
+	nameWords := tokens(e.name)
+	var nicknames, orgWords []string
+	if e.contact != nil {
+		for _, n := range e.contact.Nicknames {
+			nicknames = append(nicknames, tokens(n)...)
+		}
+		orgWords = tokens(strings.Join(append([]string{e.contact.Organization, e.contact.Title}, e.contact.Categories...), " "))
+	}
+
+	identified := false
+	for _, w := range words {
+		switch {
+		case slices.Contains(nameWords, w):
+			c.Score += scoreName
+			c.Evidence = append(c.Evidence, fmt.Sprintf("name matches %q", w))
+			identified = true
+		case slices.Contains(nicknames, w):
+			c.Score += scoreNickname
+			c.Evidence = append(c.Evidence, fmt.Sprintf("nickname matches %q", w))
+			identified = true
+		case hasPrefix(nameWords, w):
+			c.Score += scoreNamePart
+			c.Evidence = append(c.Evidence, fmt.Sprintf("name begins with %q", w))
+			identified = true
+		case hasPrefix(orgWords, w):
+			c.Score += scoreOrg
+			c.Evidence = append(c.Evidence, fmt.Sprintf("organization, title or category matches %q", w))
+		case hasPrefix(tokens(local), w):
+			c.Score += scoreEmail
+			c.Evidence = append(c.Evidence, fmt.Sprintf("address matches %q", w))
+			identified = true
+		case slices.Contains(tokens(domain), w):
+			c.Score += scoreEmail
+			c.Evidence = append(c.Evidence, fmt.Sprintf("domain matches %q", w))
+		default:
+			c.Score += scoreUnmatched
+			c.Evidence = append(c.Evidence, fmt.Sprintf("nothing matches %q", w))
+			c.unmatched++
+		}
+	}
+	if !identified {
+		return Candidate{}, false
+	}
+	if slices.Equal(nameWords, words) {
+		c.Score += scoreFullName
+		c.Evidence = append(c.Evidence, "full name matches")
+	}
+
+	if e.contact != nil {
+		c.Sources = append(c.Sources, "vcard")
+		c.Score += scoreContact
+		c.Evidence = append(c.Evidence, "vCard "+describe(e.contact))
+		if e.secondary {
+			c.Score += scoreSecondary
+			c.Evidence = append(c.Evidence, "not the preferred address of the vCard")
+		}
+	}
+	if m := e.correspondent; m != nil {
+		c.Sources = append(c.Sources, "mail")
+		if m.Received > 0 {
+			c.Evidence = append(c.Evidence, fmt.Sprintf("received %d recent messages from this address", m.Received))
+		}
+		if m.Sent > 0 {
+			c.Evidence = append(c.Evidence, fmt.Sprintf("sent %d recent messages to this address", m.Sent))
+		}
+		// Mail sent to an address says more than mail received from it
+		c.activity = m.Received + 2*m.Sent
+		c.Score += min(c.activity/2, maxActivity)
+		if !m.Last.IsZero() {
+			c.Evidence = append(c.Evidence, "last message on "+m.Last.Format(time.DateOnly))
+			if now.Sub(m.Last) <= recentDays*24*time.Hour {
+				c.Score += scoreRecent
+			}
+		}
+	}
+	return c, true
+}
+
+// describe summarizes a vCard for the evidence of a candidate, e.g.
+// "Maria Garcia (ACME, Finance; Controller) in contacts.vcf".
+func describe(c *Contact) string {
+	var details []string
+	for _, d := range []string{c.Organization, c.Title} {
+		if d != "" {
+			details = append(details, d)
+		}
+	}
+	s := c.Name
+	if len(details) > 0 {
+		s += " (" + strings.Join(details, "; ") + ")"
+	}
+	if c.Source != "" {
+		s += " in " + c.Source
+	}
+	return strings.TrimSpace(s)
+}
+
+func hasPrefix(words []string, prefix string) bool {
+	return slices.ContainsFunc(words, func(w string) bool { return strings.HasPrefix(w, prefix) })
+}
+
+// tokens splits s into lower case words without diacritics, so that José
+// matches jose.
+func tokens(s string) []string {
+	return strings.FieldsFunc(fold(s), func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+}
+
+// nameKey identifies people with the same name.
+func nameKey(name string) string {
+	return strings.Join(tokens(name), " ")
+}
+
+func fold(s string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(strings.ToLower(s)) {
+		if !unicode.Is(unicode.Mn, r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,81 @@
+package contacts
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestResolver() *Resolver {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	return &Resolver{
+		Contacts: []Contact{
+			{Name: "Maria Garcia", Emails: []string{"maria.garcia@acme.example", "maria@home.example"}, Organization: "ACME, Finance", Source: "maria.vcf"},
+			{Name: "María Rossi", Emails: []string{"m.rossi@acme.example"}, Organization: "ACME, Sales"},
+			{Name: "Robert Smith", Nicknames: []string{"Bob"}, Emails: []string{"bob@acme.example"}},
+		},
+		Correspondents: []Correspondent{
+			{Name: "Maria Garcia", Email: "maria.garcia@acme.example", Received: 12, Sent: 3, Last: now.AddDate(0, 0, -2)},
+			{Name: "Robert Smith", Email: "bob@old.example", Received: 1, Last: now.AddDate(0, -6, 0)},
+			{Name: "Max Mustermann", Email: "max@example.com", Received: 2, Last: now.AddDate(0, 0, -1)},
+		},
+		Now: now,
+	}
+}
+
+func TestResolve(t *testing.T) {
+	r := newTestResolver()
+	tests := []struct {
+		query   string
+		status  string
+		address string
+	}{
+		{"Maria from finance", Resolved, "Maria Garcia <maria.garcia@acme.example>"},
+		{"maria garcia", Resolved, "Maria Garcia <maria.garcia@acme.example>"},
+		{"Bob", Resolved, "Robert Smith <bob@acme.example>"},
+		{"Max", Resolved, "Max Mustermann <max@example.com>"},
+		{"Maria", Ambiguous, ""},
+		{"Maria from legal", Ambiguous, ""},
+		{"Nobody", NotFound, ""},
+		{" from ", NotFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			res := r.Resolve(tt.query, 0)
+			if res.Status != tt.status || res.Address != tt.address {
+				t.Errorf("Resolve(%q) = %s %q, want %s %q; candidates %+v", tt.query, res.Status, res.Address, tt.status, tt.address, res.Candidates)
+			}
+		})
+	}
+}
+
+func TestResolve_Candidates(t *testing.T) {
+	res := newTestResolver().Resolve("maria", 0)
+	var emails []string
+	for _, c := range res.Candidates {
+		emails = append(emails, c.Email)
+	}
+	want := []string{"maria.garcia@acme.example", "m.rossi@acme.example", "maria@home.example"}
+	if !slices.Equal(emails, want) {
+		t.Fatalf("candidates = %v, want %v", emails, want)
+	}
+
+	best := res.Candidates[0]
+	if !slices.Equal(best.Sources, []string{"vcard", "mail"}) {
+		t.Errorf("Sources = %v, want vcard and mail", best.Sources)
+	}
+	evidence := strings.Join(best.Evidence, "\n")
+	for _, want := range []string{`name matches "maria"`, "vCard Maria Garcia (ACME, Finance) in maria.vcf", "received 12", "sent 3", "last message on 2026-09-29"} {
+		if !strings.Contains(evidence, want) {
+			t.Errorf("Evidence = %q, want %q", best.Evidence, want)
+		}
+	}
+	if !strings.Contains(strings.Join(res.Candidates[2].Evidence, "\n"), "not the preferred address") {
+		t.Errorf("Evidence = %q, want the secondary address noted", res.Candidates[2].Evidence)
+	}
+
+	if res := newTestResolver().Resolve("maria", 1); len(res.Candidates) != 1 {
+		t.Errorf("Resolve(limit 1) = %d candidates, want 1", len(res.Candidates))
+	}
+}
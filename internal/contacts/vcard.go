@@ -0,0 +1,288 @@
+package contacts
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime/quotedprintable"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Contact is a person from a vCard.
+type Contact struct {
+	Name         string   `json:"name"`
+	Nicknames    []string `json:"nicknames,omitempty"`
+	Emails       []string `json:"emails"`
+	Organization string   `json:"organization,omitempty"`
+	Title        string   `json:"title,omitempty"`
+	Categories   []string `json:"categories,omitempty"`
+	Source       string   `json:"source,omitempty"`
+}
+
+// LoadError is a vCard file that could not be read.
+type LoadError struct {
+	Path  string `json:"path"`
+	Error string `json:"error"`
+}
+
+const ext = ".vcf"
+
+// Load loads the contacts of a vCard file, e.g. an export of the Contacts
+// app, or of all .vcf files in a directory. path may not exist. Files in a
+// directory that cannot be parsed are returned as LoadErrors.
+func Load(path string) ([]Contact, []LoadError, error) {
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []Contact{}, []LoadError{}, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read contacts: %w", err)
+	}
+	if !info.IsDir() {
+		contacts, err := loadFile(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		return contacts, []LoadError{}, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read contacts directory: %w", err)
+	}
+	contacts := []Contact{}
+	loadErrors := []LoadError{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || !strings.EqualFold(filepath.Ext(name), ext) {
+			continue
+		}
+		file := filepath.Join(path, name)
+		loaded, err := loadFile(file)
+		if err != nil {
+			loadErrors = append(loadErrors, LoadError{Path: file, Error: err.Error()})
+			continue
+		}
+		contacts = append(contacts, loaded...)
+	}
+	return contacts, loadErrors, nil
+}
+
+func loadFile(path string) ([]Contact, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read contacts: %w", err)
+	}
+	contacts, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for i := range contacts {
+		contacts[i].Source = path
+	}
+	return contacts, nil
+}
+
+// property is a content line of a vCard, e.g.
+// item1.EMAIL;TYPE=INTERNET,pref:maria@example.com.
+type property struct {
+	name   string
+	params map[string][]string
+	value  string
+}
+
+// Parse parses the vCards (versions 2.1, 3.0 and 4.0) in data. Only the
+// properties needed to find people are kept. Cards without an email address
+// are skipped.
+func Parse(data []byte) ([]Contact, error) {
+	contacts := []Contact{}
+	var card *Contact
+	var preferred int
+	for n, line := range unfold(string(data)) {
+		p, ok := parseLine(line)
+		if !ok {
+			continue
+		}
+		switch {
+		case p.name == "BEGIN" && strings.EqualFold(p.value, "VCARD"):
+			if card != nil {
+				return nil, fmt.Errorf("line %d: vCard begins before the previous one ends", n+1)
+			}
+			card, preferred = &Contact{Emails: []string{}}, 0
+		case card == nil:
+			continue
+		case p.name == "END" && strings.EqualFold(p.value, "VCARD"):
+			if len(card.Emails) > 0 {
+				contacts = append(contacts, *card)
+			}
+			card = nil
+		default:
+			card.set(p, &preferred)
+		}
+	}
+	if card != nil {
+		return nil, errors.New("vCard is not terminated by END:VCARD")
+	}
+	return contacts, nil
+}
+
+// set applies p to the contact. Preferred email addresses are kept in front
+// of the others, preferred counts them.
+func (c *Contact) set(p property, preferred *int) {
+	switch p.name {
+	case "FN":
+		c.Name = clean(unescape(p.value))
+	case "N":
+		if c.Name != "" {
+			return
+		}
+		// Family;Given;Additional;Prefix;Suffix
+		parts := splitValue(p.value, ';')
+		for len(parts) < 3 {
+			parts = append(parts, "")
+		}
+		c.Name = clean(strings.Join([]string{parts[1], parts[2], parts[0]}, " "))
+	case "EMAIL":
+		email := strings.TrimPrefix(strings.TrimSpace(unescape(p.value)), "mailto:")
+		if email == "" || slices.Contains(c.Emails, email) {
+			return
+		}
+		if p.preferred() {
+			c.Emails = slices.Insert(c.Emails, *preferred, email)
+			*preferred++
+		} else {
+			c.Emails = append(c.Emails, email)
+		}
+	case "NICKNAME":
+		for _, nickname := range splitValue(p.value, ',') {
+			if nickname = clean(nickname); nickname != "" {
+				c.Nicknames = append(c.Nicknames, nickname)
+			}
+		}
+	case "ORG":
+		// Organization name followed by units, e.g. ACME;Finance
+		var units []string
+		for _, unit := range splitValue(p.value, ';') {
+			if unit = clean(unit); unit != "" {
+				units = append(units, unit)
+			}
+		}
+		c.Organization = strings.Join(units, ", ")
+	case "TITLE":
+		c.Title = clean(unescape(p.value))
+	case "CATEGORIES":
+		for _, category := range splitValue(p.value, ',') {
+			if category = clean(category); category != "" {
+				c.Categories = append(c.Categories, category)
+			}
+		}
+	}
+}
+
+// preferred reports whether the property is marked as preferred, e.g. by
+// TYPE=pref (3.0), PREF=1 (4.0) or a bare PREF parameter (2.1).
+func (p property) preferred() bool {
+	if pref, ok := p.params["PREF"]; ok {
+		return pref[0] == "1" || strings.EqualFold(pref[0], "PREF")
+	}
+	return slices.ContainsFunc(p.params["TYPE"], func(t string) bool { return strings.EqualFold(t, "pref") })
+}
+
+// unfold splits data into content lines, joining folded lines and the soft
+// line breaks of quoted-printable values.
+func unfold(data string) []string {
+	data = strings.ReplaceAll(strings.TrimPrefix(data, "\ufeff"), "\r\n", "\n")
+	var lines []string
+	for _, line := range strings.Split(data, "\n") {
+		if len(lines) > 0 {
+			last := &lines[len(lines)-1]
+			switch {
+			case strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t"):
+				*last += line[1:]
+				continue
+			case strings.HasSuffix(*last, "=") && strings.Contains(strings.ToUpper(*last), "QUOTED-PRINTABLE"):
+				*last += "\n" + line
+				continue
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// parseLine parses a content line. Lines that are not properties are
+// ignored.
+func parseLine(line string) (property, bool) {
+	colon := -1
+	quoted := false
+	for i, r := range line {
+		if r == '"' {
+			quoted = !quoted
+		} else if r == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon <= 0 {
+		return property{}, false
+	}
+	p := property{params: map[string][]string{}, value: line[colon+1:]}
+	fields := strings.Split(line[:colon], ";")
+	p.name = strings.ToUpper(fields[0])
+	if dot := strings.LastIndexByte(p.name, '.'); dot >= 0 {
+		p.name = p.name[dot+1:]
+	}
+	for _, param := range fields[1:] {
+		key, value, ok := strings.Cut(param, "=")
+		if !ok {
+			// vCard 2.1 parameters are bare types, e.g. EMAIL;INTERNET;PREF
+			key, value = "TYPE", param
+			if strings.EqualFold(param, "PREF") {
+				key = "PREF"
+			}
+		}
+		key = strings.ToUpper(key)
+		for v := range strings.SplitSeq(strings.Trim(value, `"`), ",") {
+			p.params[key] = append(p.params[key], v)
+		}
+	}
+	if slices.ContainsFunc(p.params["ENCODING"], func(e string) bool { return strings.EqualFold(e, "QUOTED-PRINTABLE") }) {
+		decoded, err := io.ReadAll(quotedprintable.NewReader(strings.NewReader(strings.ReplaceAll(p.value, "=\n", ""))))
+		if err == nil {
+			p.value = string(decoded)
+		}
+	}
+	return p, true
+}
+
+// splitValue splits a structured value at unescaped separators and
+// unescapes the parts.
+func splitValue(value string, sep byte) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			i++
+		case sep:
+			parts = append(parts, unescape(value[start:i]))
+			start = i + 1
+		}
+	}
+	return append(parts, unescape(value[start:]))
+}
+
+var unescaper = strings.NewReplacer(`\\`, `\`, `\,`, `,`, `\;`, `;`, `\:`, `:`, `\n`, "\n", `\N`, "\n")
+
+func unescape(value string) string {
+	return unescaper.Replace(value)
+}
+
+// clean collapses white space.
+func clean(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
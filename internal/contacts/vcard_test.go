@@ -0,0 +1,97 @@
+package contacts
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const cards = "BEGIN:VCARD\r\n" +
+	"VERSION:3.0\r\n" +
+	"N:Garcia;Maria;;;\r\n" +
+	"FN:Maria Garcia\r\n" +
+	"NICKNAME:Mari\r\n" +
+	"ORG:ACME\\, Inc.;Finance\r\n" +
+	"TITLE:Controller\r\n" +
+	"item1.EMAIL;type=INTERNET:maria@home.example\r\n" +
+	"EMAIL;type=INTERNET;type=WORK;type=pref:maria.garcia@acme.ex\r\n" +
+	" ample\r\n" +
+	"CATEGORIES:Work,Budget\r\n" +
+	"END:VCARD\r\n" +
+	"BEGIN:VCARD\r\n" +
+	"VERSION:2.1\r\n" +
+	"N;ENCODING=QUOTED-PRINTABLE;CHARSET=UTF-8:M=C3=BCller;J=C3=\r\n" +
+	"=BCrgen\r\n" +
+	"EMAIL;INTERNET;PREF:juergen@example.com\r\n" +
+	"END:VCARD\r\n" +
+	"BEGIN:VCARD\r\n" +
+	"VERSION:4.0\r\n" +
+	"FN:No Address\r\n" +
+	"TEL:+1 555 0100\r\n" +
+	"END:VCARD\r\n"
+
+func TestParse(t *testing.T) {
+	contacts, err := Parse([]byte(cards))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(contacts) != 2 {
+		t.Fatalf("Parse() = %+v, want 2 contacts with addresses", contacts)
+	}
+
+	maria := contacts[0]
+	if maria.Name != "Maria Garcia" || maria.Organization != "ACME, Inc., Finance" || maria.Title != "Controller" {
+		t.Errorf("Parse() = %+v, want name, organization and title", maria)
+	}
+	if !slices.Equal(maria.Emails, []string{"maria.garcia@acme.example", "maria@home.example"}) {
+		t.Errorf("Emails = %v, want the unfolded preferred address first", maria.Emails)
+	}
+	if !slices.Equal(maria.Nicknames, []string{"Mari"}) || !slices.Equal(maria.Categories, []string{"Work", "Budget"}) {
+		t.Errorf("Parse() = %+v, want nicknames and categories", maria)
+	}
+
+	if juergen := contacts[1]; juergen.Name != "Jürgen Müller" {
+		t.Errorf("Name = %q, want the decoded quoted-printable N", juergen.Name)
+	}
+
+	if _, err := Parse([]byte("BEGIN:VCARD\nFN:Bob\n")); err == nil || !strings.Contains(err.Error(), "END:VCARD") {
+		t.Errorf("Parse() error = %v, want unterminated vCard", err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"maria.vcf":  cards,
+		"broken.VCF": "BEGIN:VCARD\nFN:Bob\n",
+		"notes.txt":  "BEGIN:VCARD\nFN:Bob\nEMAIL:bob@example.com\nEND:VCARD\n",
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	contacts, loadErrors, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(contacts) != 2 || contacts[0].Source != filepath.Join(dir, "maria.vcf") {
+		t.Errorf("Load() contacts = %+v, want those of maria.vcf", contacts)
+	}
+	if len(loadErrors) != 1 || loadErrors[0].Path != filepath.Join(dir, "broken.VCF") {
+		t.Errorf("Load() errors = %+v, want broken.VCF", loadErrors)
+	}
+
+	if contacts, _, err := Load(filepath.Join(dir, "maria.vcf")); err != nil || len(contacts) != 2 {
+		t.Errorf("Load(file) = %v, %v, want 2 contacts", contacts, err)
+	}
+	if _, _, err := Load(filepath.Join(dir, "broken.VCF")); err == nil {
+		t.Error("Load(broken file) succeeded, want error")
+	}
+	if contacts, _, err := Load(filepath.Join(dir, "missing")); err != nil || len(contacts) != 0 {
+		t.Errorf("Load(missing) = %v, %v, want no contacts", contacts, err)
+	}
+}
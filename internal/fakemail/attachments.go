@@ -0,0 +1,101 @@
+package fakemail
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+
+	"github.com/dastrobu/mail-mcp/internal/jxa"
+)
+
+type messageInput struct {
+	Account     string   `json:"account"`
+	MailboxPath []string `json:"mailboxPath"`
+	MessageID   int      `json:"message_id"`
+}
+
+// message looks up the message addressed by in.
+func (m *Mail) message(in messageInput) (*Message, *jxa.Result) {
+	mb, errResult := m.lookupMailbox(in.Account, in.MailboxPath)
+	if errResult != nil {
+		return nil, errResult
+	}
+	msg := mb.message(in.MessageID)
+	if msg == nil {
+		r := failure("Message with ID %d not found in mailbox %q. The message may have been deleted or moved.", in.MessageID, joinPath(in.MailboxPath))
+		return nil, &r
+	}
+	return msg, nil
+}
+
+func attachmentInfo(i int, att Attachment) map[string]any {
+	return map[string]any{
+		"id":         strconv.Itoa(i + 1),
+		"name":       att.Name,
+		"mimeType":   nullIfEmpty(att.MIMEType),
+		"size":       att.size(),
+		"downloaded": att.Downloaded,
+	}
+}
+
+func (m *Mail) listAttachments(args []string) jxa.Result {
+	var in messageInput
+	if err := decodeArgs(args, &in); err != nil {
+		return errParseArgs
+	}
+	msg, errResult := m.message(in)
+	if errResult != nil {
+		return *errResult
+	}
+	attachments := []map[string]any{}
+	for i, att := range msg.Attachments {
+		attachments = append(attachments, attachmentInfo(i, att))
+	}
+	return success(map[string]any{
+		"message_id":  msg.ID,
+		"attachments": attachments,
+		"count":       len(attachments),
+	})
+}
+
+var unsafeFileNameChars = regexp.MustCompile(`[/:]`)
+var leadingDots = regexp.MustCompile(`^\.+`)
+
+func (m *Mail) saveAttachment(args []string) jxa.Result {
+	var in struct {
+		messageInput
+		AttachmentID string `json:"attachment_id"`
+		Directory    string `json:"directory"`
+	}
+	if err := decodeArgs(args, &in); err != nil {
+		return errParseArgs
+	}
+	if in.Directory == "" {
+		return failure("Directory is required")
+	}
+	msg, errResult := m.message(in.messageInput)
+	if errResult != nil {
+		return *errResult
+	}
+	i, err := strconv.Atoi(in.AttachmentID)
+	if err != nil || i < 1 || i > len(msg.Attachments) {
+		return failure("Attachment with ID %q not found in message %d. Use list_attachments to get attachment IDs.", in.AttachmentID, msg.ID)
+	}
+	att := msg.Attachments[i-1]
+	if !att.Downloaded {
+		return failure("Attachment %q is not downloaded yet. Open the message in Mail.app to download it.", att.Name)
+	}
+
+	name := leadingDots.ReplaceAllString(unsafeFileNameChars.ReplaceAllString(att.Name, "_"), "_")
+	if name == "" {
+		name = "attachment"
+	}
+	path := filepath.Join(in.Directory, name)
+	if err := os.WriteFile(path, att.Content, 0o644); err != nil {
+		return failure("Failed to save attachment: %v", err)
+	}
+	info := attachmentInfo(i-1, att)
+	info["path"] = path
+	return success(info)
+}
@@ -0,0 +1,112 @@
+package fakemail
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dastrobu/mail-mcp/internal/jxa"
+)
+
+// correspondent counts the messages of a sender or recipient like the
+// list_correspondents script.
+type correspondent struct {
+	entry map[string]any
+	count int
+	last  time.Time
+}
+
+func (m *Mail) listCorrespondents(args []string) jxa.Result {
+	var in struct {
+		Since string `json:"since"`
+		Limit int    `json:"limit"`
+	}
+	if err := decodeArgs(args, &in); err != nil {
+		return errParseArgs
+	}
+	since, err := time.Parse(time.RFC3339, in.Since)
+	if err != nil {
+		return failure("since is required and must be an ISO 8601 date")
+	}
+	if in.Limit == 0 {
+		in.Limit = 1000
+	}
+
+	senders := map[string]*correspondent{}
+	recipients := map[string]*correspondent{}
+	count := func(index map[string]*correspondent, key string, entry map[string]any, date time.Time) {
+		c := index[key]
+		if c == nil {
+			c = &correspondent{entry: entry, last: date}
+			index[key] = c
+		}
+		c.count++
+		if date.After(c.last) {
+			c.last = date
+		}
+	}
+	searched := []map[string]any{}
+	messageCount := 0
+	truncated := false
+	for _, account := range m.accounts {
+		for _, mb := range account.allMailboxes() {
+			path := mb.Path()
+			received := inboxName.MatchString(path[0])
+			if !received && !sentName.MatchString(path[0]) {
+				continue
+			}
+			date := func(msg *Message) time.Time {
+				if received {
+					return msg.DateReceived
+				}
+				return msg.DateSent
+			}
+			var recent []*Message
+			for _, msg := range mb.Messages {
+				if !date(msg).IsZero() && !date(msg).Before(since) {
+					recent = append(recent, msg)
+				}
+			}
+			slices.SortStableFunc(recent, func(a, b *Message) int { return date(b).Compare(date(a)) })
+			if len(recent) > in.Limit {
+				recent, truncated = recent[:in.Limit], true
+			}
+			if len(recent) == 0 {
+				continue
+			}
+			for _, msg := range recent {
+				if received {
+					if msg.Sender != "" {
+						count(senders, msg.Sender, map[string]any{"sender": msg.Sender}, date(msg))
+					}
+					continue
+				}
+				for _, r := range append(slices.Clone(msg.To), msg.Cc...) {
+					count(recipients, strings.ToLower(r.Address), map[string]any{"name": r.Name, "address": r.Address}, date(msg))
+				}
+			}
+			messageCount += len(recent)
+			searched = append(searched, map[string]any{"account": account.Name, "mailboxPath": path})
+		}
+	}
+
+	return success(map[string]any{
+		"senders":      correspondentList(senders),
+		"recipients":   correspondentList(recipients),
+		"messageCount": messageCount,
+		"searched":     searched,
+		"truncated":    truncated,
+	})
+}
+
+func correspondentList(index map[string]*correspondent) []map[string]any {
+	list := []map[string]any{}
+	for _, key := range slices.Sorted(maps.Keys(index)) {
+		c := index[key]
+		c.entry["count"] = c.count
+		c.entry["last"] = isoTime(c.last)
+		list = append(list, c.entry)
+	}
+	return list
+}
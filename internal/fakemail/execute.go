@@ -0,0 +1,175 @@
+package fakemail
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dastrobu/mail-mcp/internal/jxa"
+)
+
+// handler answers one script. It runs with the Mail lock held and returns the
+// envelope the real script would have written.
+type handler func(m *Mail, args []string) jxa.Result
+
+var handlers = map[string]handler{
+	"list_accounts":            (*Mail).listAccounts,
+	"list_mailboxes":           (*Mail).listMailboxes,
+	"create_mailbox":           (*Mail).createMailbox,
+	"rename_mailbox":           (*Mail).renameMailbox,
+	"delete_mailbox":           (*Mail).deleteMailbox,
+	"get_message_content":      (*Mail).getMessageContent,
+	"get_message_source":       (*Mail).getMessageSource,
+	"find_messages":            (*Mail).findMessages,
+	"get_selected_messages":    (*Mail).getSelectedMessages,
+	"list_drafts":              (*Mail).listDrafts,
+	"delete_draft":             (*Mail).deleteDraft,
+	"list_outgoing_messages":   (*Mail).listOutgoingMessages,
+	"create_outgoing_message":  (*Mail).createOutgoingMessage,
+	"replace_outgoing_message": (*Mail).replaceOutgoingMessage,
+	"delete_outgoing_message":  (*Mail).deleteOutgoingMessage,
+	"send_outgoing_message":    (*Mail).sendOutgoingMessage,
+	"create_reply":             (*Mail).createReply,
+	"replace_reply":            (*Mail).replaceReply,
+	"create_forward":           (*Mail).createForward,
+	"replace_forward":          (*Mail).replaceForward,
+	"redirect_message":         (*Mail).redirectMessage,
+	"move_messages":            (*Mail).moveMessages,
+	"update_messages":          (*Mail).updateMessages,
+	"delete_messages":          (*Mail).deleteMessages,
+	"list_attachments":         (*Mail).listAttachments,
+	"save_attachment":          (*Mail).saveAttachment,
+	"get_thread":               (*Mail).getThread,
+	"list_rules":               (*Mail).listRules,
+	"create_rule":              (*Mail).createRule,
+	"update_rule":              (*Mail).updateRule,
+	"delete_rule":              (*Mail).deleteRule,
+	"list_signatures":          (*Mail).listSignatures,
+	"list_correspondents":      (*Mail).listCorrespondents,
+}
+
+// Execute answers the script identified by script.Name. The result goes
+// through jxa.ParseOutput, so errors look exactly like those of real scripts.
+func (m *Mail) Execute(ctx context.Context, script jxa.Script, args ...string) (any, error) {
+	output, err := json.Marshal(m.answer(script.Name, args))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal fake result: %w", err)
+	}
+	return jxa.ParseOutput(ctx, output, args)
+}
+
+func (m *Mail) answer(name string, args []string) jxa.Result {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.running {
+		return jxa.Result{
+			Error:     "Mail.app is not running. Please start Mail.app and try again.",
+			ErrorCode: jxa.ErrorCodeMailAppNotRunning,
+		}
+	}
+	h, ok := handlers[name]
+	if !ok {
+		return failure("fakemail: no handler for script %q", name)
+	}
+	return h(m, args)
+}
+
+// decodeArgs unmarshals the JSON argument passed as argv[0].
+func decodeArgs(args []string, v any) error {
+	if len(args) == 0 {
+		return nil
+	}
+	return json.Unmarshal([]byte(args[0]), v)
+}
+
+func success(data map[string]any) jxa.Result {
+	return jxa.Result{Success: true, Data: data}
+}
+
+func failure(format string, a ...any) jxa.Result {
+	return jxa.Result{Error: fmt.Sprintf(format, a...)}
+}
+
+var errParseArgs = failure("Failed to parse input arguments JSON")
+
+func (m *Mail) account(name string) *Account {
+	for _, a := range m.accounts {
+		if a.Name == name {
+			return a
+		}
+	}
+	return nil
+}
+
+// lookupMailbox resolves an account and mailbox path the way the scripts do,
+// returning the script's error envelope if either is missing.
+func (m *Mail) lookupMailbox(accountName string, path []string) (*Mailbox, *jxa.Result) {
+	if accountName == "" {
+		r := failure("Account name is required")
+		return nil, &r
+	}
+	if len(path) == 0 {
+		r := failure("Mailbox path is required and must be a non-empty array")
+		return nil, &r
+	}
+	account := m.account(accountName)
+	if account == nil {
+		r := failure("Account %q not found. Please verify the account name is correct.", accountName)
+		return nil, &r
+	}
+	mb := account.mailbox(path)
+	if mb == nil {
+		r := failure("Mailbox path '%s' not found in account '%s'.", joinPath(path), accountName)
+		return nil, &r
+	}
+	return mb, nil
+}
+
+func joinPath(path []string) string {
+	return strings.Join(path, " > ")
+}
+
+// isoTime formats t like JavaScript's Date.toISOString.
+func isoTime(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+	return t.UTC().Format("2006-01-02T15:04:05.000Z")
+}
+
+// preview shortens content to 100 characters like the scripts do.
+func preview(content string) string {
+	if utf8.RuneCountInString(content) <= 100 {
+		return content
+	}
+	return string([]rune(content)[:100]) + "..."
+}
+
+func addresses(recipients []Recipient) []string {
+	result := make([]string, len(recipients))
+	for i, r := range recipients {
+		result[i] = r.Address
+	}
+	return result
+}
+
+func recipientObjects(recipients []Recipient) []map[string]any {
+	result := make([]map[string]any, len(recipients))
+	for i, r := range recipients {
+		result[i] = map[string]any{"name": r.Name, "address": r.Address}
+	}
+	return result
+}
+
+// nextOffset returns the next_offset of a paginated result: end if there are
+// more results, null otherwise.
+func nextOffset(hasMore bool, end int) any {
+	if !hasMore {
+		return nil
+	}
+	return end
+}
@@ -0,0 +1,322 @@
+// Package fakemail implements an in-memory stand-in for Mail.app. It answers
+// the embedded JXA scripts by name with the same JSON envelope the scripts
+// write, so every tool can be exercised end-to-end without macOS.
+package fakemail
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Mail is an in-memory Mail.app holding accounts, mailboxes, messages and
+// outgoing messages. It implements jxa.Executor.
+type Mail struct {
+	mu         sync.Mutex
+	running    bool
+	accounts   []*Account
+	outgoing   []*OutgoingMessage
+	sent       []*OutgoingMessage
+	selected   []*Message
+	rules      []*Rule
+	signatures []Signature
+	nextID     int
+}
+
+// New returns an empty, running fake Mail.app.
+func New() *Mail {
+	return &Mail{running: true}
+}
+
+// SetRunning controls whether scripts see Mail.app as running. A stopped fake
+// answers every script with MAIL_APP_NOT_RUNNING.
+func (m *Mail) SetRunning(running bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.running = running
+}
+
+// AddAccount adds an enabled account with the given email addresses.
+func (m *Mail) AddAccount(name string, emailAddresses ...string) *Account {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	account := &Account{Name: name, Enabled: true, EmailAddresses: emailAddresses, mail: m}
+	m.accounts = append(m.accounts, account)
+	return account
+}
+
+// Select sets the messages returned by get_selected_messages.
+func (m *Mail) Select(messages ...*Message) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.selected = messages
+}
+
+// OutgoingMessages returns a snapshot of the open outgoing messages.
+func (m *Mail) OutgoingMessages() []OutgoingMessage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	result := make([]OutgoingMessage, len(m.outgoing))
+	for i, msg := range m.outgoing {
+		result[i] = *msg
+	}
+	return result
+}
+
+// SentMessages returns a snapshot of the outgoing messages that were sent.
+func (m *Mail) SentMessages() []OutgoingMessage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	result := make([]OutgoingMessage, len(m.sent))
+	for i, msg := range m.sent {
+		result[i] = *msg
+	}
+	return result
+}
+
+// newID returns the next free ID. Messages and outgoing messages share one
+// sequence so IDs never collide across kinds.
+func (m *Mail) newID() int {
+	m.nextID++
+	return m.nextID
+}
+
+// Account is a fake mail account.
+type Account struct {
+	Name           string
+	Enabled        bool
+	EmailAddresses []string
+	Mailboxes      []*Mailbox
+
+	mail *Mail
+}
+
+// AddMailbox returns the mailbox at path, creating any missing mailboxes on
+// the way.
+func (a *Account) AddMailbox(path ...string) *Mailbox {
+	a.mail.mu.Lock()
+	defer a.mail.mu.Unlock()
+	return a.addMailbox(path)
+}
+
+func (a *Account) addMailbox(path []string) *Mailbox {
+	var parent *Mailbox
+	children := &a.Mailboxes
+	for _, name := range path {
+		var next *Mailbox
+		for _, mb := range *children {
+			if mb.Name == name {
+				next = mb
+				break
+			}
+		}
+		if next == nil {
+			next = &Mailbox{Name: name, account: a, parent: parent}
+			*children = append(*children, next)
+		}
+		parent = next
+		children = &next.Mailboxes
+	}
+	return parent
+}
+
+// mailbox returns the mailbox at path or nil if it does not exist.
+func (a *Account) mailbox(path []string) *Mailbox {
+	if len(path) == 0 {
+		return nil
+	}
+	var current *Mailbox
+	children := a.Mailboxes
+	for _, name := range path {
+		current = nil
+		for _, mb := range children {
+			if mb.Name == name {
+				current = mb
+				break
+			}
+		}
+		if current == nil {
+			return nil
+		}
+		children = current.Mailboxes
+	}
+	return current
+}
+
+// detachMailbox removes mb from the children of its parent or the account.
+func (a *Account) detachMailbox(mb *Mailbox) {
+	siblings := &a.Mailboxes
+	if mb.parent != nil {
+		siblings = &mb.parent.Mailboxes
+	}
+	*siblings = slices.DeleteFunc(*siblings, func(other *Mailbox) bool { return other == mb })
+}
+
+// allMailboxes returns the mailboxes of the account and all their
+// sub-mailboxes, parents first.
+func (a *Account) allMailboxes() []*Mailbox {
+	var all []*Mailbox
+	for _, mb := range a.Mailboxes {
+		all = append(all, mb.withDescendants()...)
+	}
+	return all
+}
+
+// Mailbox is a fake mailbox. Mailboxes nest through Mailboxes.
+type Mailbox struct {
+	Name      string
+	Mailboxes []*Mailbox
+	Messages  []*Message
+
+	account *Account
+	parent  *Mailbox
+}
+
+// Path returns the mailbox path from the account root, e.g. ["Inbox", "GitHub"].
+func (mb *Mailbox) Path() []string {
+	var path []string
+	for current := mb; current != nil; current = current.parent {
+		path = append(path, current.Name)
+	}
+	slices.Reverse(path)
+	return path
+}
+
+// AddMessage stores msg in the mailbox, assigning it a fresh ID.
+func (mb *Mailbox) AddMessage(msg *Message) *Message {
+	mb.account.mail.mu.Lock()
+	defer mb.account.mail.mu.Unlock()
+	msg.ID = mb.account.mail.newID()
+	msg.mailbox = mb
+	mb.Messages = append(mb.Messages, msg)
+	return msg
+}
+
+// withDescendants returns mb followed by all its sub-mailboxes.
+func (mb *Mailbox) withDescendants() []*Mailbox {
+	all := []*Mailbox{mb}
+	for _, child := range mb.Mailboxes {
+		all = append(all, child.withDescendants()...)
+	}
+	return all
+}
+
+func (mb *Mailbox) unreadCount() int {
+	count := 0
+	for _, msg := range mb.Messages {
+		if !msg.ReadStatus {
+			count++
+		}
+	}
+	return count
+}
+
+func (mb *Mailbox) message(id int) *Message {
+	for _, msg := range mb.Messages {
+		if msg.ID == id {
+			return msg
+		}
+	}
+	return nil
+}
+
+func (mb *Mailbox) removeMessage(id int) {
+	mb.Messages = slices.DeleteFunc(mb.Messages, func(msg *Message) bool { return msg.ID == id })
+}
+
+// Message is a fake received message.
+type Message struct {
+	ID             int
+	Subject        string
+	Sender         string
+	ReplyTo        string
+	DateReceived   time.Time
+	DateSent       time.Time
+	Content        string
+	ReadStatus     bool
+	FlaggedStatus  bool
+	FlagIndex      int // flag color, only meaningful while FlaggedStatus is set
+	JunkMailStatus bool
+	WasRepliedTo   bool
+	WasForwarded   bool
+	MessageSize    int
+	MessageID      string
+	AllHeaders     string
+	Source         string // raw RFC 5322 source, AllHeaders and Content if empty
+	To             []Recipient
+	Cc             []Recipient
+	Bcc            []Recipient
+	Attachments    []Attachment
+
+	mailbox *Mailbox
+}
+
+// Mailbox returns the mailbox the message is stored in.
+func (msg *Message) Mailbox() *Mailbox {
+	return msg.mailbox
+}
+
+// flagIndex reports the flag color the way Mail.app does: -1 for unflagged
+// messages.
+func (msg *Message) flagIndex() int {
+	if !msg.FlaggedStatus {
+		return -1
+	}
+	return msg.FlagIndex
+}
+
+// source returns the raw source of the message like Message.source().
+func (msg *Message) source() string {
+	if msg.Source != "" {
+		return msg.Source
+	}
+	return strings.TrimRight(msg.AllHeaders, "\r\n") + "\n\n" + msg.Content
+}
+
+func (msg *Message) size() int {
+	if msg.MessageSize > 0 {
+		return msg.MessageSize
+	}
+	return len(msg.Content)
+}
+
+// Recipient is a message recipient.
+type Recipient struct {
+	Name    string
+	Address string
+}
+
+// Attachment is a message attachment. Its ID is its 1-based position in the
+// message's attachments.
+type Attachment struct {
+	Name       string
+	MIMEType   string // empty, as Mail.app often fails to report it
+	FileSize   int
+	Downloaded bool
+	Content    []byte
+}
+
+func (att Attachment) size() int {
+	if att.FileSize > 0 {
+		return att.FileSize
+	}
+	return len(att.Content)
+}
+
+// OutgoingMessage is an open compose window.
+type OutgoingMessage struct {
+	ID          int
+	Subject     string
+	Sender      string
+	Content     string
+	To          []string
+	Cc          []string
+	Bcc         []string
+	Attachments []string // paths of the attached files
+	Signature   string   // name of the Mail signature
+
+	// Forwarded or Redirected is the message the window was opened from
+	Forwarded  *Message
+	Redirected *Message
+}
@@ -0,0 +1,229 @@
+package fakemail
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/dastrobu/mail-mcp/internal/jxa"
+)
+
+func (m *Mail) listAccounts(args []string) jxa.Result {
+	var in struct {
+		Enabled bool `json:"enabled"`
+	}
+	if err := decodeArgs(args, &in); err != nil {
+		return errParseArgs
+	}
+
+	accounts := []map[string]any{}
+	for _, a := range m.accounts {
+		if in.Enabled && !a.Enabled {
+			continue
+		}
+		emailAddresses := a.EmailAddresses
+		if emailAddresses == nil {
+			emailAddresses = []string{}
+		}
+		accounts = append(accounts, map[string]any{
+			"name":           a.Name,
+			"enabled":        a.Enabled,
+			"emailAddresses": emailAddresses,
+			"mailboxCount":   len(a.Mailboxes),
+		})
+	}
+	return success(map[string]any{
+		"accounts": accounts,
+		"count":    len(accounts),
+	})
+}
+
+func (m *Mail) listMailboxes(args []string) jxa.Result {
+	var in struct {
+		Account     string   `json:"account"`
+		MailboxPath []string `json:"mailboxPath"`
+		Limit       int      `json:"limit"`
+		Offset      int      `json:"offset"`
+	}
+	if err := decodeArgs(args, &in); err != nil {
+		return errParseArgs
+	}
+	if in.Account == "" {
+		return failure("Account name is required")
+	}
+	account := m.account(in.Account)
+	if account == nil {
+		return failure("Account %q not found. Please verify the account name is correct.", in.Account)
+	}
+
+	source := account.Mailboxes
+	var parentPath any
+	if len(in.MailboxPath) > 0 {
+		parent := account.mailbox(in.MailboxPath)
+		if parent == nil {
+			return failure("Mailbox path '%s' not found in account '%s'.", joinPath(in.MailboxPath), in.Account)
+		}
+		source = parent.Mailboxes
+		parentPath = in.MailboxPath
+	}
+
+	end := len(source)
+	if in.Limit > 0 {
+		end = min(end, in.Offset+in.Limit)
+	}
+	mailboxes := []map[string]any{}
+	for _, mb := range source[min(in.Offset, end):end] {
+		mailboxes = append(mailboxes, map[string]any{
+			"name":            mb.Name,
+			"mailboxPath":     append(slices.Clone(in.MailboxPath), mb.Name),
+			"account":         in.Account,
+			"unreadCount":     mb.unreadCount(),
+			"messageCount":    len(mb.Messages),
+			"hasSubMailboxes": len(mb.Mailboxes) > 0,
+			"subMailboxCount": len(mb.Mailboxes),
+		})
+	}
+	return success(map[string]any{
+		"mailboxes":         mailboxes,
+		"count":             len(mailboxes),
+		"parentMailboxPath": parentPath,
+		"totalMailboxes":    len(source),
+		"offset":            in.Offset,
+		"hasMore":           len(source) > end,
+		"next_offset":       nextOffset(len(source) > end, end),
+	})
+}
+
+func (m *Mail) createMailbox(args []string) jxa.Result {
+	var in struct {
+		Account     string   `json:"account"`
+		MailboxPath []string `json:"mailboxPath"`
+	}
+	if err := decodeArgs(args, &in); err != nil {
+		return errParseArgs
+	}
+	if in.Account == "" {
+		return failure("Account name is required")
+	}
+	if len(in.MailboxPath) == 0 {
+		return failure("Mailbox path is required and must be a non-empty array")
+	}
+	account := m.account(in.Account)
+	if account == nil {
+		return failure("Account %q not found. Please verify the account name is correct.", in.Account)
+	}
+	if account.mailbox(in.MailboxPath) != nil {
+		return jxa.Result{
+			Error:     fmt.Sprintf("Mailbox path '%s' already exists in account '%s'.", joinPath(in.MailboxPath), in.Account),
+			ErrorCode: "MAILBOX_EXISTS",
+		}
+	}
+
+	createdPaths := [][]string{}
+	for i := range in.MailboxPath {
+		if account.mailbox(in.MailboxPath[:i+1]) == nil {
+			createdPaths = append(createdPaths, slices.Clone(in.MailboxPath[:i+1]))
+		}
+	}
+	account.addMailbox(in.MailboxPath)
+	return success(map[string]any{
+		"account":             in.Account,
+		"mailboxPath":         in.MailboxPath,
+		"name":                in.MailboxPath[len(in.MailboxPath)-1],
+		"createdMailboxPaths": createdPaths,
+	})
+}
+
+func (m *Mail) renameMailbox(args []string) jxa.Result {
+	var in struct {
+		Account        string   `json:"account"`
+		MailboxPath    []string `json:"mailboxPath"`
+		NewMailboxPath []string `json:"new_mailbox_path"`
+	}
+	if err := decodeArgs(args, &in); err != nil {
+		return errParseArgs
+	}
+	if len(in.NewMailboxPath) == 0 {
+		return failure("new_mailbox_path is required and must be a non-empty array")
+	}
+	mb, errResult := m.lookupMailbox(in.Account, in.MailboxPath)
+	if errResult != nil {
+		return *errResult
+	}
+	account := mb.account
+	if account.mailbox(in.NewMailboxPath) != nil {
+		return jxa.Result{
+			Error:     fmt.Sprintf("Mailbox path '%s' already exists in account '%s'.", joinPath(in.NewMailboxPath), in.Account),
+			ErrorCode: "MAILBOX_EXISTS",
+		}
+	}
+	parentPath := in.NewMailboxPath[:len(in.NewMailboxPath)-1]
+	var parent *Mailbox
+	if len(parentPath) > 0 {
+		if parent = account.mailbox(parentPath); parent == nil {
+			return jxa.Result{
+				Error:     fmt.Sprintf("Parent mailbox path '%s' not found in account '%s'. Create it with create_mailbox first.", joinPath(parentPath), in.Account),
+				ErrorCode: "MAILBOX_NOT_FOUND",
+			}
+		}
+	}
+
+	moved := mb.parent != parent
+	account.detachMailbox(mb)
+	mb.Name = in.NewMailboxPath[len(in.NewMailboxPath)-1]
+	mb.parent = parent
+	if parent == nil {
+		account.Mailboxes = append(account.Mailboxes, mb)
+	} else {
+		parent.Mailboxes = append(parent.Mailboxes, mb)
+	}
+	return success(map[string]any{
+		"account":             in.Account,
+		"previousMailboxPath": in.MailboxPath,
+		"mailboxPath":         in.NewMailboxPath,
+		"name":                mb.Name,
+		"moved":               moved,
+	})
+}
+
+func (m *Mail) deleteMailbox(args []string) jxa.Result {
+	var in struct {
+		Account     string   `json:"account"`
+		MailboxPath []string `json:"mailboxPath"`
+		Force       bool     `json:"force"`
+	}
+	if err := decodeArgs(args, &in); err != nil {
+		return errParseArgs
+	}
+	mb, errResult := m.lookupMailbox(in.Account, in.MailboxPath)
+	if errResult != nil {
+		return *errResult
+	}
+
+	messages := 0
+	subtree := mb.withDescendants()
+	for _, child := range subtree {
+		messages += len(child.Messages)
+	}
+	mailboxes := len(subtree) - 1
+	if !in.Force && (messages > 0 || mailboxes > 0) {
+		return jxa.Result{
+			Error: fmt.Sprintf("Mailbox '%s' is not empty: it contains %d message(s) and %d sub-mailbox(es). Move them first or set force to delete them with the mailbox.",
+				joinPath(in.MailboxPath), messages, mailboxes),
+			ErrorCode: "MAILBOX_NOT_EMPTY",
+		}
+	}
+
+	mb.account.detachMailbox(mb)
+	var parentPath any
+	if len(in.MailboxPath) > 1 {
+		parentPath = in.MailboxPath[:len(in.MailboxPath)-1]
+	}
+	return success(map[string]any{
+		"account":                in.Account,
+		"mailboxPath":            in.MailboxPath,
+		"parentMailboxPath":      parentPath,
+		"deleted":                true,
+		"deletedMessageCount":    messages,
+		"deletedSubMailboxCount": mailboxes,
+	})
+}
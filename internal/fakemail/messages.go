@@ -0,0 +1,500 @@
+package fakemail
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dastrobu/mail-mcp/internal/jxa"
+	"github.com/dastrobu/mail-mcp/internal/query"
+)
+
+// draftsMailboxName is the name of the per-account mailbox that stands in for
+// Mail.draftsMailbox().
+const draftsMailboxName = "Drafts"
+
+func (m *Mail) getMessageContent(args []string) jxa.Result {
+	var in struct {
+		Account     string   `json:"account"`
+		MailboxPath []string `json:"mailboxPath"`
+		MessageID   int      `json:"message_id"`
+		BodyFormat  string   `json:"body_format"`
+	}
+	if err := decodeArgs(args, &in); err != nil {
+		return errParseArgs
+	}
+	if in.MessageID < 1 {
+		return failure("Message ID is required and must be a positive integer")
+	}
+	mb, errResult := m.lookupMailbox(in.Account, in.MailboxPath)
+	if errResult != nil {
+		return *errResult
+	}
+	msg := mb.message(in.MessageID)
+	if msg == nil {
+		return failure("Message with ID %d not found in mailbox %q. The message may have been deleted or moved.", in.MessageID, joinPath(in.MailboxPath))
+	}
+
+	attachments := []map[string]any{}
+	for _, att := range msg.Attachments {
+		attachments = append(attachments, map[string]any{
+			"name":       att.Name,
+			"fileSize":   att.size(),
+			"downloaded": att.Downloaded,
+		})
+	}
+	result := map[string]any{
+		"id":            msg.ID,
+		"subject":       msg.Subject,
+		"sender":        msg.Sender,
+		"replyTo":       msg.ReplyTo,
+		"dateReceived":  isoTime(msg.DateReceived),
+		"dateSent":      isoTime(msg.DateSent),
+		"content":       msg.Content,
+		"readStatus":    msg.ReadStatus,
+		"flaggedStatus": msg.FlaggedStatus,
+		"messageSize":   msg.size(),
+		"messageId":     msg.MessageID,
+		"allHeaders":    msg.AllHeaders,
+		"toRecipients":  recipientObjects(msg.To),
+		"ccRecipients":  recipientObjects(msg.Cc),
+		"bccRecipients": recipientObjects(msg.Bcc),
+		"attachments":   attachments,
+	}
+	if in.BodyFormat != "" && in.BodyFormat != "plain" {
+		result["source"] = msg.source()
+	}
+	return success(map[string]any{"message": result})
+}
+
+func (m *Mail) getMessageSource(args []string) jxa.Result {
+	var in struct {
+		Account     string   `json:"account"`
+		MailboxPath []string `json:"mailboxPath"`
+		MessageIDs  []int    `json:"message_ids"`
+	}
+	if err := decodeArgs(args, &in); err != nil {
+		return errParseArgs
+	}
+	if len(in.MessageIDs) == 0 {
+		return failure("message_ids is required and must be a non-empty array")
+	}
+	mb, errResult := m.lookupMailbox(in.Account, in.MailboxPath)
+	if errResult != nil {
+		return *errResult
+	}
+
+	messages := []map[string]any{}
+	for _, id := range in.MessageIDs {
+		msg := mb.message(id)
+		if msg == nil {
+			messages = append(messages, map[string]any{
+				"id":    id,
+				"error": fmt.Sprintf("Message with ID %d not found in mailbox %q.", id, joinPath(in.MailboxPath)),
+			})
+			continue
+		}
+		messages = append(messages, map[string]any{
+			"id":           msg.ID,
+			"source":       msg.source(),
+			"subject":      msg.Subject,
+			"sender":       msg.Sender,
+			"dateReceived": isoTime(msg.DateReceived),
+		})
+	}
+	return success(map[string]any{"messages": messages})
+}
+
+func (m *Mail) findMessages(args []string) jxa.Result {
+	var in struct {
+		Account      string      `json:"account"`
+		MailboxPath  []string    `json:"mailboxPath"`
+		Accounts     []string    `json:"accounts"`
+		MailboxPaths [][]string  `json:"mailboxPaths"`
+		Recursive    bool        `json:"recursive"`
+		Subject      string      `json:"subject"`
+		Sender       string      `json:"sender"`
+		ReadStatus   *bool       `json:"readStatus"`
+		FlaggedOnly  bool        `json:"flaggedOnly"`
+		DateAfter    string      `json:"dateAfter"`
+		DateBefore   string      `json:"dateBefore"`
+		Limit        int         `json:"limit"`
+		Query        string      `json:"query"`
+		Filter       *query.Node `json:"filter"`
+		Offset       int         `json:"offset"`
+		BodyFormat   string      `json:"body_format"`
+		StripQuotes  bool        `json:"strip_quotes"`
+	}
+	if err := decodeArgs(args, &in); err != nil {
+		return errParseArgs
+	}
+	if in.Limit == 0 {
+		in.Limit = 50
+	}
+	if in.Limit < 1 || in.Limit > 1000 {
+		return failure("Limit must be between 1 and 1000")
+	}
+	mailboxes, notFound, errResult := m.searchScope(in.Account, in.MailboxPath, in.Accounts, in.MailboxPaths, in.Recursive)
+	if errResult != nil {
+		return *errResult
+	}
+
+	var matches []*Message
+	for _, mb := range mailboxes {
+		for _, msg := range mb.Messages {
+			if query.Match(in.Filter, queryMessage{msg}) {
+				matches = append(matches, msg)
+			}
+		}
+	}
+	// Newest first
+	slices.SortStableFunc(matches, func(a, b *Message) int { return b.DateReceived.Compare(a.DateReceived) })
+
+	end := min(len(matches), in.Offset+in.Limit)
+	messages := []map[string]any{}
+	for _, msg := range matches[min(in.Offset, end):end] {
+		messages = append(messages, withBody(map[string]any{
+			"id":              msg.ID,
+			"subject":         msg.Subject,
+			"sender":          msg.Sender,
+			"date_received":   isoTime(msg.DateReceived),
+			"date_sent":       isoTime(msg.DateSent),
+			"read_status":     msg.ReadStatus,
+			"flagged_status":  msg.FlaggedStatus,
+			"message_size":    msg.size(),
+			"content_preview": preview(msg.Content),
+			"content_length":  len(msg.Content),
+			"mailbox_path":    msg.mailbox.Path(),
+			"account":         msg.mailbox.account.Name,
+		}, msg, in.BodyFormat, in.StripQuotes))
+	}
+
+	var readStatus any
+	if in.ReadStatus != nil {
+		readStatus = *in.ReadStatus
+	}
+	return success(map[string]any{
+		"messages":            messages,
+		"count":               len(messages),
+		"total_matches":       len(matches),
+		"limit":               in.Limit,
+		"offset":              in.Offset,
+		"has_more":            len(matches) > end,
+		"next_offset":         nextOffset(len(matches) > end, end),
+		"mailboxes_searched":  len(mailboxes),
+		"mailboxes_not_found": notFound,
+		"filters_applied": map[string]any{
+			"subject":      nullIfEmpty(in.Subject),
+			"sender":       nullIfEmpty(in.Sender),
+			"read_status":  readStatus,
+			"flagged_only": in.FlaggedOnly,
+			"date_after":   nullIfEmpty(in.DateAfter),
+			"date_before":  nullIfEmpty(in.DateBefore),
+			"query":        nullIfEmpty(in.Query),
+		},
+	})
+}
+
+// searchScope resolves the mailboxes find_messages searches the way the
+// script does: accounts and mailboxPaths take precedence over account and
+// mailboxPath, and "*" matches all enabled accounts or all mailboxes.
+func (m *Mail) searchScope(account string, path []string, accounts []string, paths [][]string, recursive bool) ([]*Mailbox, []map[string]any, *jxa.Result) {
+	if len(accounts) == 0 && account != "" {
+		accounts = []string{account}
+	}
+	if len(paths) == 0 {
+		paths = [][]string{path}
+	}
+	if len(accounts) == 0 {
+		r := failure("Account name is required")
+		return nil, nil, &r
+	}
+	for _, p := range paths {
+		if len(p) == 0 {
+			r := failure("Mailbox path required")
+			return nil, nil, &r
+		}
+	}
+
+	var targets []*Account
+	if slices.Contains(accounts, "*") {
+		for _, a := range m.accounts {
+			if a.Enabled {
+				targets = append(targets, a)
+			}
+		}
+	} else {
+		for _, name := range accounts {
+			a := m.account(name)
+			if a == nil {
+				r := failure("Account %q not found.", name)
+				return nil, nil, &r
+			}
+			targets = append(targets, a)
+		}
+	}
+
+	var mailboxes []*Mailbox
+	notFound := []map[string]any{}
+	for _, a := range targets {
+		for _, p := range paths {
+			var found []*Mailbox
+			switch mb := a.mailbox(p); {
+			case len(p) == 1 && p[0] == "*":
+				found = a.allMailboxes()
+			case mb == nil:
+				notFound = append(notFound, map[string]any{"account": a.Name, "mailboxPath": p})
+			case recursive:
+				found = mb.withDescendants()
+			default:
+				found = []*Mailbox{mb}
+			}
+			for _, mb := range found {
+				if !slices.Contains(mailboxes, mb) {
+					mailboxes = append(mailboxes, mb)
+				}
+			}
+		}
+	}
+	if len(mailboxes) == 0 {
+		first := map[string]any{"account": accounts[0], "mailboxPath": paths[0]}
+		if len(notFound) > 0 {
+			first = notFound[0]
+		}
+		r := failure("Mailbox %q not found in account %q.", joinPath(first["mailboxPath"].([]string)), first["account"])
+		return nil, nil, &r
+	}
+	return mailboxes, notFound, nil
+}
+
+func (m *Mail) getSelectedMessages(args []string) jxa.Result {
+	var in struct {
+		Limit      int    `json:"limit"`
+		BodyFormat string `json:"body_format"`
+	}
+	if err := decodeArgs(args, &in); err != nil {
+		return errParseArgs
+	}
+	if in.Limit == 0 {
+		in.Limit = 5
+	}
+	if in.Limit < 1 {
+		return failure("Limit must be at least 1")
+	}
+	if in.Limit > 100 {
+		return failure("Limit cannot exceed 100")
+	}
+	if len(m.selected) == 0 {
+		return success(map[string]any{
+			"selectedMessagesCount": 0,
+			"messages":              []map[string]any{},
+		})
+	}
+
+	messages := []map[string]any{}
+	for _, msg := range m.selected[:min(len(m.selected), in.Limit)] {
+		messages = append(messages, withBody(map[string]any{
+			"id":             msg.ID,
+			"subject":        msg.Subject,
+			"sender":         msg.Sender,
+			"dateReceived":   isoTime(msg.DateReceived),
+			"dateSent":       isoTime(msg.DateSent),
+			"readStatus":     msg.ReadStatus,
+			"flaggedStatus":  msg.FlaggedStatus,
+			"junkMailStatus": msg.JunkMailStatus,
+			"mailbox":        msg.mailbox.Name,
+			"mailboxPath":    msg.mailbox.Path(),
+			"account":        msg.mailbox.account.Name,
+		}, msg, in.BodyFormat, false))
+	}
+	return success(map[string]any{
+		"messages": messages,
+		"count":    len(messages),
+	})
+}
+
+// withBody adds the content, and for html and markdown the source, to a
+// message of a list like the scripts do when a body_format is given or
+// quotes are stripped.
+func withBody(result map[string]any, msg *Message, bodyFormat string, stripQuotes bool) map[string]any {
+	if bodyFormat != "" || stripQuotes {
+		result["content"] = msg.Content
+	}
+	if bodyFormat != "" && bodyFormat != "plain" {
+		result["source"] = msg.source()
+	}
+	return result
+}
+
+// drafts returns all messages of the accounts' Drafts mailboxes.
+func (m *Mail) drafts() []*Message {
+	var result []*Message
+	for _, a := range m.accounts {
+		if mb := a.mailbox([]string{draftsMailboxName}); mb != nil {
+			result = append(result, mb.Messages...)
+		}
+	}
+	return result
+}
+
+func (m *Mail) listDrafts(args []string) jxa.Result {
+	var in struct {
+		Account string `json:"account"`
+		Limit   int    `json:"limit"`
+		Offset  int    `json:"offset"`
+	}
+	if err := decodeArgs(args, &in); err != nil {
+		return errParseArgs
+	}
+	if in.Limit == 0 {
+		in.Limit = 50
+	}
+	if in.Limit < 1 || in.Limit > 1000 {
+		return failure("Limit must be between 1 and 1000")
+	}
+	if in.Account != "" && m.account(in.Account) == nil {
+		return failure("Account %q not found. Please verify the account name is correct.", in.Account)
+	}
+
+	all := m.drafts()
+	drafts := []map[string]any{}
+	hasMore := false
+	matched := 0
+	for _, msg := range all {
+		accountName := msg.mailbox.account.Name
+		if in.Account != "" && accountName != in.Account {
+			continue
+		}
+		matched++
+		if matched <= in.Offset {
+			continue
+		}
+		if matched > in.Offset+in.Limit {
+			hasMore = true
+			break
+		}
+		drafts = append(drafts, map[string]any{
+			"draft_id":         msg.ID,
+			"subject":          msg.Subject,
+			"sender":           msg.Sender,
+			"date_received":    isoTime(msg.DateReceived),
+			"date_sent":        isoTime(msg.DateSent),
+			"content_preview":  preview(msg.Content),
+			"content_length":   len(msg.Content),
+			"to_recipients":    addresses(msg.To),
+			"cc_recipients":    addresses(msg.Cc),
+			"bcc_recipients":   addresses(msg.Bcc),
+			"to_count":         len(msg.To),
+			"cc_count":         len(msg.Cc),
+			"bcc_count":        len(msg.Bcc),
+			"total_recipients": len(msg.To) + len(msg.Cc) + len(msg.Bcc),
+			"mailbox":          msg.mailbox.Name,
+			"account":          accountName,
+		})
+	}
+	return success(map[string]any{
+		"drafts":       drafts,
+		"count":        len(drafts),
+		"total_drafts": len(all),
+		"limit":        in.Limit,
+		"offset":       in.Offset,
+		"has_more":     hasMore,
+		"next_offset":  nextOffset(hasMore, in.Offset+in.Limit),
+	})
+}
+
+func (m *Mail) deleteDraft(args []string) jxa.Result {
+	var in struct {
+		DraftID *int `json:"draft_id"`
+	}
+	if err := decodeArgs(args, &in); err != nil {
+		return errParseArgs
+	}
+	if in.DraftID == nil {
+		return jxa.Result{Error: "draft_id is required.", ErrorCode: "MISSING_PARAMETERS"}
+	}
+	for _, msg := range m.drafts() {
+		if msg.ID == *in.DraftID {
+			msg.mailbox.removeMessage(msg.ID)
+			return success(map[string]any{
+				"draft_id": msg.ID,
+				"subject":  msg.Subject,
+				"account":  msg.mailbox.account.Name,
+				"message":  "Draft deleted successfully.",
+			})
+		}
+	}
+	return failure("Draft with ID %d not found in the Drafts mailbox.", *in.DraftID)
+}
+
+func nullIfEmpty(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// queryMessage evaluates the filter of find_messages against a message the
+// way the script does against the bulk-fetched message properties.
+type queryMessage struct{ *Message }
+
+func (q queryMessage) Text(field string) string {
+	switch field {
+	case query.FieldSubject:
+		return q.Subject
+	case query.FieldSender:
+		return q.Sender
+	case query.FieldContent:
+		return q.Content
+	case query.FieldTo:
+		return recipientText(q.To)
+	case query.FieldCc:
+		return recipientText(q.Cc)
+	}
+	return ""
+}
+
+func (q queryMessage) Bool(field string) bool {
+	switch field {
+	case query.FieldReadStatus:
+		return q.ReadStatus
+	case query.FieldFlaggedStatus:
+		return q.FlaggedStatus
+	case query.FieldWasRepliedTo:
+		return q.WasRepliedTo
+	case query.FieldWasForwarded:
+		return q.WasForwarded
+	case query.FieldJunkMailStatus:
+		return q.JunkMailStatus
+	case query.FieldHasAttachment:
+		return len(q.Attachments) > 0
+	}
+	return false
+}
+
+func (q queryMessage) Number(field string) int64 {
+	switch field {
+	case query.FieldMessageSize:
+		return int64(q.size())
+	case query.FieldFlagIndex:
+		return int64(q.flagIndex())
+	}
+	return 0
+}
+
+func (q queryMessage) Time(field string) time.Time {
+	if field == query.FieldDateReceived {
+		return q.DateReceived
+	}
+	return time.Time{}
+}
+
+// recipientText joins the names and addresses of recipients.
+func recipientText(recipients []Recipient) string {
+	var parts []string
+	for _, r := range recipients {
+		parts = append(parts, r.Name, r.Address)
+	}
+	return strings.Join(parts, "\n")
+}
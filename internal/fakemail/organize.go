@@ -0,0 +1,216 @@
+package fakemail
+
+import (
+	"fmt"
+
+	"github.com/dastrobu/mail-mcp/internal/jxa"
+)
+
+func (m *Mail) moveMessages(args []string) jxa.Result {
+	var in struct {
+		Account                string   `json:"account"`
+		MailboxPath            []string `json:"mailboxPath"`
+		MessageIDs             []int    `json:"message_ids"`
+		DestinationAccount     string   `json:"destination_account"`
+		DestinationMailboxPath []string `json:"destination_mailbox_path"`
+	}
+	if err := decodeArgs(args, &in); err != nil {
+		return errParseArgs
+	}
+	if len(in.MessageIDs) == 0 {
+		return failure("message_ids is required and must be a non-empty array")
+	}
+	source, errResult := m.lookupMailbox(in.Account, in.MailboxPath)
+	if errResult != nil {
+		return *errResult
+	}
+	destination, errResult := m.lookupMailbox(in.DestinationAccount, in.DestinationMailboxPath)
+	if errResult != nil {
+		errResult.Error = "Destination: " + errResult.Error
+		return *errResult
+	}
+
+	results := []map[string]any{}
+	moved := 0
+	for _, id := range in.MessageIDs {
+		result := map[string]any{"message_id": id, "moved": false, "new_id": nil}
+		msg := source.message(id)
+		if msg == nil {
+			result["error"] = fmt.Sprintf("Message with ID %d not found in mailbox %q.", id, joinPath(in.MailboxPath))
+			results = append(results, result)
+			continue
+		}
+		m.moveMessage(msg, destination)
+		result["moved"] = true
+		result["new_id"] = msg.ID
+		result["subject"] = msg.Subject
+		results = append(results, result)
+		moved++
+	}
+	return success(map[string]any{
+		"results":      results,
+		"moved_count":  moved,
+		"failed_count": len(results) - moved,
+		"destination": map[string]any{
+			"account":     in.DestinationAccount,
+			"mailboxPath": in.DestinationMailboxPath,
+		},
+	})
+}
+
+func (m *Mail) updateMessages(args []string) jxa.Result {
+	var in struct {
+		Account        string   `json:"account"`
+		MailboxPath    []string `json:"mailboxPath"`
+		MessageIDs     []int    `json:"message_ids"`
+		ReadStatus     *bool    `json:"readStatus"`
+		FlaggedStatus  *bool    `json:"flaggedStatus"`
+		FlagIndex      *int     `json:"flagIndex"`
+		JunkMailStatus *bool    `json:"junkMailStatus"`
+	}
+	if err := decodeArgs(args, &in); err != nil {
+		return errParseArgs
+	}
+	if len(in.MessageIDs) == 0 {
+		return failure("message_ids is required and must be a non-empty array")
+	}
+	if in.ReadStatus == nil && in.FlaggedStatus == nil && in.FlagIndex == nil && in.JunkMailStatus == nil {
+		return failure("At least one of readStatus, flaggedStatus, flagIndex or junkMailStatus is required")
+	}
+	if in.FlagIndex != nil && (*in.FlagIndex < -1 || *in.FlagIndex > 6) {
+		return failure("flagIndex must be between -1 and 6")
+	}
+	mailbox, errResult := m.lookupMailbox(in.Account, in.MailboxPath)
+	if errResult != nil {
+		return *errResult
+	}
+
+	results := []map[string]any{}
+	updated := 0
+	for _, id := range in.MessageIDs {
+		result := map[string]any{"message_id": id, "updated": false}
+		msg := mailbox.message(id)
+		if msg == nil {
+			result["error"] = fmt.Sprintf("Message with ID %d not found in mailbox %q.", id, joinPath(in.MailboxPath))
+			results = append(results, result)
+			continue
+		}
+		if in.ReadStatus != nil {
+			msg.ReadStatus = *in.ReadStatus
+		}
+		if in.FlagIndex != nil {
+			msg.FlaggedStatus = *in.FlagIndex != -1
+			if msg.FlaggedStatus {
+				msg.FlagIndex = *in.FlagIndex
+			}
+		}
+		if in.FlaggedStatus != nil {
+			msg.FlaggedStatus = *in.FlaggedStatus
+		}
+		if in.JunkMailStatus != nil {
+			msg.JunkMailStatus = *in.JunkMailStatus
+		}
+		result["updated"] = true
+		result["readStatus"] = msg.ReadStatus
+		result["flaggedStatus"] = msg.FlaggedStatus
+		result["flagIndex"] = msg.flagIndex()
+		result["junkMailStatus"] = msg.JunkMailStatus
+		results = append(results, result)
+		updated++
+	}
+	return success(map[string]any{
+		"results":       results,
+		"updated_count": updated,
+		"failed_count":  len(results) - updated,
+	})
+}
+
+// trashMailboxNames are the names of the mailbox that serves as an account's
+// Trash.
+var trashMailboxNames = []string{"Trash", "Deleted Messages", "Deleted Items", "Bin"}
+
+func (m *Mail) deleteMessages(args []string) jxa.Result {
+	var in struct {
+		Account     string   `json:"account"`
+		MailboxPath []string `json:"mailboxPath"`
+		MessageIDs  []int    `json:"message_ids"`
+		Permanent   bool     `json:"permanent"`
+		DryRun      bool     `json:"dry_run"`
+	}
+	if err := decodeArgs(args, &in); err != nil {
+		return errParseArgs
+	}
+	if len(in.MessageIDs) == 0 {
+		return failure("message_ids is required and must be a non-empty array")
+	}
+	mailbox, errResult := m.lookupMailbox(in.Account, in.MailboxPath)
+	if errResult != nil {
+		return *errResult
+	}
+	var trash *Mailbox
+	for _, name := range trashMailboxNames {
+		if trash = mailbox.account.mailbox([]string{name}); trash != nil {
+			break
+		}
+	}
+	if trash == nil {
+		return failure("Trash mailbox not found in account '%s'.", in.Account)
+	}
+
+	results := []map[string]any{}
+	deleted := 0
+	for _, id := range in.MessageIDs {
+		result := map[string]any{"message_id": id, "deleted": false}
+		msg := mailbox.message(id)
+		if msg == nil {
+			result["error"] = fmt.Sprintf("Message with ID %d not found in mailbox %q.", id, joinPath(in.MailboxPath))
+			results = append(results, result)
+			continue
+		}
+		result["subject"] = msg.Subject
+		result["sender"] = msg.Sender
+		switch {
+		case in.DryRun:
+			result["would_delete"] = true
+		case in.Permanent:
+			mailbox.removeMessage(msg.ID)
+			result["deleted"] = true
+		case mailbox == trash:
+			result["error"] = "Message is already in the Trash"
+			results = append(results, result)
+			continue
+		default:
+			m.moveMessage(msg, trash)
+			result["deleted"] = true
+			result["trash_id"] = msg.ID
+		}
+		results = append(results, result)
+		deleted++
+	}
+
+	data := map[string]any{
+		"results":      results,
+		"dry_run":      in.DryRun,
+		"permanent":    in.Permanent,
+		"failed_count": len(results) - deleted,
+		"trash": map[string]any{
+			"account":     in.Account,
+			"mailboxPath": trash.Path(),
+		},
+	}
+	if in.DryRun {
+		data["would_delete_count"] = deleted
+	} else {
+		data["deleted_count"] = deleted
+	}
+	return success(data)
+}
+
+// moveMessage moves msg to destination. Like Mail.app, the moved message gets
+// a new ID.
+func (m *Mail) moveMessage(msg *Message, destination *Mailbox) {
+	msg.mailbox.removeMessage(msg.ID)
+	msg.ID = m.newID()
+	msg.mailbox = destination
+	destination.Messages = append(destination.Messages, msg)
+}
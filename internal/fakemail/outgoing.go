@@ -0,0 +1,482 @@
+package fakemail
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/dastrobu/mail-mcp/internal/jxa"
+)
+
+// pid is reported as Mail.app's process ID. Nothing is pasted into the fake,
+// so any value works.
+const pid = 0
+
+func (m *Mail) outgoingMessage(id int) *OutgoingMessage {
+	for _, msg := range m.outgoing {
+		if msg.ID == id {
+			return msg
+		}
+	}
+	return nil
+}
+
+func (m *Mail) removeOutgoingMessage(id int) {
+	m.outgoing = slices.DeleteFunc(m.outgoing, func(msg *OutgoingMessage) bool { return msg.ID == id })
+}
+
+func (m *Mail) openOutgoingMessage(msg *OutgoingMessage) *OutgoingMessage {
+	msg.ID = m.newID()
+	m.outgoing = append(m.outgoing, msg)
+	return msg
+}
+
+func (m *Mail) listOutgoingMessages(args []string) jxa.Result {
+	messages := []map[string]any{}
+	for _, msg := range m.outgoing {
+		messages = append(messages, map[string]any{
+			"outgoing_id":      msg.ID,
+			"subject":          msg.Subject,
+			"sender":           msg.Sender,
+			"content_preview":  preview(msg.Content),
+			"content_length":   len(msg.Content),
+			"to_recipients":    nonNil(msg.To),
+			"cc_recipients":    nonNil(msg.Cc),
+			"bcc_recipients":   nonNil(msg.Bcc),
+			"to_count":         len(msg.To),
+			"cc_count":         len(msg.Cc),
+			"bcc_count":        len(msg.Bcc),
+			"total_recipients": len(msg.To) + len(msg.Cc) + len(msg.Bcc),
+		})
+	}
+	return success(map[string]any{
+		"messages":       messages,
+		"count":          len(messages),
+		"total_outgoing": len(m.outgoing),
+	})
+}
+
+func (m *Mail) createOutgoingMessage(args []string) jxa.Result {
+	var in struct {
+		Account       string   `json:"account"`
+		Subject       string   `json:"subject"`
+		ToRecipients  []string `json:"to_recipients"`
+		CcRecipients  []string `json:"cc_recipients"`
+		BccRecipients []string `json:"bcc_recipients"`
+		Attachments   []string `json:"attachments"`
+		Signature     string   `json:"signature"`
+	}
+	if err := decodeArgs(args, &in); err != nil {
+		return errParseArgs
+	}
+	if in.Account == "" || in.Subject == "" {
+		return jxa.Result{Error: "Account and Subject are required parameters.", ErrorCode: "MISSING_PARAMETERS"}
+	}
+	if errResult := m.checkSignature(in.Signature); errResult != nil {
+		return *errResult
+	}
+	account := m.account(in.Account)
+	if account == nil {
+		return jxa.Result{Error: "Account '" + in.Account + "' not found.", ErrorCode: "ACCOUNT_NOT_FOUND"}
+	}
+
+	msg := m.openOutgoingMessage(&OutgoingMessage{
+		Subject:     in.Subject,
+		Sender:      account.sender(),
+		To:          in.ToRecipients,
+		Cc:          in.CcRecipients,
+		Bcc:         in.BccRecipients,
+		Attachments: in.Attachments,
+		Signature:   in.Signature,
+	})
+	return success(map[string]any{
+		"outgoing_id": msg.ID,
+		"subject":     msg.Subject,
+		"pid":         pid,
+		"message":     "Outgoing message created successfully. Window opened for content pasting.",
+	})
+}
+
+func (m *Mail) replaceOutgoingMessage(args []string) jxa.Result {
+	var in struct {
+		OutgoingID    *int      `json:"outgoing_id"`
+		Subject       *string   `json:"subject"`
+		Sender        *string   `json:"sender"`
+		ToRecipients  *[]string `json:"to_recipients"`
+		CcRecipients  *[]string `json:"cc_recipients"`
+		BccRecipients *[]string `json:"bcc_recipients"`
+		Attachments   []string  `json:"attachments"`
+		Signature     string    `json:"signature"`
+	}
+	if err := decodeArgs(args, &in); err != nil {
+		return errParseArgs
+	}
+	if in.OutgoingID == nil {
+		return jxa.Result{Error: "A valid outgoing_id is required.", ErrorCode: "MISSING_PARAMETERS"}
+	}
+	if errResult := m.checkSignature(in.Signature); errResult != nil {
+		return *errResult
+	}
+	old := m.outgoingMessage(*in.OutgoingID)
+	if old == nil {
+		return failure("Outgoing message with ID %d not found.", *in.OutgoingID)
+	}
+
+	msg := &OutgoingMessage{
+		Subject: valueOr(in.Subject, old.Subject),
+		Sender:  valueOr(in.Sender, old.Sender),
+		To:      valueOr(in.ToRecipients, old.To),
+		Cc:      valueOr(in.CcRecipients, old.Cc),
+		Bcc:     valueOr(in.BccRecipients, old.Bcc),
+		// Attachments are not carried over, as in Mail.app
+		Attachments: in.Attachments,
+		Signature:   in.Signature,
+	}
+	m.openOutgoingMessage(msg)
+	m.removeOutgoingMessage(old.ID)
+
+	// The account is the one the sender address belongs to
+	var account string
+	for _, a := range m.accounts {
+		if slices.Contains(a.EmailAddresses, msg.Sender) {
+			account = a.Name
+			break
+		}
+	}
+	return success(map[string]any{
+		"outgoing_id": msg.ID,
+		"subject":     msg.Subject,
+		"account":     account,
+		"pid":         pid,
+		"message":     "Outgoing message was successfully replaced.",
+	})
+}
+
+func (m *Mail) deleteOutgoingMessage(args []string) jxa.Result {
+	var in struct {
+		OutgoingID *int `json:"outgoing_id"`
+	}
+	if err := decodeArgs(args, &in); err != nil {
+		return errParseArgs
+	}
+	if in.OutgoingID == nil {
+		return jxa.Result{Error: "outgoing_id is required.", ErrorCode: "MISSING_PARAMETERS"}
+	}
+	msg := m.outgoingMessage(*in.OutgoingID)
+	if msg == nil {
+		return failure("Outgoing message with ID %d not found.", *in.OutgoingID)
+	}
+	m.removeOutgoingMessage(msg.ID)
+	return success(map[string]any{
+		"deleted_id": msg.ID,
+		"subject":    msg.Subject,
+		"message":    "Outgoing message deleted successfully.",
+	})
+}
+
+func (m *Mail) sendOutgoingMessage(args []string) jxa.Result {
+	var in struct {
+		OutgoingID    *int     `json:"outgoing_id"`
+		ToRecipients  []string `json:"to_recipients"`
+		CcRecipients  []string `json:"cc_recipients"`
+		BccRecipients []string `json:"bcc_recipients"`
+	}
+	if err := decodeArgs(args, &in); err != nil {
+		return errParseArgs
+	}
+	if in.OutgoingID == nil {
+		return jxa.Result{Error: "outgoing_id is required.", ErrorCode: "MISSING_PARAMETERS"}
+	}
+	msg := m.outgoingMessage(*in.OutgoingID)
+	if msg == nil {
+		return failure("Outgoing message with ID %d not found.", *in.OutgoingID)
+	}
+	if !slices.Equal(nonNil(msg.To), nonNil(in.ToRecipients)) || !slices.Equal(nonNil(msg.Cc), nonNil(in.CcRecipients)) || !slices.Equal(nonNil(msg.Bcc), nonNil(in.BccRecipients)) {
+		return jxa.Result{
+			Error:     "The recipients of the outgoing message changed after the policy check. The message was not sent.",
+			ErrorCode: "RECIPIENTS_CHANGED",
+		}
+	}
+	m.removeOutgoingMessage(msg.ID)
+	m.sent = append(m.sent, msg)
+	return success(map[string]any{
+		"outgoing_id": msg.ID,
+		"subject":     msg.Subject,
+		"message":     "Outgoing message sent successfully.",
+	})
+}
+
+// replyInput holds the arguments shared by create_reply and replace_reply.
+type replyInput struct {
+	Account     string   `json:"account"`
+	MessageID   int      `json:"message_id"`
+	MailboxPath []string `json:"mailbox_path"`
+	ReplyToAll  bool     `json:"reply_to_all"`
+	Attachments []string `json:"attachments"`
+	Signature   string   `json:"signature"`
+}
+
+// reply opens a reply to the original message like Message.reply() does.
+func (m *Mail) reply(in replyInput) (*OutgoingMessage, *jxa.Result) {
+	if in.Account == "" || in.MessageID == 0 {
+		r := jxa.Result{Error: "Account name and message ID are required.", ErrorCode: "MISSING_PARAMETERS"}
+		return nil, &r
+	}
+	if errResult := m.checkSignature(in.Signature); errResult != nil {
+		return nil, errResult
+	}
+	mb, errResult := m.lookupMailbox(in.Account, in.MailboxPath)
+	if errResult != nil {
+		return nil, errResult
+	}
+	original := mb.message(in.MessageID)
+	if original == nil {
+		r := jxa.Result{
+			Error:     fmt.Sprintf("Message with ID %d not found in mailbox '%s'.", in.MessageID, joinPath(in.MailboxPath)),
+			ErrorCode: "MESSAGE_NOT_FOUND",
+		}
+		return nil, &r
+	}
+
+	subject := original.Subject
+	if !strings.HasPrefix(strings.ToLower(subject), "re:") {
+		subject = "Re: " + subject
+	}
+	replyTo := original.ReplyTo
+	if replyTo == "" {
+		replyTo = original.Sender
+	}
+	msg := &OutgoingMessage{
+		Subject:     subject,
+		Sender:      mb.account.sender(),
+		To:          []string{replyTo},
+		Attachments: in.Attachments,
+		Signature:   in.Signature,
+	}
+	if in.ReplyToAll {
+		own := mb.account.EmailAddresses
+		for _, r := range original.To {
+			if !slices.Contains(own, r.Address) {
+				msg.To = append(msg.To, r.Address)
+			}
+		}
+		for _, r := range original.Cc {
+			if !slices.Contains(own, r.Address) {
+				msg.Cc = append(msg.Cc, r.Address)
+			}
+		}
+	}
+	return m.openOutgoingMessage(msg), nil
+}
+
+func (m *Mail) createReply(args []string) jxa.Result {
+	var in replyInput
+	if err := decodeArgs(args, &in); err != nil {
+		return errParseArgs
+	}
+	msg, errResult := m.reply(in)
+	if errResult != nil {
+		return *errResult
+	}
+	return success(map[string]any{
+		"outgoing_id": msg.ID,
+		"subject":     msg.Subject,
+		"pid":         pid,
+		"message":     "Reply message created successfully.",
+	})
+}
+
+func (m *Mail) replaceReply(args []string) jxa.Result {
+	var in struct {
+		replyInput
+		OutgoingID    int       `json:"outgoing_id"`
+		Subject       *string   `json:"subject"`
+		ToRecipients  *[]string `json:"to_recipients"`
+		CcRecipients  *[]string `json:"cc_recipients"`
+		BccRecipients *[]string `json:"bcc_recipients"`
+	}
+	if err := decodeArgs(args, &in); err != nil {
+		return errParseArgs
+	}
+	if in.OutgoingID == 0 || in.MessageID == 0 || in.Account == "" || len(in.MailboxPath) == 0 {
+		return jxa.Result{Error: "outgoing_id, message_id, account, and mailbox_path are required.", ErrorCode: "MISSING_PARAMETERS"}
+	}
+	m.removeOutgoingMessage(in.OutgoingID)
+
+	msg, errResult := m.reply(in.replyInput)
+	if errResult != nil {
+		return *errResult
+	}
+	msg.Subject = valueOr(in.Subject, msg.Subject)
+	msg.To = valueOr(in.ToRecipients, msg.To)
+	msg.Cc = valueOr(in.CcRecipients, msg.Cc)
+	msg.Bcc = valueOr(in.BccRecipients, msg.Bcc)
+	return success(map[string]any{
+		"outgoing_id": msg.ID,
+		"subject":     msg.Subject,
+		"pid":         pid,
+		"message":     "Reply was successfully replaced.",
+	})
+}
+
+// forwardInput holds the arguments shared by create_forward, replace_forward
+// and redirect_message.
+type forwardInput struct {
+	Account       string   `json:"account"`
+	MessageID     int      `json:"message_id"`
+	MailboxPath   []string `json:"mailbox_path"`
+	ToRecipients  []string `json:"to_recipients"`
+	CcRecipients  []string `json:"cc_recipients"`
+	BccRecipients []string `json:"bcc_recipients"`
+	Attachments   []string `json:"attachments"`
+	Signature     string   `json:"signature"`
+}
+
+// original looks up the message a forward or redirect is created from.
+func (m *Mail) original(in forwardInput) (*Mailbox, *Message, *jxa.Result) {
+	if in.Account == "" || in.MessageID == 0 {
+		r := jxa.Result{Error: "Account name and message ID are required.", ErrorCode: "MISSING_PARAMETERS"}
+		return nil, nil, &r
+	}
+	if len(in.ToRecipients) == 0 {
+		r := jxa.Result{Error: "At least one To recipient is required.", ErrorCode: "MISSING_PARAMETERS"}
+		return nil, nil, &r
+	}
+	mb, errResult := m.lookupMailbox(in.Account, in.MailboxPath)
+	if errResult != nil {
+		return nil, nil, errResult
+	}
+	original := mb.message(in.MessageID)
+	if original == nil {
+		r := jxa.Result{
+			Error:     fmt.Sprintf("Message with ID %d not found in mailbox '%s'.", in.MessageID, joinPath(in.MailboxPath)),
+			ErrorCode: "MESSAGE_NOT_FOUND",
+		}
+		return nil, nil, &r
+	}
+	return mb, original, nil
+}
+
+// forward opens a forward of the original message like Message.forward()
+// does. The attachments of the original are forwarded along.
+func (m *Mail) forward(in forwardInput) (*OutgoingMessage, []string, *jxa.Result) {
+	if errResult := m.checkSignature(in.Signature); errResult != nil {
+		return nil, nil, errResult
+	}
+	mb, original, errResult := m.original(in)
+	if errResult != nil {
+		return nil, nil, errResult
+	}
+	subject := original.Subject
+	if !strings.HasPrefix(strings.ToLower(subject), "fwd:") {
+		subject = "Fwd: " + subject
+	}
+	forwarded := []string{}
+	for _, att := range original.Attachments {
+		forwarded = append(forwarded, att.Name)
+	}
+	msg := m.openOutgoingMessage(&OutgoingMessage{
+		Subject:     subject,
+		Sender:      mb.account.sender(),
+		To:          in.ToRecipients,
+		Cc:          in.CcRecipients,
+		Bcc:         in.BccRecipients,
+		Attachments: in.Attachments,
+		Signature:   in.Signature,
+		Forwarded:   original,
+	})
+	return msg, forwarded, nil
+}
+
+func (m *Mail) createForward(args []string) jxa.Result {
+	var in forwardInput
+	if err := decodeArgs(args, &in); err != nil {
+		return errParseArgs
+	}
+	msg, forwarded, errResult := m.forward(in)
+	if errResult != nil {
+		return *errResult
+	}
+	return success(map[string]any{
+		"outgoing_id":           msg.ID,
+		"subject":               msg.Subject,
+		"pid":                   pid,
+		"forwarded_attachments": forwarded,
+		"message":               "Forward message created successfully.",
+	})
+}
+
+func (m *Mail) replaceForward(args []string) jxa.Result {
+	var in struct {
+		forwardInput
+		OutgoingID int     `json:"outgoing_id"`
+		Subject    *string `json:"subject"`
+	}
+	if err := decodeArgs(args, &in); err != nil {
+		return errParseArgs
+	}
+	if in.OutgoingID == 0 || in.MessageID == 0 || in.Account == "" || len(in.MailboxPath) == 0 {
+		return jxa.Result{Error: "outgoing_id, message_id, account, and mailbox_path are required.", ErrorCode: "MISSING_PARAMETERS"}
+	}
+	m.removeOutgoingMessage(in.OutgoingID)
+
+	msg, forwarded, errResult := m.forward(in.forwardInput)
+	if errResult != nil {
+		return *errResult
+	}
+	msg.Subject = valueOr(in.Subject, msg.Subject)
+	return success(map[string]any{
+		"outgoing_id":           msg.ID,
+		"subject":               msg.Subject,
+		"pid":                   pid,
+		"forwarded_attachments": forwarded,
+		"message":               "Forward was successfully replaced.",
+	})
+}
+
+func (m *Mail) redirectMessage(args []string) jxa.Result {
+	var in forwardInput
+	if err := decodeArgs(args, &in); err != nil {
+		return errParseArgs
+	}
+	_, original, errResult := m.original(in)
+	if errResult != nil {
+		return *errResult
+	}
+	// A redirect keeps the sender and subject of the original
+	msg := m.openOutgoingMessage(&OutgoingMessage{
+		Subject:    original.Subject,
+		Sender:     original.Sender,
+		To:         in.ToRecipients,
+		Cc:         in.CcRecipients,
+		Bcc:        in.BccRecipients,
+		Redirected: original,
+	})
+	return success(map[string]any{
+		"outgoing_id": msg.ID,
+		"subject":     msg.Subject,
+		"message":     "Redirect message created successfully.",
+	})
+}
+
+// sender returns the address new messages from this account are sent from.
+func (a *Account) sender() string {
+	if len(a.EmailAddresses) == 0 {
+		return ""
+	}
+	return a.EmailAddresses[0]
+}
+
+func valueOr[T any](p *T, fallback T) T {
+	if p != nil {
+		return *p
+	}
+	return fallback
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
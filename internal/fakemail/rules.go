@@ -0,0 +1,252 @@
+package fakemail
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/dastrobu/mail-mcp/internal/jxa"
+)
+
+// Rule is a fake Mail rule. Conditions and actions hold the values of Mail's
+// scripting dictionary, as the rule scripts read and write them.
+type Rule struct {
+	Name                   string          `json:"name"`
+	Enabled                bool            `json:"enabled"`
+	AllConditionsMustBeMet bool            `json:"allConditionsMustBeMet"`
+	Conditions             []RuleCondition `json:"conditions"`
+	Actions                RuleActions     `json:"actions"`
+}
+
+// RuleCondition is a condition of a fake rule, e.g. RuleType "from header".
+type RuleCondition struct {
+	RuleType   string `json:"ruleType"`
+	Qualifier  string `json:"qualifier"`
+	Expression string `json:"expression"`
+	Header     string `json:"header"`
+}
+
+// RuleMailbox is the mailbox a fake rule moves or copies messages to.
+type RuleMailbox struct {
+	Account     string   `json:"account"`
+	MailboxPath []string `json:"mailboxPath"`
+}
+
+// RuleActions are the actions of a fake rule. The fields after
+// StopEvaluatingRules are never written by the scripts.
+type RuleActions struct {
+	MoveMessage         *RuleMailbox `json:"moveMessage"`
+	CopyMessage         *RuleMailbox `json:"copyMessage"`
+	MarkRead            bool         `json:"markRead"`
+	MarkFlagged         bool         `json:"markFlagged"`
+	MarkFlagIndex       int          `json:"markFlagIndex"`
+	DeleteMessage       bool         `json:"deleteMessage"`
+	ForwardMessage      string       `json:"forwardMessage"`
+	StopEvaluatingRules bool         `json:"stopEvaluatingRules"`
+
+	ForwardText             string `json:"forwardText"`
+	RedirectMessage         string `json:"redirectMessage"`
+	ReplyText               string `json:"replyText"`
+	RunScript               string `json:"runScript"`
+	PlaySound               string `json:"playSound"`
+	ColorMessage            string `json:"colorMessage"`
+	HighlightTextUsingColor bool   `json:"highlightTextUsingColor"`
+}
+
+// AddRule appends a rule, e.g. one with actions the rule tools cannot
+// represent.
+func (m *Mail) AddRule(rule *Rule) *Rule {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rules = append(m.rules, rule)
+	return rule
+}
+
+// Rules returns a snapshot of the rules.
+func (m *Mail) Rules() []Rule {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	result := make([]Rule, len(m.rules))
+	for i, rule := range m.rules {
+		result[i] = *rule
+	}
+	return result
+}
+
+// ruleData returns a rule the way the scripts serialize it.
+func ruleData(rule *Rule, index int) map[string]any {
+	conditions := rule.Conditions
+	if conditions == nil {
+		conditions = []RuleCondition{}
+	}
+	return map[string]any{
+		"index":                  index,
+		"name":                   rule.Name,
+		"enabled":                rule.Enabled,
+		"allConditionsMustBeMet": rule.AllConditionsMustBeMet,
+		"conditions":             conditions,
+		"actions":                rule.Actions,
+	}
+}
+
+// findRule returns the rule named name and its 1-based index, or the error
+// of the scripts.
+func (m *Mail) findRule(name string) (*Rule, int, *jxa.Result) {
+	var found []int
+	for i, rule := range m.rules {
+		if rule.Name == name {
+			found = append(found, i)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil, 0, &jxa.Result{Error: fmt.Sprintf("Rule %q not found.", name), ErrorCode: "RULE_NOT_FOUND"}
+	case 1:
+		return m.rules[found[0]], found[0] + 1, nil
+	default:
+		return nil, 0, &jxa.Result{
+			Error:     fmt.Sprintf("There are %d rules named %q. Rename them in Mail so they can be told apart.", len(found), name),
+			ErrorCode: "AMBIGUOUS_RULE",
+		}
+	}
+}
+
+// checkRuleMailboxes verifies the mailboxes of move and copy actions exist.
+func (m *Mail) checkRuleMailboxes(actions *RuleActions) *jxa.Result {
+	for _, target := range []*RuleMailbox{actions.MoveMessage, actions.CopyMessage} {
+		if target == nil {
+			continue
+		}
+		account := m.account(target.Account)
+		if account == nil {
+			return &jxa.Result{Error: fmt.Sprintf("Account %q not found.", target.Account), ErrorCode: "MAILBOX_NOT_FOUND"}
+		}
+		if account.mailbox(target.MailboxPath) == nil {
+			return &jxa.Result{
+				Error:     fmt.Sprintf("Mailbox path '%s' not found in account '%s'.", joinPath(target.MailboxPath), target.Account),
+				ErrorCode: "MAILBOX_NOT_FOUND",
+			}
+		}
+	}
+	return nil
+}
+
+func (m *Mail) listRules(args []string) jxa.Result {
+	rules := []map[string]any{}
+	for i, rule := range m.rules {
+		rules = append(rules, ruleData(rule, i+1))
+	}
+	return success(map[string]any{
+		"rules": rules,
+		"count": len(rules),
+	})
+}
+
+func (m *Mail) createRule(args []string) jxa.Result {
+	var in struct {
+		Name                   string          `json:"name"`
+		Enabled                bool            `json:"enabled"`
+		AllConditionsMustBeMet bool            `json:"allConditionsMustBeMet"`
+		Conditions             []RuleCondition `json:"conditions"`
+		Actions                *RuleActions    `json:"actions"`
+	}
+	if err := decodeArgs(args, &in); err != nil {
+		return errParseArgs
+	}
+	if in.Name == "" {
+		return failure("Rule name is required")
+	}
+	if len(in.Conditions) == 0 || in.Actions == nil {
+		return failure("Conditions and actions are required")
+	}
+	if slices.ContainsFunc(m.rules, func(rule *Rule) bool { return rule.Name == in.Name }) {
+		return jxa.Result{Error: fmt.Sprintf("A rule named %q already exists.", in.Name), ErrorCode: "RULE_EXISTS"}
+	}
+	if errResult := m.checkRuleMailboxes(in.Actions); errResult != nil {
+		return *errResult
+	}
+
+	rule := &Rule{
+		Name:                   in.Name,
+		Enabled:                in.Enabled,
+		AllConditionsMustBeMet: in.AllConditionsMustBeMet,
+		Conditions:             in.Conditions,
+		Actions:                *in.Actions,
+	}
+	m.rules = append(m.rules, rule)
+	return success(map[string]any{"rule": ruleData(rule, len(m.rules))})
+}
+
+func (m *Mail) updateRule(args []string) jxa.Result {
+	var in struct {
+		Name                   string          `json:"name"`
+		NewName                string          `json:"new_name"`
+		Enabled                *bool           `json:"enabled"`
+		AllConditionsMustBeMet *bool           `json:"allConditionsMustBeMet"`
+		Conditions             []RuleCondition `json:"conditions"`
+		Actions                *RuleActions    `json:"actions"`
+	}
+	if err := decodeArgs(args, &in); err != nil {
+		return errParseArgs
+	}
+	if in.Name == "" {
+		return failure("Rule name is required")
+	}
+	rule, index, errResult := m.findRule(in.Name)
+	if errResult != nil {
+		return *errResult
+	}
+	if in.NewName != "" && in.NewName != in.Name &&
+		slices.ContainsFunc(m.rules, func(rule *Rule) bool { return rule.Name == in.NewName }) {
+		return jxa.Result{Error: fmt.Sprintf("A rule named %q already exists.", in.NewName), ErrorCode: "RULE_EXISTS"}
+	}
+	if in.Actions != nil {
+		if errResult := m.checkRuleMailboxes(in.Actions); errResult != nil {
+			return *errResult
+		}
+	}
+
+	if in.NewName != "" {
+		rule.Name = in.NewName
+	}
+	if in.Enabled != nil {
+		rule.Enabled = *in.Enabled
+	}
+	if in.AllConditionsMustBeMet != nil {
+		rule.AllConditionsMustBeMet = *in.AllConditionsMustBeMet
+	}
+	if in.Conditions != nil {
+		rule.Conditions = in.Conditions
+	}
+	if in.Actions != nil {
+		// Actions the scripts do not write are kept
+		actions := *in.Actions
+		actions.ForwardText = rule.Actions.ForwardText
+		actions.RedirectMessage = rule.Actions.RedirectMessage
+		actions.ReplyText = rule.Actions.ReplyText
+		actions.RunScript = rule.Actions.RunScript
+		actions.PlaySound = rule.Actions.PlaySound
+		actions.ColorMessage = rule.Actions.ColorMessage
+		actions.HighlightTextUsingColor = rule.Actions.HighlightTextUsingColor
+		rule.Actions = actions
+	}
+	return success(map[string]any{"rule": ruleData(rule, index)})
+}
+
+func (m *Mail) deleteRule(args []string) jxa.Result {
+	var in struct {
+		Name string `json:"name"`
+	}
+	if err := decodeArgs(args, &in); err != nil {
+		return errParseArgs
+	}
+	if in.Name == "" {
+		return failure("Rule name is required")
+	}
+	rule, index, errResult := m.findRule(in.Name)
+	if errResult != nil {
+		return *errResult
+	}
+	data := ruleData(rule, index)
+	m.rules = slices.Delete(m.rules, index-1, index)
+	return success(map[string]any{"rule": data})
+}
@@ -0,0 +1,46 @@
+package fakemail
+
+import (
+	"slices"
+
+	"github.com/dastrobu/mail-mcp/internal/jxa"
+)
+
+// Signature is a fake Mail signature.
+type Signature struct {
+	Name    string
+	Content string
+}
+
+// AddSignature adds a signature the compose scripts can apply by name.
+func (m *Mail) AddSignature(name, content string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.signatures = append(m.signatures, Signature{Name: name, Content: content})
+}
+
+// checkSignature answers SIGNATURE_NOT_FOUND for names Mail has no signature
+// for, as the compose scripts do before opening a window.
+func (m *Mail) checkSignature(name string) *jxa.Result {
+	if name == "" || slices.ContainsFunc(m.signatures, func(s Signature) bool { return s.Name == name }) {
+		return nil
+	}
+	return &jxa.Result{
+		Error:     "Signature '" + name + "' not found. Use list_signatures to see the available signatures.",
+		ErrorCode: "SIGNATURE_NOT_FOUND",
+	}
+}
+
+func (m *Mail) listSignatures(args []string) jxa.Result {
+	signatures := []map[string]any{}
+	for _, s := range m.signatures {
+		signatures = append(signatures, map[string]any{
+			"name":    s.Name,
+			"content": s.Content,
+		})
+	}
+	return success(map[string]any{
+		"signatures": signatures,
+		"count":      len(signatures),
+	})
+}
@@ -0,0 +1,98 @@
+package fakemail
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/dastrobu/mail-mcp/internal/jxa"
+)
+
+var (
+	inboxName    = regexp.MustCompile(`(?i)^inbox$`)
+	sentName     = regexp.MustCompile(`(?i)^sent( messages| items| mail)?$`)
+	archiveName  = regexp.MustCompile(`(?i)archive`)
+	whiteSpaceRe = regexp.MustCompile(`\s+`)
+)
+
+// isThreadMailbox selects the mailboxes get_thread searches by default the
+// way the script does.
+func isThreadMailbox(path []string) bool {
+	if inboxName.MatchString(path[0]) || sentName.MatchString(path[0]) {
+		return true
+	}
+	return slices.ContainsFunc(path, archiveName.MatchString)
+}
+
+func (m *Mail) getThread(args []string) jxa.Result {
+	var in struct {
+		Account      string     `json:"account"`
+		MailboxPath  []string   `json:"mailboxPath"`
+		MessageIDs   []string   `json:"message_ids"`
+		Subject      string     `json:"subject"`
+		MailboxPaths [][]string `json:"mailboxPaths"`
+		Limit        int        `json:"limit"`
+	}
+	if err := decodeArgs(args, &in); err != nil {
+		return errParseArgs
+	}
+	if in.Limit == 0 {
+		in.Limit = 100
+	}
+	source, errResult := m.lookupMailbox(in.Account, in.MailboxPath)
+	if errResult != nil {
+		return *errResult
+	}
+	account := source.account
+
+	mailboxes := []*Mailbox{source}
+	if len(in.MailboxPaths) > 0 {
+		for _, path := range in.MailboxPaths {
+			if mb := account.mailbox(path); mb != nil && !slices.Contains(mailboxes, mb) {
+				mailboxes = append(mailboxes, mb)
+			}
+		}
+	} else {
+		for _, mb := range account.allMailboxes() {
+			if mb != source && isThreadMailbox(mb.Path()) {
+				mailboxes = append(mailboxes, mb)
+			}
+		}
+	}
+
+	candidates := []map[string]any{}
+	searched := [][]string{}
+	truncated := false
+	for _, mb := range mailboxes {
+		searched = append(searched, mb.Path())
+		if truncated {
+			continue
+		}
+		for _, msg := range mb.Messages {
+			subject := whiteSpaceRe.ReplaceAllString(strings.ToLower(msg.Subject), " ")
+			bySubject := in.Subject != "" && strings.Contains(subject, in.Subject)
+			if !slices.Contains(in.MessageIDs, msg.MessageID) && !bySubject {
+				continue
+			}
+			if len(candidates) >= in.Limit {
+				truncated = true
+				break
+			}
+			candidates = append(candidates, map[string]any{
+				"id":            msg.ID,
+				"account":       account.Name,
+				"mailbox_path":  mb.Path(),
+				"subject":       msg.Subject,
+				"sender":        msg.Sender,
+				"date_received": isoTime(msg.DateReceived),
+				"message_id":    msg.MessageID,
+				"all_headers":   msg.AllHeaders,
+			})
+		}
+	}
+	return success(map[string]any{
+		"candidates":         candidates,
+		"mailboxes_searched": searched,
+		"truncated":          truncated,
+	})
+}
@@ -0,0 +1,134 @@
+package headers
+
+import (
+	"mime"
+	"net/mail"
+	"net/textproto"
+	"strings"
+
+	"golang.org/x/net/html/charset"
+)
+
+// wordDecoder decodes RFC 2047 encoded words in the charsets of the WHATWG
+// Encoding Standard, e.g. windows-1252, ISO-2022-JP or KOI8-R, besides those
+// of mime.WordDecoder (UTF-8, US-ASCII and ISO-8859-1).
+var wordDecoder = &mime.WordDecoder{CharsetReader: charset.NewReaderLabel}
+
+// Decode decodes the RFC 2047 encoded words in a header value. Words in
+// unsupported charsets are left encoded.
+func Decode(value string) string {
+	decoded, err := wordDecoder.DecodeHeader(value)
+	if err != nil {
+		return value
+	}
+	return decoded
+}
+
+// Map returns the decoded values of all fields, keyed by the canonical field
+// name, e.g. "Message-Id" or "List-Unsubscribe".
+func (h Header) Map() map[string][]string {
+	m := map[string][]string{}
+	for _, f := range h {
+		key := textproto.CanonicalMIMEHeaderKey(f.Name)
+		m[key] = append(m[key], Decode(f.Value))
+	}
+	return m
+}
+
+// Address is a mailbox of an address list.
+type Address struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+var addressParser = &mail.AddressParser{WordDecoder: wordDecoder}
+
+// ParseAddressList parses an RFC 5322 address list such as the value of a To
+// or Cc field. Group syntax is flattened into the group's mailboxes. Lists
+// that are not valid RFC 5322, which some clients produce, are split at
+// commas outside of quotes and angle brackets and parsed element by element,
+// keeping what can be parsed.
+func ParseAddressList(value string) []Address {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return []Address{}
+	}
+	if list, err := addressParser.ParseList(value); err == nil {
+		return addresses(list)
+	}
+	out := []Address{}
+	prefix := ""
+	for _, part := range splitAddressList(value) {
+		// A part without an address is most likely a name with an unquoted
+		// comma, e.g. Smith, Bob <bob@example.com>
+		if !strings.Contains(part, "@") {
+			prefix += part + ", "
+			continue
+		}
+		part, prefix = prefix+part, ""
+		if a, err := addressParser.Parse(part); err == nil {
+			out = append(out, addresses([]*mail.Address{a})...)
+		} else if email := angleAddr(part); email != "" {
+			name := strings.Trim(strings.TrimSpace(part[:strings.LastIndexByte(part, '<')]), `"`)
+			out = append(out, Address{Name: Decode(name), Email: email})
+		}
+	}
+	return out
+}
+
+func addresses(list []*mail.Address) []Address {
+	out := make([]Address, 0, len(list))
+	for _, a := range list {
+		out = append(out, Address{Name: a.Name, Email: a.Address})
+	}
+	return out
+}
+
+// splitAddressList splits an address list at commas that are not inside a
+// quoted string or angle brackets.
+func splitAddressList(value string) []string {
+	var parts []string
+	var quoted, angle bool
+	start := 0
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; {
+		case c == '\\' && quoted:
+			i++
+		case c == '"':
+			quoted = !quoted
+		case c == '<' && !quoted:
+			angle = true
+		case c == '>' && !quoted:
+			angle = false
+		case c == ',' && !quoted && !angle:
+			parts = append(parts, strings.TrimSpace(value[start:i]))
+			start = i + 1
+		}
+	}
+	parts = append(parts, strings.TrimSpace(value[start:]))
+	return parts
+}
+
+// angleAddr returns the text between the last pair of angle brackets if it
+// looks like an address.
+func angleAddr(s string) string {
+	start := strings.LastIndexByte(s, '<')
+	end := strings.LastIndexByte(s, '>')
+	if start < 0 || end < start {
+		return ""
+	}
+	addr := strings.TrimSpace(s[start+1 : end])
+	if !strings.Contains(addr, "@") {
+		return ""
+	}
+	return addr
+}
+
+// Addresses parses the address lists of all fields with the given name.
+func (h Header) Addresses(name string) []Address {
+	out := []Address{}
+	for _, v := range h.Values(name) {
+		out = append(out, ParseAddressList(v)...)
+	}
+	return out
+}
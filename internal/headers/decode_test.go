@@ -0,0 +1,100 @@
+package headers
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{name: "plain", value: "Quarterly budget", want: "Quarterly budget"},
+		{name: "utf-8 base64", value: "=?UTF-8?B?R3LDvMOfZQ==?=", want: "Grüße"},
+		{name: "utf-8 quoted printable", value: "=?utf-8?Q?Gr=C3=BC=C3=9Fe_aus_K=C3=B6ln?=", want: "Grüße aus Köln"},
+		{name: "adjacent words", value: "=?UTF-8?Q?Hello_?= =?UTF-8?Q?World?=", want: "Hello World"},
+		{name: "mixed", value: "Re: =?ISO-8859-1?Q?Caf=E9?= meeting", want: "Re: Café meeting"},
+		{name: "windows-1252", value: "=?windows-1252?Q?=80_100_=96_net?=", want: "€ 100 – net"},
+		{name: "iso-8859-15", value: "=?iso-8859-15?Q?=A4_100?=", want: "€ 100"},
+		{name: "iso-2022-jp", value: "=?ISO-2022-JP?B?GyRCRnxLXDhsGyhC?=", want: "日本語"},
+		{name: "shift_jis", value: "=?Shift_JIS?B?k/qWe4zq?=", want: "日本語"},
+		{name: "gb2312", value: "=?GB2312?B?1tDOxA==?=", want: "中文"},
+		{name: "koi8-r", value: "=?KOI8-R?B?8NLJ18XU?=", want: "Привет"},
+		{name: "windows-1251", value: "=?windows-1251?B?z/Do4uXy?=", want: "Привет"},
+		{name: "unsupported charset", value: "=?x-unknown?Q?abc?=", want: "=?x-unknown?Q?abc?="},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Decode(tt.value); got != tt.want {
+				t.Errorf("Decode(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAddressList(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  []Address
+	}{
+		{
+			name:  "single",
+			value: "alice@example.com",
+			want:  []Address{{Email: "alice@example.com"}},
+		},
+		{
+			name:  "names and quoting",
+			value: `"Doe, John" <john@example.com>, Alice <alice@example.com>`,
+			want:  []Address{{Name: "Doe, John", Email: "john@example.com"}, {Name: "Alice", Email: "alice@example.com"}},
+		},
+		{
+			name:  "encoded name",
+			value: "=?UTF-8?Q?J=C3=BCrgen_M=C3=BCller?= <juergen@example.com>",
+			want:  []Address{{Name: "Jürgen Müller", Email: "juergen@example.com"}},
+		},
+		{
+			name:  "group",
+			value: "Team: alice@example.com, bob@example.com;",
+			want:  []Address{{Email: "alice@example.com"}, {Email: "bob@example.com"}},
+		},
+		{
+			name:  "invalid elements are skipped",
+			value: "Alice <alice@example.com>, @invalid, Bob Smith (Sales) <bob@example.com>",
+			want:  []Address{{Name: "Alice", Email: "alice@example.com"}, {Name: "Bob Smith", Email: "bob@example.com"}},
+		},
+		{
+			name:  "unquoted special characters in name",
+			value: "Smith, Bob [Sales] <bob@example.com>",
+			want:  []Address{{Name: "Smith, Bob [Sales]", Email: "bob@example.com"}},
+		},
+		{
+			name:  "empty",
+			value: " ",
+			want:  []Address{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseAddressList(tt.value); !slices.Equal(got, tt.want) {
+				t.Errorf("ParseAddressList(%q) = %+v, want %+v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHeader_Map(t *testing.T) {
+	h := Parse("Subject: =?UTF-8?Q?Gr=C3=BC=C3=9Fe?=\nreceived: a\nReceived: b\nlist-unsubscribe: <mailto:u@example.com>,\n <https://example.com/u>\n")
+	m := h.Map()
+	if got := m["Subject"]; !slices.Equal(got, []string{"Grüße"}) {
+		t.Errorf("Subject = %q", got)
+	}
+	if got := m["Received"]; !slices.Equal(got, []string{"a", "b"}) {
+		t.Errorf("Received = %q", got)
+	}
+	if got := AngleList(h.Get("List-Unsubscribe")); !slices.Equal(got, []string{"mailto:u@example.com", "https://example.com/u"}) {
+		t.Errorf("List-Unsubscribe = %q", got)
+	}
+}
@@ -0,0 +1,108 @@
+// Package headers parses the raw message headers Mail.app reports in
+// allHeaders.
+package headers
+
+import (
+	"strings"
+)
+
+// Field is a single header field.
+type Field struct {
+	Name  string
+	Value string
+}
+
+// Header is the list of header fields of a message in their original order.
+type Header []Field
+
+// Parse parses raw RFC 5322 header text. Folded fields are unfolded. Lines
+// that are neither fields nor continuations, such as an mbox "From " line,
+// are skipped. Parsing stops at the first empty line, which separates the
+// header from the body.
+func Parse(raw string) Header {
+	var h Header
+	for _, line := range strings.Split(raw, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if line == "" {
+			if len(h) > 0 {
+				break
+			}
+			continue
+		}
+		if line[0] == ' ' || line[0] == '\t' {
+			// Continuation line: unfolding removes the line break only
+			if len(h) > 0 {
+				h[len(h)-1].Value += line
+			}
+			continue
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if !ok || name == "" || strings.ContainsAny(name, " \t") {
+			continue
+		}
+		h = append(h, Field{Name: name, Value: value})
+	}
+	for i := range h {
+		h[i].Value = strings.TrimSpace(h[i].Value)
+	}
+	return h
+}
+
+// Get returns the value of the first field with the given name, compared
+// case-insensitively, or "" if there is none.
+func (h Header) Get(name string) string {
+	for _, f := range h {
+		if strings.EqualFold(f.Name, name) {
+			return f.Value
+		}
+	}
+	return ""
+}
+
+// Values returns the values of all fields with the given name.
+func (h Header) Values(name string) []string {
+	var values []string
+	for _, f := range h {
+		if strings.EqualFold(f.Name, name) {
+			values = append(values, f.Value)
+		}
+	}
+	return values
+}
+
+// MessageIDs returns the message IDs in the value of a Message-ID,
+// In-Reply-To or References field, without angle brackets. Values without
+// angle brackets, as written by some clients, are split at white space.
+func MessageIDs(value string) []string {
+	if strings.ContainsRune(value, '<') {
+		return AngleList(value)
+	}
+	return strings.Fields(value)
+}
+
+// AngleList returns the entries of a list of angle-bracketed values, such as
+// the URIs of List-Unsubscribe or List-Post, without the brackets.
+func AngleList(value string) []string {
+	var out []string
+	for rest := value; ; {
+		start := strings.IndexByte(rest, '<')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(rest[start:], '>')
+		if end < 0 {
+			break
+		}
+		if v := strings.TrimSpace(rest[start+1 : start+end]); v != "" {
+			out = append(out, v)
+		}
+		rest = rest[start+end+1:]
+	}
+	return out
+}
+
+// NormalizeMessageID strips white space and angle brackets from a message ID,
+// so IDs from headers compare equal to those Mail.app reports.
+func NormalizeMessageID(id string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(id), "<"), ">")
+}
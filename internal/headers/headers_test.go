@@ -0,0 +1,70 @@
+package headers
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	raw := "From nobody Mon Feb 12 10:00:00 2024\n" +
+		"Message-ID: <2@example.com>\n" +
+		"References: <a@example.com>\n" +
+		"\t<1@example.com>\n" +
+		"Subject: Re: Quarterly\r\n" +
+		" budget\r\n" +
+		"received: from a\n" +
+		"Received: from b\n" +
+		"\n" +
+		"Body: not a header\n"
+
+	h := Parse(raw)
+	want := Header{
+		{"Message-ID", "<2@example.com>"},
+		{"References", "<a@example.com>\t<1@example.com>"},
+		{"Subject", "Re: Quarterly budget"},
+		{"received", "from a"},
+		{"Received", "from b"},
+	}
+	if !slices.Equal(h, want) {
+		t.Errorf("Parse =\n%q\nwant\n%q", h, want)
+	}
+	if got := h.Get("message-id"); got != "<2@example.com>" {
+		t.Errorf("Get(message-id) = %q", got)
+	}
+	if got := h.Get("X-Missing"); got != "" {
+		t.Errorf("Get(X-Missing) = %q, want empty", got)
+	}
+	if got := h.Values("RECEIVED"); !slices.Equal(got, []string{"from a", "from b"}) {
+		t.Errorf("Values(RECEIVED) = %q", got)
+	}
+}
+
+func TestMessageIDs(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  []string
+	}{
+		{name: "single", value: "<1@example.com>", want: []string{"1@example.com"}},
+		{name: "list", value: "<1@example.com> <2@example.com>\t<3@example.com>", want: []string{"1@example.com", "2@example.com", "3@example.com"}},
+		{name: "with comments", value: "<1@example.com> (Alice's message of Monday)", want: []string{"1@example.com"}},
+		{name: "without brackets", value: "1@example.com 2@example.com", want: []string{"1@example.com", "2@example.com"}},
+		{name: "unterminated", value: "<1@example.com> <2@exa", want: []string{"1@example.com"}},
+		{name: "empty", value: "", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MessageIDs(tt.value); !slices.Equal(got, tt.want) {
+				t.Errorf("MessageIDs(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeMessageID(t *testing.T) {
+	for _, id := range []string{"<1@example.com>", " 1@example.com ", "1@example.com"} {
+		if got := NormalizeMessageID(id); got != "1@example.com" {
+			t.Errorf("NormalizeMessageID(%q) = %q", id, got)
+		}
+	}
+}
@@ -24,8 +24,38 @@ const (
 	ErrorCodeMailAppNoPermissions = "MAIL_APP_NO_PERMISSIONS"
 )
 
+// Script is an embedded JXA script together with the name it is known by.
+// The name identifies the script to executors that do not run the source,
+// such as the in-memory fake Mail backend.
+type Script struct {
+	Name   string
+	Source string
+}
+
+// Executor runs JXA scripts and returns the data field of their result.
+type Executor interface {
+	Execute(ctx context.Context, script Script, args ...string) (any, error)
+}
+
+// OsascriptExecutor runs scripts with osascript against the real Mail.app.
+type OsascriptExecutor struct{}
+
+// Execute runs the script source via osascript.
+func (OsascriptExecutor) Execute(ctx context.Context, script Script, args ...string) (any, error) {
+	return Execute(ctx, script.Source, args...)
+}
+
 // Execute runs a JXA script with the given arguments and returns the parsed result
 func Execute(ctx context.Context, script string, args ...string) (any, error) {
+	output, err := Run(ctx, script, args...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseOutput(ctx, output, args)
+}
+
+// Run executes a JXA script via osascript and returns its raw output.
+func Run(ctx context.Context, script string, args ...string) ([]byte, error) {
 	// Build osascript command
 	cmdArgs := []string{"-l", "JavaScript", "-e", script}
 	cmdArgs = append(cmdArgs, args...)
@@ -40,6 +70,13 @@ func Execute(ctx context.Context, script string, args ...string) (any, error) {
 		return nil, fmt.Errorf("osascript execution failed: %w\nArguments: %v", err, args)
 	}
 
+	return output, nil
+}
+
+// ParseOutput parses the JSON envelope (success/data/error/errorCode) written
+// by a JXA script and returns its data field. The arguments are only used to
+// give errors more context.
+func ParseOutput(ctx context.Context, output []byte, args []string) (any, error) {
 	// Check if output is empty
 	if len(output) == 0 {
 		return nil, fmt.Errorf("osascript returned empty output (expected JSON)\nArguments: %v", args)
@@ -3,11 +3,22 @@ package jxa
 import (
 	"context"
 	"encoding/json"
+	"os/exec"
 	"strings"
 	"testing"
 )
 
+// requireOsascript skips tests that need to run real JXA scripts.
+func requireOsascript(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("osascript"); err != nil {
+		t.Skip("osascript not available")
+	}
+}
+
 func TestExecute_WrappedFormat(t *testing.T) {
+	requireOsascript(t)
+
 	// Test script that returns data wrapped in data field (required format)
 	script := `
 function run(argv) {
@@ -61,6 +72,8 @@ function run(argv) {
 }
 
 func TestExecute_MissingDataField(t *testing.T) {
+	requireOsascript(t)
+
 	// Test script that returns unwrapped format (should fail)
 	script := `
 function run(argv) {
@@ -92,6 +105,8 @@ function run(argv) {
 }
 
 func TestExecute_ScriptError(t *testing.T) {
+	requireOsascript(t)
+
 	// Test script that returns an error
 	script := `
 function run(argv) {
@@ -122,6 +137,8 @@ function run(argv) {
 }
 
 func TestExecute_WithArguments(t *testing.T) {
+	requireOsascript(t)
+
 	// Test script that uses arguments
 	script := `
 function run(argv) {
@@ -170,6 +187,8 @@ function run(argv) {
 }
 
 func TestExecute_InvalidJSON(t *testing.T) {
+	requireOsascript(t)
+
 	// Test script that returns invalid JSON
 	script := `
 function run(argv) {
@@ -192,6 +211,8 @@ function run(argv) {
 }
 
 func TestExecute_ContextCancellation(t *testing.T) {
+	requireOsascript(t)
+
 	// Test context cancellation
 	script := `
 function run(argv) {
@@ -271,6 +292,8 @@ func TestResult_JSONMarshaling(t *testing.T) {
 }
 
 func TestExecute_MailAppNotRunning(t *testing.T) {
+	requireOsascript(t)
+
 	// Test script that returns MAIL_APP_NOT_RUNNING error code
 	script := `
 function run(argv) {
@@ -306,6 +329,8 @@ function run(argv) {
 }
 
 func TestExecute_MailAppNoPermissions(t *testing.T) {
+	requireOsascript(t)
+
 	// Test script that returns MAIL_APP_NO_PERMISSIONS error code
 	script := `
 function run(argv) {
@@ -342,3 +367,78 @@ function run(argv) {
 
 	t.Logf("Error message: %v", err)
 }
+
+func TestParseOutput(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    any
+		wantErr string
+	}{
+		{
+			name:   "data field is returned",
+			output: `{"success":true,"data":{"count":2}}`,
+			want:   map[string]any{"count": float64(2)},
+		},
+		{
+			name:    "empty output",
+			output:  ``,
+			wantErr: "empty output",
+		},
+		{
+			name:    "invalid JSON",
+			output:  `not valid json`,
+			wantErr: "failed to parse osascript JSON output",
+		},
+		{
+			name:    "missing success field",
+			output:  `{"data":{}}`,
+			wantErr: "missing 'success' field",
+		},
+		{
+			name:    "missing data field",
+			output:  `{"success":true}`,
+			wantErr: "script output missing 'data' field",
+		},
+		{
+			name:    "script error",
+			output:  `{"success":false,"error":"Something went wrong"}`,
+			wantErr: "JXA script error: Something went wrong",
+		},
+		{
+			name:    "script error with logs",
+			output:  `{"success":false,"error":"boom","logs":"step 1"}`,
+			wantErr: "Logs:\nstep 1",
+		},
+		{
+			name:    "mail app not running",
+			output:  `{"success":false,"error":"x","errorCode":"MAIL_APP_NOT_RUNNING"}`,
+			wantErr: "Mail.app is not running",
+		},
+		{
+			name:    "mail app no permissions",
+			output:  `{"success":false,"error":"x","errorCode":"MAIL_APP_NO_PERMISSIONS"}`,
+			wantErr: "Mail.app automation permission denied",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseOutput(context.Background(), []byte(tt.output), nil)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseOutput() error = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseOutput() error = %v", err)
+			}
+			gotJSON, _ := json.Marshal(got)
+			wantJSON, _ := json.Marshal(tt.want)
+			if string(gotJSON) != string(wantJSON) {
+				t.Errorf("ParseOutput() = %s, want %s", gotJSON, wantJSON)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package jxa
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Recording is one captured script call: the script name, its arguments and
+// the raw output osascript returned for them.
+type Recording struct {
+	Script string   `json:"script"`
+	Args   []string `json:"args"`
+	Output string   `json:"output"`
+}
+
+// Recorder is an Executor that runs scripts with osascript and writes every
+// call to a directory, one JSON file per call, so it can be replayed later.
+type Recorder struct {
+	dir string
+	run func(ctx context.Context, script string, args ...string) ([]byte, error)
+
+	mu  sync.Mutex
+	seq int
+}
+
+// NewRecorder creates dir if needed and returns a Recorder writing to it.
+// Recordings already in dir are kept and new ones are numbered after them.
+func NewRecorder(dir string) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create recording directory: %w", err)
+	}
+	files, err := recordingFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	return &Recorder{dir: dir, run: Run, seq: len(files)}, nil
+}
+
+// Execute runs the script and records its raw output before parsing it.
+// Calls where osascript itself fails are not recorded.
+func (r *Recorder) Execute(ctx context.Context, script Script, args ...string) (any, error) {
+	output, err := r.run(ctx, script.Source, args...)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.write(Recording{Script: script.Name, Args: args, Output: string(output)}); err != nil {
+		return nil, err
+	}
+	return ParseOutput(ctx, output, args)
+}
+
+func (r *Recorder) write(rec Recording) error {
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal recording: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seq++
+	name := fmt.Sprintf("%04d-%s.json", r.seq, rec.Script)
+	if err := os.WriteFile(filepath.Join(r.dir, name), data, 0o644); err != nil {
+		return fmt.Errorf("failed to write recording: %w", err)
+	}
+	return nil
+}
+
+// Replayer is an Executor that serves recorded script output instead of
+// running osascript, so the server can run without Mail.app.
+//
+// A call is answered by the first unused recording with the same script name
+// and arguments. Once all matching recordings are used up, the last one is
+// served again, so repeated calls keep working.
+type Replayer struct {
+	mu         sync.Mutex
+	recordings []Recording
+	used       []bool
+}
+
+// NewReplayer loads all recordings from dir in the order they were written.
+func NewReplayer(dir string) (*Replayer, error) {
+	files, err := recordingFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no recordings found in %s", dir)
+	}
+
+	recordings := make([]Recording, 0, len(files))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read recording: %w", err)
+		}
+		var rec Recording
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return nil, fmt.Errorf("failed to parse recording %s: %w", file, err)
+		}
+		recordings = append(recordings, rec)
+	}
+	return &Replayer{recordings: recordings, used: make([]bool, len(recordings))}, nil
+}
+
+// Execute returns the recorded output for the script call.
+func (r *Replayer) Execute(ctx context.Context, script Script, args ...string) (any, error) {
+	output, ok := r.lookup(script.Name, args)
+	if !ok {
+		return nil, fmt.Errorf("no recording for script %q\nArguments: %v", script.Name, args)
+	}
+	return ParseOutput(ctx, []byte(output), args)
+}
+
+func (r *Replayer) lookup(name string, args []string) (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	last := -1
+	for i, rec := range r.recordings {
+		if rec.Script != name || !sameArgs(rec.Args, args) {
+			continue
+		}
+		if !r.used[i] {
+			r.used[i] = true
+			return rec.Output, true
+		}
+		last = i
+	}
+	if last < 0 {
+		return "", false
+	}
+	return r.recordings[last].Output, true
+}
+
+// sameArgs compares arguments, treating JSON arguments as equal if they
+// encode the same value regardless of key order or whitespace.
+func sameArgs(a, b []string) bool {
+	return slices.EqualFunc(a, b, func(x, y string) bool {
+		return x == y || canonicalJSON(x) == canonicalJSON(y)
+	})
+}
+
+func canonicalJSON(s string) string {
+	var v any
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		return s
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return s
+	}
+	return string(data)
+}
+
+// recordingFiles returns the recording files in dir sorted by name.
+func recordingFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recording directory: %w", err)
+	}
+	var files []string
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && strings.HasSuffix(entry.Name(), ".json") {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	slices.Sort(files)
+	return files, nil
+}
@@ -0,0 +1,92 @@
+package jxa
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	recorder, err := NewRecorder(dir)
+	if err != nil {
+		t.Fatalf("NewRecorder() error = %v", err)
+	}
+	outputs := []string{
+		`{"success":true,"data":{"count":1}}`,
+		`{"success":true,"data":{"count":2}}`,
+	}
+	calls := 0
+	recorder.run = func(ctx context.Context, script string, args ...string) ([]byte, error) {
+		output := outputs[calls]
+		calls++
+		return []byte(output), nil
+	}
+
+	script := Script{Name: "list_accounts", Source: "function run(argv) {}"}
+	for range outputs {
+		if _, err := recorder.Execute(ctx, script, `{"enabled":true}`); err != nil {
+			t.Fatalf("Recorder.Execute() error = %v", err)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Name() != "0001-list_accounts.json" {
+		t.Fatalf("recording files = %v, want 0001-list_accounts.json and 0002-list_accounts.json", entries)
+	}
+
+	replayer, err := NewReplayer(dir)
+	if err != nil {
+		t.Fatalf("NewReplayer() error = %v", err)
+	}
+
+	// Recordings are served in order; arguments match regardless of formatting.
+	for _, want := range []float64{1, 2, 2} {
+		got, err := replayer.Execute(ctx, script, `{ "enabled": true }`)
+		if err != nil {
+			t.Fatalf("Replayer.Execute() error = %v", err)
+		}
+		if count := got.(map[string]any)["count"]; count != want {
+			t.Errorf("count = %v, want %v", count, want)
+		}
+	}
+
+	_, err = replayer.Execute(ctx, script, `{"enabled":false}`)
+	if err == nil || !strings.Contains(err.Error(), `no recording for script "list_accounts"`) {
+		t.Errorf("Replayer.Execute() error = %v, want missing recording error", err)
+	}
+}
+
+func TestRecorder_ContinuesNumbering(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(dir+"/0001-list_accounts.json", []byte(`{}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	recorder, err := NewRecorder(dir)
+	if err != nil {
+		t.Fatalf("NewRecorder() error = %v", err)
+	}
+	recorder.run = func(ctx context.Context, script string, args ...string) ([]byte, error) {
+		return []byte(`{"success":false,"error":"boom"}`), nil
+	}
+
+	// Script errors are recorded too, so they can be replayed.
+	if _, err := recorder.Execute(context.Background(), Script{Name: "find_messages"}); err == nil {
+		t.Fatal("Recorder.Execute() error = nil, want script error")
+	}
+	if _, err := os.Stat(dir + "/0002-find_messages.json"); err != nil {
+		t.Errorf("expected second recording: %v", err)
+	}
+}
+
+func TestNewReplayer_EmptyDirectory(t *testing.T) {
+	if _, err := NewReplayer(t.TempDir()); err == nil {
+		t.Error("NewReplayer() error = nil, want error for empty directory")
+	}
+}
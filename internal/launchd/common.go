@@ -35,6 +35,7 @@ type Config struct {
 	LogPath    string
 	ErrPath    string
 	Debug      bool
+	EnableSend bool
 	RunAtLoad  bool
 }
 
@@ -124,6 +124,7 @@ func createPlist(cfg *Config) error {
 		LogPath    string
 		ErrPath    string
 		Debug      bool
+		EnableSend bool
 		RunAtLoad  bool
 	}{
 		Label:      Label,
@@ -133,6 +134,7 @@ func createPlist(cfg *Config) error {
 		LogPath:    cfg.LogPath,
 		ErrPath:    cfg.ErrPath,
 		Debug:      cfg.Debug,
+		EnableSend: cfg.EnableSend,
 		RunAtLoad:  cfg.RunAtLoad,
 	}
 
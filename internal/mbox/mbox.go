@@ -0,0 +1,142 @@
+// Package mbox writes and reads mailbox files in the mboxrd format.
+//
+// Messages are separated by "From " lines. Lines of a message that start
+// with "From ", possibly preceded by ">" characters, are escaped by one more
+// ">" so they cannot be mistaken for separators, and unescaped when read.
+package mbox
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Writer writes messages to an mbox file.
+type Writer struct {
+	w io.Writer
+}
+
+// NewWriter returns a writer appending messages to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// WriteMessage writes a message with its separator line. sender is the
+// envelope sender address, MAILER-DAEMON if empty. Line endings of source
+// are converted to LF.
+func (w *Writer) WriteMessage(sender string, date time.Time, source []byte) error {
+	if sender == "" || strings.ContainsAny(sender, " \t\r\n") {
+		sender = "MAILER-DAEMON"
+	}
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From %s %s\n", sender, date.UTC().Format(time.ANSIC))
+	b.Write(Escape(normalize(source)))
+	b.WriteString("\n")
+	_, err := w.w.Write(b.Bytes())
+	return err
+}
+
+// normalize converts line endings to LF and ends the message with one.
+func normalize(source []byte) []byte {
+	source = bytes.ReplaceAll(source, []byte("\r\n"), []byte("\n"))
+	if len(source) > 0 && source[len(source)-1] != '\n' {
+		source = append(source, '\n')
+	}
+	return source
+}
+
+// fromLine reports whether line is "From " preceded by any number of ">".
+func fromLine(line []byte) bool {
+	return bytes.HasPrefix(bytes.TrimLeft(line, ">"), []byte("From "))
+}
+
+// Escape prefixes the lines of a message that start with "From ", possibly
+// preceded by ">", with ">".
+func Escape(message []byte) []byte {
+	var b bytes.Buffer
+	for line := range bytes.Lines(message) {
+		if fromLine(line) {
+			b.WriteByte('>')
+		}
+		b.Write(line)
+	}
+	return b.Bytes()
+}
+
+// Unescape reverses Escape.
+func Unescape(message []byte) []byte {
+	var b bytes.Buffer
+	for line := range bytes.Lines(message) {
+		if line[0] == '>' && fromLine(line) {
+			line = line[1:]
+		}
+		b.Write(line)
+	}
+	return b.Bytes()
+}
+
+// Message is a message read from an mbox file.
+type Message struct {
+	Separator string // the "From " line, without line ending
+	Source    []byte // the unescaped message, with LF line endings
+}
+
+// Read splits an mbox file into its messages. The empty line that ends each
+// message is removed.
+func Read(r io.Reader) ([]Message, error) {
+	var messages []Message
+	var body bytes.Buffer
+	flush := func() {
+		if len(messages) == 0 {
+			return
+		}
+		source := bytes.TrimSuffix(body.Bytes(), []byte("\n"))
+		messages[len(messages)-1].Source = Unescape(source)
+		body.Reset()
+	}
+
+	br := bufio.NewReader(r)
+	for n := 1; ; n++ {
+		line, err := br.ReadBytes('\n')
+		if len(line) > 0 {
+			if bytes.HasPrefix(line, []byte("From ")) {
+				flush()
+				messages = append(messages, Message{Separator: strings.TrimRight(string(line), "\r\n")})
+			} else if len(messages) == 0 {
+				return nil, fmt.Errorf("line %d: mbox does not start with a From line", n)
+			} else {
+				body.Write(line)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	flush()
+	return messages, nil
+}
+
+// Validate checks that data, an mbox file, holds exactly the given messages:
+// every "From " line inside them must have been escaped, so none of them is
+// split, and reading must restore their sources.
+func Validate(data []byte, sources [][]byte) error {
+	messages, err := Read(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	if len(messages) != len(sources) {
+		return fmt.Errorf("mbox holds %d messages, want %d: a From line was not escaped", len(messages), len(sources))
+	}
+	for i, m := range messages {
+		if !bytes.Equal(m.Source, normalize(sources[i])) {
+			return fmt.Errorf("message %d of the mbox differs from its source", i+1)
+		}
+	}
+	return nil
+}
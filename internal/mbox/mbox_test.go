@@ -0,0 +1,94 @@
+package mbox
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    string
+	}{
+		{"no from line", "Subject: Hi\n\nHello\n", "Subject: Hi\n\nHello\n"},
+		{"from line", "\nFrom here on\n", "\n>From here on\n"},
+		{"escaped from line", ">From here\n>>From there\n", ">>From here\n>>>From there\n"},
+		{"from without space", "From: Bob\nFromage\n", "From: Bob\nFromage\n"},
+		{"quote without from", "> From the thread\n", "> From the thread\n"},
+		{"last line without line ending", "a\nFrom b", "a\n>From b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Escape([]byte(tt.message))
+			if string(got) != tt.want {
+				t.Errorf("Escape() = %q, want %q", got, tt.want)
+			}
+			if back := Unescape(got); string(back) != tt.message {
+				t.Errorf("Unescape(Escape()) = %q, want %q", back, tt.message)
+			}
+		})
+	}
+}
+
+func TestWriteRead(t *testing.T) {
+	date := time.Date(2024, 2, 12, 9, 5, 0, 0, time.UTC)
+	sources := [][]byte{
+		[]byte("From: alice@example.com\r\nSubject: One\r\n\r\nFrom the start\r\n>From quoted\r\n"),
+		[]byte("Subject: Two\n\nNo final line ending"),
+		[]byte("Subject: Three\n\n\n"),
+	}
+
+	var b bytes.Buffer
+	w := NewWriter(&b)
+	if err := w.WriteMessage("alice@example.com", date, sources[0]); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteMessage("Alice <alice@example.com>", date, sources[1]); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteMessage("", date, sources[2]); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "From alice@example.com Mon Feb 12 09:05:00 2024\n" +
+		"From: alice@example.com\nSubject: One\n\n>From the start\n>>From quoted\n\n" +
+		"From MAILER-DAEMON Mon Feb 12 09:05:00 2024\n" +
+		"Subject: Two\n\nNo final line ending\n\n" +
+		"From MAILER-DAEMON Mon Feb 12 09:05:00 2024\n" +
+		"Subject: Three\n\n\n\n"
+	if b.String() != want {
+		t.Errorf("mbox =\n%q\nwant\n%q", b.String(), want)
+	}
+
+	messages, err := Read(strings.NewReader(b.String()))
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(messages) != 3 {
+		t.Fatalf("Read() returned %d messages, want 3", len(messages))
+	}
+	if messages[0].Separator != "From alice@example.com Mon Feb 12 09:05:00 2024" {
+		t.Errorf("Separator = %q", messages[0].Separator)
+	}
+	if err := Validate(b.Bytes(), sources); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	sources := [][]byte{[]byte("Subject: One\n\nFrom the start\n")}
+	unescaped := "From MAILER-DAEMON Mon Feb 12 09:05:00 2024\nSubject: One\n\nFrom the start\n\n"
+	if err := Validate([]byte(unescaped), sources); err == nil {
+		t.Error("Validate() of an unescaped From line succeeded, want error")
+	}
+	if err := Validate([]byte("Subject: One\n"), sources); err == nil {
+		t.Error("Validate() without separator succeeded, want error")
+	}
+	changed := "From MAILER-DAEMON Mon Feb 12 09:05:00 2024\nSubject: Two\n\n>From the start\n\n"
+	if err := Validate([]byte(changed), sources); err == nil {
+		t.Error("Validate() of a changed message succeeded, want error")
+	}
+}
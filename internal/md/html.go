@@ -0,0 +1,522 @@
+package md
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// FromHTML converts an HTML mail body to Markdown. Scripts, style blocks,
+// hidden elements and tracking pixels are dropped. Links keep their targets.
+// Tables are converted to GFM tables unless they are used for layout, in
+// which case their cells are rendered as paragraphs.
+func FromHTML(src string) (string, error) {
+	doc, err := html.Parse(strings.NewReader(src))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse html: %w", err)
+	}
+	w := &writer{}
+	node(w, doc)
+	return cleanup(w.String()), nil
+}
+
+// writer collects Markdown. White space and line breaks are kept pending
+// until the next text is written, so runs of them collapse and none are
+// written at the start or end.
+type writer struct {
+	b       strings.Builder
+	pending string // "", " ", "\n" or "\n\n"
+	lead    bool   // white space was pending before the first text
+}
+
+func (w *writer) write(s string) {
+	if s == "" {
+		return
+	}
+	if w.b.Len() > 0 {
+		w.b.WriteString(w.pending)
+	} else if w.pending != "" {
+		w.lead = true
+	}
+	w.pending = ""
+	w.b.WriteString(s)
+}
+
+func (w *writer) brk(s string) {
+	if len(s) > len(w.pending) {
+		w.pending = s
+	}
+}
+
+func (w *writer) space()     { w.brk(" ") }
+func (w *writer) lineBreak() { w.brk("\n") }
+func (w *writer) block()     { w.brk("\n\n") }
+
+func (w *writer) String() string { return w.b.String() }
+
+// render renders the children of n into a new writer.
+func render(n *html.Node) *writer {
+	w := &writer{}
+	children(w, n)
+	return w
+}
+
+func children(w *writer, n *html.Node) {
+	for c := range n.ChildNodes() {
+		node(w, c)
+	}
+}
+
+func node(w *writer, n *html.Node) {
+	switch n.Type {
+	case html.DocumentNode:
+		children(w, n)
+		return
+	case html.TextNode:
+		text(w, n.Data)
+		return
+	case html.ElementNode:
+	default:
+		return
+	}
+	if hidden(n) {
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.Head, atom.Script, atom.Style, atom.Title, atom.Meta, atom.Link,
+		atom.Noscript, atom.Template, atom.Iframe, atom.Object, atom.Embed,
+		atom.Input, atom.Select, atom.Textarea, atom.Svg, atom.Canvas:
+		return
+	case atom.Br:
+		w.lineBreak()
+	case atom.Hr:
+		w.block()
+		w.write("---")
+		w.block()
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		if s := inline(render(n).String()); s != "" {
+			w.block()
+			w.write(strings.Repeat("#", int(n.Data[1]-'0')) + " " + s)
+			w.block()
+		}
+	case atom.P, atom.Div, atom.Section, atom.Article, atom.Header, atom.Footer,
+		atom.Main, atom.Nav, atom.Aside, atom.Address, atom.Center, atom.Figure,
+		atom.Figcaption, atom.Details, atom.Summary, atom.Fieldset, atom.Form,
+		atom.Dl, atom.Dt, atom.Dd, atom.Body, atom.Html:
+		w.block()
+		children(w, n)
+		w.block()
+	case atom.Blockquote:
+		if s := render(n).String(); s != "" {
+			w.block()
+			w.write(prefixLines(s, "> ", ">"))
+			w.block()
+		}
+	case atom.Pre:
+		var b strings.Builder
+		preText(&b, n)
+		if s := strings.Trim(b.String(), "\n"); s != "" {
+			w.block()
+			w.write("```\n" + s + "\n```")
+			w.block()
+		}
+	case atom.Ul, atom.Ol:
+		list(w, n)
+	case atom.Li:
+		// A list item outside of a list
+		w.block()
+		w.write(prefixFirst(render(n).String(), "- "))
+		w.block()
+	case atom.Table:
+		table(w, n)
+	case atom.A:
+		link(w, n)
+	case atom.Img:
+		image(w, n)
+	case atom.Strong, atom.B:
+		wrap(w, n, "**")
+	case atom.Em, atom.I:
+		wrap(w, n, "*")
+	case atom.Del, atom.S, atom.Strike:
+		wrap(w, n, "~~")
+	case atom.Code, atom.Kbd, atom.Samp, atom.Tt:
+		if s := strings.TrimSpace(textContent(n)); s != "" {
+			fence := "`"
+			if strings.Contains(s, "`") {
+				fence = "``"
+			}
+			w.write(fence + s + fence)
+		}
+	default:
+		children(w, n)
+	}
+}
+
+// zeroWidth removes invisible characters used to pad preview texts.
+var zeroWidth = strings.NewReplacer("\u200b", "", "\u200c", "", "\u200d", "", "\ufeff", "", "\u034f", "", "\u00ad", "")
+
+func text(w *writer, s string) {
+	s = zeroWidth.Replace(s)
+	words := strings.Fields(s)
+	if len(words) == 0 {
+		if s != "" {
+			w.space()
+		}
+		return
+	}
+	if unicode.IsSpace([]rune(s)[0]) {
+		w.space()
+	}
+	w.write(escape(strings.Join(words, " ")))
+	if r := []rune(s); unicode.IsSpace(r[len(r)-1]) {
+		w.space()
+	}
+}
+
+// escape escapes the characters that would be read as Markdown emphasis,
+// code or links. Underscores inside words are left alone, they don't
+// start emphasis.
+func escape(s string) string {
+	var b strings.Builder
+	r := []rune(s)
+	for i, c := range r {
+		switch c {
+		case '\\', '*', '`', '[', ']':
+			b.WriteRune('\\')
+		case '_':
+			if i == 0 || i == len(r)-1 || !isWord(r[i-1]) || !isWord(r[i+1]) {
+				b.WriteRune('\\')
+			}
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+func isWord(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }
+
+// wrap writes the children of n enclosed in the emphasis marker, keeping
+// the white space around them outside of the markers.
+func wrap(w *writer, n *html.Node, marker string) {
+	sub := render(n)
+	s := sub.String()
+	if s == "" {
+		if sub.lead || sub.pending != "" {
+			w.space()
+		}
+		return
+	}
+	if sub.lead {
+		w.space()
+	}
+	w.write(marker + s + marker)
+	if sub.pending != "" {
+		w.space()
+	}
+}
+
+func link(w *writer, n *html.Node) {
+	href := strings.TrimSpace(attr(n, "href"))
+	sub := render(n)
+	s := inline(sub.String())
+	if sub.lead {
+		w.space()
+	}
+	lower := strings.ToLower(href)
+	switch {
+	case href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(lower, "javascript:"):
+		w.write(s)
+	case s == "" || s == escape(href) || s == escape(strings.TrimPrefix(href, "mailto:")):
+		w.write("<" + href + ">")
+	default:
+		w.write("[" + s + "](" + linkTarget(href) + ")")
+	}
+	if sub.pending != "" {
+		w.space()
+	}
+}
+
+// linkTarget encloses targets with spaces or parentheses in angle brackets.
+func linkTarget(href string) string {
+	if strings.ContainsAny(href, " ()") {
+		return "<" + href + ">"
+	}
+	return href
+}
+
+func image(w *writer, n *html.Node) {
+	src := strings.TrimSpace(attr(n, "src"))
+	if src == "" || trackingPixel(n) {
+		return
+	}
+	alt := escape(strings.Join(strings.Fields(attr(n, "alt")), " "))
+	lower := strings.ToLower(src)
+	if !strings.HasPrefix(lower, "http://") && !strings.HasPrefix(lower, "https://") {
+		// Inline (cid:) and data images can't be displayed, keep the text
+		w.write(alt)
+		return
+	}
+	w.write("![" + alt + "](" + linkTarget(src) + ")")
+}
+
+// trackingPixel reports whether an image is 1x1 pixel or smaller, as used to
+// track when a mail is opened.
+func trackingPixel(n *html.Node) bool {
+	style := styles(n)
+	tiny := func(attrName string) bool {
+		v := attr(n, attrName)
+		if s, ok := style[attrName]; ok {
+			v = s
+		}
+		v = strings.TrimSuffix(strings.TrimSpace(v), "px")
+		size, err := strconv.ParseFloat(v, 64)
+		return err == nil && size <= 1
+	}
+	return tiny("width") || tiny("height")
+}
+
+// hidden reports whether an element is not displayed.
+func hidden(n *html.Node) bool {
+	if _, ok := attrOK(n, "hidden"); ok {
+		return true
+	}
+	if strings.EqualFold(attr(n, "type"), "hidden") {
+		return true
+	}
+	style := styles(n)
+	switch {
+	case style["display"] == "none",
+		style["visibility"] == "hidden",
+		style["mso-hide"] == "all",
+		style["opacity"] == "0",
+		style["max-height"] == "0" || style["max-height"] == "0px":
+		return true
+	}
+	return false
+}
+
+// styles returns the declarations of the style attribute, lower-cased and
+// without !important.
+func styles(n *html.Node) map[string]string {
+	m := map[string]string{}
+	for decl := range strings.SplitSeq(attr(n, "style"), ";") {
+		name, value, ok := strings.Cut(decl, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(strings.ToLower(value)), "!important"))
+		m[strings.TrimSpace(strings.ToLower(name))] = value
+	}
+	return m
+}
+
+func attr(n *html.Node, name string) string {
+	v, _ := attrOK(n, name)
+	return v
+}
+
+func attrOK(n *html.Node, name string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Namespace == "" && a.Key == name {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+func list(w *writer, n *html.Node) {
+	number, _ := strconv.Atoi(attr(n, "start"))
+	if number == 0 {
+		number = 1
+	}
+	var items []string
+	for c := range n.ChildNodes() {
+		if c.Type != html.ElementNode || hidden(c) {
+			continue
+		}
+		s := render(c).String()
+		if s == "" {
+			continue
+		}
+		if c.DataAtom != atom.Li && len(items) > 0 {
+			// A nested list directly inside the list belongs to the
+			// previous item
+			items[len(items)-1] += "\n" + prefixLines(s, "    ", "")
+			continue
+		}
+		marker := "- "
+		if n.DataAtom == atom.Ol {
+			marker = strconv.Itoa(number) + ". "
+			number++
+		}
+		items = append(items, prefixFirst(s, marker))
+	}
+	if len(items) > 0 {
+		w.block()
+		w.write(strings.Join(items, "\n"))
+		w.block()
+	}
+}
+
+func table(w *writer, n *html.Node) {
+	rows := tableRows(n)
+	if layoutTable(n, rows) {
+		for _, row := range rows {
+			for _, cell := range row {
+				w.block()
+				children(w, cell)
+				w.block()
+			}
+		}
+		return
+	}
+
+	cols := 0
+	for _, row := range rows {
+		cols = max(cols, len(row))
+	}
+	var lines []string
+	for i, row := range rows {
+		cells := make([]string, cols)
+		for j, cell := range row {
+			s := inline(render(cell).String())
+			cells[j] = strings.ReplaceAll(s, "|", `\|`)
+		}
+		lines = append(lines, "| "+strings.Join(cells, " | ")+" |")
+		if i == 0 {
+			lines = append(lines, "|"+strings.Repeat(" --- |", cols))
+		}
+	}
+	w.block()
+	w.write(strings.Join(lines, "\n"))
+	w.block()
+}
+
+// tableRows returns the cells of the rows of a table, not of nested tables.
+func tableRows(n *html.Node) [][]*html.Node {
+	var rows [][]*html.Node
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		for c := range n.ChildNodes() {
+			if c.Type != html.ElementNode || hidden(c) {
+				continue
+			}
+			switch c.DataAtom {
+			case atom.Thead, atom.Tbody, atom.Tfoot:
+				walk(c)
+			case atom.Tr:
+				var row []*html.Node
+				for cell := range c.ChildNodes() {
+					if cell.Type == html.ElementNode && (cell.DataAtom == atom.Td || cell.DataAtom == atom.Th) && !hidden(cell) {
+						row = append(row, cell)
+					}
+				}
+				if len(row) > 0 {
+					rows = append(rows, row)
+				}
+			}
+		}
+	}
+	walk(n)
+	return rows
+}
+
+// layoutTable reports whether a table arranges content rather than holding
+// data: it is marked as presentation, has a single column, or its cells
+// contain blocks, images or other tables.
+func layoutTable(n *html.Node, rows [][]*html.Node) bool {
+	if role := attr(n, "role"); role == "presentation" || role == "none" {
+		return true
+	}
+	if len(rows) == 0 {
+		return true
+	}
+	cols := 0
+	for _, row := range rows {
+		cols = max(cols, len(row))
+		for _, cell := range row {
+			for d := range cell.Descendants() {
+				switch d.DataAtom {
+				case atom.Table, atom.P, atom.Div, atom.Ul, atom.Ol, atom.Blockquote, atom.Pre,
+					atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6, atom.Img:
+					return true
+				}
+			}
+		}
+	}
+	return cols < 2
+}
+
+func preText(b *strings.Builder, n *html.Node) {
+	for c := range n.ChildNodes() {
+		switch {
+		case c.Type == html.TextNode:
+			b.WriteString(c.Data)
+		case c.Type == html.ElementNode && c.DataAtom == atom.Br:
+			b.WriteString("\n")
+		case c.Type == html.ElementNode && !hidden(c):
+			preText(b, c)
+		}
+	}
+}
+
+func textContent(n *html.Node) string {
+	var b strings.Builder
+	for d := range n.Descendants() {
+		if d.Type == html.TextNode {
+			b.WriteString(d.Data)
+		}
+	}
+	return strings.Join(strings.Fields(zeroWidth.Replace(b.String())), " ")
+}
+
+// inline joins the lines of s, for headings, links and table cells.
+func inline(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// prefixFirst prefixes the first line of s and indents the others to match.
+func prefixFirst(s, prefix string) string {
+	first, rest, _ := strings.Cut(s, "\n")
+	if rest == "" {
+		return prefix + first
+	}
+	return prefix + first + "\n" + prefixLines(rest, strings.Repeat(" ", len(prefix)), "")
+}
+
+// prefixLines prefixes every line of s, empty lines with emptyPrefix.
+func prefixLines(s, prefix, emptyPrefix string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if line == "" {
+			lines[i] = emptyPrefix
+		} else {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// cleanup removes trailing white space and repeated empty lines.
+func cleanup(s string) string {
+	var out []string
+	empty := false
+	for line := range strings.SplitSeq(s, "\n") {
+		line = strings.TrimRightFunc(line, unicode.IsSpace)
+		if line == "" {
+			if empty || len(out) == 0 {
+				continue
+			}
+			empty = true
+		} else {
+			empty = false
+		}
+		out = append(out, line)
+	}
+	return strings.TrimSpace(strings.Join(out, "\n"))
+}
@@ -0,0 +1,114 @@
+package md
+
+import "testing"
+
+func TestFromHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{
+			name: "paragraphs and line breaks",
+			html: "<p>Hello   <b>World</b>!</p><p>Line 1<br>Line 2</p>",
+			want: "Hello **World**!\n\nLine 1\nLine 2",
+		},
+		{
+			name: "emphasis keeps white space outside of markers",
+			html: "<p>a<strong> bold </strong>b <em>it</em>, <s>gone</s></p>",
+			want: "a **bold** b *it*, ~~gone~~",
+		},
+		{
+			name: "headings",
+			html: "<h1>Title</h1><h3>Sub\n title</h3><p>Text</p>",
+			want: "# Title\n\n### Sub title\n\nText",
+		},
+		{
+			name: "links keep their targets",
+			html: `<p>See <a href="https://example.com/a">the docs</a>, <a href="https://example.com">https://example.com</a>, <a href="mailto:me@example.com">me@example.com</a> and <a href="#top">top</a>.</p>`,
+			want: "See [the docs](https://example.com/a), <https://example.com>, <mailto:me@example.com> and top.",
+		},
+		{
+			name: "link targets with parentheses",
+			html: `<a href="https://en.wikipedia.org/wiki/Go_(language)">Go</a>`,
+			want: "[Go](<https://en.wikipedia.org/wiki/Go_(language)>)",
+		},
+		{
+			name: "style, script and head are dropped",
+			html: "<html><head><title>T</title><style>p{color:red}</style></head><body><script>alert(1)</script><p>Body</p></body></html>",
+			want: "Body",
+		},
+		{
+			name: "hidden elements are dropped",
+			html: `<div style="display: none !important">preheader</div><span style="visibility:hidden">x</span><div hidden>y</div><div style="max-height:0;overflow:hidden">z</div><!--[if mso]><p>mso</p><![endif]--><p>Visible</p>`,
+			want: "Visible",
+		},
+		{
+			name: "tracking pixels are dropped, images kept",
+			html: `<p><img src="https://t.example.com/open.gif" width="1" height="1"><img src="https://t.example.com/o.gif" style="width:0px;height:0px"><img src="https://example.com/logo.png" alt="Logo"><img src="cid:part1" alt="Inline"></p>`,
+			want: "![Logo](https://example.com/logo.png)Inline",
+		},
+		{
+			name: "linked image",
+			html: `<a href="https://example.com"><img src="https://example.com/b.png" alt="Buy"></a>`,
+			want: "[![Buy](https://example.com/b.png)](https://example.com)",
+		},
+		{
+			name: "lists",
+			html: "<ul><li>One</li><li>Two<ul><li>Nested</li></ul></li></ul><ol start=\"3\"><li>Three</li><li>Four</li></ol>",
+			want: "- One\n- Two\n\n  - Nested\n\n3. Three\n4. Four",
+		},
+		{
+			name: "blockquote",
+			html: "<blockquote><p>Quoted</p><p>Text</p></blockquote>",
+			want: "> Quoted\n>\n> Text",
+		},
+		{
+			name: "pre and code",
+			html: "<p>Run <code>go test</code>:</p><pre>line 1\n  line 2</pre>",
+			want: "Run `go test`:\n\n```\nline 1\n  line 2\n```",
+		},
+		{
+			name: "data table",
+			html: "<table><tr><th>Name</th><th>Amount</th></tr><tr><td>A|B</td><td>10</td></tr><tr><td>C</td></tr></table>",
+			want: "| Name | Amount |\n| --- | --- |\n| A\\|B | 10 |\n| C |  |",
+		},
+		{
+			name: "layout table",
+			html: `<table role="presentation"><tr><td><p>Column 1</p></td><td>Column 2</td></tr></table>`,
+			want: "Column 1\n\nColumn 2",
+		},
+		{
+			name: "nested layout table",
+			html: "<table><tr><td><table><tr><td>Name</td><td>Value</td></tr></table></td></tr></table>",
+			want: "| Name | Value |\n| --- | --- |",
+		},
+		{
+			name: "markdown characters are escaped",
+			html: "<p>2 * 3 = 6, [x], snake_case, _under_</p>",
+			want: `2 \* 3 = 6, \[x\], snake_case, \_under\_`,
+		},
+		{
+			name: "zero width padding and entities",
+			html: "<p>Preview&nbsp;&zwnj;&nbsp;&zwnj;</p><p>R&amp;D &lt;tag&gt;</p>",
+			want: "Preview\n\nR&D <tag>",
+		},
+		{
+			name: "empty",
+			html: "",
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FromHTML(tt.html)
+			if err != nil {
+				t.Fatalf("FromHTML() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("FromHTML() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
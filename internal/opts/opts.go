@@ -26,6 +26,10 @@ type RunCmd struct {
 	Port      int                   `long:"port" env:"APPLE_MAIL_MCP_PORT" description:"HTTP port (only used with --transport=http)" default:"8787"`
 	Host      string                `long:"host" env:"APPLE_MAIL_MCP_HOST" description:"HTTP host (only used with --transport=http)" default:"localhost"`
 	Debug     bool                  `long:"debug" env:"APPLE_MAIL_MCP_DEBUG" description:"Enable debug logging of tool calls and results to stderr"`
+	Record    string                `long:"record" env:"APPLE_MAIL_MCP_RECORD" value-name:"DIR" description:"Record every JXA script call and its raw output to this directory"`
+	Replay    string                `long:"replay" env:"APPLE_MAIL_MCP_REPLAY" value-name:"DIR" description:"Serve JXA script calls from recordings in this directory instead of Mail.app"`
+
+	tools.Config
 
 	Handler func() error
 }
@@ -68,9 +72,10 @@ type LaunchdCreateCmd struct {
 	DisableRunAtLoad bool `long:"disable-run-at-load" description:"Disable automatic startup on login (service must be started manually)"`
 
 	// Configuration for the service
-	Port  int    `long:"port" description:"HTTP port for the service" default:"8787"`
-	Host  string `long:"host" description:"HTTP host for the service" default:"localhost"`
-	Debug bool   `long:"debug" description:"Enable debug logging for the service"`
+	Port       int    `long:"port" description:"HTTP port for the service" default:"8787"`
+	Host       string `long:"host" description:"HTTP host for the service" default:"localhost"`
+	Debug      bool   `long:"debug" description:"Enable debug logging for the service"`
+	EnableSend bool   `long:"enable-send" description:"Allow the service to send messages with send_outgoing_message and schedule_send"`
 
 	Handler func() error
 }
@@ -111,6 +116,8 @@ func (c *LaunchdRestartCmd) Execute(args []string) error {
 
 // ToolCmd holds tool subcommands
 type ToolCmd struct {
+	tools.Config
+
 	ListAccounts           ListAccountsCmd           `command:"list_accounts" description:"Lists all configured email accounts"`
 	ListMailboxes          ListMailboxesCmd          `command:"list_mailboxes" description:"Lists mailboxes for a specific account"`
 	GetMessageContent      GetMessageContentCmd      `command:"get_message_content" description:"Retrieves the full content of a specific message"`
@@ -124,6 +131,33 @@ type ToolCmd struct {
 	ReplaceOutgoingMessage ReplaceOutgoingMessageCmd `command:"replace_outgoing_message" description:"Replaces an existing outgoing message"`
 	DeleteOutgoingMessage  DeleteOutgoingMessageCmd  `command:"delete_outgoing_message" description:"Deletes an outgoing message"`
 	FindMessages           FindMessagesCmd           `command:"find_messages" description:"Find messages in a mailbox"`
+	MoveMessages           MoveMessagesCmd           `command:"move_messages" description:"Moves messages to another mailbox"`
+	UpdateMessages         UpdateMessagesCmd         `command:"update_messages" description:"Updates read, flagged, flag color and junk status of messages"`
+	DeleteMessages         DeleteMessagesCmd         `command:"delete_messages" description:"Moves messages to the Trash or deletes them permanently"`
+	ListAttachments        ListAttachmentsCmd        `command:"list_attachments" description:"Lists the attachments of a message"`
+	SaveAttachment         SaveAttachmentCmd         `command:"save_attachment" description:"Saves an attachment to the attachments directory"`
+	GetThread              GetThreadCmd              `command:"get_thread" description:"Reconstruct the conversation a message belongs to"`
+	GetMessageSource       GetMessageSourceCmd       `command:"get_message_source" description:"Retrieves the raw source of a message"`
+	ExportMessages         ExportMessagesCmd         `command:"export_messages" description:"Exports messages as .eml files or one mbox file"`
+	CreateForward          CreateForwardCmd          `command:"create_forward" description:"Forwards a message with an optional note"`
+	ReplaceForward         ReplaceForwardCmd         `command:"replace_forward" description:"Replaces a forward with new recipients and note"`
+	RedirectMessage        RedirectMessageCmd        `command:"redirect_message" description:"Redirects a message unchanged to new recipients"`
+	SendOutgoingMessage    SendOutgoingMessageCmd    `command:"send_outgoing_message" description:"Sends an outgoing message after a policy check"`
+	ScheduleSend           ScheduleSendCmd           `command:"schedule_send" description:"Queues an outgoing message to be sent later"`
+	ListScheduled          ListScheduledCmd          `command:"list_scheduled" description:"Lists scheduled messages"`
+	CancelScheduled        CancelScheduledCmd        `command:"cancel_scheduled" description:"Cancels a scheduled message"`
+	CreateMailbox          CreateMailboxCmd          `command:"create_mailbox" description:"Create a mailbox"`
+	RenameMailbox          RenameMailboxCmd          `command:"rename_mailbox" description:"Rename or move a mailbox"`
+	DeleteMailbox          DeleteMailboxCmd          `command:"delete_mailbox" description:"Delete a mailbox"`
+	ListRules              ListRulesCmd              `command:"list_rules" description:"List the rules of Mail"`
+	CreateRule             CreateRuleCmd             `command:"create_rule" description:"Create a rule"`
+	UpdateRule             UpdateRuleCmd             `command:"update_rule" description:"Update a rule"`
+	DeleteRule             DeleteRuleCmd             `command:"delete_rule" description:"Delete a rule"`
+	ListSignatures         ListSignaturesCmd         `command:"list_signatures" description:"List signatures"`
+	ListTemplates          ListTemplatesCmd          `command:"list_templates" description:"List message templates"`
+	CreateFromTemplate     CreateFromTemplateCmd     `command:"create_from_template" description:"Create an outgoing message from a template"`
+	MailMerge              MailMergeCmd              `command:"mail_merge" description:"Create one outgoing message per row of a dataset"`
+	ResolveContacts        ResolveContactsCmd        `command:"resolve_contacts" description:"Find the addresses of people by name"`
 }
 
 // ListAccountsCmd represents the 'tool list_accounts' command
@@ -307,6 +341,384 @@ func (c *DeleteOutgoingMessageCmd) Execute(args []string) error {
 	return nil
 }
 
+// MoveMessagesCmd represents the 'tool move_messages' command
+type MoveMessagesCmd struct {
+	tools.MoveMessagesInput
+	Handler func(tools.MoveMessagesInput) error
+}
+
+// Execute runs the move_messages tool command
+func (c *MoveMessagesCmd) Execute(args []string) error {
+	if c.Handler != nil {
+		return c.Handler(c.MoveMessagesInput)
+	}
+	return nil
+}
+
+// UpdateMessagesCmd represents the 'tool update_messages' command
+type UpdateMessagesCmd struct {
+	tools.UpdateMessagesInput
+	Handler func(tools.UpdateMessagesInput) error
+}
+
+// Execute runs the update_messages tool command
+func (c *UpdateMessagesCmd) Execute(args []string) error {
+	if c.Handler != nil {
+		return c.Handler(c.UpdateMessagesInput)
+	}
+	return nil
+}
+
+// DeleteMessagesCmd represents the 'tool delete_messages' command
+type DeleteMessagesCmd struct {
+	tools.DeleteMessagesInput
+	Handler func(tools.DeleteMessagesInput) error
+}
+
+// Execute runs the delete_messages tool command
+func (c *DeleteMessagesCmd) Execute(args []string) error {
+	if c.Handler != nil {
+		return c.Handler(c.DeleteMessagesInput)
+	}
+	return nil
+}
+
+// ListAttachmentsCmd represents the 'tool list_attachments' command
+type ListAttachmentsCmd struct {
+	tools.ListAttachmentsInput
+	Handler func(tools.ListAttachmentsInput) error
+}
+
+// Execute runs the list_attachments tool command
+func (c *ListAttachmentsCmd) Execute(args []string) error {
+	if c.Handler != nil {
+		return c.Handler(c.ListAttachmentsInput)
+	}
+	return nil
+}
+
+// SaveAttachmentCmd represents the 'tool save_attachment' command
+type SaveAttachmentCmd struct {
+	tools.SaveAttachmentInput
+	Handler func(tools.SaveAttachmentInput) error
+}
+
+// Execute runs the save_attachment tool command
+func (c *SaveAttachmentCmd) Execute(args []string) error {
+	if c.Handler != nil {
+		return c.Handler(c.SaveAttachmentInput)
+	}
+	return nil
+}
+
+// GetThreadCmd represents the 'tool get_thread' command
+type GetThreadCmd struct {
+	tools.GetThreadInput
+	Handler func(tools.GetThreadInput) error
+}
+
+// Execute runs the get_thread tool command
+func (c *GetThreadCmd) Execute(args []string) error {
+	if c.Handler != nil {
+		return c.Handler(c.GetThreadInput)
+	}
+	return nil
+}
+
+// GetMessageSourceCmd represents the 'tool get_message_source' command
+type GetMessageSourceCmd struct {
+	tools.GetMessageSourceInput
+	Handler func(tools.GetMessageSourceInput) error
+}
+
+// Execute runs the get_message_source tool command
+func (c *GetMessageSourceCmd) Execute(args []string) error {
+	if c.Handler != nil {
+		return c.Handler(c.GetMessageSourceInput)
+	}
+	return nil
+}
+
+// ExportMessagesCmd represents the 'tool export_messages' command
+type ExportMessagesCmd struct {
+	tools.ExportMessagesInput
+	Handler func(tools.ExportMessagesInput) error
+}
+
+// Execute runs the export_messages tool command
+func (c *ExportMessagesCmd) Execute(args []string) error {
+	if c.Handler != nil {
+		return c.Handler(c.ExportMessagesInput)
+	}
+	return nil
+}
+
+// CreateForwardCmd represents the 'tool create_forward' command
+type CreateForwardCmd struct {
+	tools.CreateForwardInput
+	Handler func(tools.CreateForwardInput) error
+}
+
+// Execute runs the create_forward tool command
+func (c *CreateForwardCmd) Execute(args []string) error {
+	if c.Handler != nil {
+		return c.Handler(c.CreateForwardInput)
+	}
+	return nil
+}
+
+// ReplaceForwardCmd represents the 'tool replace_forward' command
+type ReplaceForwardCmd struct {
+	tools.ReplaceForwardInput
+	Handler func(tools.ReplaceForwardInput) error
+}
+
+// Execute runs the replace_forward tool command
+func (c *ReplaceForwardCmd) Execute(args []string) error {
+	if c.Handler != nil {
+		return c.Handler(c.ReplaceForwardInput)
+	}
+	return nil
+}
+
+// RedirectMessageCmd represents the 'tool redirect_message' command
+type RedirectMessageCmd struct {
+	tools.RedirectMessageInput
+	Handler func(tools.RedirectMessageInput) error
+}
+
+// Execute runs the redirect_message tool command
+func (c *RedirectMessageCmd) Execute(args []string) error {
+	if c.Handler != nil {
+		return c.Handler(c.RedirectMessageInput)
+	}
+	return nil
+}
+
+// SendOutgoingMessageCmd represents the 'tool send_outgoing_message' command
+type SendOutgoingMessageCmd struct {
+	tools.SendOutgoingMessageInput
+	Handler func(tools.SendOutgoingMessageInput) error
+}
+
+// Execute runs the send_outgoing_message tool command
+func (c *SendOutgoingMessageCmd) Execute(args []string) error {
+	if c.Handler != nil {
+		return c.Handler(c.SendOutgoingMessageInput)
+	}
+	return nil
+}
+
+// ScheduleSendCmd represents the 'tool schedule_send' command
+type ScheduleSendCmd struct {
+	tools.ScheduleSendInput
+	Handler func(tools.ScheduleSendInput) error
+}
+
+// Execute runs the schedule_send tool command
+func (c *ScheduleSendCmd) Execute(args []string) error {
+	if c.Handler != nil {
+		return c.Handler(c.ScheduleSendInput)
+	}
+	return nil
+}
+
+// ListScheduledCmd represents the 'tool list_scheduled' command
+type ListScheduledCmd struct {
+	tools.ListScheduledInput
+	Handler func(tools.ListScheduledInput) error
+}
+
+// Execute runs the list_scheduled tool command
+func (c *ListScheduledCmd) Execute(args []string) error {
+	if c.Handler != nil {
+		return c.Handler(c.ListScheduledInput)
+	}
+	return nil
+}
+
+// CancelScheduledCmd represents the 'tool cancel_scheduled' command
+type CancelScheduledCmd struct {
+	tools.CancelScheduledInput
+	Handler func(tools.CancelScheduledInput) error
+}
+
+// Execute runs the cancel_scheduled tool command
+func (c *CancelScheduledCmd) Execute(args []string) error {
+	if c.Handler != nil {
+		return c.Handler(c.CancelScheduledInput)
+	}
+	return nil
+}
+
+// CreateMailboxCmd represents the 'tool create_mailbox' command
+type CreateMailboxCmd struct {
+	tools.CreateMailboxInput
+	Handler func(tools.CreateMailboxInput) error
+}
+
+// Execute runs the create_mailbox tool command
+func (c *CreateMailboxCmd) Execute(args []string) error {
+	if c.Handler != nil {
+		return c.Handler(c.CreateMailboxInput)
+	}
+	return nil
+}
+
+// RenameMailboxCmd represents the 'tool rename_mailbox' command
+type RenameMailboxCmd struct {
+	tools.RenameMailboxInput
+	Handler func(tools.RenameMailboxInput) error
+}
+
+// Execute runs the rename_mailbox tool command
+func (c *RenameMailboxCmd) Execute(args []string) error {
+	if c.Handler != nil {
+		return c.Handler(c.RenameMailboxInput)
+	}
+	return nil
+}
+
+// DeleteMailboxCmd represents the 'tool delete_mailbox' command
+type DeleteMailboxCmd struct {
+	tools.DeleteMailboxInput
+	Handler func(tools.DeleteMailboxInput) error
+}
+
+// Execute runs the delete_mailbox tool command
+func (c *DeleteMailboxCmd) Execute(args []string) error {
+	if c.Handler != nil {
+		return c.Handler(c.DeleteMailboxInput)
+	}
+	return nil
+}
+
+// ListRulesCmd represents the 'tool list_rules' command
+type ListRulesCmd struct {
+	tools.ListRulesInput
+	Handler func(tools.ListRulesInput) error
+}
+
+// Execute runs the list_rules tool command
+func (c *ListRulesCmd) Execute(args []string) error {
+	if c.Handler != nil {
+		return c.Handler(c.ListRulesInput)
+	}
+	return nil
+}
+
+// CreateRuleCmd represents the 'tool create_rule' command
+type CreateRuleCmd struct {
+	tools.CreateRuleInput
+	Handler func(tools.CreateRuleInput) error
+}
+
+// Execute runs the create_rule tool command
+func (c *CreateRuleCmd) Execute(args []string) error {
+	if c.Handler != nil {
+		return c.Handler(c.CreateRuleInput)
+	}
+	return nil
+}
+
+// UpdateRuleCmd represents the 'tool update_rule' command
+type UpdateRuleCmd struct {
+	tools.UpdateRuleInput
+	Handler func(tools.UpdateRuleInput) error
+}
+
+// Execute runs the update_rule tool command
+func (c *UpdateRuleCmd) Execute(args []string) error {
+	if c.Handler != nil {
+		return c.Handler(c.UpdateRuleInput)
+	}
+	return nil
+}
+
+// DeleteRuleCmd represents the 'tool delete_rule' command
+type DeleteRuleCmd struct {
+	tools.DeleteRuleInput
+	Handler func(tools.DeleteRuleInput) error
+}
+
+// Execute runs the delete_rule tool command
+func (c *DeleteRuleCmd) Execute(args []string) error {
+	if c.Handler != nil {
+		return c.Handler(c.DeleteRuleInput)
+	}
+	return nil
+}
+
+// ListSignaturesCmd represents the 'tool list_signatures' command
+type ListSignaturesCmd struct {
+	tools.ListSignaturesInput
+	Handler func(tools.ListSignaturesInput) error
+}
+
+// Execute runs the list_signatures tool command
+func (c *ListSignaturesCmd) Execute(args []string) error {
+	if c.Handler != nil {
+		return c.Handler(c.ListSignaturesInput)
+	}
+	return nil
+}
+
+// ListTemplatesCmd represents the 'tool list_templates' command
+type ListTemplatesCmd struct {
+	tools.ListTemplatesInput
+	Handler func(tools.ListTemplatesInput) error
+}
+
+// Execute runs the list_templates tool command
+func (c *ListTemplatesCmd) Execute(args []string) error {
+	if c.Handler != nil {
+		return c.Handler(c.ListTemplatesInput)
+	}
+	return nil
+}
+
+// CreateFromTemplateCmd represents the 'tool create_from_template' command
+type CreateFromTemplateCmd struct {
+	tools.CreateFromTemplateInput
+	Handler func(tools.CreateFromTemplateInput) error
+}
+
+// Execute runs the create_from_template tool command
+func (c *CreateFromTemplateCmd) Execute(args []string) error {
+	if c.Handler != nil {
+		return c.Handler(c.CreateFromTemplateInput)
+	}
+	return nil
+}
+
+// MailMergeCmd represents the 'tool mail_merge' command
+type MailMergeCmd struct {
+	tools.MailMergeInput
+	Handler func(tools.MailMergeInput) error
+}
+
+// Execute runs the mail_merge tool command
+func (c *MailMergeCmd) Execute(args []string) error {
+	if c.Handler != nil {
+		return c.Handler(c.MailMergeInput)
+	}
+	return nil
+}
+
+// ResolveContactsCmd represents the 'tool resolve_contacts' command
+type ResolveContactsCmd struct {
+	tools.ResolveContactsInput
+	Handler func(tools.ResolveContactsInput) error
+}
+
+// Execute runs the resolve_contacts tool command
+func (c *ResolveContactsCmd) Execute(args []string) error {
+	if c.Handler != nil {
+		return c.Handler(c.ResolveContactsInput)
+	}
+	return nil
+}
+
 var GlobalOpts = Options{}
 
 // Parse parses command-line arguments and environment variables
@@ -237,3 +237,132 @@ func TestParse_FlagsOverrideEnvironment(t *testing.T) {
 		t.Errorf("Expected port 6000 from flag, got %d", GlobalOpts.Run.Port)
 	}
 }
+
+func TestParse_RecordAndReplay(t *testing.T) {
+	oldArgs := os.Args
+	defer func() { os.Args = oldArgs }()
+
+	os.Args = []string{"mail-mcp", "run", "--record=/tmp/rec"}
+	if _, err := Parse(); err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+	if GlobalOpts.Run.Record != "/tmp/rec" {
+		t.Errorf("Expected record '/tmp/rec', got '%s'", GlobalOpts.Run.Record)
+	}
+
+	os.Args = []string{"mail-mcp", "run", "--record=", "--replay=/tmp/rec"}
+	if _, err := Parse(); err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+	if GlobalOpts.Run.Replay != "/tmp/rec" {
+		t.Errorf("Expected replay '/tmp/rec', got '%s'", GlobalOpts.Run.Replay)
+	}
+}
+
+func TestParse_AttachmentsDir(t *testing.T) {
+	oldArgs := os.Args
+	defer func() { os.Args = oldArgs }()
+
+	os.Args = []string{"mail-mcp", "run", "--attachments-dir=/tmp/att"}
+	if _, err := Parse(); err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+	if GlobalOpts.Run.AttachmentsDir != "/tmp/att" {
+		t.Errorf("Expected attachments dir '/tmp/att', got '%s'", GlobalOpts.Run.AttachmentsDir)
+	}
+
+	os.Args = []string{"mail-mcp", "tool", "--attachments-dir=/tmp/att", "save_attachment", "--account=Work", "--attachment-id=1"}
+	if _, err := Parse(); err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+	if GlobalOpts.Tool.AttachmentsDir != "/tmp/att" {
+		t.Errorf("Expected attachments dir '/tmp/att', got '%s'", GlobalOpts.Tool.AttachmentsDir)
+	}
+}
+
+func TestParse_AttachmentAllowDirs(t *testing.T) {
+	oldArgs := os.Args
+	defer func() { os.Args = oldArgs }()
+
+	os.Args = []string{"mail-mcp", "run", "--attachment-allow-dir=/tmp/a", "--attachment-allow-dir=/tmp/b"}
+	if _, err := Parse(); err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+	if got := GlobalOpts.Run.AttachmentAllowDirs; len(got) != 2 || got[0] != "/tmp/a" || got[1] != "/tmp/b" {
+		t.Errorf("Expected attachment allow dirs [/tmp/a /tmp/b], got %v", got)
+	}
+
+	t.Setenv("APPLE_MAIL_MCP_ATTACHMENT_ALLOW_DIRS", "/tmp/c:/tmp/d")
+	GlobalOpts = Options{}
+	os.Args = []string{"mail-mcp", "run"}
+	if _, err := Parse(); err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+	if got := GlobalOpts.Run.AttachmentAllowDirs; len(got) != 2 || got[0] != "/tmp/c" || got[1] != "/tmp/d" {
+		t.Errorf("Expected attachment allow dirs [/tmp/c /tmp/d] from environment, got %v", got)
+	}
+}
+
+func TestParse_MailboxPaths(t *testing.T) {
+	oldArgs := os.Args
+	defer func() { os.Args = oldArgs }()
+
+	os.Args = []string{"mail-mcp", "tool", "find_messages", "--account=Work",
+		`--mailbox-paths=["Inbox","GitHub"]`, "--mailbox-paths", `["Archive"]`}
+	if _, err := Parse(); err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+	paths := GlobalOpts.Tool.FindMessages.MailboxPaths
+	if len(paths) != 2 || len(paths[0]) != 2 || paths[0][1] != "GitHub" || paths[1][0] != "Archive" {
+		t.Errorf("Expected [[Inbox GitHub] [Archive]], got %v", paths)
+	}
+
+	os.Args = []string{"mail-mcp", "tool", "find_messages", "--account=Work", "--mailbox-paths=Inbox"}
+	if _, err := Parse(); err == nil {
+		t.Error("Expected an error for a mailbox path that is not JSON")
+	}
+}
+
+func TestParse_RuleJSONFlags(t *testing.T) {
+	oldArgs := os.Args
+	defer func() { os.Args = oldArgs }()
+
+	os.Args = []string{"mail-mcp", "tool", "create_rule", "--name=Invoices",
+		`--condition={"type":"from","value":"billing@example.com"}`,
+		`--condition={"type":"subject","qualifier":"begins_with","value":"Invoice"}`,
+		`--actions={"move_to":{"account":"Work","mailboxPath":["Invoices"]},"mark_read":true}`,
+	}
+	if _, err := Parse(); err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+	input := GlobalOpts.Tool.CreateRule.CreateRuleInput
+	if len(input.Conditions) != 2 || input.Conditions[1].Qualifier != "begins_with" {
+		t.Errorf("Expected 2 conditions, the second begins_with, got %+v", input.Conditions)
+	}
+	if input.Actions.MoveTo == nil || input.Actions.MoveTo.MailboxPath[0] != "Invoices" || !input.Actions.MarkRead {
+		t.Errorf("Expected move to Invoices and mark read, got %+v", input.Actions)
+	}
+
+	os.Args = []string{"mail-mcp", "tool", "update_rule", "--name=Invoices", `--actions={"delete":true}`}
+	if _, err := Parse(); err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+	if actions := GlobalOpts.Tool.UpdateRule.Actions; actions == nil || !actions.Delete {
+		t.Errorf("Expected delete action, got %+v", actions)
+	}
+}
+
+func TestParse_TemplateVariables(t *testing.T) {
+	oldArgs := os.Args
+	defer func() { os.Args = oldArgs }()
+
+	os.Args = []string{"mail-mcp", "tool", "create_from_template", "--template=reset",
+		"--var=name=Bob", "--var", "ticket=42=a"}
+	if _, err := Parse(); err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+	vars := GlobalOpts.Tool.CreateFromTemplate.Variables
+	if vars["name"] != "Bob" || vars["ticket"] != "42=a" {
+		t.Errorf("Expected name=Bob and ticket=42=a, got %v", vars)
+	}
+}
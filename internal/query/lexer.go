@@ -0,0 +1,135 @@
+package query
+
+import (
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokError
+	tokAnd
+	tokOr
+	tokNot
+	tokLParen
+	tokRParen
+	tokPredicate
+)
+
+type token struct {
+	kind tokenKind
+	pos  int
+	text string // the token as written, or the message of a tokError
+
+	// Predicates only: name op value, e.g. subject:"hello" or size>=1MB
+	name  string
+	op    string
+	value string
+}
+
+type lexer struct {
+	input string
+	pos   int
+}
+
+func (l *lexer) lex() token {
+	for l.pos < len(l.input) && unicode.IsSpace(rune(l.input[l.pos])) {
+		l.pos++
+	}
+	start := l.pos
+	if l.pos >= len(l.input) {
+		return token{kind: tokEOF, pos: start}
+	}
+
+	switch l.input[l.pos] {
+	case '(':
+		l.pos++
+		return token{kind: tokLParen, pos: start, text: "("}
+	case ')':
+		l.pos++
+		return token{kind: tokRParen, pos: start, text: ")"}
+	case '"':
+		return token{kind: tokError, pos: start, text: "quoted text needs a field, e.g. subject:\"...\""}
+	}
+
+	for l.pos < len(l.input) && isNameChar(l.input[l.pos]) {
+		l.pos++
+	}
+	name := l.input[start:l.pos]
+	if name == "" {
+		l.pos++
+		return token{kind: tokError, pos: start, text: "unexpected " + string(l.input[start])}
+	}
+
+	op := l.operator()
+	if op == "" {
+		switch strings.ToUpper(name) {
+		case "AND":
+			return token{kind: tokAnd, pos: start, text: name}
+		case "OR":
+			return token{kind: tokOr, pos: start, text: name}
+		case "NOT":
+			return token{kind: tokNot, pos: start, text: name}
+		}
+		return token{kind: tokError, pos: start, text: "expected field:value, got " + quote(name)}
+	}
+
+	value, ok := l.value()
+	if !ok {
+		return token{kind: tokError, pos: start, text: "unterminated quoted text"}
+	}
+	return token{kind: tokPredicate, pos: start, text: l.input[start:l.pos], name: name, op: op, value: value}
+}
+
+// operator consumes the operator after a predicate name, if any.
+func (l *lexer) operator() string {
+	for _, op := range []string{">=", "<=", ":", ">", "<"} {
+		if strings.HasPrefix(l.input[l.pos:], op) {
+			l.pos += len(op)
+			return op
+		}
+	}
+	return ""
+}
+
+// value consumes the value of a predicate: quoted text with \" and \\
+// escapes, or everything up to the next space or parenthesis.
+func (l *lexer) value() (string, bool) {
+	if l.pos < len(l.input) && l.input[l.pos] == '"' {
+		l.pos++
+		var b strings.Builder
+		for l.pos < len(l.input) {
+			c := l.input[l.pos]
+			l.pos++
+			switch {
+			case c == '"':
+				return b.String(), true
+			case c == '\\' && l.pos < len(l.input):
+				b.WriteByte(l.input[l.pos])
+				l.pos++
+			default:
+				b.WriteByte(c)
+			}
+		}
+		return "", false
+	}
+	start := l.pos
+	for l.pos < len(l.input) {
+		c := l.input[l.pos]
+		if c == '(' || c == ')' || unicode.IsSpace(rune(c)) {
+			break
+		}
+		l.pos++
+	}
+	return l.input[start:l.pos], true
+}
+
+func isNameChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_'
+}
+
+func quote(s string) string {
+	return "\"" + s + "\""
+}
@@ -0,0 +1,90 @@
+package query
+
+import (
+	"strings"
+	"time"
+)
+
+// Message is a message a query can be evaluated against.
+type Message interface {
+	// Text returns the text of a text field. For the recipient fields it
+	// returns names and addresses of all recipients.
+	Text(field string) string
+	Bool(field string) bool
+	Number(field string) int64
+	Time(field string) time.Time
+}
+
+// Match reports whether m matches the query n. A nil query matches every
+// message. Nodes decoded from JSON are supported, i.e. numbers may be
+// float64.
+func Match(n *Node, m Message) bool {
+	if n == nil {
+		return true
+	}
+	switch n.Op {
+	case OpAnd:
+		for _, a := range n.Args {
+			if !Match(a, m) {
+				return false
+			}
+		}
+		return true
+	case OpOr:
+		for _, a := range n.Args {
+			if Match(a, m) {
+				return true
+			}
+		}
+		return false
+	case OpNot:
+		return len(n.Args) == 1 && !Match(n.Args[0], m)
+	case OpContains:
+		text, _ := n.Value.(string)
+		return strings.Contains(strings.ToLower(m.Text(n.Field)), strings.ToLower(text))
+	case OpIs:
+		value, _ := n.Value.(bool)
+		return m.Bool(n.Field) == value
+	case OpEq, OpGt, OpGe, OpLt, OpLe:
+		return compare(n.Op, m.Number(n.Field), number(n.Value))
+	case OpAfter, OpBefore:
+		s, _ := n.Value.(string)
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return false
+		}
+		if n.Op == OpAfter {
+			return m.Time(n.Field).After(t)
+		}
+		return m.Time(n.Field).Before(t)
+	}
+	return false
+}
+
+func compare(op string, a, b int64) bool {
+	switch op {
+	case OpEq:
+		return a == b
+	case OpGt:
+		return a > b
+	case OpGe:
+		return a >= b
+	case OpLt:
+		return a < b
+	case OpLe:
+		return a <= b
+	}
+	return false
+}
+
+func number(v any) int64 {
+	switch v := v.(type) {
+	case int64:
+		return v
+	case int:
+		return int64(v)
+	case float64:
+		return int64(v)
+	}
+	return 0
+}
@@ -0,0 +1,386 @@
+// Package query parses the message query expressions of find_messages into
+// a tree of predicates that the find_messages script evaluates.
+//
+// A query combines predicates with AND, OR and NOT and parentheses. Adjacent
+// predicates are joined with AND. Keywords are case-insensitive.
+//
+//	from:boss (subject:"budget 2024" OR has:attachment) NOT is:read size>1MB
+//
+// Supported predicates:
+//
+//	subject:TEXT from:TEXT to:TEXT cc:TEXT body:TEXT   substring match, case-insensitive
+//	is:read is:unread is:flagged is:unflagged
+//	is:replied is:forwarded is:junk                    message status
+//	has:attachment                                     at least one attachment
+//	flag:COLOR                                         red, orange, yellow, green, blue, purple, gray or 0-6
+//	size>N size>=N size<N size<=N                      size in bytes, with optional unit K, KB, M, MB, G or GB
+//	after:DATE before:DATE                             received after or before a date (2006-01-02 or RFC 3339)
+package query
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Operators of a Node.
+const (
+	OpAnd      = "and"
+	OpOr       = "or"
+	OpNot      = "not"
+	OpContains = "contains" // Text(Field) contains Value, case-insensitive
+	OpIs       = "is"       // Bool(Field) equals Value
+	OpEq       = "eq"       // Number(Field) equals Value
+	OpGt       = "gt"       // Number(Field) is greater than Value
+	OpGe       = "ge"
+	OpLt       = "lt"
+	OpLe       = "le"
+	OpAfter    = "after"  // Time(Field) is after Value (RFC 3339)
+	OpBefore   = "before" // Time(Field) is before Value (RFC 3339)
+)
+
+// Fields predicates refer to. They are named after the Mail.app message
+// properties they are evaluated against, except for the recipient lists and
+// hasAttachment.
+const (
+	FieldSubject        = "subject"
+	FieldSender         = "sender"
+	FieldTo             = "to"
+	FieldCc             = "cc"
+	FieldContent        = "content"
+	FieldReadStatus     = "readStatus"
+	FieldFlaggedStatus  = "flaggedStatus"
+	FieldWasRepliedTo   = "wasRepliedTo"
+	FieldWasForwarded   = "wasForwarded"
+	FieldJunkMailStatus = "junkMailStatus"
+	FieldHasAttachment  = "hasAttachment"
+	FieldFlagIndex      = "flagIndex"
+	FieldMessageSize    = "messageSize"
+	FieldDateReceived   = "dateReceived"
+)
+
+// Node is a node of a parsed query. And and Or nodes have two or more Args,
+// Not nodes exactly one. All other nodes are predicates on Field.
+type Node struct {
+	Op    string  `json:"op"`
+	Args  []*Node `json:"args,omitempty"`
+	Field string  `json:"field,omitempty"`
+	Value any     `json:"value,omitempty"`
+}
+
+// And joins nodes with AND, skipping nil nodes. It returns nil if no nodes
+// are left and the node itself if only one is left.
+func And(nodes ...*Node) *Node {
+	var args []*Node
+	for _, n := range nodes {
+		if n != nil {
+			args = append(args, n)
+		}
+	}
+	switch len(args) {
+	case 0:
+		return nil
+	case 1:
+		return args[0]
+	}
+	return &Node{Op: OpAnd, Args: args}
+}
+
+// Contains returns a predicate that matches if field contains text.
+func Contains(field, text string) *Node {
+	return &Node{Op: OpContains, Field: field, Value: text}
+}
+
+// Is returns a predicate that matches if the boolean field equals value.
+func Is(field string, value bool) *Node {
+	return &Node{Op: OpIs, Field: field, Value: value}
+}
+
+// Compare returns a predicate that compares the numeric field with value
+// using op, one of OpEq, OpGt, OpGe, OpLt and OpLe.
+func Compare(op, field string, value int64) *Node {
+	return &Node{Op: op, Field: field, Value: value}
+}
+
+// After returns a predicate that matches messages received after t.
+func After(t time.Time) *Node {
+	return &Node{Op: OpAfter, Field: FieldDateReceived, Value: t.UTC().Format(time.RFC3339)}
+}
+
+// Before returns a predicate that matches messages received before t.
+func Before(t time.Time) *Node {
+	return &Node{Op: OpBefore, Field: FieldDateReceived, Value: t.UTC().Format(time.RFC3339)}
+}
+
+// FlagColors maps flag color names to Mail.app flag indexes.
+var FlagColors = map[string]int64{
+	"red":    0,
+	"orange": 1,
+	"yellow": 2,
+	"green":  3,
+	"blue":   4,
+	"purple": 5,
+	"gray":   6,
+	"grey":   6,
+}
+
+// ParseDate parses a date as used by after: and before:, either a plain date
+// (midnight UTC) or an RFC 3339 timestamp.
+func ParseDate(s string) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD or RFC 3339", s)
+	}
+	return t, nil
+}
+
+// Error is a syntax or validation error in a query.
+type Error struct {
+	Pos int // byte offset in the query
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("invalid query at position %d: %s", e.Pos, e.Msg)
+}
+
+// Parse parses a query expression.
+func Parse(s string) (*Node, error) {
+	p := &parser{lexer: lexer{input: s}}
+	p.next()
+	if p.tok.kind == tokEOF {
+		return nil, &Error{Pos: 0, Msg: "empty query"}
+	}
+	n, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, p.unexpected()
+	}
+	return n, nil
+}
+
+type parser struct {
+	lexer
+	tok token
+}
+
+func (p *parser) next() {
+	p.tok = p.lex()
+}
+
+func (p *parser) unexpected() error {
+	switch p.tok.kind {
+	case tokEOF:
+		return &Error{Pos: p.tok.pos, Msg: "unexpected end of query"}
+	case tokError:
+		return &Error{Pos: p.tok.pos, Msg: p.tok.text}
+	}
+	return &Error{Pos: p.tok.pos, Msg: fmt.Sprintf("unexpected %q", p.tok.text)}
+}
+
+// parseOr parses: and ("OR" and)*
+func (p *parser) parseOr() (*Node, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	args := []*Node{first}
+	for p.tok.kind == tokOr {
+		p.next()
+		n, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, n)
+	}
+	if len(args) == 1 {
+		return first, nil
+	}
+	return &Node{Op: OpOr, Args: args}, nil
+}
+
+// parseAnd parses: not (["AND"] not)*
+func (p *parser) parseAnd() (*Node, error) {
+	first, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	args := []*Node{first}
+	for {
+		switch p.tok.kind {
+		case tokAnd:
+			p.next()
+		case tokNot, tokLParen, tokPredicate:
+			// implicit AND
+		default:
+			return And(args...), nil
+		}
+		n, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, n)
+	}
+}
+
+// parseNot parses: "NOT" not | "(" or ")" | predicate
+func (p *parser) parseNot() (*Node, error) {
+	switch p.tok.kind {
+	case tokNot:
+		p.next()
+		n, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &Node{Op: OpNot, Args: []*Node{n}}, nil
+	case tokLParen:
+		p.next()
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokRParen {
+			if p.tok.kind == tokEOF {
+				return nil, &Error{Pos: p.tok.pos, Msg: "missing )"}
+			}
+			return nil, p.unexpected()
+		}
+		p.next()
+		return n, nil
+	case tokPredicate:
+		n, err := predicate(p.tok)
+		if err != nil {
+			return nil, err
+		}
+		p.next()
+		return n, nil
+	}
+	return nil, p.unexpected()
+}
+
+// textFields maps the names of substring predicates to their fields.
+var textFields = map[string]string{
+	"subject": FieldSubject,
+	"from":    FieldSender,
+	"sender":  FieldSender,
+	"to":      FieldTo,
+	"cc":      FieldCc,
+	"body":    FieldContent,
+}
+
+// statusValues maps the values of is: to boolean predicates.
+var statusValues = map[string]*Node{
+	"read":      Is(FieldReadStatus, true),
+	"unread":    Is(FieldReadStatus, false),
+	"flagged":   Is(FieldFlaggedStatus, true),
+	"unflagged": Is(FieldFlaggedStatus, false),
+	"replied":   Is(FieldWasRepliedTo, true),
+	"forwarded": Is(FieldWasForwarded, true),
+	"junk":      Is(FieldJunkMailStatus, true),
+}
+
+var comparisonOps = map[string]string{
+	">":  OpGt,
+	">=": OpGe,
+	"<":  OpLt,
+	"<=": OpLe,
+}
+
+// predicate converts a predicate token into a node.
+func predicate(tok token) (*Node, error) {
+	fail := func(format string, a ...any) error {
+		return &Error{Pos: tok.pos, Msg: fmt.Sprintf(format, a...)}
+	}
+	name := strings.ToLower(tok.name)
+	value := tok.value
+
+	if tok.op != ":" {
+		if name != "size" {
+			return nil, fail("%q does not support %s, only size does", tok.name, tok.op)
+		}
+		size, err := parseSize(value)
+		if err != nil {
+			return nil, fail("%v", err)
+		}
+		return Compare(comparisonOps[tok.op], FieldMessageSize, size), nil
+	}
+
+	if value == "" {
+		return nil, fail("missing value for %q", tok.name)
+	}
+	if field, ok := textFields[name]; ok {
+		return Contains(field, value), nil
+	}
+	switch name {
+	case "is":
+		n, ok := statusValues[strings.ToLower(value)]
+		if !ok {
+			return nil, fail("unknown status %q, expected one of read, unread, flagged, unflagged, replied, forwarded, junk", value)
+		}
+		clone := *n
+		return &clone, nil
+	case "has":
+		if v := strings.ToLower(value); v != "attachment" && v != "attachments" {
+			return nil, fail("unknown value %q for has, expected attachment", value)
+		}
+		return Is(FieldHasAttachment, true), nil
+	case "flag":
+		if index, ok := FlagColors[strings.ToLower(value)]; ok {
+			return Compare(OpEq, FieldFlagIndex, index), nil
+		}
+		index, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || index < 0 || index > 6 {
+			return nil, fail("unknown flag color %q, expected red, orange, yellow, green, blue, purple, gray or 0-6", value)
+		}
+		return Compare(OpEq, FieldFlagIndex, index), nil
+	case "after", "before":
+		t, err := ParseDate(value)
+		if err != nil {
+			return nil, fail("%v", err)
+		}
+		if name == "after" {
+			return After(t), nil
+		}
+		return Before(t), nil
+	case "size":
+		return nil, fail("size needs a comparison, e.g. size>1MB")
+	}
+	return nil, fail("unknown field %q", tok.name)
+}
+
+// parseSize parses a size in bytes with an optional unit.
+func parseSize(s string) (int64, error) {
+	units := []struct {
+		suffix string
+		factor int64
+	}{
+		{"kb", 1 << 10}, {"mb", 1 << 20}, {"gb", 1 << 30},
+		{"k", 1 << 10}, {"m", 1 << 20}, {"g", 1 << 30}, {"b", 1},
+	}
+	lower := strings.ToLower(s)
+	factor := int64(1)
+	for _, u := range units {
+		if strings.HasSuffix(lower, u.suffix) {
+			lower = strings.TrimSuffix(lower, u.suffix)
+			factor = u.factor
+			break
+		}
+	}
+	// ParseFloat accepts NaN and Inf, which compare with no size
+	n, err := strconv.ParseFloat(lower, 64)
+	if err != nil || n < 0 || math.IsNaN(n) || math.IsInf(n, 0) {
+		return 0, fmt.Errorf("invalid size %q, expected e.g. 500, 10KB or 1.5MB", s)
+	}
+	size := n * float64(factor)
+	if size >= math.MaxInt64 {
+		return 0, fmt.Errorf("size %q is out of range", s)
+	}
+	return int64(size), nil
+}
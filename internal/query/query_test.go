@@ -0,0 +1,230 @@
+package query
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string // JSON of the expected tree
+	}{
+		{
+			name:  "single predicate",
+			query: "subject:invoice",
+			want:  `{"op":"contains","field":"subject","value":"invoice"}`,
+		},
+		{
+			name:  "quoted value",
+			query: `subject:"budget \"2024\""`,
+			want:  `{"op":"contains","field":"subject","value":"budget \"2024\""}`,
+		},
+		{
+			name:  "from maps to sender",
+			query: "from:alice",
+			want:  `{"op":"contains","field":"sender","value":"alice"}`,
+		},
+		{
+			name:  "body maps to content",
+			query: "body:agenda",
+			want:  `{"op":"contains","field":"content","value":"agenda"}`,
+		},
+		{
+			name:  "implicit and",
+			query: "to:bob cc:carol",
+			want:  `{"op":"and","args":[{"op":"contains","field":"to","value":"bob"},{"op":"contains","field":"cc","value":"carol"}]}`,
+		},
+		{
+			name:  "and binds tighter than or",
+			query: "is:read OR is:flagged AND has:attachment",
+			want:  `{"op":"or","args":[{"op":"is","field":"readStatus","value":true},{"op":"and","args":[{"op":"is","field":"flaggedStatus","value":true},{"op":"is","field":"hasAttachment","value":true}]}]}`,
+		},
+		{
+			name:  "parentheses and not",
+			query: "not (is:junk or is:replied)",
+			want:  `{"op":"not","args":[{"op":"or","args":[{"op":"is","field":"junkMailStatus","value":true},{"op":"is","field":"wasRepliedTo","value":true}]}]}`,
+		},
+		{
+			name:  "unread is false read status",
+			query: "is:unread",
+			want:  `{"op":"is","field":"readStatus","value":false}`,
+		},
+		{
+			name:  "flag color",
+			query: "flag:Green",
+			want:  `{"op":"eq","field":"flagIndex","value":3}`,
+		},
+		{
+			name:  "flag index",
+			query: "flag:6",
+			want:  `{"op":"eq","field":"flagIndex","value":6}`,
+		},
+		{
+			name:  "size with unit",
+			query: "size>=1.5MB",
+			want:  `{"op":"ge","field":"messageSize","value":1572864}`,
+		},
+		{
+			name:  "size in bytes",
+			query: "size<500",
+			want:  `{"op":"lt","field":"messageSize","value":500}`,
+		},
+		{
+			name:  "date",
+			query: "after:2024-01-02",
+			want:  `{"op":"after","field":"dateReceived","value":"2024-01-02T00:00:00Z"}`,
+		},
+		{
+			name:  "timestamp",
+			query: "before:2024-01-02T10:00:00+02:00",
+			want:  `{"op":"before","field":"dateReceived","value":"2024-01-02T08:00:00Z"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := Parse(tt.query)
+			if err != nil {
+				t.Fatalf("Parse(%q) error: %v", tt.query, err)
+			}
+			got, err := json.Marshal(n)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Parse(%q)\n got: %s\nwant: %s", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string // substring of the error
+	}{
+		{name: "empty", query: "  ", want: "position 0: empty query"},
+		{name: "bare word", query: "invoice", want: `expected field:value, got "invoice"`},
+		{name: "quoted text without field", query: `"invoice"`, want: "quoted text needs a field"},
+		{name: "unknown field", query: "subject:a foo:bar", want: `position 10: unknown field "foo"`},
+		{name: "missing value", query: "subject:", want: `missing value for "subject"`},
+		{name: "unterminated quote", query: `subject:"abc`, want: "unterminated quoted text"},
+		{name: "unknown status", query: "is:important", want: `unknown status "important"`},
+		{name: "unknown flag color", query: "flag:pink", want: `unknown flag color "pink"`},
+		{name: "flag index out of range", query: "flag:7", want: `unknown flag color "7"`},
+		{name: "invalid size", query: "size>big", want: `invalid size "big"`},
+		{name: "size not a number", query: "size>nan", want: `invalid size "nan"`},
+		{name: "infinite size", query: "size<Inf", want: `invalid size "Inf"`},
+		{name: "size out of range", query: "size>9000000000GB", want: `size "9000000000GB" is out of range`},
+		{name: "size without comparison", query: "size:10", want: "size needs a comparison"},
+		{name: "comparison on text field", query: "subject>a", want: `"subject" does not support >`},
+		{name: "invalid date", query: "after:yesterday", want: `invalid date "yesterday"`},
+		{name: "missing closing parenthesis", query: "(is:read OR is:flagged", want: "missing )"},
+		{name: "unexpected closing parenthesis", query: "is:read)", want: `position 7: unexpected ")"`},
+		{name: "dangling operator", query: "is:read AND", want: "unexpected end of query"},
+		{name: "double operator", query: "is:read OR OR is:flagged", want: `unexpected "OR"`},
+		{name: "stray character", query: "is:read & is:flagged", want: "unexpected &"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.query)
+			if err == nil {
+				t.Fatalf("Parse(%q) expected error", tt.query)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse(%q) error = %q, want it to contain %q", tt.query, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnd(t *testing.T) {
+	if And() != nil || And(nil, nil) != nil {
+		t.Error("And of no nodes should be nil")
+	}
+	n := Is(FieldReadStatus, true)
+	if And(nil, n) != n {
+		t.Error("And of a single node should be the node")
+	}
+	if got := And(n, n, nil); got.Op != OpAnd || len(got.Args) != 2 {
+		t.Errorf("And(n, n, nil) = %+v", got)
+	}
+}
+
+type testMessage struct {
+	text    map[string]string
+	bools   map[string]bool
+	numbers map[string]int64
+	times   map[string]time.Time
+}
+
+func (m testMessage) Text(field string) string    { return m.text[field] }
+func (m testMessage) Bool(field string) bool      { return m.bools[field] }
+func (m testMessage) Number(field string) int64   { return m.numbers[field] }
+func (m testMessage) Time(field string) time.Time { return m.times[field] }
+
+func TestMatch(t *testing.T) {
+	msg := testMessage{
+		text: map[string]string{
+			FieldSubject: "Quarterly Budget",
+			FieldSender:  "Alice <alice@example.com>",
+			FieldTo:      "Bob bob@example.com",
+		},
+		bools:   map[string]bool{FieldReadStatus: true, FieldHasAttachment: true},
+		numbers: map[string]int64{FieldMessageSize: 2048, FieldFlagIndex: -1},
+		times:   map[string]time.Time{FieldDateReceived: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)},
+	}
+
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"subject:budget", true},
+		{"subject:invoice", false},
+		{"from:ALICE to:bob@example.com", true},
+		{"is:read has:attachment", true},
+		{"is:unread", false},
+		{"is:flagged OR subject:quarterly", true},
+		{"NOT from:alice", false},
+		{"size>2KB", false},
+		{"size>=2KB size<3KB", true},
+		{"flag:red", false},
+		{"after:2024-02-29 before:2024-03-02", true},
+		{"after:2024-03-02", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			n, err := Parse(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := Match(n, msg); got != tt.want {
+				t.Errorf("Match(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+
+			// The tree passed to the script is decoded from JSON.
+			data, err := json.Marshal(n)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var decoded Node
+			if err := json.Unmarshal(data, &decoded); err != nil {
+				t.Fatal(err)
+			}
+			if got := Match(&decoded, msg); got != tt.want {
+				t.Errorf("Match(%q) after JSON round trip = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+
+	if !Match(nil, msg) {
+		t.Error("nil query should match every message")
+	}
+}
@@ -0,0 +1,266 @@
+// Package quote detects the quoted history and trailing signature of a
+// plain text or Markdown message body, so they can be stripped.
+//
+// Quoted history is recognized by
+//   - attribution lines such as "On Mon, 1 Jan 2024, Bob wrote:" in several
+//     languages, possibly wrapped over two lines,
+//   - lines prefixed with ">",
+//   - Outlook separators, either an "-----Original Message-----" line or a
+//     block of From:, Sent: and To: or Subject: lines in several languages.
+//
+// Signatures are recognized by the "-- " delimiter, mobile client footers
+// such as "Sent from my iPhone" and a closing such as "Best regards," that is
+// followed by a few short lines only.
+package quote
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Result is a message body with quoted history and signature removed.
+type Result struct {
+	Text           string
+	QuoteChars     int // characters of quoted history removed
+	SignatureChars int // characters of the signature removed
+}
+
+// Removed returns the number of characters removed.
+func (r Result) Removed() int {
+	return r.QuoteChars + r.SignatureChars
+}
+
+// Strip removes quoted history and then the trailing signature from text.
+func Strip(text string) Result {
+	withoutQuotes := StripQuotes(text)
+	stripped := StripSignature(withoutQuotes)
+	return Result{
+		Text:           stripped,
+		QuoteChars:     utf8.RuneCountInString(text) - utf8.RuneCountInString(withoutQuotes),
+		SignatureChars: utf8.RuneCountInString(withoutQuotes) - utf8.RuneCountInString(stripped),
+	}
+}
+
+// attributionStarts are the attribution lines per language, they start
+// with a preposition and the date, e.g. "On ..., Bob wrote:", "Am ...
+// schrieb Bob:" or "Le ..., Bob a écrit :".
+var attributionStarts = []string{
+	`on\b.*\bwrote`,           // English
+	`am\b.*\bschrieb\b.*`,     // German
+	`le\b.*\ba écrit`,         // French
+	`el\b.*\bescribió`,        // Spanish
+	`il\b.*\bha scritto`,      // Italian
+	`op\b.*\bschreef\b.*`,     // Dutch
+	`em\b.*\bescreveu`,        // Portuguese
+	`den\b.*\bskrev\b.*`,      // Swedish, Danish, Norwegian
+	`.*\bnapisał(?:\(a\)|a)?`, // Polish
+}
+
+// attribution matches the line introducing a quote, including those without
+// a date, e.g. "Bob <bob@example.com> wrote:".
+var attribution = regexp.MustCompile(`(?i)^\s*(?:` + strings.Join(attributionStarts, "|") +
+	`|.*\b(?:wrote|schrieb\b.*|a écrit|ha scritto|skrev\b.*))\s*:\s*$`)
+
+// wrappedAttribution matches attributions wrapped over two lines. Only those
+// with a start are accepted, the line before an attribution is not part of
+// it.
+var wrappedAttribution = regexp.MustCompile(`(?i)^\s*(?:` + strings.Join(attributionStarts, "|") + `)\s*:\s*$`)
+
+// outlookSeparator matches the line Outlook and other clients put above the
+// original message.
+var outlookSeparator = regexp.MustCompile(`(?i)^\s*(?:-{2,}\s*(?:original message|ursprüngliche nachricht|message d'origine|mensaje original|messaggio originale|oorspronkelijk bericht|mensagem original|originalmeddelande|oprindelig meddelelse|opprinnelig melding)\s*-{2,}|_{10,})\s*$`)
+
+// Field names of the header block Outlook quotes the original message with.
+var (
+	outlookFrom    = regexp.MustCompile(`(?i)^\s*\*{0,2}(?:from|von|de|da|van|från|fra|od)\s*:`)
+	outlookSent    = regexp.MustCompile(`(?i)^\s*\*{0,2}(?:sent|date|gesendet|datum|envoyé|date d'envoi|enviado|fecha|inviato|data|verzonden|skickat|sendt|wysłano)\s*:`)
+	outlookTo      = regexp.MustCompile(`(?i)^\s*\*{0,2}(?:to|an|à|para|a|aan|till|til|do)\s*:`)
+	outlookSubject = regexp.MustCompile(`(?i)^\s*\*{0,2}(?:subject|betreff|objet|asunto|oggetto|onderwerp|assunto|ämne|emne|temat)\s*:`)
+)
+
+// StripQuotes removes quoted history from text. Everything below an Outlook
+// separator is removed. An attribution line is removed together with the
+// quoted lines following it; if replies are interleaved with the quote,
+// only the quoted lines are removed. Other blocks of quoted lines are
+// removed as well.
+func StripQuotes(text string) string {
+	lines := strings.Split(text, "\n")
+	keep := make([]bool, len(lines))
+	for i := range keep {
+		keep[i] = true
+	}
+
+	for i := 0; i < len(lines); i++ {
+		if outlookHeader(lines, i) {
+			// Outlook quotes without prefixes, the original message
+			// follows until the end
+			for j := i; j < len(lines); j++ {
+				keep[j] = false
+			}
+			break
+		}
+		if quoted(lines[i]) {
+			keep[i] = false
+			continue
+		}
+		if n := attributionLines(lines, i); n > 0 {
+			// Only an attribution followed by a quote is one, "wrote:" may
+			// also end a sentence of the reply
+			next := i + n
+			for next < len(lines) && strings.TrimSpace(lines[next]) == "" {
+				next++
+			}
+			if next == len(lines) || quoted(lines[next]) {
+				for j := i; j < i+n; j++ {
+					keep[j] = false
+				}
+				i += n - 1
+			}
+		}
+	}
+
+	if !slices.Contains(keep, false) {
+		return text
+	}
+	var out []string
+	for i, line := range lines {
+		if keep[i] {
+			out = append(out, line)
+		} else if len(out) > 0 && strings.TrimSpace(out[len(out)-1]) == "" {
+			// Collapse the empty lines around a removed block
+			out = out[:len(out)-1]
+		}
+	}
+	return trimEmptyLines(out)
+}
+
+// quoted reports whether a line is prefixed with ">".
+func quoted(line string) bool {
+	return strings.HasPrefix(strings.TrimLeft(line, " \t"), ">")
+}
+
+// attributionLines returns the number of lines, 1 or 2, of the attribution
+// starting at line i, or 0 if there is none. Clients wrap long attributions,
+// e.g. "On Mon, 1 Jan 2024 at 10:00, Bob Smith <" and "bob@example.com>
+// wrote:".
+func attributionLines(lines []string, i int) int {
+	line := lines[i]
+	if strings.TrimSpace(line) == "" || quoted(line) {
+		return 0
+	}
+	if attribution.MatchString(line) {
+		return 1
+	}
+	if i+1 < len(lines) && !quoted(lines[i+1]) && strings.TrimSpace(lines[i+1]) != "" &&
+		wrappedAttribution.MatchString(line+" "+lines[i+1]) {
+		return 2
+	}
+	return 0
+}
+
+// outlookHeader reports whether line i starts an Outlook quote: a separator
+// line, or a From: line followed by Sent: and To: or Subject: lines.
+func outlookHeader(lines []string, i int) bool {
+	if outlookSeparator.MatchString(lines[i]) {
+		return true
+	}
+	if !outlookFrom.MatchString(lines[i]) {
+		return false
+	}
+	var sent, other bool
+	for _, line := range lines[i+1 : min(len(lines), i+6)] {
+		switch {
+		case outlookSent.MatchString(line):
+			sent = true
+		case outlookTo.MatchString(line), outlookSubject.MatchString(line):
+			other = true
+		}
+	}
+	return sent && other
+}
+
+// maxSignatureLines is the maximum number of non-empty lines of a signature.
+const maxSignatureLines = 10
+
+// mobileFooter matches the footer mobile mail clients add.
+var mobileFooter = regexp.MustCompile(`(?i)^\s*(?:sent from my|sent with|sent from mail for|sent via|get outlook for|von meinem|gesendet von|envoyé de mon|envoyé depuis|enviado desde mi|enviado do meu|inviato da|verzonden vanaf|skickat från)\b`)
+
+// closing matches a line that only holds a closing phrase.
+var closing = regexp.MustCompile(`(?i)^\s*(?:(?:best|kind|warm|many thanks and|with best)?\s*regards|best(?: wishes)?|cheers|thanks(?: again)?|thank you|sincerely|yours(?: sincerely| truly)?|all the best|(?:viele|liebe|beste|freundliche)\s+grüße|mit freundlichen grüßen|mfg|lg|vg|gruß|grüße|cordialement|bien à vous|saludos(?: cordiales)?|un saludo|cordiali saluti|saluti|met vriendelijke groet(?:en)?|groeten|atenciosamente|abraços|med vänliga hälsningar|mvh|hälsningar)\s*[,.!]?\s*$`)
+
+// StripSignature removes the trailing signature of text.
+func StripSignature(text string) string {
+	lines := strings.Split(text, "\n")
+	end := len(lines)
+
+	// Footers of mobile clients come last
+	for i := len(lines) - 1; i >= 0; i-- {
+		if strings.TrimSpace(lines[i]) == "" {
+			continue
+		}
+		if mobileFooter.MatchString(lines[i]) {
+			end = i
+		}
+		break
+	}
+
+	// The standard delimiter is "-- ", some clients drop the space
+	for i := end - 1; i >= 0; i-- {
+		if line := strings.TrimRight(lines[i], "\r"); line == "-- " || line == "--" {
+			if nonEmpty(lines[i+1:end]) <= maxSignatureLines {
+				end = i
+			}
+			break
+		}
+	}
+
+	// A closing followed by a name and a few more short lines. Something
+	// must remain, a message may start with "Thanks,".
+	for i := end - 1; i > 0 && end-i <= maxSignatureLines+1; i-- {
+		if closing.MatchString(lines[i]) {
+			if shortLines(lines[i+1:end]) && nonEmpty(lines[:i]) > 0 {
+				end = i
+			}
+			break
+		}
+	}
+
+	if end == len(lines) {
+		return text
+	}
+	return trimEmptyLines(lines[:end])
+}
+
+func nonEmpty(lines []string) int {
+	n := 0
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// shortLines reports whether lines look like the rest of a signature: a
+// name, a title, a phone number, but no questions.
+func shortLines(lines []string) bool {
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if utf8.RuneCountInString(line) > 60 || strings.HasSuffix(line, "?") {
+			return false
+		}
+	}
+	return nonEmpty(lines) <= 6
+}
+
+func trimEmptyLines(lines []string) string {
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,297 @@
+package quote
+
+import (
+	"strings"
+	"testing"
+)
+
+func lines(l ...string) string { return strings.Join(l, "\n") }
+
+func TestStripQuotes(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "no quote",
+			text: "Hi Bob,\n\nsounds good.\n",
+			want: "Hi Bob,\n\nsounds good.\n",
+		},
+		{
+			name: "top posting with attribution",
+			text: lines(
+				"Sounds good.",
+				"",
+				"On Mon, 12 Feb 2024 at 10:00, Alice <alice@example.com> wrote:",
+				"> Shall we meet?",
+				">",
+				"> > Earlier",
+			),
+			want: "Sounds good.",
+		},
+		{
+			name: "wrapped attribution",
+			text: lines(
+				"Yes.",
+				"",
+				"On Mon, Feb 12, 2024 at 10:00 AM Alice Smith <",
+				"alice@example.com> wrote:",
+				"",
+				"> Shall we meet?",
+			),
+			want: "Yes.",
+		},
+		{
+			name: "german attribution",
+			text: lines(
+				"Passt.",
+				"",
+				"Am 12.02.2024 um 10:00 schrieb Alice Schmidt <alice@example.com>:",
+				"> Treffen wir uns?",
+			),
+			want: "Passt.",
+		},
+		{
+			name: "french attribution",
+			text: lines(
+				"D'accord.",
+				"Le lun. 12 févr. 2024 à 10:00, Alice <alice@example.com> a écrit :",
+				"> On se voit ?",
+			),
+			want: "D'accord.",
+		},
+		{
+			name: "spanish attribution",
+			text: lines(
+				"Vale.",
+				"El lun, 12 feb 2024 a las 10:00, Alice (<alice@example.com>) escribió:",
+				"> ¿Nos vemos?",
+			),
+			want: "Vale.",
+		},
+		{
+			name: "interleaved reply keeps the answers",
+			text: lines(
+				"Answers inline.",
+				"",
+				"Alice <alice@example.com> wrote:",
+				"> First question?",
+				"Yes.",
+				"",
+				"> Second question?",
+				"No.",
+			),
+			want: lines(
+				"Answers inline.",
+				"Yes.",
+				"No.",
+			),
+		},
+		{
+			name: "wrote without quote is kept",
+			text: lines(
+				"This is what the reviewer wrote:",
+				"the patch needs tests.",
+			),
+			want: lines(
+				"This is what the reviewer wrote:",
+				"the patch needs tests.",
+			),
+		},
+		{
+			name: "outlook separator",
+			text: lines(
+				"Please find it attached.",
+				"",
+				"-----Original Message-----",
+				"From: Alice",
+				"Sent: Monday, February 12, 2024 10:00 AM",
+				"Subject: Report",
+				"",
+				"Can you send the report?",
+			),
+			want: "Please find it attached.",
+		},
+		{
+			name: "outlook header block in german",
+			text: lines(
+				"Anbei.",
+				"",
+				"________________________________",
+				"Von: Alice Schmidt <alice@example.com>",
+				"Gesendet: Montag, 12. Februar 2024 10:00",
+				"An: Bob",
+				"Betreff: Bericht",
+				"",
+				"Schickst du den Bericht?",
+			),
+			want: "Anbei.",
+		},
+		{
+			name: "outlook header block without separator in markdown",
+			text: lines(
+				"Done.",
+				"",
+				"**From:** Alice <alice@example.com>",
+				"**Sent:** Monday, February 12, 2024 10:00 AM",
+				"**To:** Bob",
+				"**Subject:** Task",
+				"",
+				"Please do it.",
+			),
+			want: "Done.",
+		},
+		{
+			name: "single from line is kept",
+			text: lines(
+				"From: the team",
+				"Subject: is not a header here",
+			),
+			want: lines(
+				"From: the team",
+				"Subject: is not a header here",
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StripQuotes(tt.text); got != tt.want {
+				t.Errorf("StripQuotes() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStripSignature(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "no signature",
+			text: "See you tomorrow.\n",
+			want: "See you tomorrow.\n",
+		},
+		{
+			name: "delimiter",
+			text: lines(
+				"See you tomorrow.",
+				"",
+				"-- ",
+				"Bob Smith",
+				"ACME Inc.",
+			),
+			want: "See you tomorrow.",
+		},
+		{
+			name: "delimiter followed by a long text is kept",
+			text: "Intro\n--\n" + strings.Repeat("line\n", 20),
+			want: "Intro\n--\n" + strings.Repeat("line\n", 20),
+		},
+		{
+			name: "mobile footer",
+			text: lines(
+				"Ok!",
+				"",
+				"Sent from my iPhone",
+			),
+			want: "Ok!",
+		},
+		{
+			name: "german mobile footer",
+			text: "Ok!\n\nVon meinem iPhone gesendet\n",
+			want: "Ok!",
+		},
+		{
+			name: "closing with name and title",
+			text: lines(
+				"The numbers are final.",
+				"",
+				"Best regards,",
+				"Bob Smith",
+				"Head of Finance",
+				"+1 555 0100",
+			),
+			want: "The numbers are final.",
+		},
+		{
+			name: "german closing",
+			text: "Die Zahlen stehen.\n\nViele Grüße\nBob\n",
+			want: "Die Zahlen stehen.",
+		},
+		{
+			name: "closing at the start is kept",
+			text: lines(
+				"Thanks,",
+				"can you send it again?",
+			),
+			want: lines(
+				"Thanks,",
+				"can you send it again?",
+			),
+		},
+		{
+			name: "closing followed by a long paragraph is kept",
+			text: lines(
+				"Intro.",
+				"Thanks!",
+				"And one more thing I forgot to mention in the previous paragraph of this mail.",
+			),
+			want: lines(
+				"Intro.",
+				"Thanks!",
+				"And one more thing I forgot to mention in the previous paragraph of this mail.",
+			),
+		},
+		{
+			name: "delimiter and mobile footer",
+			text: lines(
+				"Ok.",
+				"-- ",
+				"Bob",
+				"",
+				"Get Outlook for iOS",
+			),
+			want: "Ok.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StripSignature(tt.text); got != tt.want {
+				t.Errorf("StripSignature() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStrip(t *testing.T) {
+	text := lines(
+		"Sounds good.",
+		"",
+		"Cheers,",
+		"Bob",
+		"",
+		"On Mon, 12 Feb 2024, Alice wrote:",
+		"> Shall we meet?",
+	)
+	got := Strip(text)
+	if got.Text != "Sounds good." {
+		t.Errorf("Text = %q, want %q", got.Text, "Sounds good.")
+	}
+	quote := len("\n\nOn Mon, 12 Feb 2024, Alice wrote:\n> Shall we meet?")
+	signature := len("\n\nCheers,\nBob")
+	if got.QuoteChars != quote || got.SignatureChars != signature {
+		t.Errorf("QuoteChars, SignatureChars = %d, %d, want %d, %d", got.QuoteChars, got.SignatureChars, quote, signature)
+	}
+	if got.Removed() != len(text)-len(got.Text) {
+		t.Errorf("Removed() = %d, want %d", got.Removed(), len(text)-len(got.Text))
+	}
+
+	if got := Strip("Grüße aus Köln\n> Zitat"); got.Text != "Grüße aus Köln" || got.QuoteChars != 8 {
+		t.Errorf("Strip() = %+v, want characters, not bytes, counted", got)
+	}
+}
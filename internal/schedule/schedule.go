@@ -0,0 +1,392 @@
+// Package schedule keeps a queue of outgoing messages to be sent at a given
+// time. The queue is stored in a JSON file, so it survives restarts of the
+// server, and is worked off by a Scheduler.
+package schedule
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Status is the state of an Entry.
+type Status string
+
+// Statuses of an Entry. Entries start pending and end sent, failed or
+// canceled.
+const (
+	StatusPending  Status = "pending"
+	StatusSending  Status = "sending"
+	StatusSent     Status = "sent"
+	StatusFailed   Status = "failed"
+	StatusCanceled Status = "canceled"
+)
+
+// keepFinished is how long finished entries are kept, so their outcome can
+// still be listed.
+const keepFinished = 7 * 24 * time.Hour
+
+// Entry is an outgoing message scheduled to be sent.
+type Entry struct {
+	ID         string     `json:"id"`
+	OutgoingID int        `json:"outgoing_id"`
+	Subject    string     `json:"subject"`
+	Recipients []string   `json:"recipients"`
+	SendAt     time.Time  `json:"send_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	Status     Status     `json:"status"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+func (e Entry) finished() bool {
+	return e.Status == StatusSent || e.Status == StatusFailed || e.Status == StatusCanceled
+}
+
+// fileLocks serializes access to schedule files within the process, the
+// tools and the scheduler loop share the file. Other processes, e.g. the
+// launchd service and a stdio server, are kept out by a lock on a file next
+// to the schedule file, see lock.
+var fileLocks sync.Map
+
+// Store is a schedule file. Every operation reads and writes the whole file.
+type Store struct {
+	path string
+	mu   *sync.Mutex
+}
+
+// NewStore returns the store for the schedule file at path. The file is
+// created on the first write.
+func NewStore(path string) *Store {
+	mu, _ := fileLocks.LoadOrStore(path, &sync.Mutex{})
+	return &Store{path: path, mu: mu.(*sync.Mutex)}
+}
+
+// Path returns the path of the schedule file.
+func (s *Store) Path() string {
+	return s.path
+}
+
+func (s *Store) load() ([]Entry, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return []Entry{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schedule: %w", err)
+	}
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse schedule %s: %w", s.path, err)
+	}
+	return entries, nil
+}
+
+// save writes entries atomically, dropping entries that finished long ago.
+func (s *Store) save(entries []Entry, now time.Time) error {
+	entries = slices.DeleteFunc(entries, func(e Entry) bool {
+		return e.finished() && e.FinishedAt != nil && now.Sub(*e.FinishedAt) > keepFinished
+	})
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal schedule: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create schedule directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".schedule-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to write schedule: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write schedule: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write schedule: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to write schedule: %w", err)
+	}
+	return nil
+}
+
+// lock locks the schedule file for the process and against other processes.
+// The returned function unlocks it.
+func (s *Store) lock() (func(), error) {
+	s.mu.Lock()
+	f, err := lockFile(s.path+".lock", syscall.LOCK_EX)
+	if err != nil {
+		s.mu.Unlock()
+		return nil, err
+	}
+	return func() {
+		f.Close()
+		s.mu.Unlock()
+	}, nil
+}
+
+// lockFile opens path, creating it, and locks it with flock. The lock is
+// released when the file is closed or the process exits.
+func lockFile(path string, how int) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create schedule directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+	if err := syscall.Flock(int(f.Fd()), how); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// update runs fn on the entries and saves them if fn changed them. The file is
+// not rewritten by every check of the scheduler for due entries.
+func (s *Store) update(now time.Time, fn func(entries []Entry) ([]Entry, error)) error {
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	entries, err := s.load()
+	if err != nil {
+		return err
+	}
+	before, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("failed to marshal schedule: %w", err)
+	}
+	entries, err = fn(entries)
+	if err != nil {
+		return err
+	}
+	after, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("failed to marshal schedule: %w", err)
+	}
+	if bytes.Equal(before, after) {
+		return nil
+	}
+	return s.save(entries, now)
+}
+
+// Add schedules a new entry and returns it with its ID set.
+func (s *Store) Add(e Entry, now time.Time) (Entry, error) {
+	e.ID = rand.Text()[:12]
+	e.CreatedAt = now
+	e.Status = StatusPending
+	err := s.update(now, func(entries []Entry) ([]Entry, error) {
+		for _, other := range entries {
+			if other.OutgoingID == e.OutgoingID && (other.Status == StatusPending || other.Status == StatusSending) {
+				return nil, fmt.Errorf("outgoing message %d is already scheduled as %s", e.OutgoingID, other.ID)
+			}
+		}
+		return append(entries, e), nil
+	})
+	if err != nil {
+		return Entry{}, err
+	}
+	return e, nil
+}
+
+// List returns all entries ordered by the time they are sent at.
+func (s *Store) List() ([]Entry, error) {
+	unlock, err := s.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	entries, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(entries, func(a, b Entry) int { return a.SendAt.Compare(b.SendAt) })
+	return entries, nil
+}
+
+// Cancel cancels a pending entry.
+func (s *Store) Cancel(id string, now time.Time) (Entry, error) {
+	var canceled Entry
+	err := s.update(now, func(entries []Entry) ([]Entry, error) {
+		i := slices.IndexFunc(entries, func(e Entry) bool { return e.ID == id })
+		if i < 0 {
+			return nil, fmt.Errorf("scheduled message %s not found", id)
+		}
+		if entries[i].Status != StatusPending {
+			return nil, fmt.Errorf("scheduled message %s cannot be canceled, it is %s", id, entries[i].Status)
+		}
+		entries[i].Status = StatusCanceled
+		entries[i].FinishedAt = &now
+		canceled = entries[i]
+		return entries, nil
+	})
+	return canceled, err
+}
+
+// claimDue marks the pending entries that are due as sending and returns
+// them, so they can neither be canceled nor sent twice.
+func (s *Store) claimDue(now time.Time) ([]Entry, error) {
+	var due []Entry
+	err := s.update(now, func(entries []Entry) ([]Entry, error) {
+		for i := range entries {
+			if entries[i].Status == StatusPending && !entries[i].SendAt.After(now) {
+				entries[i].Status = StatusSending
+				due = append(due, entries[i])
+			}
+		}
+		return entries, nil
+	})
+	return due, err
+}
+
+// finish records the outcome of sending an entry.
+func (s *Store) finish(id string, sendErr error, now time.Time) error {
+	return s.update(now, func(entries []Entry) ([]Entry, error) {
+		i := slices.IndexFunc(entries, func(e Entry) bool { return e.ID == id })
+		if i < 0 {
+			return entries, nil
+		}
+		entries[i].Status = StatusSent
+		if sendErr != nil {
+			entries[i].Status = StatusFailed
+			entries[i].Error = sendErr.Error()
+		}
+		entries[i].FinishedAt = &now
+		return entries, nil
+	})
+}
+
+// recoverInterrupted fails entries left sending by a previous process. Whether they
+// were sent is unknown, so they are not retried. It must only be called by the
+// scheduler holding the scheduler lock, other schedulers may still be sending
+// otherwise.
+func (s *Store) recoverInterrupted(now time.Time) error {
+	return s.update(now, func(entries []Entry) ([]Entry, error) {
+		for i := range entries {
+			if entries[i].Status == StatusSending {
+				entries[i].Status = StatusFailed
+				entries[i].Error = "interrupted while sending, check the Sent mailbox before scheduling it again"
+				entries[i].FinishedAt = &now
+			}
+		}
+		return entries, nil
+	})
+}
+
+// Clock tells the time. Tests replace it to drive the scheduler loop.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// SystemClock is the Clock of the operating system.
+var SystemClock Clock = systemClock{}
+
+// SendFunc sends the outgoing message of an entry.
+type SendFunc func(ctx context.Context, e Entry) error
+
+// Scheduler sends the entries of a Store when they are due.
+type Scheduler struct {
+	Store    *Store
+	Clock    Clock
+	Send     SendFunc
+	Interval time.Duration // how often the store is checked for due entries, defaultInterval if not positive
+}
+
+// defaultInterval is how often the store is checked by default. Messages are
+// sent up to this long after they are due.
+const defaultInterval = 10 * time.Second
+
+// RunDue sends all entries that are due and records their outcome.
+func (s *Scheduler) RunDue(ctx context.Context) error {
+	due, err := s.Store.claimDue(s.Clock.Now())
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, e := range due {
+		sendErr := s.Send(ctx, e)
+		if err := s.Store.finish(e.ID, sendErr, s.Clock.Now()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// lockScheduler takes the scheduler lock of the schedule file without
+// waiting. Only the process holding it sends entries, so every entry is sent
+// once even if several servers run with the same file. The returned function
+// releases the lock.
+func (s *Store) lockScheduler() (func(), bool, error) {
+	f, err := lockFile(s.path+".scheduler.lock", syscall.LOCK_EX|syscall.LOCK_NB)
+	if errors.Is(err, syscall.EWOULDBLOCK) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to lock scheduler: %w", err)
+	}
+	return func() { f.Close() }, true, nil
+}
+
+// Run sends due entries until ctx is done. While another process runs a
+// scheduler on the same file, Run waits for it to stop. Entries a previous
+// process was sending when it stopped are marked failed first. Errors of the
+// store are passed to logf and do not stop the loop.
+func (s *Scheduler) Run(ctx context.Context, logf func(format string, args ...any)) error {
+	interval := s.Interval
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+	waiting := false
+	for {
+		unlock, ok, err := s.Store.lockScheduler()
+		if err != nil {
+			return err
+		}
+		if ok {
+			defer unlock()
+			break
+		}
+		if !waiting {
+			logf("scheduler: another process sends the messages of %s, waiting for it to stop", s.Store.Path())
+			waiting = true
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.Clock.After(interval):
+		}
+	}
+
+	if err := s.Store.recoverInterrupted(s.Clock.Now()); err != nil {
+		return err
+	}
+	for {
+		if err := s.RunDue(ctx); err != nil {
+			logf("scheduler: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.Clock.After(interval):
+		}
+	}
+}
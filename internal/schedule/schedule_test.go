@@ -0,0 +1,293 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock only moves when advanced. Channels returned by After fire once
+// the clock has been advanced past their deadline.
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []waiter
+}
+
+type waiter struct {
+	deadline time.Time
+	ch       chan time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	c.waiters = append(c.waiters, waiter{c.now.Add(d), ch})
+	return ch
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	var pending []waiter
+	for _, w := range c.waiters {
+		if w.deadline.After(c.now) {
+			pending = append(pending, w)
+		} else {
+			w.ch <- c.now
+		}
+	}
+	c.waiters = pending
+}
+
+func (c *fakeClock) Waiting() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waiters)
+}
+
+var start = time.Date(2024, 2, 12, 9, 0, 0, 0, time.UTC)
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "schedule.json")
+	store := NewStore(path)
+
+	a, err := store.Add(Entry{OutgoingID: 1, Subject: "A", SendAt: start.Add(time.Hour)}, start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.ID == "" || a.Status != StatusPending || !a.CreatedAt.Equal(start) {
+		t.Errorf("Add() = %+v", a)
+	}
+	if _, err := store.Add(Entry{OutgoingID: 1, SendAt: start}, start); err == nil || !strings.Contains(err.Error(), "already scheduled") {
+		t.Errorf("Add() of a scheduled message error = %v", err)
+	}
+	if _, err := store.Add(Entry{OutgoingID: 2, Subject: "B", SendAt: start.Add(time.Minute)}, start); err != nil {
+		t.Fatal(err)
+	}
+
+	// A second store on the same file sees the entries, as after a restart
+	entries, err := NewStore(path).List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Subject != "B" || entries[1].Subject != "A" {
+		t.Errorf("List() = %+v, want B and A ordered by send time", entries)
+	}
+
+	canceled, err := store.Cancel(a.ID, start)
+	if err != nil || canceled.Status != StatusCanceled {
+		t.Fatalf("Cancel() = %+v, %v", canceled, err)
+	}
+	if _, err := store.Cancel(a.ID, start); err == nil {
+		t.Error("Cancel() of a canceled entry succeeded")
+	}
+	if _, err := store.Cancel("missing", start); err == nil {
+		t.Error("Cancel() of a missing entry succeeded")
+	}
+
+	// Finished entries are dropped after a week
+	if _, err := store.Add(Entry{OutgoingID: 3, SendAt: start}, start.Add(8*24*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	entries, _ = store.List()
+	for _, e := range entries {
+		if e.ID == a.ID {
+			t.Errorf("entry canceled 8 days ago was kept: %+v", e)
+		}
+	}
+}
+
+func TestScheduler(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "schedule.json"))
+	clock := &fakeClock{now: start}
+	sent := make(chan Entry, 10)
+	scheduler := &Scheduler{
+		Store:    store,
+		Clock:    clock,
+		Interval: time.Second,
+		Send: func(ctx context.Context, e Entry) error {
+			sent <- e
+			if e.OutgoingID == 2 {
+				return fmt.Errorf("outgoing message with ID 2 not found")
+			}
+			return nil
+		},
+	}
+
+	now, _ := store.Add(Entry{OutgoingID: 1, SendAt: start.Add(30 * time.Second)}, start)
+	failing, _ := store.Add(Entry{OutgoingID: 2, SendAt: start.Add(30 * time.Second)}, start)
+	later, _ := store.Add(Entry{OutgoingID: 3, SendAt: start.Add(time.Hour)}, start)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- scheduler.Run(ctx, t.Logf) }()
+
+	// advance moves the clock once the loop waits for it
+	advance := func(d time.Duration) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for clock.Waiting() == 0 {
+			if time.Now().After(deadline) {
+				t.Fatal("scheduler loop does not wait for the clock")
+			}
+			time.Sleep(time.Millisecond)
+		}
+		clock.Advance(d)
+	}
+
+	advance(29 * time.Second)
+	advance(time.Second)
+	advance(time.Second) // the loop ran the due entries before waiting again
+	for _, want := range []int{1, 2} {
+		select {
+		case e := <-sent:
+			if e.OutgoingID != want {
+				t.Errorf("sent %d, want %d", e.OutgoingID, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("entry %d was not sent", want)
+		}
+	}
+	if _, err := store.Cancel(later.ID, clock.Now()); err != nil {
+		t.Errorf("Cancel() of a pending entry error = %v", err)
+	}
+	advance(2 * time.Hour)
+	advance(time.Second)
+	cancel()
+	clock.Advance(time.Second)
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Run() = %v, want context.Canceled", err)
+	}
+	select {
+	case e := <-sent:
+		t.Errorf("canceled entry %d was sent", e.OutgoingID)
+	default:
+	}
+
+	entries, _ := store.List()
+	status := map[string]Entry{}
+	for _, e := range entries {
+		status[e.ID] = e
+	}
+	if e := status[now.ID]; e.Status != StatusSent || e.FinishedAt == nil {
+		t.Errorf("entry = %+v, want sent", e)
+	}
+	if e := status[failing.ID]; e.Status != StatusFailed || !strings.Contains(e.Error, "not found") {
+		t.Errorf("entry = %+v, want failed with the send error", e)
+	}
+	if e := status[later.ID]; e.Status != StatusCanceled {
+		t.Errorf("entry = %+v, want canceled", e)
+	}
+}
+
+func TestScheduler_NothingDue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schedule.json")
+	store := NewStore(path)
+	store.Add(Entry{OutgoingID: 1, SendAt: start.Add(time.Hour)}, start)
+	old := start.Add(-time.Hour)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	scheduler := &Scheduler{Store: store, Clock: &fakeClock{now: start}, Send: func(ctx context.Context, e Entry) error {
+		t.Errorf("entry %s is not due", e.ID)
+		return nil
+	}}
+	if err := scheduler.RunDue(context.Background()); err != nil {
+		t.Fatalf("RunDue() error = %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.ModTime().Equal(old) {
+		t.Errorf("schedule was rewritten at %v without changes", info.ModTime())
+	}
+}
+
+func TestScheduler_RecoversInterruptedSends(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "schedule.json"))
+	e, _ := store.Add(Entry{OutgoingID: 1, SendAt: start}, start)
+	if due, err := store.claimDue(start); err != nil || len(due) != 1 {
+		t.Fatalf("claimDue() = %v, %v", due, err)
+	}
+
+	// A new process starts while the entry is still marked as sending
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	scheduler := &Scheduler{Store: store, Clock: &fakeClock{now: start}, Send: func(ctx context.Context, e Entry) error {
+		t.Errorf("interrupted entry %s was sent again", e.ID)
+		return nil
+	}}
+	if err := scheduler.Run(ctx, t.Logf); err != context.Canceled {
+		t.Fatalf("Run() = %v", err)
+	}
+	entries, _ := store.List()
+	if entries[0].ID != e.ID || entries[0].Status != StatusFailed || !strings.Contains(entries[0].Error, "interrupted") {
+		t.Errorf("entry = %+v, want failed as interrupted", entries[0])
+	}
+}
+
+func TestScheduler_SingleInstance(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schedule.json")
+	store := NewStore(path)
+	e, _ := store.Add(Entry{OutgoingID: 1, SendAt: start}, start)
+	if due, err := store.claimDue(start); err != nil || len(due) != 1 {
+		t.Fatalf("claimDue() = %v, %v", due, err)
+	}
+
+	// Another process holds the scheduler lock and is still sending
+	unlock, ok, err := NewStore(path).lockScheduler()
+	if err != nil || !ok {
+		t.Fatalf("lockScheduler() = %v, %v", ok, err)
+	}
+	if _, ok, err := store.lockScheduler(); err != nil || ok {
+		t.Fatalf("second lockScheduler() = %v, %v, want the lock taken", ok, err)
+	}
+
+	clock := &fakeClock{now: start}
+	scheduler := &Scheduler{Store: store, Clock: clock, Interval: time.Second, Send: func(ctx context.Context, e Entry) error {
+		t.Errorf("entry %s was sent by the waiting scheduler", e.ID)
+		return nil
+	}}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- scheduler.Run(ctx, t.Logf) }()
+	for clock.Waiting() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	entries, _ := store.List()
+	if entries[0].ID != e.ID || entries[0].Status != StatusSending {
+		t.Errorf("entry = %+v, want still sending while the other scheduler runs", entries[0])
+	}
+	unlock()
+
+	// Once the other process stopped, the scheduler takes over
+	clock.Advance(time.Second)
+	for clock.Waiting() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	clock.Advance(time.Second)
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Run() = %v, want context.Canceled", err)
+	}
+	entries, _ = store.List()
+	if entries[0].Status != StatusFailed {
+		t.Errorf("entry = %+v, want failed as interrupted after taking over", entries[0])
+	}
+}
@@ -0,0 +1,328 @@
+// Package templates loads message templates from a directory. A template is a
+// Markdown file with YAML front matter, e.g. support-reset.md:
+//
+//	---
+//	description: Password reset instructions
+//	subject: "Your password reset for ticket {{.ticket}}"
+//	to: ["{{.email}}"]
+//	variables: [name, email, ticket]
+//	---
+//	Hi {{.name}},
+//
+//	please follow the link below to reset your password.
+//
+// The subject, the recipients and the body are rendered with text/template.
+// The variables listed in the front matter are required, a template referring
+// to any other variable fails to render unless it is given as well.
+package templates
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Template is a message template.
+type Template struct {
+	Name          string   `json:"name" yaml:"-"`
+	Path          string   `json:"path" yaml:"-"`
+	Description   string   `json:"description,omitempty" yaml:"description"`
+	Account       string   `json:"account,omitempty" yaml:"account"`
+	Subject       string   `json:"subject" yaml:"subject"`
+	To            []string `json:"to,omitempty" yaml:"to"`
+	Cc            []string `json:"cc,omitempty" yaml:"cc"`
+	Bcc           []string `json:"bcc,omitempty" yaml:"bcc"`
+	ContentFormat string   `json:"content_format,omitempty" yaml:"content_format"`
+	Variables     []string `json:"variables" yaml:"variables"`
+	Body          string   `json:"-" yaml:"-"`
+}
+
+// Message is a rendered template.
+type Message struct {
+	Account       string
+	Subject       string
+	To            []string
+	Cc            []string
+	Bcc           []string
+	ContentFormat string
+	Content       string
+}
+
+// LoadError is a file in the template directory that is not a valid
+// template.
+type LoadError struct {
+	Path  string `json:"path"`
+	Error string `json:"error"`
+}
+
+const ext = ".md"
+
+const delimiter = "---"
+
+// Parse parses the template name from the content of a template file.
+func Parse(name string, data []byte) (*Template, error) {
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	rest, ok := strings.CutPrefix(text, delimiter+"\n")
+	if !ok {
+		return nil, fmt.Errorf("template %s must start with front matter between %s lines", name, delimiter)
+	}
+	var frontMatter, body string
+	if before, after, ok := strings.Cut(rest, "\n"+delimiter+"\n"); ok {
+		frontMatter, body = before, after
+	} else if before, ok := strings.CutSuffix(rest, "\n"+delimiter); ok {
+		frontMatter = before
+	} else {
+		return nil, fmt.Errorf("template %s: front matter is not closed by a %s line", name, delimiter)
+	}
+
+	t := &Template{Name: name, Body: strings.TrimSpace(body)}
+	decoder := yaml.NewDecoder(strings.NewReader(frontMatter))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(t); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("template %s: invalid front matter: %w", name, err)
+	}
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Validate checks a template, e.g. one that is not loaded from a file.
+func (t *Template) Validate() error {
+	if t.Variables == nil {
+		t.Variables = []string{}
+	}
+	if strings.TrimSpace(t.Subject) == "" {
+		return fmt.Errorf("template %s has no subject", t.Name)
+	}
+	if strings.TrimSpace(t.Body) == "" {
+		return fmt.Errorf("template %s has no body", t.Name)
+	}
+	switch t.ContentFormat {
+	case "", "plain", "markdown":
+	default:
+		return fmt.Errorf("template %s: invalid content_format %q, use 'plain' or 'markdown'", t.Name, t.ContentFormat)
+	}
+	for _, v := range t.Variables {
+		if strings.TrimSpace(v) == "" {
+			return fmt.Errorf("template %s: variable names must not be empty", t.Name)
+		}
+	}
+	// Report syntax errors before rendering
+	for _, text := range t.texts() {
+		if _, err := parseText(text); err != nil {
+			return fmt.Errorf("template %s: %w", t.Name, err)
+		}
+	}
+	return nil
+}
+
+// texts returns the parts of the template that are rendered.
+func (t *Template) texts() []string {
+	texts := []string{t.Subject, t.Body}
+	texts = append(texts, t.To...)
+	texts = append(texts, t.Cc...)
+	return append(texts, t.Bcc...)
+}
+
+func parseText(text string) (*template.Template, error) {
+	return template.New("").Option("missingkey=error").Parse(text)
+}
+
+// Load loads the template name from dir.
+func Load(dir, name string) (*Template, error) {
+	if name == "" || name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		return nil, fmt.Errorf("invalid template name %q", name)
+	}
+	path := filepath.Join(dir, name+ext)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("template %q not found in %s", name, dir)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template: %w", err)
+	}
+	t, err := Parse(name, data)
+	if err != nil {
+		return nil, err
+	}
+	t.Path = path
+	return t, nil
+}
+
+// List loads all templates in dir, which may not exist. Files that are not
+// valid templates are returned as LoadErrors.
+func List(dir string) ([]*Template, []LoadError, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []*Template{}, []LoadError{}, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read template directory: %w", err)
+	}
+	templates := []*Template{}
+	loadErrors := []LoadError{}
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ext)
+		if !ok || entry.IsDir() || strings.HasPrefix(name, ".") {
+			continue
+		}
+		t, err := Load(dir, name)
+		if err != nil {
+			loadErrors = append(loadErrors, LoadError{Path: filepath.Join(dir, entry.Name()), Error: err.Error()})
+			continue
+		}
+		templates = append(templates, t)
+	}
+	return templates, loadErrors, nil
+}
+
+// Missing returns the variables vars lacks: required variables that are not
+// given or blank, and variables the template refers to that are not given.
+func (t *Template) Missing(vars map[string]string) []string {
+	var missing []string
+	for _, v := range t.Variables {
+		if strings.TrimSpace(vars[v]) == "" {
+			missing = append(missing, v)
+		}
+	}
+	for _, v := range t.Fields() {
+		if _, ok := vars[v]; !ok && !slices.Contains(missing, v) {
+			missing = append(missing, v)
+		}
+	}
+	return missing
+}
+
+// Fields returns the variables the template refers to, e.g. name for
+// {{.name}}, in order of appearance.
+func (t *Template) Fields() []string {
+	var fields []string
+	add := func(name string) {
+		if !slices.Contains(fields, name) {
+			fields = append(fields, name)
+		}
+	}
+	for _, text := range t.texts() {
+		tmpl, err := parseText(text)
+		if err != nil || tmpl.Tree == nil {
+			continue
+		}
+		walkFields(tmpl.Tree.Root, true, add)
+	}
+	return fields
+}
+
+// walkFields calls add for the variables node refers to. Within range and
+// with blocks, the dot is another value, only fields of $ are variables
+// there.
+func walkFields(node parse.Node, dot bool, add func(string)) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			walkFields(child, dot, add)
+		}
+	case *parse.ActionNode:
+		walkFields(n.Pipe, dot, add)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			for _, arg := range cmd.Args {
+				walkFields(arg, dot, add)
+			}
+		}
+	case *parse.FieldNode:
+		if dot {
+			add(n.Ident[0])
+		}
+	case *parse.VariableNode:
+		if n.Ident[0] == "$" && len(n.Ident) > 1 {
+			add(n.Ident[1])
+		}
+	case *parse.IfNode:
+		walkFields(n.Pipe, dot, add)
+		walkFields(n.List, dot, add)
+		walkFields(n.ElseList, dot, add)
+	case *parse.RangeNode:
+		walkFields(n.Pipe, dot, add)
+		walkFields(n.List, false, add)
+		walkFields(n.ElseList, dot, add)
+	case *parse.WithNode:
+		walkFields(n.Pipe, dot, add)
+		walkFields(n.List, false, add)
+		walkFields(n.ElseList, dot, add)
+	case *parse.TemplateNode:
+		walkFields(n.Pipe, dot, add)
+	}
+}
+
+// Render renders the template with vars. All required variables must be
+// given. Recipients that render blank are dropped, so optional recipients can
+// be left out.
+func (t *Template) Render(vars map[string]string) (Message, error) {
+	if missing := t.Missing(vars); len(missing) > 0 {
+		return Message{}, fmt.Errorf("template %s: missing variables: %s", t.Name, strings.Join(missing, ", "))
+	}
+	if vars == nil {
+		vars = map[string]string{}
+	}
+	render := func(text string) (string, error) {
+		tmpl, err := parseText(text)
+		if err != nil {
+			return "", err
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, vars); err != nil {
+			return "", fmt.Errorf("template %s: %w", t.Name, err)
+		}
+		return buf.String(), nil
+	}
+	renderAll := func(texts []string) ([]string, error) {
+		var rendered []string
+		for _, text := range texts {
+			r, err := render(text)
+			if err != nil {
+				return nil, err
+			}
+			if r = strings.TrimSpace(r); r != "" {
+				rendered = append(rendered, r)
+			}
+		}
+		return rendered, nil
+	}
+
+	m := Message{Account: t.Account, ContentFormat: t.ContentFormat}
+	var err error
+	if m.Subject, err = render(t.Subject); err != nil {
+		return Message{}, err
+	}
+	m.Subject = strings.TrimSpace(m.Subject)
+	if m.Content, err = render(t.Body); err != nil {
+		return Message{}, err
+	}
+	if m.To, err = renderAll(t.To); err != nil {
+		return Message{}, err
+	}
+	if m.Cc, err = renderAll(t.Cc); err != nil {
+		return Message{}, err
+	}
+	if m.Bcc, err = renderAll(t.Bcc); err != nil {
+		return Message{}, err
+	}
+	return m, nil
+}
@@ -0,0 +1,134 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const reset = `---
+description: Password reset
+subject: "Password reset for ticket {{.ticket}}"
+to: ["{{.email}}"]
+cc: ["{{.manager}}"]
+variables: [name, email, ticket]
+---
+
+Hi {{.name}},
+
+please reset your password.
+`
+
+func TestParse(t *testing.T) {
+	tmpl, err := Parse("reset", []byte(reset))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tmpl.Description != "Password reset" || !slices.Equal(tmpl.Variables, []string{"name", "email", "ticket"}) {
+		t.Errorf("Parse() = %+v, want the front matter", tmpl)
+	}
+	if tmpl.Body != "Hi {{.name}},\n\nplease reset your password." {
+		t.Errorf("Body = %q, want the trimmed body", tmpl.Body)
+	}
+
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{name: "no front matter", data: "Hi", wantErr: "must start with front matter"},
+		{name: "unclosed front matter", data: "---\nsubject: Hi\nHi", wantErr: "not closed"},
+		{name: "unknown field", data: "---\nsubject: Hi\nvars: [a]\n---\nHi", wantErr: "invalid front matter"},
+		{name: "no subject", data: "---\nto: [a@example.com]\n---\nHi", wantErr: "no subject"},
+		{name: "no body", data: "---\nsubject: Hi\n---\n", wantErr: "no body"},
+		{name: "invalid content format", data: "---\nsubject: Hi\ncontent_format: html\n---\nHi", wantErr: "invalid content_format"},
+		{name: "syntax error", data: "---\nsubject: Hi\n---\nHi {{.name", wantErr: "unclosed action"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse("x", []byte(tt.data)); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Parse() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	tmpl, err := Parse("reset", []byte(reset))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vars := map[string]string{"name": "Bob", "email": "bob@example.com", "ticket": "42", "manager": ""}
+	m, err := tmpl.Render(vars)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Subject != "Password reset for ticket 42" || !slices.Equal(m.To, []string{"bob@example.com"}) || len(m.Cc) != 0 {
+		t.Errorf("Render() = %+v, want rendered subject and recipients without the blank Cc", m)
+	}
+	if !strings.HasPrefix(m.Content, "Hi Bob,") {
+		t.Errorf("Content = %q, want the rendered body", m.Content)
+	}
+
+	if missing := tmpl.Missing(map[string]string{"name": "Bob", "email": " "}); !slices.Equal(missing, []string{"email", "ticket", "manager"}) {
+		t.Errorf("Missing() = %v, want [email ticket manager]", missing)
+	}
+	if _, err := tmpl.Render(map[string]string{"name": "Bob"}); err == nil || !strings.Contains(err.Error(), "email, ticket") {
+		t.Errorf("Render() error = %v, want the missing variables", err)
+	}
+	// manager may be blank, but the template refers to it
+	delete(vars, "manager")
+	if _, err := tmpl.Render(vars); err == nil || !strings.Contains(err.Error(), "manager") {
+		t.Errorf("Render() error = %v, want the undefined variable", err)
+	}
+}
+
+func TestFields(t *testing.T) {
+	tmpl := &Template{
+		Subject: "{{.event}}: {{if .vip}}VIP {{end}}{{.name}}",
+		To:      []string{"{{.email}}"},
+		Body:    "{{range .talks}}{{.title}} {{$.name}}{{end}}{{with .room}}{{.floor}}{{else}}{{.venue}}{{end}}",
+	}
+	want := []string{"event", "vip", "name", "talks", "room", "venue", "email"}
+	if got := tmpl.Fields(); !slices.Equal(got, want) {
+		t.Errorf("Fields() = %v, want %v", got, want)
+	}
+}
+
+func TestList(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"reset.md":  reset,
+		"broken.md": "no front matter",
+		"notes.txt": "not a template",
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	templates, loadErrors, err := List(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(templates) != 1 || templates[0].Name != "reset" || templates[0].Path != filepath.Join(dir, "reset.md") {
+		t.Errorf("List() templates = %+v, want reset", templates)
+	}
+	if len(loadErrors) != 1 || loadErrors[0].Path != filepath.Join(dir, "broken.md") {
+		t.Errorf("List() errors = %+v, want broken.md", loadErrors)
+	}
+
+	if templates, _, err := List(filepath.Join(dir, "missing")); err != nil || len(templates) != 0 {
+		t.Errorf("List(missing) = %v, %v, want no templates", templates, err)
+	}
+
+	for _, name := range []string{"missing", "../reset", ".hidden", ""} {
+		if _, err := Load(dir, name); err == nil {
+			t.Errorf("Load(%q) succeeded, want error", name)
+		}
+	}
+}
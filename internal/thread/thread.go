@@ -0,0 +1,180 @@
+// Package thread reconstructs conversations from message headers.
+//
+// Messages are linked to their parent through In-Reply-To and References,
+// in that order. Messages that cannot be linked that way are attached to the
+// earliest message with the same normalized subject.
+package thread
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dastrobu/mail-mcp/internal/headers"
+)
+
+// How a message was linked to its parent.
+const (
+	LinkedByInReplyTo  = "in-reply-to"
+	LinkedByReferences = "references"
+	LinkedBySubject    = "subject"
+)
+
+// Message is a message to thread.
+type Message struct {
+	MessageID  string   // without angle brackets
+	InReplyTo  []string // message IDs, without angle brackets
+	References []string // message IDs, oldest first, without angle brackets
+	Subject    string
+	Date       time.Time
+	Data       any // passed through to the Node
+}
+
+// FromHeader returns a message with the links taken from h. messageID, as
+// reported by Mail.app, is used if h has no Message-ID.
+func FromHeader(h headers.Header, messageID, subject string, date time.Time, data any) Message {
+	m := Message{
+		MessageID:  headers.NormalizeMessageID(messageID),
+		InReplyTo:  headers.MessageIDs(h.Get("In-Reply-To")),
+		References: headers.MessageIDs(strings.Join(h.Values("References"), " ")),
+		Subject:    subject,
+		Date:       date,
+		Data:       data,
+	}
+	if ids := headers.MessageIDs(h.Get("Message-ID")); len(ids) > 0 {
+		m.MessageID = ids[0]
+	}
+	return m
+}
+
+// Node is a message in a thread with its replies.
+type Node struct {
+	Message  Message
+	LinkedBy string // how the message was linked to its parent, "" for roots
+	Children []*Node
+}
+
+// Build threads messages into a forest ordered by date. Messages with the
+// same message ID are included once, the first one wins.
+func Build(messages []Message) []*Node {
+	var nodes []*Node
+	byID := map[string]*Node{}
+	for _, m := range messages {
+		if m.MessageID != "" {
+			if _, ok := byID[m.MessageID]; ok {
+				continue
+			}
+		}
+		n := &Node{Message: m}
+		nodes = append(nodes, n)
+		if m.MessageID != "" {
+			byID[m.MessageID] = n
+		}
+	}
+	slices.SortStableFunc(nodes, func(a, b *Node) int { return a.Message.Date.Compare(b.Message.Date) })
+
+	parents := map[*Node]*Node{}
+	// isAncestor reports whether a is n or an ancestor of n, linking n
+	// under a would create a cycle then.
+	isAncestor := func(a, n *Node) bool {
+		for ; n != nil; n = parents[n] {
+			if n == a {
+				return true
+			}
+		}
+		return false
+	}
+	link := func(n, parent *Node, by string) bool {
+		if parent == nil || isAncestor(n, parent) {
+			return false
+		}
+		parents[n] = parent
+		n.LinkedBy = by
+		return true
+	}
+
+	for _, n := range nodes {
+		linked := false
+		for _, id := range slices.Backward(n.Message.InReplyTo) {
+			if linked = link(n, byID[id], LinkedByInReplyTo); linked {
+				break
+			}
+		}
+		if linked {
+			continue
+		}
+		// The last reference is the direct parent, earlier ones are
+		// ancestors, used if the parent is missing.
+		for _, id := range slices.Backward(n.Message.References) {
+			if link(n, byID[id], LinkedByReferences) {
+				break
+			}
+		}
+	}
+
+	// Fall back to the subject for messages without links, or whose parents
+	// are missing, attaching them to the earliest message of the same
+	// subject.
+	first := map[string]*Node{}
+	for _, n := range nodes {
+		key := NormalizeSubject(n.Message.Subject)
+		if key == "" {
+			continue
+		}
+		if f, ok := first[key]; !ok {
+			first[key] = n
+		} else if parents[n] == nil {
+			link(n, f, LinkedBySubject)
+		}
+	}
+
+	var roots []*Node
+	for _, n := range nodes {
+		if p := parents[n]; p != nil {
+			p.Children = append(p.Children, n)
+		} else {
+			roots = append(roots, n)
+		}
+	}
+	return roots
+}
+
+// subjectPrefix matches reply and forward prefixes in several languages and
+// mailing list tags.
+var subjectPrefix = regexp.MustCompile(`(?i)^\s*(?:(?:re|fwd?|aw|wg|sv|vs|antw|tr|rif)(?:\[\d+\])?\s*:|\[[^\]]*\])\s*`)
+
+// NormalizeSubject strips reply and forward prefixes and mailing list tags
+// from a subject, collapses white space and lower-cases it.
+func NormalizeSubject(subject string) string {
+	for {
+		stripped := subjectPrefix.ReplaceAllString(subject, "")
+		if stripped == subject {
+			break
+		}
+		subject = stripped
+	}
+	return strings.ToLower(strings.Join(strings.Fields(subject), " "))
+}
+
+// Walk calls fn for every node of the forest in depth-first order with its
+// depth, 0 for roots.
+func Walk(roots []*Node, fn func(n *Node, depth int)) {
+	var walk func(nodes []*Node, depth int)
+	walk = func(nodes []*Node, depth int) {
+		for _, n := range nodes {
+			fn(n, depth)
+			walk(n.Children, depth+1)
+		}
+	}
+	walk(roots, 0)
+}
+
+// Len returns the number of messages in the forest.
+func Len(roots []*Node) int {
+	n := 0
+	for _, r := range roots {
+		n += 1 + Len(r.Children)
+	}
+	return n
+}
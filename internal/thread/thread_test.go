@@ -0,0 +1,117 @@
+package thread
+
+import (
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dastrobu/mail-mcp/internal/headers"
+)
+
+// loadFixtures reads the header fixtures in testdata.
+func loadFixtures(t *testing.T) []Message {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join("testdata", "*.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var messages []Message
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h := headers.Parse(string(data))
+		date, err := mail.ParseDate(h.Get("Date"))
+		if err != nil {
+			t.Fatalf("%s: %v", file, err)
+		}
+		messages = append(messages, FromHeader(h, "", h.Get("Subject"), date, filepath.Base(file)))
+	}
+	return messages
+}
+
+// render renders a forest as one line per message, indented by depth.
+func render(roots []*Node) string {
+	var b strings.Builder
+	Walk(roots, func(n *Node, depth int) {
+		b.WriteString(strings.Repeat("  ", depth))
+		b.WriteString(n.Message.Data.(string))
+		if n.LinkedBy != "" {
+			b.WriteString(" (" + n.LinkedBy + ")")
+		}
+		b.WriteString("\n")
+	})
+	return b.String()
+}
+
+func TestBuild(t *testing.T) {
+	messages := loadFixtures(t)
+	roots := Build(messages)
+
+	want := "" +
+		"7-unrelated.txt\n" +
+		"1-question.txt\n" +
+		"  2-reply-bob.txt (in-reply-to)\n" +
+		"    4-reply-to-bob.txt (in-reply-to)\n" +
+		"  3-reply-carol.txt (references)\n" +
+		"  5-missing-parent.txt (references)\n" +
+		"  6-no-headers.txt (subject)\n"
+	if got := render(roots); got != want {
+		t.Errorf("Build =\n%s\nwant\n%s", got, want)
+	}
+	if got := Len(roots); got != len(messages) {
+		t.Errorf("Len = %d, want %d", got, len(messages))
+	}
+}
+
+func TestBuild_Duplicates(t *testing.T) {
+	date := time.Date(2024, 2, 12, 9, 0, 0, 0, time.UTC)
+	roots := Build([]Message{
+		{MessageID: "1@example.com", Subject: "Hello", Date: date, Data: "inbox"},
+		{MessageID: "1@example.com", Subject: "Hello", Date: date, Data: "archive"},
+		{MessageID: "2@example.com", InReplyTo: []string{"1@example.com"}, Subject: "Re: Hello", Date: date.Add(time.Hour), Data: "reply"},
+	})
+	if got, want := render(roots), "inbox\n  reply (in-reply-to)\n"; got != want {
+		t.Errorf("Build =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestBuild_Cycle(t *testing.T) {
+	date := time.Date(2024, 2, 12, 9, 0, 0, 0, time.UTC)
+	roots := Build([]Message{
+		{MessageID: "a", InReplyTo: []string{"b"}, Date: date, Data: "a"},
+		{MessageID: "b", InReplyTo: []string{"a"}, Date: date.Add(time.Hour), Data: "b"},
+	})
+	if got, want := render(roots), "b\n  a (in-reply-to)\n"; got != want {
+		t.Errorf("Build =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestNormalizeSubject(t *testing.T) {
+	tests := []struct {
+		subject string
+		want    string
+	}{
+		{"Quarterly budget", "quarterly budget"},
+		{"Re: Quarterly budget", "quarterly budget"},
+		{"RE: re: Quarterly budget", "quarterly budget"},
+		{"Fwd: Re: Quarterly budget", "quarterly budget"},
+		{"FW: Quarterly budget", "quarterly budget"},
+		{"AW: WG: Quarterly budget", "quarterly budget"},
+		{"Re[2]: Quarterly budget", "quarterly budget"},
+		{"[team] Re: [team] Quarterly   budget ", "quarterly budget"},
+		{"Reply needed", "reply needed"},
+		{"Re:", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.subject, func(t *testing.T) {
+			if got := NormalizeSubject(tt.subject); got != tt.want {
+				t.Errorf("NormalizeSubject(%q) = %q, want %q", tt.subject, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package tools
+
+import (
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxInlineAttachmentSize limits how much of a text-like attachment
+// save_attachment returns inline.
+const maxInlineAttachmentSize = 1 << 20
+
+// textAttachmentTypes maps the extensions of text-like attachments, whose
+// content can be returned inline, to their MIME types.
+var textAttachmentTypes = map[string]string{
+	".txt": "text/plain",
+	".csv": "text/csv",
+	".ics": "text/calendar",
+	".eml": "message/rfc822",
+}
+
+// attachmentMIMEType guesses the MIME type of an attachment from its name.
+// Mail.app often fails to report the MIME type of attachments.
+func attachmentMIMEType(name string) string {
+	ext := strings.ToLower(filepath.Ext(name))
+	if t, ok := textAttachmentTypes[ext]; ok {
+		return t
+	}
+	if t := mime.TypeByExtension(ext); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
+
+// isTextAttachment reports whether the content of an attachment can be
+// returned inline.
+func isTextAttachment(name string) bool {
+	_, ok := textAttachmentTypes[strings.ToLower(filepath.Ext(name))]
+	return ok
+}
+
+// fillAttachmentMIMETypes sets a guessed mimeType on every attachment in
+// data["attachments"] for which Mail.app reported none.
+func fillAttachmentMIMETypes(data any) {
+	result, ok := data.(map[string]any)
+	if !ok {
+		return
+	}
+	attachments, _ := result["attachments"].([]any)
+	for _, a := range attachments {
+		att, ok := a.(map[string]any)
+		if !ok {
+			continue
+		}
+		if t, _ := att["mimeType"].(string); t == "" {
+			name, _ := att["name"].(string)
+			att["mimeType"] = attachmentMIMEType(name)
+		}
+	}
+}
+
+// resolveAttachments checks that every path names an existing regular file in
+// one of the allowed directories and returns the paths with symlinks resolved.
+// Symlinks are resolved first, so a link cannot point out of an allowed
+// directory.
+func resolveAttachments(paths []string, allowDirs []string) ([]string, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+	if len(allowDirs) == 0 {
+		return nil, fmt.Errorf("attachments are disabled: no attachment directories configured (set --attachment-allow-dir or APPLE_MAIL_MCP_ATTACHMENT_ALLOW_DIRS)")
+	}
+
+	var allowed []string
+	for _, dir := range allowDirs {
+		dir, err := filepath.EvalSymlinks(dir)
+		if err != nil {
+			// A missing directory cannot contain attachments
+			continue
+		}
+		if dir, err = filepath.Abs(dir); err == nil {
+			allowed = append(allowed, dir)
+		}
+	}
+
+	resolved := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if !filepath.IsAbs(path) {
+			return nil, fmt.Errorf("attachment path must be absolute: %q", path)
+		}
+		file, err := filepath.EvalSymlinks(path)
+		if err != nil {
+			return nil, fmt.Errorf("attachment not found: %q", path)
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, fmt.Errorf("attachment not found: %q", path)
+		}
+		if !info.Mode().IsRegular() {
+			return nil, fmt.Errorf("attachment is not a regular file: %q", path)
+		}
+		if !inAnyDir(file, allowed) {
+			return nil, fmt.Errorf("attachment is not in an allowed directory: %q", path)
+		}
+		resolved = append(resolved, file)
+	}
+	return resolved, nil
+}
+
+// inAnyDir reports whether path is inside one of dirs.
+func inAnyDir(path string, dirs []string) bool {
+	for _, dir := range dirs {
+		rel, err := filepath.Rel(dir, path)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolveAttachments(t *testing.T) {
+	root := t.TempDir()
+	allowed := filepath.Join(root, "allowed")
+	other := filepath.Join(root, "other")
+	for _, dir := range []string{allowed, filepath.Join(allowed, "sub"), other} {
+		if err := os.Mkdir(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, file := range []string{filepath.Join(allowed, "report.pdf"), filepath.Join(allowed, "sub", "data.csv"), filepath.Join(other, "secret.txt")} {
+		if err := os.WriteFile(file, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(filepath.Join(other, "secret.txt"), filepath.Join(allowed, "link.txt")); err != nil {
+		t.Fatal(err)
+	}
+	// A directory that merely shares the prefix of the allowed one
+	if err := os.Mkdir(allowed+"2", 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(allowed+"2", "file.txt"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		paths     []string
+		allowDirs []string
+		wantErr   string
+	}{
+		{name: "no attachments", paths: nil},
+		{name: "allowed", paths: []string{filepath.Join(allowed, "report.pdf"), filepath.Join(allowed, "sub", "data.csv")}, allowDirs: []string{allowed}},
+		{name: "not configured", paths: []string{filepath.Join(allowed, "report.pdf")}, wantErr: "attachments are disabled"},
+		{name: "relative", paths: []string{"report.pdf"}, allowDirs: []string{allowed}, wantErr: "must be absolute"},
+		{name: "missing", paths: []string{filepath.Join(allowed, "missing.pdf")}, allowDirs: []string{allowed}, wantErr: "not found"},
+		{name: "directory", paths: []string{filepath.Join(allowed, "sub")}, allowDirs: []string{allowed}, wantErr: "not a regular file"},
+		{name: "outside", paths: []string{filepath.Join(other, "secret.txt")}, allowDirs: []string{allowed}, wantErr: "not in an allowed directory"},
+		{name: "dot dot", paths: []string{filepath.Join(allowed, "..", "other", "secret.txt")}, allowDirs: []string{allowed}, wantErr: "not in an allowed directory"},
+		{name: "symlink out", paths: []string{filepath.Join(allowed, "link.txt")}, allowDirs: []string{allowed}, wantErr: "not in an allowed directory"},
+		{name: "prefix sibling", paths: []string{filepath.Join(allowed+"2", "file.txt")}, allowDirs: []string{allowed}, wantErr: "not in an allowed directory"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveAttachments(tt.paths, tt.allowDirs)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("resolveAttachments() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveAttachments() error = %v", err)
+			}
+			if len(got) != len(tt.paths) {
+				t.Errorf("resolveAttachments() = %v, want %d paths", got, len(tt.paths))
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dastrobu/mail-mcp/internal/body"
+	"github.com/dastrobu/mail-mcp/internal/md"
+)
+
+// BodyFormat constants for the message bodies returned by the read tools
+const (
+	BodyFormatPlain    = "plain"
+	BodyFormatHTML     = "html"
+	BodyFormatMarkdown = "markdown"
+)
+
+// normalizeBodyFormat validates a body_format parameter. An empty format
+// stays empty, the tools pick their own default.
+func normalizeBodyFormat(format string) (string, error) {
+	normalized := strings.ToLower(strings.TrimSpace(format))
+	switch normalized {
+	case "", BodyFormatPlain, BodyFormatHTML, BodyFormatMarkdown:
+		return normalized, nil
+	default:
+		return "", fmt.Errorf("invalid body_format: %s (must be plain, html or markdown)", normalized)
+	}
+}
+
+// applyBodyFormat replaces the plain content of a message returned by a
+// script with its body in format, taken from the message source the script
+// added for html and markdown. The source is removed. Messages without an
+// HTML part keep their plain content, formatKey reports the format actually
+// returned.
+func applyBodyFormat(message map[string]any, format, contentKey, formatKey string) {
+	source, _ := message["source"].(string)
+	delete(message, "source")
+	message[formatKey] = BodyFormatPlain
+	if format == BodyFormatPlain || source == "" {
+		return
+	}
+
+	parts, err := body.Parse(source)
+	if err != nil || parts.HTML == "" {
+		return
+	}
+	content := parts.HTML
+	if format == BodyFormatMarkdown {
+		if content, err = md.FromHTML(parts.HTML); err != nil {
+			return
+		}
+	}
+	message[contentKey] = content
+	message[formatKey] = format
+}
+
+// applyBodyFormats applies the body format to each message of a list in a
+// script result.
+func applyBodyFormats(data any, listKey, format, contentKey, formatKey string) {
+	forEachMessage(data, listKey, func(message map[string]any) {
+		applyBodyFormat(message, format, contentKey, formatKey)
+	})
+}
+
+// forEachMessage calls fn for each message of a list in a script result.
+func forEachMessage(data any, listKey string, fn func(message map[string]any)) {
+	result, ok := data.(map[string]any)
+	if !ok {
+		return
+	}
+	messages, _ := result[listKey].([]any)
+	for _, m := range messages {
+		if message, ok := m.(map[string]any); ok {
+			fn(message)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dastrobu/mail-mcp/internal/jxa"
+	"github.com/dastrobu/mail-mcp/internal/schedule"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type CancelScheduledInput struct {
+	ScheduleID string `json:"schedule_id" jsonschema:"The ID of the scheduled message as returned by schedule_send or list_scheduled" long:"schedule-id" description:"The ID of the scheduled message"`
+}
+
+// RegisterCancelScheduled registers the cancel_scheduled tool with the MCP server
+func RegisterCancelScheduled(srv *mcp.Server, executor jxa.Executor, cfg Config) {
+	mcp.AddTool(srv,
+		&mcp.Tool{
+			Name:        "cancel_scheduled",
+			Description: "Cancels a message queued with schedule_send that has not been sent yet. The outgoing message stays open in Mail.app, so it can be edited and scheduled again.",
+			InputSchema: GenerateSchema[CancelScheduledInput](),
+			Annotations: &mcp.ToolAnnotations{
+				Title:           "Cancel Scheduled Message",
+				ReadOnlyHint:    false,
+				IdempotentHint:  false,
+				DestructiveHint: new(false),
+				OpenWorldHint:   new(false),
+			},
+		},
+		func(ctx context.Context, request *mcp.CallToolRequest, input CancelScheduledInput) (*mcp.CallToolResult, any, error) {
+			return HandleCancelScheduled(ctx, executor, cfg, schedule.SystemClock, request, input)
+		},
+	)
+}
+
+func HandleCancelScheduled(ctx context.Context, executor jxa.Executor, cfg Config, clock schedule.Clock, request *mcp.CallToolRequest, input CancelScheduledInput) (*mcp.CallToolResult, any, error) {
+	if input.ScheduleID == "" {
+		return nil, nil, fmt.Errorf("schedule_id is required")
+	}
+	store, err := scheduleStore(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	entry, err := store.Cancel(input.ScheduleID, clock.Now())
+	if err != nil {
+		return nil, nil, err
+	}
+	return nil, map[string]any{
+		"scheduled": entry,
+		"message":   "Scheduled message canceled. The outgoing message is still open in Mail.app.",
+	}, nil
+}
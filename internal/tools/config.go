@@ -0,0 +1,35 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Config holds settings of the tools that are given on the command line of
+// the run and tool commands rather than per call.
+type Config struct {
+	AttachmentsDir        string        `long:"attachments-dir" env:"APPLE_MAIL_MCP_ATTACHMENTS_DIR" value-name:"DIR" description:"Directory save_attachment writes attachments to. save_attachment is disabled if not set."`
+	ExportDir             string        `long:"export-dir" env:"APPLE_MAIL_MCP_EXPORT_DIR" value-name:"DIR" description:"Directory export_messages writes .eml and mbox files to. export_messages is disabled if not set."`
+	AttachmentAllowDirs   []string      `long:"attachment-allow-dir" env:"APPLE_MAIL_MCP_ATTACHMENT_ALLOW_DIRS" env-delim:":" value-name:"DIR" description:"Directory the compose tools may attach files from. Can be specified multiple times. Attaching files is disabled if not set."`
+	EnableSend            bool          `long:"enable-send" env:"APPLE_MAIL_MCP_ENABLE_SEND" description:"Allow send_outgoing_message to send messages. send_outgoing_message is disabled if not set."`
+	SendAllowDomains      []string      `long:"send-allow-domain" env:"APPLE_MAIL_MCP_SEND_ALLOW_DOMAINS" env-delim:"," value-name:"DOMAIN" description:"Domain send_outgoing_message may send to, including subdomains. Can be specified multiple times."`
+	SendAllowSenderDomain bool          `long:"send-allow-sender-domain" env:"APPLE_MAIL_MCP_SEND_ALLOW_SENDER_DOMAIN" description:"Allow send_outgoing_message to send to the domain of the sending account. Do not set for accounts of providers such as gmail.com, it would allow every user of the provider."`
+	SendMaxRecipients     int           `long:"send-max-recipients" env:"APPLE_MAIL_MCP_SEND_MAX_RECIPIENTS" value-name:"N" description:"Maximum number of recipients of a message send_outgoing_message sends (default: 10)"`
+	UndoSendDelay         time.Duration `long:"undo-send-delay" env:"APPLE_MAIL_MCP_UNDO_SEND_DELAY" value-name:"DURATION" description:"Delay before schedule_send sends a message without send_at, during which it can be canceled (default: 30s)"`
+	ScheduleFile          string        `long:"schedule-file" env:"APPLE_MAIL_MCP_SCHEDULE_FILE" value-name:"FILE" description:"File scheduled messages are stored in (default: schedule.json in ~/Library/Application Support/com.github.dastrobu.mail-mcp)"`
+	SignatureDir          string        `long:"signature-dir" env:"APPLE_MAIL_MCP_SIGNATURE_DIR" value-name:"DIR" description:"Directory with Markdown signatures named after the account, e.g. Work.md, that the compose tools append to the content (default: signatures in ~/Library/Application Support/com.github.dastrobu.mail-mcp)"`
+	TemplateDir           string        `long:"template-dir" env:"APPLE_MAIL_MCP_TEMPLATE_DIR" value-name:"DIR" description:"Directory with the Markdown message templates of create_from_template (default: templates in ~/Library/Application Support/com.github.dastrobu.mail-mcp)"`
+	ContactsPath          string        `long:"contacts-path" env:"APPLE_MAIL_MCP_CONTACTS_PATH" value-name:"PATH" description:"vCard file, e.g. an export of the Contacts app, or directory of .vcf files resolve_contacts looks up names in (default: contacts in ~/Library/Application Support/com.github.dastrobu.mail-mcp)"`
+}
+
+// appSupportPath returns the path of name in the directory of the server in
+// the user's Application Support directory.
+func appSupportPath(name string) (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine the Application Support directory: %w", err)
+	}
+	return filepath.Join(dir, "com.github.dastrobu.mail-mcp", name), nil
+}
@@ -0,0 +1,91 @@
+package tools
+
+import (
+	"crypto/rand"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// confirmationTTL is how long a confirmation token issued by a dry run stays valid.
+const confirmationTTL = 5 * time.Minute
+
+// confirmations issues single-use tokens that bind a destructive operation to
+// a preceding dry run of the very same operation. Tokens live in memory only,
+// so they do not survive a server restart.
+type confirmations struct {
+	mu     sync.Mutex
+	now    func() time.Time
+	tokens map[string]confirmation
+}
+
+type confirmation struct {
+	fingerprint string
+	expires     time.Time
+}
+
+func newConfirmations() *confirmations {
+	return &confirmations{now: time.Now, tokens: map[string]confirmation{}}
+}
+
+// deleteConfirmations guards permanent deletion in delete_messages.
+var deleteConfirmations = newConfirmations()
+
+// issue returns a new token for the operation identified by fingerprint.
+func (c *confirmations) issue(fingerprint string) (string, time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	for token, conf := range c.tokens {
+		if !now.Before(conf.expires) {
+			delete(c.tokens, token)
+		}
+	}
+
+	token := rand.Text()
+	expires := now.Add(confirmationTTL)
+	c.tokens[token] = confirmation{fingerprint: fingerprint, expires: expires}
+	return token, expires
+}
+
+// redeem consumes token if it was issued for fingerprint and has not expired.
+// The confirmation is returned so that it can be restored if the operation
+// fails.
+func (c *confirmations) redeem(token, fingerprint string) (confirmation, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	conf, ok := c.tokens[token]
+	if !ok {
+		return confirmation{}, fmt.Errorf("unknown or already used confirmation token")
+	}
+	if !c.now().Before(conf.expires) {
+		delete(c.tokens, token)
+		return confirmation{}, fmt.Errorf("confirmation token expired, run a new dry run")
+	}
+	if conf.fingerprint != fingerprint {
+		return confirmation{}, fmt.Errorf("confirmation token was issued for a different set of messages")
+	}
+	delete(c.tokens, token)
+	return conf, nil
+}
+
+// restore makes a redeemed token valid again until it expires, e.g. after the
+// operation it confirmed failed.
+func (c *confirmations) restore(token string, conf confirmation) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tokens[token] = conf
+}
+
+// messagesFingerprint identifies a set of messages independent of the order
+// and duplicates of ids.
+func messagesFingerprint(account string, mailboxPath []string, ids []int) string {
+	sorted := slices.Clone(ids)
+	slices.Sort(sorted)
+	sorted = slices.Compact(sorted)
+	return fmt.Sprintf("%s\x00%s\x00%v", account, strings.Join(mailboxPath, "\x00"), sorted)
+}
@@ -0,0 +1,48 @@
+package tools
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConfirmations(t *testing.T) {
+	now := time.Date(2024, 2, 11, 10, 0, 0, 0, time.UTC)
+	c := newConfirmations()
+	c.now = func() time.Time { return now }
+
+	fp := messagesFingerprint("Work", []string{"Inbox"}, []int{2, 1, 2})
+	if other := messagesFingerprint("Work", []string{"Inbox"}, []int{1, 2}); other != fp {
+		t.Errorf("fingerprint depends on order or duplicates: %q != %q", other, fp)
+	}
+
+	token, expires := c.issue(fp)
+	if !expires.Equal(now.Add(confirmationTTL)) {
+		t.Errorf("expires = %v, want %v", expires, now.Add(confirmationTTL))
+	}
+	if _, err := c.redeem(token, messagesFingerprint("Work", []string{"Inbox"}, []int{1, 2, 3})); err == nil {
+		t.Error("redeem with a different fingerprint succeeded, want error")
+	}
+	if _, err := c.redeem(token, fp); err != nil {
+		t.Errorf("redeem = %v, want nil", err)
+	}
+	if _, err := c.redeem(token, fp); err == nil {
+		t.Error("second redeem succeeded, want error")
+	}
+
+	// A restored token can be redeemed again, e.g. after a failed deletion
+	token, _ = c.issue(fp)
+	conf, err := c.redeem(token, fp)
+	if err != nil {
+		t.Fatalf("redeem = %v, want nil", err)
+	}
+	c.restore(token, conf)
+	if _, err := c.redeem(token, fp); err != nil {
+		t.Errorf("redeem of a restored token = %v, want nil", err)
+	}
+
+	token, _ = c.issue(fp)
+	now = now.Add(confirmationTTL)
+	if _, err := c.redeem(token, fp); err == nil {
+		t.Error("redeem of an expired token succeeded, want error")
+	}
+}
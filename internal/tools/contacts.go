@@ -0,0 +1,198 @@
+package tools
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dastrobu/mail-mcp/internal/address"
+	"github.com/dastrobu/mail-mcp/internal/contacts"
+	"github.com/dastrobu/mail-mcp/internal/headers"
+	"github.com/dastrobu/mail-mcp/internal/jxa"
+)
+
+//go:embed scripts/list_correspondents.js
+var listCorrespondentsSource string
+
+var listCorrespondentsScript = jxa.Script{Name: "list_correspondents", Source: listCorrespondentsSource}
+
+const (
+	// defaultContactDays is how far back recent mail is indexed by default.
+	defaultContactDays = 90
+	maxContactDays     = 365
+)
+
+// contactIndex is what names are resolved with: the vCards and the
+// correspondents of recent mail.
+type contactIndex struct {
+	resolver     *contacts.Resolver
+	path         string
+	loadErrors   []contacts.LoadError
+	messageCount int
+	truncated    bool
+}
+
+func contactsPath(cfg Config) (string, error) {
+	if cfg.ContactsPath != "" {
+		return cfg.ContactsPath, nil
+	}
+	return appSupportPath("contacts")
+}
+
+// loadContactIndex loads the vCards and indexes the senders and recipients of
+// the mail of the last days.
+func loadContactIndex(ctx context.Context, executor jxa.Executor, cfg Config, days int) (*contactIndex, error) {
+	path, err := contactsPath(cfg)
+	if err != nil {
+		return nil, err
+	}
+	cards, loadErrors, err := contacts.Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	args, err := json.Marshal(map[string]any{"since": now.AddDate(0, 0, -days).UTC().Format(time.RFC3339)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal input for JXA: %w", err)
+	}
+	data, err := executor.Execute(ctx, listCorrespondentsScript, string(args))
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute list_correspondents: %w", err)
+	}
+	var result struct {
+		Senders []struct {
+			Sender string `json:"sender"`
+			Count  int    `json:"count"`
+			Last   string `json:"last"`
+		} `json:"senders"`
+		Recipients []struct {
+			Name    string `json:"name"`
+			Address string `json:"address"`
+			Count   int    `json:"count"`
+			Last    string `json:"last"`
+		} `json:"recipients"`
+		MessageCount int  `json:"messageCount"`
+		Truncated    bool `json:"truncated"`
+	}
+	if err := remarshal(data, &result); err != nil {
+		return nil, err
+	}
+
+	// Senders are counted by how Mail shows them, merge them by address
+	var correspondents []contacts.Correspondent
+	byEmail := map[string]int{}
+	add := func(a address.Address, received, sent int, last string) {
+		key := strings.ToLower(a.Email)
+		i, ok := byEmail[key]
+		if !ok {
+			i = len(correspondents)
+			byEmail[key] = i
+			correspondents = append(correspondents, contacts.Correspondent{Email: a.Email})
+		}
+		c := &correspondents[i]
+		if c.Name == "" {
+			c.Name = a.Name
+		}
+		c.Received += received
+		c.Sent += sent
+		if t, err := time.Parse(time.RFC3339, last); err == nil && t.After(c.Last) {
+			c.Last = t
+		}
+	}
+	for _, s := range result.Senders {
+		for _, h := range headers.ParseAddressList(s.Sender) {
+			if a, err := address.Normalize(h.Name, h.Email); err == nil {
+				add(a, s.Count, 0, s.Last)
+			}
+		}
+	}
+	for _, r := range result.Recipients {
+		if a, err := address.Normalize(r.Name, r.Address); err == nil {
+			add(a, 0, r.Count, r.Last)
+		}
+	}
+
+	return &contactIndex{
+		resolver:     &contacts.Resolver{Contacts: cards, Correspondents: correspondents, Now: now},
+		path:         path,
+		loadErrors:   loadErrors,
+		messageCount: result.MessageCount,
+		truncated:    result.Truncated,
+	}, nil
+}
+
+// resolvedName is a recipient given by name and the address it resolved to.
+type resolvedName struct {
+	Name    string `json:"name"`
+	Address string `json:"address"`
+}
+
+// resolveRecipientNames replaces the recipients of a compose tool that are
+// names rather than addresses, i.e. have no @, with the addresses they
+// resolve to, if resolve is set. If a name does not resolve, the call fails
+// with the candidates to choose from, nothing is guessed.
+func resolveRecipientNames(ctx context.Context, executor jxa.Executor, cfg Config, resolve bool, to, cc, bcc *[]string) ([]resolvedName, error) {
+	if !resolve {
+		return nil, nil
+	}
+	lists := []*[]string{to, cc, bcc}
+	hasNames := false
+	for _, list := range lists {
+		if list != nil && slices.ContainsFunc(*list, isName) {
+			hasNames = true
+		}
+	}
+	if !hasNames {
+		return nil, nil
+	}
+	index, err := loadContactIndex(ctx, executor, cfg, defaultContactDays)
+	if err != nil {
+		return nil, err
+	}
+
+	var resolved []resolvedName
+	var problems []string
+	for _, list := range lists {
+		if list == nil {
+			continue
+		}
+		for i, value := range *list {
+			if !isName(value) {
+				continue
+			}
+			res := index.resolver.Resolve(strings.TrimSpace(value), 5)
+			if res.Status != contacts.Resolved {
+				problems = append(problems, unresolvedName(res))
+				continue
+			}
+			(*list)[i] = res.Address
+			resolved = append(resolved, resolvedName{Name: value, Address: res.Address})
+		}
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("could not resolve recipient names, give addresses instead (see resolve_contacts for the evidence): %s", strings.Join(problems, "; "))
+	}
+	return resolved, nil
+}
+
+func isName(value string) bool {
+	return strings.TrimSpace(value) != "" && !strings.Contains(value, "@")
+}
+
+// unresolvedName describes the choice a name leaves, e.g.
+// `"Maria" is ambiguous, choose one of: Maria Garcia <maria@example.com>, ...`.
+func unresolvedName(res contacts.Resolution) string {
+	if len(res.Candidates) == 0 {
+		return fmt.Sprintf("no contact found for %q", res.Query)
+	}
+	choices := make([]string, len(res.Candidates))
+	for i, c := range res.Candidates {
+		choices[i] = c.Address
+	}
+	return fmt.Sprintf("%q is ambiguous, choose one of: %s", res.Query, strings.Join(choices, ", "))
+}
@@ -0,0 +1,156 @@
+package tools
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/dastrobu/mail-mcp/internal/jxa"
+	"github.com/dastrobu/mail-mcp/internal/mac"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+//go:embed scripts/create_forward.js
+var createForwardSource string
+
+var createForwardScript = jxa.Script{Name: "create_forward", Source: createForwardSource}
+
+type CreateForwardInput struct {
+	MessageID     int      `json:"message_id" jsonschema:"The ID of the message to forward" long:"message-id" description:"The ID of the message to forward"`
+	Account       string   `json:"account" jsonschema:"The name of the account the original message is in" long:"account" description:"The name of the account the original message is in"`
+	MailboxPath   []string `json:"mailbox_path" jsonschema:"The full path to the mailbox of the original message (e.g., [\"Inbox\", \"Subfolder\"])" long:"mailbox-path" description:"The full path to the mailbox of the original message (e.g., [\"Inbox\", \"Subfolder\"]). Can be specified multiple times."`
+	ToRecipients  []string `json:"to_recipients" jsonschema:"List of To recipients" long:"to-recipients" description:"List of To recipients. Can be specified multiple times."`
+	CcRecipients  []string `json:"cc_recipients,omitempty" jsonschema:"List of CC recipients" long:"cc-recipients" description:"List of CC recipients. Can be specified multiple times."`
+	BccRecipients []string `json:"bcc_recipients,omitempty" jsonschema:"List of BCC recipients" long:"bcc-recipients" description:"List of BCC recipients. Can be specified multiple times."`
+	ResolveNames  bool     `json:"resolve_names,omitempty" jsonschema:"Optional: look up recipients given by name instead of address, e.g. 'Maria from finance', like resolve_contacts does. A name that is ambiguous or unknown fails the call with the candidates to choose from." long:"resolve-names" description:"Look up recipients given by name instead of address like resolve_contacts does"`
+	Content       string   `json:"content,omitempty" jsonschema:"Optional note above the forwarded message. Supports Markdown formatting." long:"content" description:"Optional note above the forwarded message. Supports Markdown formatting."`
+	ContentFormat *string  `json:"content_format,omitempty" jsonschema:"Content format: 'plain' or 'markdown'. Default is 'markdown'." long:"content-format" description:"Content format: 'plain' or 'markdown'. Default is 'markdown'."`
+	Attachments   []string `json:"attachments,omitempty" jsonschema:"Absolute paths of local files to attach in addition to the attachments of the original message. The files must be in a directory the server allows attachments from." long:"attachment" description:"Absolute path of a local file to attach. Can be specified multiple times."`
+	Signature     string   `json:"signature,omitempty" jsonschema:"Optional: name of a Mail signature to add (see list_signatures), or 'none' for no signature. By default the Markdown signature of the account in the signature directory is appended to the content, if there is one." long:"signature" description:"Name of a Mail signature to add, or 'none' for no signature. By default the Markdown signature of the account is appended."`
+}
+
+func RegisterCreateForward(srv *mcp.Server, executor jxa.Executor, cfg Config) {
+	mcp.AddTool(srv,
+		&mcp.Tool{
+			Name:        "create_forward",
+			Description: "Forwards a message with its attachments, opens it as a new window, and pastes an optional note above the forwarded content. Returns the new Outgoing Message ID. NOTE: Mail.app may auto-save this message as a draft. If replacing this forward, use replace_forward.",
+			InputSchema: GenerateSchema[CreateForwardInput](),
+			Annotations: &mcp.ToolAnnotations{
+				Title:           "Create Forward",
+				ReadOnlyHint:    false,
+				IdempotentHint:  false,
+				DestructiveHint: new(true), // Creates a new message window
+				OpenWorldHint:   new(true),
+			},
+		},
+		func(ctx context.Context, request *mcp.CallToolRequest, input CreateForwardInput) (*mcp.CallToolResult, any, error) {
+			return HandleCreateForward(ctx, executor, cfg, request, input)
+		},
+	)
+}
+
+func HandleCreateForward(ctx context.Context, executor jxa.Executor, cfg Config, request *mcp.CallToolRequest, input CreateForwardInput) (*mcp.CallToolResult, any, error) {
+	// 1. Input Validation and Setup
+	if input.Account == "" || input.MessageID == 0 || len(input.MailboxPath) == 0 || len(input.ToRecipients) == 0 {
+		return nil, nil, fmt.Errorf("account, message_id, mailbox_path, and to_recipients are required")
+	}
+	contentFormat, err := ValidateAndNormalizeContentFormat(input.ContentFormat)
+	if err != nil {
+		return nil, nil, err
+	}
+	resolvedNames, err := resolveRecipientNames(ctx, executor, cfg, input.ResolveNames, &input.ToRecipients, &input.CcRecipients, &input.BccRecipients)
+	if err != nil {
+		return nil, nil, err
+	}
+	recipients, err := normalizeRecipients(&input.ToRecipients, &input.CcRecipients, &input.BccRecipients)
+	if err != nil {
+		return nil, nil, err
+	}
+	attachments, err := resolveAttachments(input.Attachments, cfg.AttachmentAllowDirs)
+	if err != nil {
+		return nil, nil, err
+	}
+	input.Attachments = attachments
+	if input.Content != "" {
+		if err := mac.EnsureAccessibility(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// 2. Prepare content for clipboard and JXA
+	htmlContent, plainContent, err := ToClipboardContent(input.Content, contentFormat)
+	if err != nil {
+		return nil, nil, err
+	}
+	signature, err := chooseSignature(cfg, input.Account, input.Signature)
+	if err != nil {
+		return nil, nil, err
+	}
+	// A Markdown signature is only added below a note
+	if input.Content == "" {
+		signature.File = nil
+	}
+	htmlContent, plainContent, err = signature.apply(htmlContent, plainContent)
+	if err != nil {
+		return nil, nil, err
+	}
+	input.Signature = signature.Mail
+
+	// 3. Execute JXA to create the forward
+	inputJSON, err := json.Marshal(input)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal input for JXA: %w", err)
+	}
+
+	resultAny, err := executor.Execute(ctx, createForwardScript, string(inputJSON))
+	if err != nil {
+		return nil, nil, fmt.Errorf("JXA execution failed: %w", err)
+	}
+
+	resultMap, ok := resultAny.(map[string]any)
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid JXA result format")
+	}
+
+	// 4. Extract data for pasting
+	outgoingID, idOk := resultMap["outgoing_id"].(float64)
+	resultSubject, subjectOk := resultMap["subject"].(string)
+	mailPID, pidOk := resultMap["pid"].(float64)
+
+	if !idOk || !subjectOk || !pidOk {
+		return nil, nil, fmt.Errorf("JXA result is missing required fields (outgoing_id, subject, pid)")
+	}
+
+	// 5. Paste the note above the forwarded content
+	if input.Content != "" {
+		if err := mac.PasteIntoWindow(ctx, int(mailPID), resultSubject, 5*time.Second, htmlContent, plainContent); err != nil {
+			return nil, nil, fmt.Errorf("accessibility paste operation failed: %w", err)
+		}
+		time.Sleep(250 * time.Millisecond)
+	}
+
+	// 6. Return success
+	finalResult := map[string]any{
+		"outgoing_id":           outgoingID,
+		"subject":               resultSubject,
+		"forwarded_attachments": resultMap["forwarded_attachments"],
+		"message":               "Forward created.",
+	}
+	if input.Content != "" {
+		finalResult["message"] = "Forward created and note pasted."
+	}
+	finalResult["recipients"] = recipients
+	if len(resolvedNames) > 0 {
+		finalResult["resolved_names"] = resolvedNames
+	}
+	if len(attachments) > 0 {
+		finalResult["attachments"] = attachments
+	}
+	if s := signature.result(); s != nil {
+		finalResult["signature"] = s
+	}
+
+	return nil, finalResult, nil
+}
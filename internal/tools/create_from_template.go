@@ -0,0 +1,114 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dastrobu/mail-mcp/internal/jxa"
+	"github.com/dastrobu/mail-mcp/internal/templates"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// CreateFromTemplateInput defines input parameters for create_from_template tool
+type CreateFromTemplateInput struct {
+	Template      string            `json:"template" jsonschema:"Name of the template as returned by list_templates" long:"template" description:"Name of the template"`
+	Variables     map[string]string `json:"variables,omitempty" jsonschema:"Values of the template variables by name. All variables the template requires must be given." long:"var" key-value-delimiter:"=" description:"Value of a template variable as name=value. Can be specified multiple times."`
+	Account       string            `json:"account,omitempty" jsonschema:"Optional: name of the account to send from. Overrides the account of the template, required if the template has none." long:"account" description:"Name of the account to send from, overrides the account of the template"`
+	ToRecipients  *[]string         `json:"to_recipients,omitempty" jsonschema:"Optional: To recipients replacing those of the template" long:"to-recipients" description:"To recipients replacing those of the template. Can be specified multiple times."`
+	CcRecipients  *[]string         `json:"cc_recipients,omitempty" jsonschema:"Optional: CC recipients replacing those of the template" long:"cc-recipients" description:"CC recipients replacing those of the template. Can be specified multiple times."`
+	BccRecipients *[]string         `json:"bcc_recipients,omitempty" jsonschema:"Optional: BCC recipients replacing those of the template" long:"bcc-recipients" description:"BCC recipients replacing those of the template. Can be specified multiple times."`
+	Attachments   []string          `json:"attachments,omitempty" jsonschema:"Absolute paths of local files to attach. The files must be in a directory the server allows attachments from." long:"attachment" description:"Absolute path of a local file to attach. Can be specified multiple times."`
+	Signature     string            `json:"signature,omitempty" jsonschema:"Optional: name of a Mail signature to add (see list_signatures), or 'none' for no signature. By default the Markdown signature of the account in the signature directory is appended to the content, if there is one." long:"signature" description:"Name of a Mail signature to add, or 'none' for no signature. By default the Markdown signature of the account is appended."`
+}
+
+// RegisterCreateFromTemplate registers the create_from_template tool with the MCP server
+func RegisterCreateFromTemplate(srv *mcp.Server, executor jxa.Executor, cfg Config) {
+	mcp.AddTool(srv,
+		&mcp.Tool{
+			Name:        "create_from_template",
+			Description: "Creates a new outgoing message from a template (see list_templates). The subject, recipients and body of the template are rendered with the given variables, then the message is created like create_outgoing_message does. Fails without opening a window if a required variable is missing. The message is NOT sent.",
+			InputSchema: GenerateSchema[CreateFromTemplateInput](),
+			Annotations: &mcp.ToolAnnotations{
+				Title:           "Create Message from Template",
+				ReadOnlyHint:    false,
+				IdempotentHint:  false,
+				DestructiveHint: new(true),
+				OpenWorldHint:   new(true),
+			},
+		},
+		func(ctx context.Context, request *mcp.CallToolRequest, input CreateFromTemplateInput) (*mcp.CallToolResult, any, error) {
+			return HandleCreateFromTemplate(ctx, executor, cfg, request, input)
+		},
+	)
+}
+
+func HandleCreateFromTemplate(ctx context.Context, executor jxa.Executor, cfg Config, request *mcp.CallToolRequest, input CreateFromTemplateInput) (*mcp.CallToolResult, any, error) {
+	if input.Template == "" {
+		return nil, nil, fmt.Errorf("template is required")
+	}
+	dir, err := templateDir(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	tmpl, err := templates.Load(dir, input.Template)
+	if err != nil {
+		return nil, nil, err
+	}
+	createInput, err := templateMessageInput(tmpl, input.Variables, input.Account)
+	if err != nil {
+		return nil, nil, err
+	}
+	if input.ToRecipients != nil {
+		createInput.ToRecipients = input.ToRecipients
+	}
+	if input.CcRecipients != nil {
+		createInput.CcRecipients = input.CcRecipients
+	}
+	if input.BccRecipients != nil {
+		createInput.BccRecipients = input.BccRecipients
+	}
+	createInput.Attachments = input.Attachments
+	createInput.Signature = input.Signature
+
+	result, data, err := HandleCreateOutgoingMessage(ctx, executor, cfg, request, createInput)
+	if err != nil {
+		return nil, nil, err
+	}
+	if m, ok := data.(map[string]any); ok {
+		m["template"] = tmpl.Name
+	}
+	return result, data, nil
+}
+
+// templateMessageInput renders tmpl with vars into the input of
+// create_outgoing_message. account overrides the account of the template.
+func templateMessageInput(tmpl *templates.Template, vars map[string]string, account string) (CreateOutgoingMessageInput, error) {
+	m, err := tmpl.Render(vars)
+	if err != nil {
+		return CreateOutgoingMessageInput{}, err
+	}
+	if account == "" {
+		account = m.Account
+	}
+	if account == "" {
+		return CreateOutgoingMessageInput{}, fmt.Errorf("template %s has no account, pass one", tmpl.Name)
+	}
+	input := CreateOutgoingMessageInput{
+		Account: account,
+		Subject: m.Subject,
+		Content: m.Content,
+	}
+	if m.ContentFormat != "" {
+		input.ContentFormat = &m.ContentFormat
+	}
+	if len(m.To) > 0 {
+		input.ToRecipients = &m.To
+	}
+	if len(m.Cc) > 0 {
+		input.CcRecipients = &m.Cc
+	}
+	if len(m.Bcc) > 0 {
+		input.BccRecipients = &m.Bcc
+	}
+	return input, nil
+}
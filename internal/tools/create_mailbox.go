@@ -0,0 +1,82 @@
+package tools
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/dastrobu/mail-mcp/internal/jxa"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+//go:embed scripts/create_mailbox.js
+var createMailboxSource string
+
+var createMailboxScript = jxa.Script{Name: "create_mailbox", Source: createMailboxSource}
+
+// CreateMailboxInput defines input parameters for create_mailbox tool
+type CreateMailboxInput struct {
+	Account     string   `json:"account" jsonschema:"Name of the email account" long:"account" description:"Name of the email account"`
+	MailboxPath []string `json:"mailboxPath" jsonschema:"Path of the mailbox to create as an array (e.g. ['Projects'] or ['Projects', 'Alpha']). Missing parent mailboxes are created as well. Note: Mailbox names are case-sensitive and must not contain '/'." long:"mailbox-path" description:"Path of the mailbox to create. Can be specified multiple times for nested paths."`
+}
+
+// RegisterCreateMailbox registers the create_mailbox tool with the MCP server
+func RegisterCreateMailbox(srv *mcp.Server, executor jxa.Executor) {
+	mcp.AddTool(srv,
+		&mcp.Tool{
+			Name:        "create_mailbox",
+			Description: "Creates a mailbox (folder) in an account of Apple Mail, including missing parent mailboxes. Fails if the mailbox already exists. Returns the mailboxPath of the new mailbox for follow-up calls, e.g. move_messages.",
+			InputSchema: GenerateSchema[CreateMailboxInput](),
+			Annotations: &mcp.ToolAnnotations{
+				Title:           "Create Mailbox",
+				ReadOnlyHint:    false,
+				IdempotentHint:  false,
+				DestructiveHint: new(false),
+				OpenWorldHint:   new(true),
+			},
+		},
+		func(ctx context.Context, request *mcp.CallToolRequest, input CreateMailboxInput) (*mcp.CallToolResult, any, error) {
+			return HandleCreateMailbox(ctx, executor, request, input)
+		},
+	)
+}
+
+func HandleCreateMailbox(ctx context.Context, executor jxa.Executor, request *mcp.CallToolRequest, input CreateMailboxInput) (*mcp.CallToolResult, any, error) {
+	if input.Account == "" {
+		return nil, nil, fmt.Errorf("account is required")
+	}
+	if err := validateMailboxNames("mailboxPath", input.MailboxPath); err != nil {
+		return nil, nil, err
+	}
+
+	inputJSON, err := json.Marshal(input)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal input for JXA: %w", err)
+	}
+
+	data, err := executor.Execute(ctx, createMailboxScript, string(inputJSON))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to execute create_mailbox: %w", err)
+	}
+
+	return nil, data, nil
+}
+
+// validateMailboxNames checks a path of mailboxes to create or rename to.
+// Mail separates nested mailboxes with "/", so names must not contain it.
+func validateMailboxNames(field string, path []string) error {
+	if len(path) == 0 {
+		return fmt.Errorf("%s is required and must be a non-empty array", field)
+	}
+	for _, name := range path {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("%s must not contain empty mailbox names", field)
+		}
+		if strings.Contains(name, "/") {
+			return fmt.Errorf("%s: mailbox name %q must not contain '/', use one array element per level", field, name)
+		}
+	}
+	return nil
+}
@@ -15,7 +15,9 @@ import (
 )
 
 //go:embed scripts/create_outgoing_message.js
-var createOutgoingMessageScript string
+var createOutgoingMessageSource string
+
+var createOutgoingMessageScript = jxa.Script{Name: "create_outgoing_message", Source: createOutgoingMessageSource}
 
 type CreateOutgoingMessageInput struct {
 	Account       string    `json:"account" jsonschema:"The name of the account to send from" long:"account" description:"The name of the account to send from"`
@@ -25,9 +27,12 @@ type CreateOutgoingMessageInput struct {
 	ToRecipients  *[]string `json:"to_recipients,omitempty" jsonschema:"List of To recipients" long:"to-recipients" description:"List of To recipients. Can be specified multiple times."`
 	CcRecipients  *[]string `json:"cc_recipients,omitempty" jsonschema:"List of CC recipients" long:"cc-recipients" description:"List of CC recipients. Can be specified multiple times."`
 	BccRecipients *[]string `json:"bcc_recipients,omitempty" jsonschema:"List of BCC recipients" long:"bcc-recipients" description:"List of BCC recipients. Can be specified multiple times."`
+	ResolveNames  bool      `json:"resolve_names,omitempty" jsonschema:"Optional: look up recipients given by name instead of address, e.g. 'Maria from finance', like resolve_contacts does. A name that is ambiguous or unknown fails the call with the candidates to choose from." long:"resolve-names" description:"Look up recipients given by name instead of address like resolve_contacts does"`
+	Attachments   []string  `json:"attachments,omitempty" jsonschema:"Absolute paths of local files to attach. The files must be in a directory the server allows attachments from." long:"attachment" description:"Absolute path of a local file to attach. Can be specified multiple times."`
+	Signature     string    `json:"signature,omitempty" jsonschema:"Optional: name of a Mail signature to add (see list_signatures), or 'none' for no signature. By default the Markdown signature of the account in the signature directory is appended to the content, if there is one." long:"signature" description:"Name of a Mail signature to add, or 'none' for no signature. By default the Markdown signature of the account is appended."`
 }
 
-func RegisterCreateOutgoingMessage(srv *mcp.Server) {
+func RegisterCreateOutgoingMessage(srv *mcp.Server, executor jxa.Executor, cfg Config) {
 	mcp.AddTool(srv,
 		&mcp.Tool{
 			Name:        "create_outgoing_message",
@@ -42,12 +47,12 @@ func RegisterCreateOutgoingMessage(srv *mcp.Server) {
 			},
 		},
 		func(ctx context.Context, request *mcp.CallToolRequest, input CreateOutgoingMessageInput) (*mcp.CallToolResult, any, error) {
-			return HandleCreateOutgoingMessage(ctx, request, input)
+			return HandleCreateOutgoingMessage(ctx, executor, cfg, request, input)
 		},
 	)
 }
 
-func HandleCreateOutgoingMessage(ctx context.Context, request *mcp.CallToolRequest, input CreateOutgoingMessageInput) (*mcp.CallToolResult, any, error) {
+func HandleCreateOutgoingMessage(ctx context.Context, executor jxa.Executor, cfg Config, request *mcp.CallToolRequest, input CreateOutgoingMessageInput) (*mcp.CallToolResult, any, error) {
 	// 1. Input Validation & Setup
 	if input.Account == "" || input.Subject == "" || input.Content == "" {
 		return nil, nil, fmt.Errorf("account, subject, and content are required")
@@ -56,6 +61,19 @@ func HandleCreateOutgoingMessage(ctx context.Context, request *mcp.CallToolReque
 	if err != nil {
 		return nil, nil, err
 	}
+	resolvedNames, err := resolveRecipientNames(ctx, executor, cfg, input.ResolveNames, input.ToRecipients, input.CcRecipients, input.BccRecipients)
+	if err != nil {
+		return nil, nil, err
+	}
+	recipients, err := normalizeRecipients(input.ToRecipients, input.CcRecipients, input.BccRecipients)
+	if err != nil {
+		return nil, nil, err
+	}
+	attachments, err := resolveAttachments(input.Attachments, cfg.AttachmentAllowDirs)
+	if err != nil {
+		return nil, nil, err
+	}
+	input.Attachments = attachments
 	if err := mac.EnsureAccessibility(); err != nil {
 		return nil, nil, err
 	}
@@ -65,6 +83,15 @@ func HandleCreateOutgoingMessage(ctx context.Context, request *mcp.CallToolReque
 	if err != nil {
 		return nil, nil, err
 	}
+	signature, err := chooseSignature(cfg, input.Account, input.Signature)
+	if err != nil {
+		return nil, nil, err
+	}
+	htmlContent, plainContent, err = signature.apply(htmlContent, plainContent)
+	if err != nil {
+		return nil, nil, err
+	}
+	input.Signature = signature.Mail
 
 	// 3. Execute JXA to create and save the draft
 	inputJSON, err := json.Marshal(input)
@@ -72,7 +99,7 @@ func HandleCreateOutgoingMessage(ctx context.Context, request *mcp.CallToolReque
 		return nil, nil, fmt.Errorf("failed to marshal input for JXA: %w", err)
 	}
 
-	resultAny, err := jxa.Execute(ctx, createOutgoingMessageScript, string(inputJSON))
+	resultAny, err := executor.Execute(ctx, createOutgoingMessageScript, string(inputJSON))
 	if err != nil {
 		return nil, nil, fmt.Errorf("JXA execution failed: %w", err)
 	}
@@ -103,6 +130,16 @@ func HandleCreateOutgoingMessage(ctx context.Context, request *mcp.CallToolReque
 		"subject":     resultSubject,
 		"message":     "Outgoing message created and content pasted. Note: Paste success is not verified.",
 	}
+	finalResult["recipients"] = recipients
+	if len(resolvedNames) > 0 {
+		finalResult["resolved_names"] = resolvedNames
+	}
+	if len(attachments) > 0 {
+		finalResult["attachments"] = attachments
+	}
+	if s := signature.result(); s != nil {
+		finalResult["signature"] = s
+	}
 
 	return nil, finalResult, nil
 }
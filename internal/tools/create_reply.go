@@ -15,7 +15,9 @@ import (
 )
 
 //go:embed scripts/create_reply.js
-var createReplyScript string
+var createReplySource string
+
+var createReplyScript = jxa.Script{Name: "create_reply", Source: createReplySource}
 
 type CreateReplyInput struct {
 	MessageID     int      `json:"message_id" jsonschema:"The ID of the message to reply to" long:"message-id" description:"The ID of the message to reply to"`
@@ -24,9 +26,11 @@ type CreateReplyInput struct {
 	Content       string   `json:"content" jsonschema:"Email body content for the reply. Supports Markdown formatting." long:"content" description:"Email body content for the reply. Supports Markdown formatting."`
 	ContentFormat *string  `json:"content_format,omitempty" jsonschema:"Content format: 'plain' or 'markdown'. Default is 'markdown'." long:"content-format" description:"Content format: 'plain' or 'markdown'. Default is 'markdown'."`
 	ReplyToAll    bool     `json:"reply_to_all,omitempty" jsonschema:"Reply to all recipients. Default is false." long:"reply-to-all" description:"Reply to all recipients. Default is false."`
+	Attachments   []string `json:"attachments,omitempty" jsonschema:"Absolute paths of local files to attach. The files must be in a directory the server allows attachments from." long:"attachment" description:"Absolute path of a local file to attach. Can be specified multiple times."`
+	Signature     string   `json:"signature,omitempty" jsonschema:"Optional: name of a Mail signature to add (see list_signatures), or 'none' for no signature. By default the Markdown signature of the account in the signature directory is appended to the content, if there is one." long:"signature" description:"Name of a Mail signature to add, or 'none' for no signature. By default the Markdown signature of the account is appended."`
 }
 
-func RegisterCreateReply(srv *mcp.Server) {
+func RegisterCreateReply(srv *mcp.Server, executor jxa.Executor, cfg Config) {
 	mcp.AddTool(srv,
 		&mcp.Tool{
 			Name:        "create_reply",
@@ -41,12 +45,12 @@ func RegisterCreateReply(srv *mcp.Server) {
 			},
 		},
 		func(ctx context.Context, request *mcp.CallToolRequest, input CreateReplyInput) (*mcp.CallToolResult, any, error) {
-			return HandleCreateReply(ctx, request, input)
+			return HandleCreateReply(ctx, executor, cfg, request, input)
 		},
 	)
 }
 
-func HandleCreateReply(ctx context.Context, request *mcp.CallToolRequest, input CreateReplyInput) (*mcp.CallToolResult, any, error) {
+func HandleCreateReply(ctx context.Context, executor jxa.Executor, cfg Config, request *mcp.CallToolRequest, input CreateReplyInput) (*mcp.CallToolResult, any, error) {
 	// 1. Input Validation and Setup
 	if input.Account == "" || input.MessageID == 0 || input.Content == "" || len(input.MailboxPath) == 0 {
 		return nil, nil, fmt.Errorf("account, message_id, content, and mailbox_path are required")
@@ -55,6 +59,11 @@ func HandleCreateReply(ctx context.Context, request *mcp.CallToolRequest, input
 	if err != nil {
 		return nil, nil, err
 	}
+	attachments, err := resolveAttachments(input.Attachments, cfg.AttachmentAllowDirs)
+	if err != nil {
+		return nil, nil, err
+	}
+	input.Attachments = attachments
 	if err := mac.EnsureAccessibility(); err != nil {
 		return nil, nil, err
 	}
@@ -64,6 +73,15 @@ func HandleCreateReply(ctx context.Context, request *mcp.CallToolRequest, input
 	if err != nil {
 		return nil, nil, err
 	}
+	signature, err := chooseSignature(cfg, input.Account, input.Signature)
+	if err != nil {
+		return nil, nil, err
+	}
+	htmlContent, plainContent, err = signature.apply(htmlContent, plainContent)
+	if err != nil {
+		return nil, nil, err
+	}
+	input.Signature = signature.Mail
 
 	// 3. Execute JXA to create the reply
 	inputJSON, err := json.Marshal(input)
@@ -71,7 +89,7 @@ func HandleCreateReply(ctx context.Context, request *mcp.CallToolRequest, input
 		return nil, nil, fmt.Errorf("failed to marshal input for JXA: %w", err)
 	}
 
-	resultAny, err := jxa.Execute(ctx, createReplyScript, string(inputJSON))
+	resultAny, err := executor.Execute(ctx, createReplyScript, string(inputJSON))
 	if err != nil {
 		return nil, nil, fmt.Errorf("JXA execution failed: %w", err)
 	}
@@ -102,6 +120,12 @@ func HandleCreateReply(ctx context.Context, request *mcp.CallToolRequest, input
 		"subject":     resultSubject,
 		"message":     "Reply created and content pasted.",
 	}
+	if len(attachments) > 0 {
+		finalResult["attachments"] = attachments
+	}
+	if s := signature.result(); s != nil {
+		finalResult["signature"] = s
+	}
 
 	return nil, finalResult, nil
 }
@@ -0,0 +1,88 @@
+package tools
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+
+	"github.com/dastrobu/mail-mcp/internal/jxa"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+//go:embed scripts/create_rule.js
+var createRuleSource string
+
+var createRuleScript = jxa.Script{Name: "create_rule", Source: createRuleSource}
+
+// CreateRuleInput defines input parameters for create_rule tool
+type CreateRuleInput struct {
+	Name       string          `json:"name" jsonschema:"Name of the rule. Must not be used by another rule." long:"name" description:"Name of the rule"`
+	Disabled   bool            `json:"disabled,omitempty" jsonschema:"Create the rule disabled. Default: false" long:"disabled" description:"Create the rule disabled"`
+	Match      string          `json:"match,omitempty" jsonschema:"Whether all or any of the conditions must be met: all or any. Default: all" long:"match" description:"Whether all or any of the conditions must be met: all or any. Default: all"`
+	Conditions []RuleCondition `json:"conditions" jsonschema:"Conditions of the rule" long:"condition" description:"Condition as JSON, e.g. {\"type\":\"from\",\"value\":\"billing@example.com\"}. Can be specified multiple times."`
+	Actions    RuleActions     `json:"actions" jsonschema:"Actions of the rule, at least one is required" long:"actions" description:"Actions as JSON, e.g. {\"move_to\":{\"account\":\"Work\",\"mailboxPath\":[\"Invoices\"]}}"`
+}
+
+// RegisterCreateRule registers the create_rule tool with the MCP server
+func RegisterCreateRule(srv *mcp.Server, executor jxa.Executor) {
+	mcp.AddTool(srv,
+		&mcp.Tool{
+			Name:        "create_rule",
+			Description: "Creates a rule in Apple Mail. It is added after the existing rules and applies to new incoming messages. Supports conditions on From, To, Cc, subject, content, account and any header field, and the actions move, copy, mark as read, flag, delete, forward and stop evaluating rules. Returns the created rule as list_rules does.",
+			InputSchema: GenerateSchema[CreateRuleInput](),
+			Annotations: &mcp.ToolAnnotations{
+				Title:           "Create Rule",
+				ReadOnlyHint:    false,
+				IdempotentHint:  false,
+				DestructiveHint: new(false),
+				OpenWorldHint:   new(true),
+			},
+		},
+		func(ctx context.Context, request *mcp.CallToolRequest, input CreateRuleInput) (*mcp.CallToolResult, any, error) {
+			return HandleCreateRule(ctx, executor, request, input)
+		},
+	)
+}
+
+func HandleCreateRule(ctx context.Context, executor jxa.Executor, request *mcp.CallToolRequest, input CreateRuleInput) (*mcp.CallToolResult, any, error) {
+	if input.Name == "" {
+		return nil, nil, fmt.Errorf("name is required")
+	}
+	if input.Match == "" {
+		input.Match = "all"
+	}
+	if err := validateRuleMatch(input.Match); err != nil {
+		return nil, nil, err
+	}
+	conditions, err := toMailConditions(input.Conditions)
+	if err != nil {
+		return nil, nil, err
+	}
+	actions, err := toMailActions(input.Actions)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	inputJSON, err := json.Marshal(map[string]any{
+		"name":                   input.Name,
+		"enabled":                !input.Disabled,
+		"allConditionsMustBeMet": input.Match == "all",
+		"conditions":             conditions,
+		"actions":                actions,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal input for JXA: %w", err)
+	}
+
+	data, err := executor.Execute(ctx, createRuleScript, string(inputJSON))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to execute create_rule: %w", err)
+	}
+	rule, err := ruleResult(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return nil, map[string]any{"rule": rule}, nil
+}
@@ -0,0 +1,67 @@
+package tools
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// cursor is the content of the opaque cursors of the paginated tools. It ties
+// an offset into the results to the filters the results were produced with,
+// so a cursor cannot be used to page through the results of another query.
+type cursor struct {
+	Filter string `json:"f"`
+	Offset int    `json:"o"`
+}
+
+// filterHash returns a short hash of the filters of a paginated request.
+// filters must not include the cursor or the page size.
+func filterHash(filters any) (string, error) {
+	data, err := json.Marshal(filters)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash filters: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8]), nil
+}
+
+func encodeCursor(hash string, offset int) string {
+	data, _ := json.Marshal(cursor{Filter: hash, Offset: offset})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor returns the offset of a cursor. An empty cursor starts at
+// offset 0. Cursors issued for other filters than hash are rejected.
+func decodeCursor(s string, hash string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid cursor")
+	}
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.Offset < 0 {
+		return 0, fmt.Errorf("invalid cursor")
+	}
+	if c.Filter != hash {
+		return 0, fmt.Errorf("cursor does not match the current filters; repeat the request without cursor to start over")
+	}
+	return c.Offset, nil
+}
+
+// setNextCursor replaces the next_offset the scripts report with an opaque
+// next_cursor, which is null on the last page.
+func setNextCursor(data any, hash string) {
+	result, ok := data.(map[string]any)
+	if !ok {
+		return
+	}
+	result["next_cursor"] = nil
+	if next, ok := result["next_offset"].(float64); ok {
+		result["next_cursor"] = encodeCursor(hash, int(next))
+	}
+	delete(result, "next_offset")
+}
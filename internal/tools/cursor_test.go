@@ -0,0 +1,47 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCursor(t *testing.T) {
+	hash, err := filterHash(ListDraftsInput{Account: "Work"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _ := filterHash(ListDraftsInput{Account: "Personal"})
+	if hash == other {
+		t.Fatalf("filter hashes of different filters are equal: %q", hash)
+	}
+
+	if offset, err := decodeCursor("", hash); err != nil || offset != 0 {
+		t.Errorf("decodeCursor(\"\") = %d, %v, want 0, nil", offset, err)
+	}
+	c := encodeCursor(hash, 50)
+	if offset, err := decodeCursor(c, hash); err != nil || offset != 50 {
+		t.Errorf("decodeCursor = %d, %v, want 50, nil", offset, err)
+	}
+	if _, err := decodeCursor(c, other); err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Errorf("decodeCursor with other filters = %v, want mismatch error", err)
+	}
+	for _, invalid := range []string{"!!", "bm90IGpzb24", encodeCursor(hash, -1)} {
+		if _, err := decodeCursor(invalid, hash); err == nil || err.Error() != "invalid cursor" {
+			t.Errorf("decodeCursor(%q) = %v, want invalid cursor", invalid, err)
+		}
+	}
+
+	data := map[string]any{"next_offset": float64(100)}
+	setNextCursor(data, hash)
+	if _, ok := data["next_offset"]; ok {
+		t.Error("next_offset not removed")
+	}
+	if offset, err := decodeCursor(data["next_cursor"].(string), hash); err != nil || offset != 100 {
+		t.Errorf("next_cursor decodes to %d, %v, want 100, nil", offset, err)
+	}
+	data = map[string]any{"next_offset": nil}
+	setNextCursor(data, hash)
+	if v, ok := data["next_cursor"]; !ok || v != nil {
+		t.Errorf("next_cursor = %v, want null on the last page", v)
+	}
+}
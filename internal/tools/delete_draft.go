@@ -13,13 +13,15 @@ import (
 )
 
 //go:embed scripts/delete_draft.js
-var deleteDraftScript string
+var deleteDraftSource string
+
+var deleteDraftScript = jxa.Script{Name: "delete_draft", Source: deleteDraftSource}
 
 type DeleteDraftInput struct {
 	DraftID int `json:"draft_id" jsonschema:"The ID of the draft to delete" long:"draft-id" description:"The ID of the draft to delete"`
 }
 
-func RegisterDeleteDraft(srv *mcp.Server) {
+func RegisterDeleteDraft(srv *mcp.Server, executor jxa.Executor) {
 	mcp.AddTool(srv,
 		&mcp.Tool{
 			Name:        "delete_draft",
@@ -33,11 +35,13 @@ func RegisterDeleteDraft(srv *mcp.Server) {
 				OpenWorldHint:   new(true),
 			},
 		},
-		HandleDeleteDraft,
+		func(ctx context.Context, request *mcp.CallToolRequest, input DeleteDraftInput) (*mcp.CallToolResult, any, error) {
+			return HandleDeleteDraft(ctx, executor, request, input)
+		},
 	)
 }
 
-func HandleDeleteDraft(ctx context.Context, request *mcp.CallToolRequest, input DeleteDraftInput) (*mcp.CallToolResult, any, error) {
+func HandleDeleteDraft(ctx context.Context, executor jxa.Executor, request *mcp.CallToolRequest, input DeleteDraftInput) (*mcp.CallToolResult, any, error) {
 	// Prepare arguments for JXA
 	inputJSON, err := json.Marshal(input)
 	if err != nil {
@@ -45,7 +49,7 @@ func HandleDeleteDraft(ctx context.Context, request *mcp.CallToolRequest, input
 	}
 
 	// Execute JXA
-	data, err := jxa.Execute(ctx, deleteDraftScript, string(inputJSON))
+	data, err := executor.Execute(ctx, deleteDraftScript, string(inputJSON))
 	if err != nil {
 		return nil, nil, err
 	}
@@ -0,0 +1,65 @@
+package tools
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+
+	"github.com/dastrobu/mail-mcp/internal/jxa"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+//go:embed scripts/delete_mailbox.js
+var deleteMailboxSource string
+
+var deleteMailboxScript = jxa.Script{Name: "delete_mailbox", Source: deleteMailboxSource}
+
+// DeleteMailboxInput defines input parameters for delete_mailbox tool
+type DeleteMailboxInput struct {
+	Account     string   `json:"account" jsonschema:"Name of the email account" long:"account" description:"Name of the email account"`
+	MailboxPath []string `json:"mailboxPath" jsonschema:"Path to the mailbox to delete as an array (e.g. ['Projects', 'Alpha']). Note: Mailbox names are case-sensitive." long:"mailbox-path" description:"Path to the mailbox to delete. Can be specified multiple times for nested paths."`
+	Force       bool     `json:"force,omitempty" jsonschema:"Delete the mailbox even if it contains messages or sub-mailboxes. They are deleted with it and cannot be recovered. Default: false" long:"force" description:"Delete the mailbox even if it contains messages or sub-mailboxes"`
+}
+
+// RegisterDeleteMailbox registers the delete_mailbox tool with the MCP server
+func RegisterDeleteMailbox(srv *mcp.Server, executor jxa.Executor) {
+	mcp.AddTool(srv,
+		&mcp.Tool{
+			Name:        "delete_mailbox",
+			Description: "Deletes a mailbox (folder) in Apple Mail. Fails with MAILBOX_NOT_EMPTY if the mailbox or one of its sub-mailboxes contains messages, or if it has sub-mailboxes, unless force is true; with force the messages and sub-mailboxes are deleted permanently. Returns the deleted mailboxPath and the parentMailboxPath that remains.",
+			InputSchema: GenerateSchema[DeleteMailboxInput](),
+			Annotations: &mcp.ToolAnnotations{
+				Title:           "Delete Mailbox",
+				ReadOnlyHint:    false,
+				IdempotentHint:  false,
+				DestructiveHint: new(true),
+				OpenWorldHint:   new(true),
+			},
+		},
+		func(ctx context.Context, request *mcp.CallToolRequest, input DeleteMailboxInput) (*mcp.CallToolResult, any, error) {
+			return HandleDeleteMailbox(ctx, executor, request, input)
+		},
+	)
+}
+
+func HandleDeleteMailbox(ctx context.Context, executor jxa.Executor, request *mcp.CallToolRequest, input DeleteMailboxInput) (*mcp.CallToolResult, any, error) {
+	if input.Account == "" {
+		return nil, nil, fmt.Errorf("account is required")
+	}
+	if len(input.MailboxPath) == 0 {
+		return nil, nil, fmt.Errorf("mailboxPath is required and must be a non-empty array")
+	}
+
+	inputJSON, err := json.Marshal(input)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal input for JXA: %w", err)
+	}
+
+	data, err := executor.Execute(ctx, deleteMailboxScript, string(inputJSON))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to execute delete_mailbox: %w", err)
+	}
+
+	return nil, data, nil
+}
@@ -0,0 +1,94 @@
+package tools
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/dastrobu/mail-mcp/internal/jxa"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+//go:embed scripts/delete_messages.js
+var deleteMessagesSource string
+
+var deleteMessagesScript = jxa.Script{Name: "delete_messages", Source: deleteMessagesSource}
+
+// DeleteMessagesInput defines input parameters for delete_messages tool
+type DeleteMessagesInput struct {
+	Account           string   `json:"account" jsonschema:"Name of the email account the messages are in" long:"account" description:"Name of the email account the messages are in"`
+	MailboxPath       []string `json:"mailboxPath" jsonschema:"Path to the mailbox as an array (e.g. ['Inbox']). Use the mailboxPath field from get_selected_messages or find_messages. Note: Mailbox names are case-sensitive." long:"mailbox-path" description:"Path to the mailbox. Can be specified multiple times for nested paths."`
+	MessageIDs        []int    `json:"message_ids" jsonschema:"IDs of the messages to delete" long:"message-id" description:"ID of a message to delete. Can be specified multiple times."`
+	Permanent         bool     `json:"permanent,omitempty" jsonschema:"Delete permanently instead of moving to the Trash. Requires confirmation_token from a preceding dry run." long:"permanent" description:"Delete permanently instead of moving to the Trash"`
+	DryRun            bool     `json:"dry_run,omitempty" jsonschema:"Only report which messages would be deleted. With permanent=true, returns a confirmation_token." long:"dry-run" description:"Only report which messages would be deleted"`
+	ConfirmationToken string   `json:"confirmation_token,omitempty" jsonschema:"Token returned by a dry run with permanent=true for the same messages. Required for permanent deletion, valid once and for 5 minutes." long:"confirmation-token" description:"Token returned by a dry run with permanent=true"`
+}
+
+// RegisterDeleteMessages registers the delete_messages tool with the MCP server
+func RegisterDeleteMessages(srv *mcp.Server, executor jxa.Executor) {
+	mcp.AddTool(srv,
+		&mcp.Tool{
+			Name:        "delete_messages",
+			Description: "Deletes received messages by moving them to the account's Trash. Permanent deletion needs two calls: a dry run with permanent=true returns a confirmation_token, which must then be passed with permanent=true for the same messages.",
+			InputSchema: GenerateSchema[DeleteMessagesInput](),
+			Annotations: &mcp.ToolAnnotations{
+				Title:           "Delete Messages",
+				ReadOnlyHint:    false,
+				IdempotentHint:  false,
+				DestructiveHint: new(true),
+				OpenWorldHint:   new(true),
+			},
+		},
+		func(ctx context.Context, request *mcp.CallToolRequest, input DeleteMessagesInput) (*mcp.CallToolResult, any, error) {
+			return HandleDeleteMessages(ctx, executor, request, input)
+		},
+	)
+}
+
+func HandleDeleteMessages(ctx context.Context, executor jxa.Executor, request *mcp.CallToolRequest, input DeleteMessagesInput) (*mcp.CallToolResult, any, error) {
+	if len(input.MailboxPath) == 0 {
+		return nil, nil, fmt.Errorf("mailboxPath is required and must be a non-empty array")
+	}
+	if len(input.MessageIDs) == 0 {
+		return nil, nil, fmt.Errorf("message_ids is required and must be a non-empty array")
+	}
+
+	fingerprint := messagesFingerprint(input.Account, input.MailboxPath, input.MessageIDs)
+	var redeemed *confirmation
+	if input.Permanent && !input.DryRun {
+		if input.ConfirmationToken == "" {
+			return nil, nil, fmt.Errorf("permanent deletion requires the confirmation_token returned by a dry run with permanent=true")
+		}
+		conf, err := deleteConfirmations.redeem(input.ConfirmationToken, fingerprint)
+		if err != nil {
+			return nil, nil, err
+		}
+		redeemed = &conf
+	}
+
+	inputJSON, err := json.Marshal(input)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal input for JXA: %w", err)
+	}
+
+	data, err := executor.Execute(ctx, deleteMessagesScript, string(inputJSON))
+	if err != nil {
+		// The token stays valid to retry, it is only used up by a deletion
+		if redeemed != nil {
+			deleteConfirmations.restore(input.ConfirmationToken, *redeemed)
+		}
+		return nil, nil, fmt.Errorf("failed to execute delete_messages: %w", err)
+	}
+
+	if input.Permanent && input.DryRun {
+		if result, ok := data.(map[string]any); ok {
+			token, expires := deleteConfirmations.issue(fingerprint)
+			result["confirmation_token"] = token
+			result["confirmation_expires_at"] = expires.UTC().Format(time.RFC3339)
+		}
+	}
+
+	return nil, data, nil
+}
@@ -13,13 +13,15 @@ import (
 )
 
 //go:embed scripts/delete_outgoing_message.js
-var deleteOutgoingMessageScript string
+var deleteOutgoingMessageSource string
+
+var deleteOutgoingMessageScript = jxa.Script{Name: "delete_outgoing_message", Source: deleteOutgoingMessageSource}
 
 type DeleteOutgoingMessageInput struct {
 	OutgoingID int `json:"outgoing_id" jsonschema:"The ID of the outgoing message to delete" long:"outgoing-id" description:"The ID of the outgoing message to delete"`
 }
 
-func RegisterDeleteOutgoingMessage(srv *mcp.Server) {
+func RegisterDeleteOutgoingMessage(srv *mcp.Server, executor jxa.Executor) {
 	mcp.AddTool(srv,
 		&mcp.Tool{
 			Name:        "delete_outgoing_message",
@@ -33,11 +35,13 @@ func RegisterDeleteOutgoingMessage(srv *mcp.Server) {
 				OpenWorldHint:   new(true),
 			},
 		},
-		HandleDeleteOutgoingMessage,
+		func(ctx context.Context, request *mcp.CallToolRequest, input DeleteOutgoingMessageInput) (*mcp.CallToolResult, any, error) {
+			return HandleDeleteOutgoingMessage(ctx, executor, request, input)
+		},
 	)
 }
 
-func HandleDeleteOutgoingMessage(ctx context.Context, request *mcp.CallToolRequest, input DeleteOutgoingMessageInput) (*mcp.CallToolResult, any, error) {
+func HandleDeleteOutgoingMessage(ctx context.Context, executor jxa.Executor, request *mcp.CallToolRequest, input DeleteOutgoingMessageInput) (*mcp.CallToolResult, any, error) {
 	// Prepare arguments for JXA
 	inputJSON, err := json.Marshal(input)
 	if err != nil {
@@ -45,7 +49,7 @@ func HandleDeleteOutgoingMessage(ctx context.Context, request *mcp.CallToolReque
 	}
 
 	// Execute JXA
-	data, err := jxa.Execute(ctx, deleteOutgoingMessageScript, string(inputJSON))
+	data, err := executor.Execute(ctx, deleteOutgoingMessageScript, string(inputJSON))
 	if err != nil {
 		return nil, nil, err
 	}
@@ -0,0 +1,64 @@
+package tools
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+
+	"github.com/dastrobu/mail-mcp/internal/jxa"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+//go:embed scripts/delete_rule.js
+var deleteRuleSource string
+
+var deleteRuleScript = jxa.Script{Name: "delete_rule", Source: deleteRuleSource}
+
+// DeleteRuleInput defines input parameters for delete_rule tool
+type DeleteRuleInput struct {
+	Name string `json:"name" jsonschema:"Name of the rule to delete" long:"name" description:"Name of the rule to delete"`
+}
+
+// RegisterDeleteRule registers the delete_rule tool with the MCP server
+func RegisterDeleteRule(srv *mcp.Server, executor jxa.Executor) {
+	mcp.AddTool(srv,
+		&mcp.Tool{
+			Name:        "delete_rule",
+			Description: "Deletes a rule of Apple Mail, identified by its name. Messages filed by the rule are not affected. Returns the deleted rule as list_rules did, so it can be recreated with create_rule if it had no unsupported parts.",
+			InputSchema: GenerateSchema[DeleteRuleInput](),
+			Annotations: &mcp.ToolAnnotations{
+				Title:           "Delete Rule",
+				ReadOnlyHint:    false,
+				IdempotentHint:  false,
+				DestructiveHint: new(true),
+				OpenWorldHint:   new(true),
+			},
+		},
+		func(ctx context.Context, request *mcp.CallToolRequest, input DeleteRuleInput) (*mcp.CallToolResult, any, error) {
+			return HandleDeleteRule(ctx, executor, request, input)
+		},
+	)
+}
+
+func HandleDeleteRule(ctx context.Context, executor jxa.Executor, request *mcp.CallToolRequest, input DeleteRuleInput) (*mcp.CallToolResult, any, error) {
+	if input.Name == "" {
+		return nil, nil, fmt.Errorf("name is required")
+	}
+
+	inputJSON, err := json.Marshal(input)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal input for JXA: %w", err)
+	}
+
+	data, err := executor.Execute(ctx, deleteRuleScript, string(inputJSON))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to execute delete_rule: %w", err)
+	}
+	rule, err := ruleResult(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return nil, map[string]any{"deleted": true, "rule": rule}, nil
+}
@@ -0,0 +1,222 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/dastrobu/mail-mcp/internal/headers"
+	"github.com/dastrobu/mail-mcp/internal/jxa"
+	"github.com/dastrobu/mail-mcp/internal/mbox"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Export formats
+const (
+	ExportFormatEML  = "eml"
+	ExportFormatMbox = "mbox"
+)
+
+// ExportMessagesInput defines input parameters for export_messages tool
+type ExportMessagesInput struct {
+	Account     string   `json:"account" jsonschema:"Name of the email account" long:"account" description:"Name of the email account"`
+	MailboxPath []string `json:"mailboxPath" jsonschema:"Path to the mailbox as an array (e.g. ['Inbox']). Use the mailboxPath field from get_selected_messages or find_messages. Note: Mailbox names are case-sensitive." long:"mailbox-path" description:"Path to the mailbox. Can be specified multiple times for nested paths."`
+	MessageIDs  []int    `json:"message_ids" jsonschema:"IDs of the messages to export (1-100)" long:"message-id" description:"ID of a message to export. Can be specified multiple times."`
+	Format      string   `json:"format,omitempty" jsonschema:"eml (default) writes one .eml file per message, mbox writes all messages into one mbox file" long:"format" description:"eml (default) writes one .eml file per message, mbox writes one mbox file"`
+	Name        string   `json:"name,omitempty" jsonschema:"File name of the mbox file (default: messages.mbox). Ignored for eml." long:"name" description:"File name of the mbox file (default: messages.mbox)"`
+}
+
+// RegisterExportMessages registers the export_messages tool with the MCP server
+func RegisterExportMessages(srv *mcp.Server, executor jxa.Executor, cfg Config) {
+	mcp.AddTool(srv,
+		&mcp.Tool{
+			Name:        "export_messages",
+			Description: "Exports the raw source of messages to the export directory configured for the server, as one .eml file per message or as one mbox file. Returns the paths of the files. Existing files are not overwritten.",
+			InputSchema: GenerateSchema[ExportMessagesInput](),
+			Annotations: &mcp.ToolAnnotations{
+				Title:           "Export Messages",
+				ReadOnlyHint:    false,
+				IdempotentHint:  false,
+				DestructiveHint: new(false),
+				OpenWorldHint:   new(true),
+			},
+		},
+		func(ctx context.Context, request *mcp.CallToolRequest, input ExportMessagesInput) (*mcp.CallToolResult, any, error) {
+			return HandleExportMessages(ctx, executor, cfg, request, input)
+		},
+	)
+}
+
+func HandleExportMessages(ctx context.Context, executor jxa.Executor, cfg Config, request *mcp.CallToolRequest, input ExportMessagesInput) (*mcp.CallToolResult, any, error) {
+	if cfg.ExportDir == "" {
+		return nil, nil, fmt.Errorf("export_messages is disabled: no export directory configured (set --export-dir or APPLE_MAIL_MCP_EXPORT_DIR)")
+	}
+	if len(input.MailboxPath) == 0 {
+		return nil, nil, fmt.Errorf("mailboxPath is required and must be a non-empty array")
+	}
+	if len(input.MessageIDs) == 0 {
+		return nil, nil, fmt.Errorf("message_ids is required and must be a non-empty array")
+	}
+	if len(input.MessageIDs) > 100 {
+		return nil, nil, fmt.Errorf("at most 100 messages can be exported per call")
+	}
+
+	// Apply default format
+	if input.Format == "" {
+		input.Format = ExportFormatEML
+	}
+	if input.Format != ExportFormatEML && input.Format != ExportFormatMbox {
+		return nil, nil, fmt.Errorf("invalid format: %s (must be eml or mbox)", input.Format)
+	}
+	if input.Name == "" {
+		input.Name = "messages.mbox"
+	}
+	if input.Format == ExportFormatMbox && input.Name != filepath.Base(input.Name) {
+		return nil, nil, fmt.Errorf("name must be a file name, not a path")
+	}
+
+	dir, err := filepath.Abs(cfg.ExportDir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid export directory: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, nil, fmt.Errorf("failed to create export directory: %w", err)
+	}
+
+	messages, err := fetchMessageSources(ctx, executor, input.Account, input.MailboxPath, input.MessageIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	var exported []messageSource
+	failed := []map[string]any{}
+	for _, m := range messages {
+		if m.Error != "" {
+			failed = append(failed, map[string]any{"message_id": m.ID, "error": m.Error})
+		} else {
+			exported = append(exported, m)
+		}
+	}
+	if len(exported) == 0 {
+		return nil, nil, fmt.Errorf("none of the messages could be exported: %v", failed)
+	}
+
+	result := map[string]any{
+		"format":         input.Format,
+		"directory":      dir,
+		"exported_count": len(exported),
+		"failed_count":   len(failed),
+		"failed":         failed,
+	}
+	if input.Format == ExportFormatMbox {
+		path, size, err := writeMbox(dir, input.Name, exported)
+		if err != nil {
+			return nil, nil, err
+		}
+		ids := []int{}
+		for _, m := range exported {
+			ids = append(ids, m.ID)
+		}
+		result["path"] = path
+		result["size"] = size
+		result["message_ids"] = ids
+		result["validated"] = true
+		return nil, result, nil
+	}
+
+	files := []map[string]any{}
+	for _, m := range exported {
+		path, err := writeUniqueFile(dir, emlFileName(m), []byte(m.Source))
+		if err != nil {
+			return nil, nil, err
+		}
+		files = append(files, map[string]any{"message_id": m.ID, "path": path, "size": len(m.Source)})
+	}
+	result["files"] = files
+	return nil, result, nil
+}
+
+// writeMbox writes messages into a new mbox file and reads it back to
+// validate that every "From " line was escaped. The file is removed if the
+// validation fails.
+func writeMbox(dir, name string, messages []messageSource) (string, int, error) {
+	var b strings.Builder
+	w := mbox.NewWriter(&b)
+	sources := make([][]byte, 0, len(messages))
+	for _, m := range messages {
+		date, _ := time.Parse(time.RFC3339, m.DateReceived)
+		var sender string
+		if addresses := headers.ParseAddressList(m.Sender); len(addresses) > 0 {
+			sender = addresses[0].Email
+		}
+		if err := w.WriteMessage(sender, date, []byte(m.Source)); err != nil {
+			return "", 0, fmt.Errorf("failed to write mbox: %w", err)
+		}
+		sources = append(sources, []byte(m.Source))
+	}
+
+	path, err := writeUniqueFile(dir, name, []byte(b.String()))
+	if err != nil {
+		return "", 0, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read back mbox: %w", err)
+	}
+	if err := mbox.Validate(data, sources); err != nil {
+		os.Remove(path)
+		return "", 0, fmt.Errorf("exported mbox is invalid: %w", err)
+	}
+	return path, len(data), nil
+}
+
+// emlFileName names the .eml file of a message after its ID and subject.
+func emlFileName(m messageSource) string {
+	subject := strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' || unicode.IsControl(r) {
+			return '_'
+		}
+		return r
+	}, strings.Join(strings.Fields(m.Subject), " "))
+	if runes := []rune(subject); len(runes) > 80 {
+		subject = strings.TrimSpace(string(runes[:80]))
+	}
+	subject = strings.TrimLeft(subject, ".")
+	if subject == "" {
+		return fmt.Sprintf("%d.eml", m.ID)
+	}
+	return fmt.Sprintf("%d %s.eml", m.ID, subject)
+}
+
+// writeUniqueFile writes data to a new file in dir, adding a counter to the
+// name if a file of that name already exists.
+func writeUniqueFile(dir, name string, data []byte) (string, error) {
+	ext := filepath.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	for i := 0; ; i++ {
+		candidate := name
+		if i > 0 {
+			candidate = fmt.Sprintf("%s (%d)%s", stem, i, ext)
+		}
+		path := filepath.Join(dir, candidate)
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err != nil {
+			if os.IsExist(err) {
+				continue
+			}
+			return "", fmt.Errorf("failed to create %s: %w", candidate, err)
+		}
+		if _, err := f.Write(data); err != nil {
+			f.Close()
+			os.Remove(path)
+			return "", fmt.Errorf("failed to write %s: %w", candidate, err)
+		}
+		if err := f.Close(); err != nil {
+			return "", fmt.Errorf("failed to write %s: %w", candidate, err)
+		}
+		return path, nil
+	}
+}
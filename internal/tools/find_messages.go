@@ -5,33 +5,69 @@ import (
 	_ "embed"
 	"encoding/json"
 	"fmt"
+	"unicode/utf8"
 
 	"github.com/dastrobu/mail-mcp/internal/jxa"
+	"github.com/dastrobu/mail-mcp/internal/query"
+	"github.com/dastrobu/mail-mcp/internal/quote"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
 //go:embed scripts/find_messages.js
-var findMessagesScript string
+var findMessagesSource string
+
+var findMessagesScript = jxa.Script{Name: "find_messages", Source: findMessagesSource}
+
+// Mailboxes are mailbox paths, e.g. [["Inbox"], ["Inbox", "GitHub"]].
+type Mailboxes [][]string
+
+// UnmarshalFlag parses a mailbox path given as JSON on the command line, so
+// that several paths can be given.
+func (m *Mailboxes) UnmarshalFlag(value string) error {
+	var path []string
+	if err := json.Unmarshal([]byte(value), &path); err != nil {
+		return fmt.Errorf("invalid mailbox path %s, expected a JSON array such as [\"Inbox\",\"GitHub\"]", value)
+	}
+	*m = append(*m, path)
+	return nil
+}
 
 // FindMessagesInput defines input parameters for find_messages tool
 type FindMessagesInput struct {
-	Account     string   `json:"account" jsonschema:"Name of the email account" long:"account" description:"Name of the email account"`
-	MailboxPath []string `json:"mailboxPath" jsonschema:"Mailbox path array (e.g., ['Inbox'] or ['Inbox', 'GitHub']). Note: Mailbox names are case-sensitive." long:"mailbox-path" description:"Mailbox path (can be specified multiple times for nested mailboxes). Note: Mailbox names are case-sensitive."`
-	Subject     string   `json:"subject,omitempty" jsonschema:"Filter by subject (substring match)" long:"subject" description:"Filter by subject (substring match)"`
-	Sender      string   `json:"sender,omitempty" jsonschema:"Filter by sender email address (substring match)" long:"sender" description:"Filter by sender email address (substring match)"`
-	ReadStatus  *bool    `json:"readStatus,omitempty" jsonschema:"Filter by read status (true for read, false for unread)" long:"read-status" description:"Filter by read status (true for read, false for unread)"`
-	FlaggedOnly bool     `json:"flaggedOnly,omitempty" jsonschema:"Filter for flagged messages only" long:"flagged-only" description:"Filter for flagged messages only"`
-	DateAfter   string   `json:"dateAfter,omitempty" jsonschema:"Filter for messages received after this ISO date (e.g., '2024-01-01T00:00:00Z')" long:"date-after" description:"Filter for messages received after this ISO date (e.g., '2024-01-01T00:00:00Z')"`
-	DateBefore  string   `json:"dateBefore,omitempty" jsonschema:"Filter for messages received before this ISO date (e.g., '2024-12-31T23:59:59Z')" long:"date-before" description:"Filter for messages received before this ISO date (e.g., '2024-12-31T23:59:59Z')"`
-	Limit       int      `json:"limit,omitempty" jsonschema:"Maximum number of messages to return (1-1000, default: 50)" long:"limit" description:"Maximum number of messages to return (1-1000, default: 50)"`
+	Account        string    `json:"account,omitempty" jsonschema:"Name of the email account. Ignored if accounts is given." long:"account" description:"Name of the email account"`
+	MailboxPath    []string  `json:"mailboxPath,omitempty" jsonschema:"Mailbox path array (e.g., ['Inbox'] or ['Inbox', 'GitHub']). ['*'] searches all mailboxes. Ignored if mailboxPaths is given. Note: Mailbox names are case-sensitive." long:"mailbox-path" description:"Mailbox path (can be specified multiple times for nested mailboxes). '*' searches all mailboxes. Note: Mailbox names are case-sensitive."`
+	Accounts       []string  `json:"accounts,omitempty" jsonschema:"Names of the accounts to search, or ['*'] for all enabled accounts" long:"accounts" description:"Name of an account to search. Can be specified multiple times. '*' searches all enabled accounts."`
+	MailboxPaths   Mailboxes `json:"mailboxPaths,omitempty" jsonschema:"Mailbox paths to search in each account (e.g., [['Inbox'], ['Archive']]). [['*']] searches all mailboxes." long:"mailbox-paths" description:"Mailbox path to search in each account as a JSON array, e.g. [\"Inbox\",\"GitHub\"]. Can be specified multiple times. [\"*\"] searches all mailboxes."`
+	Recursive      bool      `json:"recursive,omitempty" jsonschema:"Also search the sub-mailboxes of the given mailboxes" long:"recursive" description:"Also search the sub-mailboxes of the given mailboxes"`
+	Subject        string    `json:"subject,omitempty" jsonschema:"Filter by subject (substring match)" long:"subject" description:"Filter by subject (substring match)"`
+	Sender         string    `json:"sender,omitempty" jsonschema:"Filter by sender email address (substring match)" long:"sender" description:"Filter by sender email address (substring match)"`
+	ReadStatus     *bool     `json:"readStatus,omitempty" jsonschema:"Filter by read status (true for read, false for unread)" long:"read-status" description:"Filter by read status (true for read, false for unread)"`
+	FlaggedOnly    bool      `json:"flaggedOnly,omitempty" jsonschema:"Filter for flagged messages only" long:"flagged-only" description:"Filter for flagged messages only"`
+	DateAfter      string    `json:"dateAfter,omitempty" jsonschema:"Filter for messages received after this ISO date (e.g., '2024-01-01T00:00:00Z')" long:"date-after" description:"Filter for messages received after this ISO date (e.g., '2024-01-01T00:00:00Z')"`
+	DateBefore     string    `json:"dateBefore,omitempty" jsonschema:"Filter for messages received before this ISO date (e.g., '2024-12-31T23:59:59Z')" long:"date-before" description:"Filter for messages received before this ISO date (e.g., '2024-12-31T23:59:59Z')"`
+	To             string    `json:"to,omitempty" jsonschema:"Filter by To recipient name or address (substring match)" long:"to" description:"Filter by To recipient name or address (substring match)"`
+	Cc             string    `json:"cc,omitempty" jsonschema:"Filter by Cc recipient name or address (substring match)" long:"cc" description:"Filter by Cc recipient name or address (substring match)"`
+	Body           string    `json:"body,omitempty" jsonschema:"Filter by message body (substring match). Slow on large mailboxes, combine with other filters." long:"body" description:"Filter by message body (substring match)"`
+	HasAttachment  *bool     `json:"hasAttachment,omitempty" jsonschema:"Filter for messages with (true) or without (false) attachments" long:"has-attachment" description:"Filter for messages with (true) or without (false) attachments"`
+	MinSize        *int      `json:"minSize,omitempty" jsonschema:"Filter for messages of at least this size in bytes" long:"min-size" description:"Filter for messages of at least this size in bytes"`
+	MaxSize        *int      `json:"maxSize,omitempty" jsonschema:"Filter for messages of at most this size in bytes" long:"max-size" description:"Filter for messages of at most this size in bytes"`
+	FlagIndex      *int      `json:"flagIndex,omitempty" jsonschema:"Filter by flag color: 0 red, 1 orange, 2 yellow, 3 green, 4 blue, 5 purple, 6 gray" long:"flag-index" description:"Filter by flag color: 0 red, 1 orange, 2 yellow, 3 green, 4 blue, 5 purple, 6 gray"`
+	WasRepliedTo   *bool     `json:"wasRepliedTo,omitempty" jsonschema:"Filter by replied status" long:"was-replied-to" description:"Filter by replied status"`
+	WasForwarded   *bool     `json:"wasForwarded,omitempty" jsonschema:"Filter by forwarded status" long:"was-forwarded" description:"Filter by forwarded status"`
+	JunkMailStatus *bool     `json:"junkMailStatus,omitempty" jsonschema:"Filter by junk status" long:"junk-mail-status" description:"Filter by junk status"`
+	Query          string    `json:"query,omitempty" jsonschema:"Query expression combining predicates with AND, OR, NOT and parentheses, e.g. 'from:alice (subject:invoice OR has:attachment) NOT is:read'. Predicates: subject:, from:, to:, cc:, body:, is:read|unread|flagged|unflagged|replied|forwarded|junk, has:attachment, flag:red|orange|yellow|green|blue|purple|gray, size>N, size<N (units K, M, G), after:DATE, before:DATE. Quote values with spaces. Combined with the other filters using AND." long:"query" description:"Query expression, e.g. 'from:alice (subject:invoice OR has:attachment) NOT is:read'"`
+	BodyFormat     string    `json:"body_format,omitempty" jsonschema:"Also return the full body of each message as content, in this format: plain (the text Mail.app shows), html (the HTML part of the message source) or markdown (the HTML part converted to Markdown). Messages without an HTML part are returned as plain text, see body_format in each message. Omit to return previews only." long:"body-format" description:"Also return the full body of each message in this format: plain, html or markdown"`
+	StripQuotes    bool      `json:"strip_quotes,omitempty" jsonschema:"Remove quoted history and the trailing signature before the content preview is taken, and from content if body_format is given. content_length is the length after stripping, stripped_chars the number of characters removed. Not supported with body_format html." long:"strip-quotes" description:"Remove quoted history and the trailing signature from the content preview"`
+	Limit          int       `json:"limit,omitempty" jsonschema:"Maximum number of messages to return (1-1000, default: 50)" long:"limit" description:"Maximum number of messages to return (1-1000, default: 50)"`
+	Cursor         string    `json:"cursor,omitempty" jsonschema:"Cursor from the next_cursor of a previous call to fetch the next page. The other parameters except limit and body_format must be unchanged." long:"cursor" description:"Cursor from the next_cursor of a previous call to fetch the next page"`
 }
 
 // RegisterFindMessages registers the find_messages tool with the MCP server
-func RegisterFindMessages(srv *mcp.Server) {
+func RegisterFindMessages(srv *mcp.Server, executor jxa.Executor) {
 	mcp.AddTool(srv,
 		&mcp.Tool{
 			Name:        "find_messages",
-			Description: "Find messages in a mailbox. At least one filter criterion must be specified.",
+			Description: "Find messages in one or more mailboxes, possibly across accounts. Results are sorted by date received, newest first, and tagged with their account and mailbox_path. Pass next_cursor as cursor to fetch the next page. At least one filter criterion must be specified.",
 			InputSchema: GenerateSchema[FindMessagesInput](),
 			Annotations: &mcp.ToolAnnotations{
 				Title:           "Find Messages",
@@ -41,11 +77,13 @@ func RegisterFindMessages(srv *mcp.Server) {
 				OpenWorldHint:   new(true),
 			},
 		},
-		HandleFindMessages,
+		func(ctx context.Context, request *mcp.CallToolRequest, input FindMessagesInput) (*mcp.CallToolResult, any, error) {
+			return HandleFindMessages(ctx, executor, request, input)
+		},
 	)
 }
 
-func HandleFindMessages(ctx context.Context, request *mcp.CallToolRequest, input FindMessagesInput) (*mcp.CallToolResult, any, error) {
+func HandleFindMessages(ctx context.Context, executor jxa.Executor, request *mcp.CallToolRequest, input FindMessagesInput) (*mcp.CallToolResult, any, error) {
 	// Apply default limit
 	if input.Limit == 0 {
 		input.Limit = 50
@@ -56,33 +94,170 @@ func HandleFindMessages(ctx context.Context, request *mcp.CallToolRequest, input
 		return nil, nil, fmt.Errorf("limit must be between 1 and 1000")
 	}
 
-	// Validate mailbox path
-	if len(input.MailboxPath) == 0 {
-		return nil, nil, fmt.Errorf("mailboxPath is required")
+	// Validate the search scope
+	if input.Account == "" && len(input.Accounts) == 0 {
+		return nil, nil, fmt.Errorf("account or accounts is required")
+	}
+	if len(input.MailboxPath) == 0 && len(input.MailboxPaths) == 0 {
+		return nil, nil, fmt.Errorf("mailboxPath or mailboxPaths is required")
+	}
+	for _, path := range input.MailboxPaths {
+		if len(path) == 0 {
+			return nil, nil, fmt.Errorf("mailboxPaths must not contain empty paths")
+		}
 	}
 
-	// Require at least one filter criterion
-	hasFilter := input.Subject != "" ||
-		input.Sender != "" ||
-		input.ReadStatus != nil ||
-		input.FlaggedOnly ||
-		input.DateAfter != "" ||
-		input.DateBefore != ""
+	bodyFormat, err := normalizeBodyFormat(input.BodyFormat)
+	if err != nil {
+		return nil, nil, err
+	}
+	input.BodyFormat = bodyFormat
+	if err := validateStripQuotes(input.StripQuotes, input.BodyFormat); err != nil {
+		return nil, nil, err
+	}
+
+	// Build the filter the script evaluates: all given criteria combined
+	// with AND. The query is parsed here so malformed queries fail before
+	// Mail.app is involved.
+	filter, err := findMessagesFilter(input)
+	if err != nil {
+		return nil, nil, err
+	}
+	if filter == nil {
+		return nil, nil, fmt.Errorf("at least one filter criterion is required (subject, sender, to, cc, body, readStatus, flaggedOnly, flagIndex, hasAttachment, minSize, maxSize, wasRepliedTo, wasForwarded, junkMailStatus, dateAfter, dateBefore, or query)")
+	}
 
-	if !hasFilter {
-		return nil, nil, fmt.Errorf("at least one filter criterion is required (subject, sender, readStatus, flaggedOnly, dateAfter, or dateBefore)")
+	// Resolve the cursor against the filters without the page parameters
+	filters := input
+	filters.Cursor, filters.Limit, filters.BodyFormat, filters.StripQuotes = "", 0, "", false
+	hash, err := filterHash(filters)
+	if err != nil {
+		return nil, nil, err
+	}
+	offset, err := decodeCursor(input.Cursor, hash)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	// Marshal input to JSON
-	inputJSON, err := json.Marshal(input)
+	inputJSON, err := json.Marshal(struct {
+		FindMessagesInput
+		Filter *query.Node `json:"filter"`
+		Offset int         `json:"offset"`
+	}{input, filter, offset})
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to marshal input for JXA: %w", err)
 	}
 
-	data, err := jxa.Execute(ctx, findMessagesScript, string(inputJSON))
+	data, err := executor.Execute(ctx, findMessagesScript, string(inputJSON))
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to execute find_messages: %w", err)
 	}
+	setNextCursor(data, hash)
+	if input.StripQuotes {
+		stripPreviews(data, input.BodyFormat != "")
+	}
+	if input.BodyFormat != "" {
+		applyBodyFormats(data, "messages", input.BodyFormat, "content", "body_format")
+	}
+	if input.StripQuotes && input.BodyFormat != "" {
+		forEachMessage(data, "messages", func(message map[string]any) {
+			stripContent(message, "content")
+		})
+	}
 
 	return nil, data, nil
 }
+
+// stripPreviews takes the content preview and length of each message from
+// the full content, the script returns it for strip_quotes, with quotes and
+// signature removed. The content itself is only kept if keepContent is set.
+func stripPreviews(data any, keepContent bool) {
+	forEachMessage(data, "messages", func(message map[string]any) {
+		content, _ := message["content"].(string)
+		result := quote.Strip(content)
+		message["content_preview"] = contentPreview(result.Text)
+		message["content_length"] = utf8.RuneCountInString(result.Text)
+		message["stripped_chars"] = result.Removed()
+		if !keepContent {
+			delete(message, "content")
+		}
+	})
+}
+
+// findMessagesFilter combines the filter criteria of input into a single
+// query tree. It returns nil if no criteria are given.
+func findMessagesFilter(input FindMessagesInput) (*query.Node, error) {
+	var nodes []*query.Node
+	for _, text := range []struct{ field, value string }{
+		{query.FieldSubject, input.Subject},
+		{query.FieldSender, input.Sender},
+		{query.FieldTo, input.To},
+		{query.FieldCc, input.Cc},
+		{query.FieldContent, input.Body},
+	} {
+		if text.value != "" {
+			nodes = append(nodes, query.Contains(text.field, text.value))
+		}
+	}
+	for _, status := range []struct {
+		field string
+		value *bool
+	}{
+		{query.FieldReadStatus, input.ReadStatus},
+		{query.FieldHasAttachment, input.HasAttachment},
+		{query.FieldWasRepliedTo, input.WasRepliedTo},
+		{query.FieldWasForwarded, input.WasForwarded},
+		{query.FieldJunkMailStatus, input.JunkMailStatus},
+	} {
+		if status.value != nil {
+			nodes = append(nodes, query.Is(status.field, *status.value))
+		}
+	}
+	if input.FlaggedOnly {
+		nodes = append(nodes, query.Is(query.FieldFlaggedStatus, true))
+	}
+	if input.FlagIndex != nil {
+		if *input.FlagIndex < 0 || *input.FlagIndex > 6 {
+			return nil, fmt.Errorf("flagIndex must be between 0 and 6")
+		}
+		nodes = append(nodes, query.Compare(query.OpEq, query.FieldFlagIndex, int64(*input.FlagIndex)))
+	}
+	if input.MinSize != nil {
+		if *input.MinSize < 0 {
+			return nil, fmt.Errorf("minSize must not be negative")
+		}
+		nodes = append(nodes, query.Compare(query.OpGe, query.FieldMessageSize, int64(*input.MinSize)))
+	}
+	if input.MaxSize != nil {
+		if *input.MaxSize < 0 {
+			return nil, fmt.Errorf("maxSize must not be negative")
+		}
+		if input.MinSize != nil && *input.MinSize > *input.MaxSize {
+			return nil, fmt.Errorf("minSize must not be greater than maxSize")
+		}
+		nodes = append(nodes, query.Compare(query.OpLe, query.FieldMessageSize, int64(*input.MaxSize)))
+	}
+	if input.DateAfter != "" {
+		t, err := query.ParseDate(input.DateAfter)
+		if err != nil {
+			return nil, fmt.Errorf("dateAfter: %w", err)
+		}
+		nodes = append(nodes, query.After(t))
+	}
+	if input.DateBefore != "" {
+		t, err := query.ParseDate(input.DateBefore)
+		if err != nil {
+			return nil, fmt.Errorf("dateBefore: %w", err)
+		}
+		nodes = append(nodes, query.Before(t))
+	}
+	if input.Query != "" {
+		n, err := query.Parse(input.Query)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, n)
+	}
+	return query.And(nodes...), nil
+}
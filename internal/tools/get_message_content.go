@@ -5,27 +5,34 @@ import (
 	_ "embed"
 	"encoding/json"
 	"fmt"
+	"strings"
 
+	"github.com/dastrobu/mail-mcp/internal/headers"
 	"github.com/dastrobu/mail-mcp/internal/jxa"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
 //go:embed scripts/get_message_content.js
-var getMessageContentScript string
+var getMessageContentSource string
+
+var getMessageContentScript = jxa.Script{Name: "get_message_content", Source: getMessageContentSource}
 
 // GetMessageContentInput defines input parameters for get_message_content tool
 type GetMessageContentInput struct {
 	Account     string   `json:"account" jsonschema:"Name of the email account" long:"account" description:"Name of the email account"`
 	MailboxPath []string `json:"mailboxPath" jsonschema:"Path to the mailbox as an array (e.g. ['Inbox'] for top-level or ['Inbox','GitHub'] for nested mailbox). Use the mailboxPath field from get_selected_messages. Note: Mailbox names are case-sensitive." long:"mailbox-path" description:"Path to the mailbox. Can be specified multiple times for nested paths."`
 	MessageID   int      `json:"message_id" jsonschema:"The unique ID of the message to retrieve" long:"message-id" description:"The unique ID of the message to retrieve"`
+	RawHeaders  bool     `json:"raw_headers,omitempty" jsonschema:"Also return the raw header text as allHeaders. The parsed headers are always returned." long:"raw-headers" description:"Also return the raw header text as allHeaders"`
+	BodyFormat  string   `json:"body_format,omitempty" jsonschema:"Format of the returned content: plain (default, the text Mail.app shows), html (the HTML part of the message source) or markdown (the HTML part converted to Markdown, keeping links and tables). Messages without an HTML part are returned as plain text, see bodyFormat in the result." long:"body-format" description:"Format of the returned content: plain, html or markdown (default: plain)"`
+	StripQuotes bool     `json:"strip_quotes,omitempty" jsonschema:"Remove quoted history (On ... wrote: blocks, > prefixed lines, Outlook From:/Sent: blocks) and the trailing signature from content. strippedChars reports the number of characters removed. Not supported with body_format html." long:"strip-quotes" description:"Remove quoted history and the trailing signature from the content"`
 }
 
 // RegisterGetMessageContent registers the get_message_content tool with the MCP server
-func RegisterGetMessageContent(srv *mcp.Server) {
+func RegisterGetMessageContent(srv *mcp.Server, executor jxa.Executor) {
 	mcp.AddTool(srv,
 		&mcp.Tool{
 			Name:        "get_message_content",
-			Description: "Retrieves the full content (body) of a specific message by its ID from a specific account and mailbox. Supports nested mailboxes via mailboxPath array. IMPORTANT: Use the mailboxPath field from get_selected_messages output, not the mailbox field.",
+			Description: "Retrieves the full content (body) of a specific message by its ID from a specific account and mailbox. Supports nested mailboxes via mailboxPath array. Headers are returned parsed: a headers map with decoded values plus from, to, cc, inReplyTo, references, listId and listUnsubscribe. IMPORTANT: Use the mailboxPath field from get_selected_messages output, not the mailbox field.",
 			InputSchema: GenerateSchema[GetMessageContentInput](),
 			Annotations: &mcp.ToolAnnotations{
 				Title:           "Get Message Content",
@@ -35,16 +42,30 @@ func RegisterGetMessageContent(srv *mcp.Server) {
 				OpenWorldHint:   new(true),
 			},
 		},
-		HandleGetMessageContent,
+		func(ctx context.Context, request *mcp.CallToolRequest, input GetMessageContentInput) (*mcp.CallToolResult, any, error) {
+			return HandleGetMessageContent(ctx, executor, request, input)
+		},
 	)
 }
 
-func HandleGetMessageContent(ctx context.Context, request *mcp.CallToolRequest, input GetMessageContentInput) (*mcp.CallToolResult, any, error) {
+func HandleGetMessageContent(ctx context.Context, executor jxa.Executor, request *mcp.CallToolRequest, input GetMessageContentInput) (*mcp.CallToolResult, any, error) {
 	// Validate mailboxPath
 	if len(input.MailboxPath) == 0 {
 		return nil, nil, fmt.Errorf("mailboxPath is required and must be a non-empty array")
 	}
 
+	bodyFormat, err := normalizeBodyFormat(input.BodyFormat)
+	if err != nil {
+		return nil, nil, err
+	}
+	if bodyFormat == "" {
+		bodyFormat = BodyFormatPlain
+	}
+	input.BodyFormat = bodyFormat
+	if err := validateStripQuotes(input.StripQuotes, input.BodyFormat); err != nil {
+		return nil, nil, err
+	}
+
 	// Marshal input to JSON
 	inputJSON, err := json.Marshal(input)
 	if err != nil {
@@ -52,10 +73,59 @@ func HandleGetMessageContent(ctx context.Context, request *mcp.CallToolRequest,
 	}
 
 	// Execute JXA script with input as JSON string
-	data, err := jxa.Execute(ctx, getMessageContentScript, string(inputJSON))
+	data, err := executor.Execute(ctx, getMessageContentScript, string(inputJSON))
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to execute get_message_content: %w", err)
 	}
 
+	if result, ok := data.(map[string]any); ok {
+		if message, ok := result["message"].(map[string]any); ok {
+			structureHeaders(message, input.RawHeaders)
+			applyBodyFormat(message, input.BodyFormat, "content", "bodyFormat")
+			if input.StripQuotes {
+				message["strippedChars"] = stripContent(message, "content")
+			}
+		}
+	}
+
 	return nil, data, nil
 }
+
+// structureHeaders parses the raw allHeaders of a message into a headers map,
+// keyed by canonical field name, and typed fields for the fields agents need
+// most. The raw text is only kept if keepRaw is set.
+func structureHeaders(message map[string]any, keepRaw bool) {
+	raw, _ := message["allHeaders"].(string)
+	h := headers.Parse(raw)
+
+	message["headers"] = h.Map()
+	message["from"] = h.Addresses("From")
+	message["to"] = h.Addresses("To")
+	message["cc"] = h.Addresses("Cc")
+	message["inReplyTo"] = nil
+	if ids := headers.MessageIDs(h.Get("In-Reply-To")); len(ids) > 0 {
+		message["inReplyTo"] = ids[0]
+	}
+	message["references"] = nonNilStrings(headers.MessageIDs(strings.Join(h.Values("References"), " ")))
+	message["listId"] = nil
+	if listID := h.Get("List-Id"); listID != "" {
+		if ids := headers.AngleList(listID); len(ids) > 0 {
+			message["listId"] = ids[0]
+		} else {
+			message["listId"] = listID
+		}
+	}
+	message["listUnsubscribe"] = nonNilStrings(headers.AngleList(h.Get("List-Unsubscribe")))
+
+	if !keepRaw {
+		delete(message, "allHeaders")
+	}
+}
+
+// nonNilStrings returns s or an empty slice, so it marshals as [].
+func nonNilStrings(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
@@ -0,0 +1,115 @@
+package tools
+
+import (
+	"context"
+	_ "embed"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/dastrobu/mail-mcp/internal/jxa"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+//go:embed scripts/get_message_source.js
+var getMessageSourceSource string
+
+var getMessageSourceScript = jxa.Script{Name: "get_message_source", Source: getMessageSourceSource}
+
+// GetMessageSourceInput defines input parameters for get_message_source tool
+type GetMessageSourceInput struct {
+	Account     string   `json:"account" jsonschema:"Name of the email account" long:"account" description:"Name of the email account"`
+	MailboxPath []string `json:"mailboxPath" jsonschema:"Path to the mailbox as an array (e.g. ['Inbox']). Use the mailboxPath field from get_selected_messages or find_messages. Note: Mailbox names are case-sensitive." long:"mailbox-path" description:"Path to the mailbox. Can be specified multiple times for nested paths."`
+	MessageID   int      `json:"message_id" jsonschema:"The unique ID of the message" long:"message-id" description:"The unique ID of the message"`
+	Base64      bool     `json:"base64,omitempty" jsonschema:"Return the source base64-encoded, e.g. to pass it on unchanged" long:"base64" description:"Return the source base64-encoded"`
+}
+
+// RegisterGetMessageSource registers the get_message_source tool with the MCP server
+func RegisterGetMessageSource(srv *mcp.Server, executor jxa.Executor) {
+	mcp.AddTool(srv,
+		&mcp.Tool{
+			Name:        "get_message_source",
+			Description: "Returns the raw RFC 822 source of a message, headers and MIME body as stored by Mail.app, optionally base64-encoded. Use it to archive messages or pass them on to other systems.",
+			InputSchema: GenerateSchema[GetMessageSourceInput](),
+			Annotations: &mcp.ToolAnnotations{
+				Title:           "Get Message Source",
+				ReadOnlyHint:    true,
+				IdempotentHint:  true,
+				DestructiveHint: new(false),
+				OpenWorldHint:   new(true),
+			},
+		},
+		func(ctx context.Context, request *mcp.CallToolRequest, input GetMessageSourceInput) (*mcp.CallToolResult, any, error) {
+			return HandleGetMessageSource(ctx, executor, request, input)
+		},
+	)
+}
+
+// messageSource is a message as reported by the get_message_source script.
+type messageSource struct {
+	ID           int    `json:"id"`
+	Subject      string `json:"subject"`
+	Sender       string `json:"sender"`
+	DateReceived string `json:"dateReceived"`
+	Source       string `json:"source"`
+	Error        string `json:"error"`
+}
+
+func HandleGetMessageSource(ctx context.Context, executor jxa.Executor, request *mcp.CallToolRequest, input GetMessageSourceInput) (*mcp.CallToolResult, any, error) {
+	if len(input.MailboxPath) == 0 {
+		return nil, nil, fmt.Errorf("mailboxPath is required and must be a non-empty array")
+	}
+	if input.MessageID < 1 {
+		return nil, nil, fmt.Errorf("message_id is required and must be a positive integer")
+	}
+
+	messages, err := fetchMessageSources(ctx, executor, input.Account, input.MailboxPath, []int{input.MessageID})
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(messages) != 1 {
+		return nil, nil, fmt.Errorf("unexpected result from get_message_source: %d messages", len(messages))
+	}
+	m := messages[0]
+	if m.Error != "" {
+		return nil, nil, fmt.Errorf("%s", m.Error)
+	}
+
+	encoding, source := "text", m.Source
+	if input.Base64 {
+		encoding, source = "base64", base64.StdEncoding.EncodeToString([]byte(m.Source))
+	}
+	return nil, map[string]any{
+		"message_id":    m.ID,
+		"subject":       m.Subject,
+		"sender":        m.Sender,
+		"date_received": m.DateReceived,
+		"size":          len(m.Source),
+		"encoding":      encoding,
+		"source":        source,
+	}, nil
+}
+
+// fetchMessageSources reads the sources of messages of a mailbox. Messages
+// that could not be read carry an error.
+func fetchMessageSources(ctx context.Context, executor jxa.Executor, account string, mailboxPath []string, ids []int) ([]messageSource, error) {
+	inputJSON, err := json.Marshal(map[string]any{
+		"account":     account,
+		"mailboxPath": mailboxPath,
+		"message_ids": ids,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal input for JXA: %w", err)
+	}
+	data, err := executor.Execute(ctx, getMessageSourceScript, string(inputJSON))
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute get_message_source: %w", err)
+	}
+	var result struct {
+		Messages []messageSource `json:"messages"`
+	}
+	if err := remarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse get_message_source result: %w", err)
+	}
+	return result.Messages, nil
+}
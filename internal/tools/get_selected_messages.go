@@ -11,15 +11,18 @@ import (
 )
 
 //go:embed scripts/get_selected_messages.js
-var getSelectedMessagesScript string
+var getSelectedMessagesSource string
+
+var getSelectedMessagesScript = jxa.Script{Name: "get_selected_messages", Source: getSelectedMessagesSource}
 
 // GetSelectedMessagesInput defines input parameters for get_selected_messages tool
 type GetSelectedMessagesInput struct {
-	Limit int `json:"limit,omitempty" jsonschema:"Maximum number of messages to return (1-100, default 5)" long:"limit" description:"Maximum number of messages to return (1-100, default 5)"`
+	Limit      int    `json:"limit,omitempty" jsonschema:"Maximum number of messages to return (1-100, default 5)" long:"limit" description:"Maximum number of messages to return (1-100, default 5)"`
+	BodyFormat string `json:"body_format,omitempty" jsonschema:"Also return the full body of each message as content, in this format: plain (the text Mail.app shows), html (the HTML part of the message source) or markdown (the HTML part converted to Markdown). Messages without an HTML part are returned as plain text, see bodyFormat in each message." long:"body-format" description:"Also return the full body of each message in this format: plain, html or markdown"`
 }
 
 // RegisterGetSelectedMessages registers the get_selected_messages tool with the MCP server
-func RegisterGetSelectedMessages(srv *mcp.Server) {
+func RegisterGetSelectedMessages(srv *mcp.Server, executor jxa.Executor) {
 	mcp.AddTool(srv,
 		&mcp.Tool{
 			Name:        "get_selected_messages",
@@ -33,25 +36,36 @@ func RegisterGetSelectedMessages(srv *mcp.Server) {
 				OpenWorldHint:   new(true),
 			},
 		},
-		HandleGetSelectedMessages,
+		func(ctx context.Context, request *mcp.CallToolRequest, input GetSelectedMessagesInput) (*mcp.CallToolResult, any, error) {
+			return HandleGetSelectedMessages(ctx, executor, request, input)
+		},
 	)
 }
 
-func HandleGetSelectedMessages(ctx context.Context, request *mcp.CallToolRequest, input GetSelectedMessagesInput) (*mcp.CallToolResult, any, error) {
+func HandleGetSelectedMessages(ctx context.Context, executor jxa.Executor, request *mcp.CallToolRequest, input GetSelectedMessagesInput) (*mcp.CallToolResult, any, error) {
 	// Apply default for limit if not specified
 	if input.Limit == 0 {
 		input.Limit = 5 // default
 	}
 
+	bodyFormat, err := normalizeBodyFormat(input.BodyFormat)
+	if err != nil {
+		return nil, nil, err
+	}
+	input.BodyFormat = bodyFormat
+
 	inputJSON, err := json.Marshal(input)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to marshal input for JXA: %w", err)
 	}
 
-	data, err := jxa.Execute(ctx, getSelectedMessagesScript, string(inputJSON))
+	data, err := executor.Execute(ctx, getSelectedMessagesScript, string(inputJSON))
 	if err != nil {
 		return nil, nil, err
 	}
+	if input.BodyFormat != "" {
+		applyBodyFormats(data, "messages", input.BodyFormat, "content", "bodyFormat")
+	}
 
 	return nil, data, nil
 }
@@ -0,0 +1,242 @@
+package tools
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/dastrobu/mail-mcp/internal/headers"
+	"github.com/dastrobu/mail-mcp/internal/jxa"
+	"github.com/dastrobu/mail-mcp/internal/thread"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+//go:embed scripts/get_thread.js
+var getThreadSource string
+
+var getThreadScript = jxa.Script{Name: "get_thread", Source: getThreadSource}
+
+// GetThreadInput defines input parameters for get_thread tool
+type GetThreadInput struct {
+	Account      string    `json:"account" jsonschema:"Name of the email account" long:"account" description:"Name of the email account"`
+	MailboxPath  []string  `json:"mailboxPath" jsonschema:"Path to the mailbox of the message as an array (e.g. ['Inbox']). Note: Mailbox names are case-sensitive." long:"mailbox-path" description:"Path to the mailbox of the message. Can be specified multiple times for nested paths."`
+	MessageID    int       `json:"message_id" jsonschema:"The ID of a message of the thread" long:"message-id" description:"The ID of a message of the thread"`
+	MailboxPaths Mailboxes `json:"mailboxPaths,omitempty" jsonschema:"Mailboxes of the account to search for the other messages of the thread. Default: the Inbox and its sub-mailboxes, the Sent mailbox and all mailboxes with Archive in their path." long:"mailbox-paths" description:"Mailbox of the account to search for the other messages of the thread as a JSON array, e.g. [\"Archive\"]. Can be specified multiple times."`
+	Limit        int       `json:"limit,omitempty" jsonschema:"Maximum number of candidate messages to examine (1-500, default: 100)" long:"limit" description:"Maximum number of candidate messages to examine (1-500, default: 100)"`
+}
+
+// RegisterGetThread registers the get_thread tool with the MCP server
+func RegisterGetThread(srv *mcp.Server, executor jxa.Executor) {
+	mcp.AddTool(srv,
+		&mcp.Tool{
+			Name:        "get_thread",
+			Description: "Reconstructs the conversation a message belongs to. Collects related messages from the Inbox, Sent and archive mailboxes of the account, links them through the Message-ID, In-Reply-To and References headers, falling back to the normalized subject, and returns them as a tree of replies ordered by date.",
+			InputSchema: GenerateSchema[GetThreadInput](),
+			Annotations: &mcp.ToolAnnotations{
+				Title:           "Get Thread",
+				ReadOnlyHint:    true,
+				IdempotentHint:  true,
+				DestructiveHint: new(false),
+				OpenWorldHint:   new(true),
+			},
+		},
+		func(ctx context.Context, request *mcp.CallToolRequest, input GetThreadInput) (*mcp.CallToolResult, any, error) {
+			return HandleGetThread(ctx, executor, request, input)
+		},
+	)
+}
+
+// threadMessage is a message as reported by the get_thread script.
+type threadMessage struct {
+	ID           int      `json:"id"`
+	Account      string   `json:"account"`
+	MailboxPath  []string `json:"mailbox_path"`
+	Subject      string   `json:"subject"`
+	Sender       string   `json:"sender"`
+	DateReceived string   `json:"date_received"`
+	MessageID    string   `json:"message_id"`
+	AllHeaders   string   `json:"all_headers"`
+
+	isTarget bool // the message get_thread was called with
+}
+
+// threadNode is a message of the thread in the tool's result.
+type threadNode struct {
+	ID           int           `json:"id"`
+	Account      string        `json:"account"`
+	MailboxPath  []string      `json:"mailbox_path"`
+	Subject      string        `json:"subject"`
+	Sender       string        `json:"sender"`
+	DateReceived string        `json:"date_received"`
+	MessageID    string        `json:"message_id"`
+	InReplyTo    string        `json:"in_reply_to,omitempty"`
+	LinkedBy     string        `json:"linked_by,omitempty"`
+	IsTarget     bool          `json:"is_target,omitempty"`
+	Replies      []*threadNode `json:"replies"`
+}
+
+func HandleGetThread(ctx context.Context, executor jxa.Executor, request *mcp.CallToolRequest, input GetThreadInput) (*mcp.CallToolResult, any, error) {
+	// Validate mailboxPath
+	if len(input.MailboxPath) == 0 {
+		return nil, nil, fmt.Errorf("mailboxPath is required and must be a non-empty array")
+	}
+	if input.MessageID < 1 {
+		return nil, nil, fmt.Errorf("message_id is required and must be a positive integer")
+	}
+	for _, path := range input.MailboxPaths {
+		if len(path) == 0 {
+			return nil, nil, fmt.Errorf("mailboxPaths must not contain empty paths")
+		}
+	}
+
+	// Apply default limit
+	if input.Limit == 0 {
+		input.Limit = 100
+	}
+	if input.Limit < 1 || input.Limit > 500 {
+		return nil, nil, fmt.Errorf("limit must be between 1 and 500")
+	}
+
+	// Read the headers of the message to learn the message IDs and subject
+	// of the thread
+	target, err := fetchThreadMessage(ctx, executor, input)
+	if err != nil {
+		return nil, nil, err
+	}
+	links := threadMessageOf(target)
+	ids := slices.Concat([]string{links.MessageID}, links.References, links.InReplyTo)
+	ids = slices.DeleteFunc(ids, func(id string) bool { return id == "" })
+
+	inputJSON, err := json.Marshal(map[string]any{
+		"account":      input.Account,
+		"mailboxPath":  input.MailboxPath,
+		"message_ids":  ids,
+		"subject":      thread.NormalizeSubject(target.Subject),
+		"mailboxPaths": input.MailboxPaths,
+		"limit":        input.Limit,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal input for JXA: %w", err)
+	}
+
+	data, err := executor.Execute(ctx, getThreadScript, string(inputJSON))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to execute get_thread: %w", err)
+	}
+	var result struct {
+		Candidates        []threadMessage `json:"candidates"`
+		MailboxesSearched [][]string      `json:"mailboxes_searched"`
+		Truncated         bool            `json:"truncated"`
+	}
+	if err := remarshal(data, &result); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse get_thread result: %w", err)
+	}
+
+	// The message itself comes first, so it wins over copies of it in other
+	// mailboxes
+	messages := []thread.Message{links}
+	for _, c := range result.Candidates {
+		messages = append(messages, threadMessageOf(c))
+	}
+
+	// Only the tree containing the message is the thread, candidates
+	// selected by a similar subject may form trees of their own
+	var tree []*thread.Node
+	for _, r := range thread.Build(messages) {
+		thread.Walk([]*thread.Node{r}, func(n *thread.Node, _ int) {
+			if n.Message.Data.(threadMessage).isTarget {
+				tree = []*thread.Node{r}
+			}
+		})
+	}
+
+	return nil, map[string]any{
+		"thread":             threadNodes(tree),
+		"count":              thread.Len(tree),
+		"mailboxes_searched": result.MailboxesSearched,
+		"truncated":          result.Truncated,
+	}, nil
+}
+
+// fetchThreadMessage reads the message get_thread starts from.
+func fetchThreadMessage(ctx context.Context, executor jxa.Executor, input GetThreadInput) (threadMessage, error) {
+	inputJSON, err := json.Marshal(GetMessageContentInput{
+		Account:     input.Account,
+		MailboxPath: input.MailboxPath,
+		MessageID:   input.MessageID,
+	})
+	if err != nil {
+		return threadMessage{}, fmt.Errorf("failed to marshal input for JXA: %w", err)
+	}
+	data, err := executor.Execute(ctx, getMessageContentScript, string(inputJSON))
+	if err != nil {
+		return threadMessage{}, fmt.Errorf("failed to execute get_message_content: %w", err)
+	}
+	var content struct {
+		Message struct {
+			ID           int    `json:"id"`
+			Subject      string `json:"subject"`
+			Sender       string `json:"sender"`
+			DateReceived string `json:"dateReceived"`
+			MessageID    string `json:"messageId"`
+			AllHeaders   string `json:"allHeaders"`
+		} `json:"message"`
+	}
+	if err := remarshal(data, &content); err != nil {
+		return threadMessage{}, fmt.Errorf("failed to parse get_message_content result: %w", err)
+	}
+	m := content.Message
+	return threadMessage{
+		isTarget:     true,
+		ID:           m.ID,
+		Account:      input.Account,
+		MailboxPath:  input.MailboxPath,
+		Subject:      m.Subject,
+		Sender:       m.Sender,
+		DateReceived: m.DateReceived,
+		MessageID:    m.MessageID,
+		AllHeaders:   m.AllHeaders,
+	}, nil
+}
+
+func threadMessageOf(m threadMessage) thread.Message {
+	date, _ := time.Parse(time.RFC3339, m.DateReceived)
+	return thread.FromHeader(headers.Parse(m.AllHeaders), m.MessageID, m.Subject, date, m)
+}
+
+func threadNodes(nodes []*thread.Node) []*threadNode {
+	out := []*threadNode{}
+	for _, n := range nodes {
+		m := n.Message.Data.(threadMessage)
+		var inReplyTo string
+		if len(n.Message.InReplyTo) > 0 {
+			inReplyTo = n.Message.InReplyTo[0]
+		}
+		out = append(out, &threadNode{
+			ID:           m.ID,
+			Account:      m.Account,
+			MailboxPath:  m.MailboxPath,
+			Subject:      m.Subject,
+			Sender:       m.Sender,
+			DateReceived: m.DateReceived,
+			MessageID:    n.Message.MessageID,
+			InReplyTo:    inReplyTo,
+			LinkedBy:     n.LinkedBy,
+			IsTarget:     m.isTarget,
+			Replies:      threadNodes(n.Children),
+		})
+	}
+	return out
+}
+
+// remarshal converts the generic result of a script into v.
+func remarshal(data any, v any) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
@@ -11,7 +11,9 @@ import (
 )
 
 //go:embed scripts/list_accounts.js
-var listAccountsScript string
+var listAccountsSource string
+
+var listAccountsScript = jxa.Script{Name: "list_accounts", Source: listAccountsSource}
 
 // ListAccountsInput defines input parameters for list_accounts tool
 type ListAccountsInput struct {
@@ -19,7 +21,7 @@ type ListAccountsInput struct {
 }
 
 // RegisterListAccounts registers the list_accounts tool with the MCP server
-func RegisterListAccounts(srv *mcp.Server) {
+func RegisterListAccounts(srv *mcp.Server, executor jxa.Executor) {
 	mcp.AddTool(srv,
 		&mcp.Tool{
 			Name:        "list_accounts",
@@ -33,18 +35,20 @@ func RegisterListAccounts(srv *mcp.Server) {
 				OpenWorldHint:   new(true),
 			},
 		},
-		HandleListAccounts,
+		func(ctx context.Context, request *mcp.CallToolRequest, input ListAccountsInput) (*mcp.CallToolResult, any, error) {
+			return HandleListAccounts(ctx, executor, request, input)
+		},
 	)
 }
 
-func HandleListAccounts(ctx context.Context, request *mcp.CallToolRequest, input ListAccountsInput) (*mcp.CallToolResult, any, error) {
+func HandleListAccounts(ctx context.Context, executor jxa.Executor, request *mcp.CallToolRequest, input ListAccountsInput) (*mcp.CallToolResult, any, error) {
 	// Execute JXA script with enabled filter
 	inputJSON, err := json.Marshal(input)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to marshal input for JXA: %w", err)
 	}
 
-	data, err := jxa.Execute(ctx, listAccountsScript, string(inputJSON))
+	data, err := executor.Execute(ctx, listAccountsScript, string(inputJSON))
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to execute list_accounts: %w", err)
 	}
@@ -0,0 +1,64 @@
+package tools
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+
+	"github.com/dastrobu/mail-mcp/internal/jxa"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+//go:embed scripts/list_attachments.js
+var listAttachmentsSource string
+
+var listAttachmentsScript = jxa.Script{Name: "list_attachments", Source: listAttachmentsSource}
+
+// ListAttachmentsInput defines input parameters for list_attachments tool
+type ListAttachmentsInput struct {
+	Account     string   `json:"account" jsonschema:"Name of the email account" long:"account" description:"Name of the email account"`
+	MailboxPath []string `json:"mailboxPath" jsonschema:"Path to the mailbox as an array (e.g. ['Inbox']). Use the mailboxPath field from get_selected_messages or find_messages. Note: Mailbox names are case-sensitive." long:"mailbox-path" description:"Path to the mailbox. Can be specified multiple times for nested paths."`
+	MessageID   int      `json:"message_id" jsonschema:"The unique ID of the message" long:"message-id" description:"The unique ID of the message"`
+}
+
+// RegisterListAttachments registers the list_attachments tool with the MCP server
+func RegisterListAttachments(srv *mcp.Server, executor jxa.Executor) {
+	mcp.AddTool(srv,
+		&mcp.Tool{
+			Name:        "list_attachments",
+			Description: "Lists the attachments of a message with their ID, name, MIME type, size in bytes and whether they are downloaded. Use the ID with save_attachment.",
+			InputSchema: GenerateSchema[ListAttachmentsInput](),
+			Annotations: &mcp.ToolAnnotations{
+				Title:           "List Attachments",
+				ReadOnlyHint:    true,
+				IdempotentHint:  true,
+				DestructiveHint: new(false),
+				OpenWorldHint:   new(true),
+			},
+		},
+		func(ctx context.Context, request *mcp.CallToolRequest, input ListAttachmentsInput) (*mcp.CallToolResult, any, error) {
+			return HandleListAttachments(ctx, executor, request, input)
+		},
+	)
+}
+
+func HandleListAttachments(ctx context.Context, executor jxa.Executor, request *mcp.CallToolRequest, input ListAttachmentsInput) (*mcp.CallToolResult, any, error) {
+	if len(input.MailboxPath) == 0 {
+		return nil, nil, fmt.Errorf("mailboxPath is required and must be a non-empty array")
+	}
+
+	inputJSON, err := json.Marshal(input)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal input for JXA: %w", err)
+	}
+
+	data, err := executor.Execute(ctx, listAttachmentsScript, string(inputJSON))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to execute list_attachments: %w", err)
+	}
+
+	fillAttachmentMIMETypes(data)
+
+	return nil, data, nil
+}
@@ -11,20 +11,23 @@ import (
 )
 
 //go:embed scripts/list_drafts.js
-var listDraftsScript string
+var listDraftsSource string
+
+var listDraftsScript = jxa.Script{Name: "list_drafts", Source: listDraftsSource}
 
 // ListDraftsInput defines input parameters for list_drafts tool
 type ListDraftsInput struct {
 	Account string `json:"account,omitempty" jsonschema:"Optional: Name of the email account to filter drafts by" long:"account" description:"Optional: Name of the email account to filter drafts by"`
 	Limit   int    `json:"limit,omitempty" jsonschema:"Maximum number of drafts to return (1-1000, default: 50)" long:"limit" description:"Maximum number of drafts to return (1-1000, default: 50)"`
+	Cursor  string `json:"cursor,omitempty" jsonschema:"Cursor from the next_cursor of a previous call to fetch the next page. account must be unchanged." long:"cursor" description:"Cursor from the next_cursor of a previous call to fetch the next page"`
 }
 
 // RegisterListDrafts registers the list_drafts tool with the MCP server
-func RegisterListDrafts(srv *mcp.Server) {
+func RegisterListDrafts(srv *mcp.Server, executor jxa.Executor) {
 	mcp.AddTool(srv,
 		&mcp.Tool{
 			Name:        "list_drafts",
-			Description: "Lists draft messages from the global Drafts mailbox, optionally filtered by a specific account. Returns Message.id() values for persistent drafts saved in the Drafts mailbox. These are different from OutgoingMessage objects. Use list_outgoing_messages to see in-memory drafts instead.",
+			Description: "Lists draft messages from the global Drafts mailbox, optionally filtered by a specific account. Returns Message.id() values for persistent drafts saved in the Drafts mailbox. These are different from OutgoingMessage objects. Use list_outgoing_messages to see in-memory drafts instead. Pass next_cursor as cursor to fetch the next page.",
 			InputSchema: GenerateSchema[ListDraftsInput](),
 			Annotations: &mcp.ToolAnnotations{
 				Title:           "List Draft Messages",
@@ -34,11 +37,13 @@ func RegisterListDrafts(srv *mcp.Server) {
 				OpenWorldHint:   new(true),
 			},
 		},
-		HandleListDrafts,
+		func(ctx context.Context, request *mcp.CallToolRequest, input ListDraftsInput) (*mcp.CallToolResult, any, error) {
+			return HandleListDrafts(ctx, executor, request, input)
+		},
 	)
 }
 
-func HandleListDrafts(ctx context.Context, request *mcp.CallToolRequest, input ListDraftsInput) (*mcp.CallToolResult, any, error) {
+func HandleListDrafts(ctx context.Context, executor jxa.Executor, request *mcp.CallToolRequest, input ListDraftsInput) (*mcp.CallToolResult, any, error) {
 	// Apply default limit
 	if input.Limit == 0 {
 		input.Limit = 50
@@ -49,15 +54,29 @@ func HandleListDrafts(ctx context.Context, request *mcp.CallToolRequest, input L
 		return nil, nil, fmt.Errorf("limit must be between 1 and 1000")
 	}
 
-	inputJSON, err := json.Marshal(input)
+	// Resolve the cursor against the filters without the page parameters
+	hash, err := filterHash(ListDraftsInput{Account: input.Account})
+	if err != nil {
+		return nil, nil, err
+	}
+	offset, err := decodeCursor(input.Cursor, hash)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	inputJSON, err := json.Marshal(struct {
+		ListDraftsInput
+		Offset int `json:"offset"`
+	}{input, offset})
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to marshal input for JXA: %w", err)
 	}
 
-	data, err := jxa.Execute(ctx, listDraftsScript, string(inputJSON))
+	data, err := executor.Execute(ctx, listDraftsScript, string(inputJSON))
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to execute list_drafts: %w", err)
 	}
+	setNextCursor(data, hash)
 
 	return nil, data, nil
 }
@@ -11,20 +11,24 @@ import (
 )
 
 //go:embed scripts/list_mailboxes.js
-var listMailboxesScript string
+var listMailboxesSource string
+
+var listMailboxesScript = jxa.Script{Name: "list_mailboxes", Source: listMailboxesSource}
 
 // ListMailboxesInput defines input parameters for list_mailboxes tool
 type ListMailboxesInput struct {
 	Account     string   `json:"account" jsonschema:"Name of the email account" long:"account" description:"Name of the email account"`
 	MailboxPath []string `json:"mailboxPath,omitempty" jsonschema:"Optional path to a mailbox to list its sub-mailboxes (e.g. ['Inbox'] to list mailboxes under Inbox). If omitted, lists top-level mailboxes. Note: Mailbox names are case-sensitive." long:"mailbox-path" description:"Optional path to a mailbox to list its sub-mailboxes (e.g. Inbox to list mailboxes under Inbox). Can be specified multiple times for nested paths."`
+	Limit       int      `json:"limit,omitempty" jsonschema:"Maximum number of mailboxes to return (1-1000). If omitted, all mailboxes are returned." long:"limit" description:"Maximum number of mailboxes to return (1-1000). If omitted, all mailboxes are returned."`
+	Cursor      string   `json:"cursor,omitempty" jsonschema:"Cursor from the next_cursor of a previous call to fetch the next page. account and mailboxPath must be unchanged." long:"cursor" description:"Cursor from the next_cursor of a previous call to fetch the next page"`
 }
 
 // RegisterListMailboxes registers the list_mailboxes tool with the MCP server
-func RegisterListMailboxes(srv *mcp.Server) {
+func RegisterListMailboxes(srv *mcp.Server, executor jxa.Executor) {
 	mcp.AddTool(srv,
 		&mcp.Tool{
 			Name:        "list_mailboxes",
-			Description: "Lists mailboxes (folders) for a specific account in Apple Mail. By default lists top-level mailboxes. Optionally provide mailboxPath to list sub-mailboxes of a specific mailbox. Returns mailboxPath for each mailbox to support nested mailbox navigation.",
+			Description: "Lists mailboxes (folders) for a specific account in Apple Mail. By default lists top-level mailboxes. Optionally provide mailboxPath to list sub-mailboxes of a specific mailbox. Returns mailboxPath for each mailbox to support nested mailbox navigation. With limit, pass next_cursor as cursor to fetch the next page.",
 			InputSchema: GenerateSchema[ListMailboxesInput](),
 			Annotations: &mcp.ToolAnnotations{
 				Title:           "List Mailboxes",
@@ -34,20 +38,41 @@ func RegisterListMailboxes(srv *mcp.Server) {
 				OpenWorldHint:   new(true),
 			},
 		},
-		HandleListMailboxes,
+		func(ctx context.Context, request *mcp.CallToolRequest, input ListMailboxesInput) (*mcp.CallToolResult, any, error) {
+			return HandleListMailboxes(ctx, executor, request, input)
+		},
 	)
 }
 
-func HandleListMailboxes(ctx context.Context, request *mcp.CallToolRequest, input ListMailboxesInput) (*mcp.CallToolResult, any, error) {
-	inputJSON, err := json.Marshal(input)
+func HandleListMailboxes(ctx context.Context, executor jxa.Executor, request *mcp.CallToolRequest, input ListMailboxesInput) (*mcp.CallToolResult, any, error) {
+	// Validate limit, 0 lists all mailboxes
+	if input.Limit < 0 || input.Limit > 1000 {
+		return nil, nil, fmt.Errorf("limit must be between 1 and 1000")
+	}
+
+	// Resolve the cursor against the filters without the page parameters
+	hash, err := filterHash(ListMailboxesInput{Account: input.Account, MailboxPath: input.MailboxPath})
+	if err != nil {
+		return nil, nil, err
+	}
+	offset, err := decodeCursor(input.Cursor, hash)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	inputJSON, err := json.Marshal(struct {
+		ListMailboxesInput
+		Offset int `json:"offset"`
+	}{input, offset})
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to marshal input for JXA: %w", err)
 	}
 
-	data, err := jxa.Execute(ctx, listMailboxesScript, string(inputJSON))
+	data, err := executor.Execute(ctx, listMailboxesScript, string(inputJSON))
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to execute list_mailboxes: %w", err)
 	}
+	setNextCursor(data, hash)
 
 	return nil, data, nil
 }
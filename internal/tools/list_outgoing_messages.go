@@ -9,10 +9,12 @@ import (
 )
 
 //go:embed scripts/list_outgoing_messages.js
-var listOutgoingMessagesScript string
+var listOutgoingMessagesSource string
+
+var listOutgoingMessagesScript = jxa.Script{Name: "list_outgoing_messages", Source: listOutgoingMessagesSource}
 
 // RegisterListOutgoingMessages registers the list_outgoing_messages tool with the MCP server
-func RegisterListOutgoingMessages(srv *mcp.Server) {
+func RegisterListOutgoingMessages(srv *mcp.Server, executor jxa.Executor) {
 	mcp.AddTool(srv,
 		&mcp.Tool{
 			Name:        "list_outgoing_messages",
@@ -26,12 +28,14 @@ func RegisterListOutgoingMessages(srv *mcp.Server) {
 				OpenWorldHint:   new(true),
 			},
 		},
-		HandleListOutgoingMessages,
+		func(ctx context.Context, request *mcp.CallToolRequest, input struct{}) (*mcp.CallToolResult, any, error) {
+			return HandleListOutgoingMessages(ctx, executor, request, input)
+		},
 	)
 }
 
-func HandleListOutgoingMessages(ctx context.Context, request *mcp.CallToolRequest, input struct{}) (*mcp.CallToolResult, any, error) {
-	data, err := jxa.Execute(ctx, listOutgoingMessagesScript)
+func HandleListOutgoingMessages(ctx context.Context, executor jxa.Executor, request *mcp.CallToolRequest, input struct{}) (*mcp.CallToolResult, any, error) {
+	data, err := executor.Execute(ctx, listOutgoingMessagesScript)
 	if err != nil {
 		return nil, nil, err
 	}
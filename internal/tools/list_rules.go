@@ -0,0 +1,79 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dastrobu/mail-mcp/internal/jxa"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ListRulesInput defines input parameters for list_rules tool
+type ListRulesInput struct {
+	Enabled bool `json:"enabled,omitempty" jsonschema:"Only list enabled rules. Default: false" long:"enabled" description:"Only list enabled rules"`
+}
+
+// RegisterListRules registers the list_rules tool with the MCP server
+func RegisterListRules(srv *mcp.Server, executor jxa.Executor) {
+	mcp.AddTool(srv,
+		&mcp.Tool{
+			Name:        "list_rules",
+			Description: "Lists the rules of Apple Mail in the order they are evaluated, with their name, enabled state, whether all or any conditions must match, conditions and actions. Conditions and actions these tools cannot represent (e.g. running an AppleScript or playing a sound) are listed in unsupported instead of being left out.",
+			InputSchema: GenerateSchema[ListRulesInput](),
+			Annotations: &mcp.ToolAnnotations{
+				Title:           "List Rules",
+				ReadOnlyHint:    true,
+				IdempotentHint:  true,
+				DestructiveHint: new(false),
+				OpenWorldHint:   new(true),
+			},
+		},
+		func(ctx context.Context, request *mcp.CallToolRequest, input ListRulesInput) (*mcp.CallToolResult, any, error) {
+			return HandleListRules(ctx, executor, request, input)
+		},
+	)
+}
+
+func HandleListRules(ctx context.Context, executor jxa.Executor, request *mcp.CallToolRequest, input ListRulesInput) (*mcp.CallToolResult, any, error) {
+	rules, err := fetchRules(ctx, executor)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	listed := []Rule{}
+	unsupported := 0
+	for _, rule := range rules {
+		if input.Enabled && !rule.Enabled {
+			continue
+		}
+		listed = append(listed, rule)
+		if len(rule.Unsupported) > 0 {
+			unsupported++
+		}
+	}
+
+	return nil, map[string]any{
+		"rules":                  listed,
+		"count":                  len(listed),
+		"with_unsupported_count": unsupported,
+	}, nil
+}
+
+// findRule returns the rule with the given name. Mail allows rules with the
+// same name, they cannot be told apart and are rejected.
+func findRule(rules []Rule, name string) (Rule, error) {
+	var found []Rule
+	for _, rule := range rules {
+		if rule.Name == name {
+			found = append(found, rule)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return Rule{}, fmt.Errorf("rule %q not found", name)
+	case 1:
+		return found[0], nil
+	default:
+		return Rule{}, fmt.Errorf("there are %d rules named %q, rename them in Mail so they can be told apart", len(found), name)
+	}
+}
@@ -0,0 +1,55 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/dastrobu/mail-mcp/internal/jxa"
+	"github.com/dastrobu/mail-mcp/internal/schedule"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type ListScheduledInput struct {
+	IncludeFinished bool `json:"include_finished,omitempty" jsonschema:"Also list messages that were sent, failed or canceled in the last 7 days" long:"include-finished" description:"Also list messages that were sent, failed or canceled in the last 7 days"`
+}
+
+// RegisterListScheduled registers the list_scheduled tool with the MCP server
+func RegisterListScheduled(srv *mcp.Server, executor jxa.Executor, cfg Config) {
+	mcp.AddTool(srv,
+		&mcp.Tool{
+			Name:        "list_scheduled",
+			Description: "Lists the messages queued with schedule_send, ordered by the time they are sent at. Returns the schedule_id needed by cancel_scheduled.",
+			InputSchema: GenerateSchema[ListScheduledInput](),
+			Annotations: &mcp.ToolAnnotations{
+				Title:           "List Scheduled Messages",
+				ReadOnlyHint:    true,
+				IdempotentHint:  true,
+				DestructiveHint: new(false),
+				OpenWorldHint:   new(false),
+			},
+		},
+		func(ctx context.Context, request *mcp.CallToolRequest, input ListScheduledInput) (*mcp.CallToolResult, any, error) {
+			return HandleListScheduled(ctx, executor, cfg, request, input)
+		},
+	)
+}
+
+func HandleListScheduled(ctx context.Context, executor jxa.Executor, cfg Config, request *mcp.CallToolRequest, input ListScheduledInput) (*mcp.CallToolResult, any, error) {
+	store, err := scheduleStore(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	entries, err := store.List()
+	if err != nil {
+		return nil, nil, err
+	}
+	scheduled := []schedule.Entry{}
+	for _, e := range entries {
+		if input.IncludeFinished || e.Status == schedule.StatusPending || e.Status == schedule.StatusSending {
+			scheduled = append(scheduled, e)
+		}
+	}
+	return nil, map[string]any{
+		"scheduled": scheduled,
+		"count":     len(scheduled),
+	}, nil
+}
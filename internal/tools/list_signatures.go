@@ -0,0 +1,63 @@
+package tools
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+
+	"github.com/dastrobu/mail-mcp/internal/jxa"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+//go:embed scripts/list_signatures.js
+var listSignaturesSource string
+
+var listSignaturesScript = jxa.Script{Name: "list_signatures", Source: listSignaturesSource}
+
+// ListSignaturesInput defines input parameters for list_signatures tool
+type ListSignaturesInput struct{}
+
+// RegisterListSignatures registers the list_signatures tool with the MCP server
+func RegisterListSignatures(srv *mcp.Server, executor jxa.Executor, cfg Config) {
+	mcp.AddTool(srv,
+		&mcp.Tool{
+			Name:        "list_signatures",
+			Description: "Lists the signatures that can be used with the signature parameter of the tools composing messages: the signatures configured in Mail.app by name, and the Markdown signatures per account in the signature directory (<account>.md), which are appended to messages of that account unless a Mail signature or \"none\" is given.",
+			InputSchema: GenerateSchema[ListSignaturesInput](),
+			Annotations: &mcp.ToolAnnotations{
+				Title:           "List Signatures",
+				ReadOnlyHint:    true,
+				IdempotentHint:  true,
+				DestructiveHint: new(false),
+				OpenWorldHint:   new(true),
+			},
+		},
+		func(ctx context.Context, request *mcp.CallToolRequest, input ListSignaturesInput) (*mcp.CallToolResult, any, error) {
+			return HandleListSignatures(ctx, executor, cfg, request, input)
+		},
+	)
+}
+
+func HandleListSignatures(ctx context.Context, executor jxa.Executor, cfg Config, request *mcp.CallToolRequest, input ListSignaturesInput) (*mcp.CallToolResult, any, error) {
+	dir, fileSignatures, err := listFileSignatures(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data, err := executor.Execute(ctx, listSignaturesScript, "{}")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to execute list_signatures: %w", err)
+	}
+	var result struct {
+		Signatures []map[string]any `json:"signatures"`
+	}
+	if err := remarshal(data, &result); err != nil {
+		return nil, nil, err
+	}
+
+	return nil, map[string]any{
+		"mail_signatures": result.Signatures,
+		"file_signatures": fileSignatures,
+		"signature_dir":   dir,
+	}, nil
+}
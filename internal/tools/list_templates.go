@@ -0,0 +1,57 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/dastrobu/mail-mcp/internal/jxa"
+	"github.com/dastrobu/mail-mcp/internal/templates"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ListTemplatesInput defines input parameters for list_templates tool
+type ListTemplatesInput struct{}
+
+// RegisterListTemplates registers the list_templates tool with the MCP server
+func RegisterListTemplates(srv *mcp.Server, executor jxa.Executor, cfg Config) {
+	mcp.AddTool(srv,
+		&mcp.Tool{
+			Name:        "list_templates",
+			Description: "Lists the message templates in the template directory with their description, subject, recipients and required variables. Use create_from_template to create a message from a template. Files that are not valid templates are listed in errors.",
+			InputSchema: GenerateSchema[ListTemplatesInput](),
+			Annotations: &mcp.ToolAnnotations{
+				Title:           "List Templates",
+				ReadOnlyHint:    true,
+				IdempotentHint:  true,
+				DestructiveHint: new(false),
+				OpenWorldHint:   new(false),
+			},
+		},
+		func(ctx context.Context, request *mcp.CallToolRequest, input ListTemplatesInput) (*mcp.CallToolResult, any, error) {
+			return HandleListTemplates(ctx, executor, cfg, request, input)
+		},
+	)
+}
+
+func HandleListTemplates(ctx context.Context, executor jxa.Executor, cfg Config, request *mcp.CallToolRequest, input ListTemplatesInput) (*mcp.CallToolResult, any, error) {
+	dir, err := templateDir(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	list, loadErrors, err := templates.List(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	return nil, map[string]any{
+		"templates":    list,
+		"count":        len(list),
+		"errors":       loadErrors,
+		"template_dir": dir,
+	}, nil
+}
+
+func templateDir(cfg Config) (string, error) {
+	if cfg.TemplateDir != "" {
+		return cfg.TemplateDir, nil
+	}
+	return appSupportPath("templates")
+}
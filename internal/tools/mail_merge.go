@@ -0,0 +1,316 @@
+package tools
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/dastrobu/mail-mcp/internal/jxa"
+	"github.com/dastrobu/mail-mcp/internal/mac"
+	"github.com/dastrobu/mail-mcp/internal/templates"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxMergeRows is the maximum number of rows of a mail merge, each opens a
+// window in Mail.app.
+const maxMergeRows = 200
+
+// MailMergeInput defines input parameters for mail_merge tool
+type MailMergeInput struct {
+	Template      string    `json:"template,omitempty" jsonschema:"Name of a template as returned by list_templates. Either template or subject and content are required." long:"template" description:"Name of a template"`
+	Subject       string    `json:"subject,omitempty" jsonschema:"Subject with placeholders such as {{.name}}, used instead of a template" long:"subject" description:"Subject with placeholders, used instead of a template"`
+	Content       string    `json:"content,omitempty" jsonschema:"Markdown body with placeholders such as {{.name}}, used instead of a template" long:"content" description:"Markdown body with placeholders, used instead of a template"`
+	ToRecipients  *[]string `json:"to_recipients,omitempty" jsonschema:"Optional: To recipients with placeholders, replacing those of the template. Default without a template: {{.email}}" long:"to-recipients" description:"To recipient with placeholders, replacing those of the template (default without a template: {{.email}}). Can be specified multiple times."`
+	CcRecipients  *[]string `json:"cc_recipients,omitempty" jsonschema:"Optional: CC recipients with placeholders, replacing those of the template" long:"cc-recipients" description:"CC recipient with placeholders, replacing those of the template. Can be specified multiple times."`
+	BccRecipients *[]string `json:"bcc_recipients,omitempty" jsonschema:"Optional: BCC recipients with placeholders, replacing those of the template" long:"bcc-recipients" description:"BCC recipient with placeholders, replacing those of the template. Can be specified multiple times."`
+	Data          string    `json:"data" jsonschema:"The rows to merge, either CSV with a header row or a JSON array of objects. The column names or keys are the variables of the placeholders." long:"data" description:"The rows to merge as CSV with a header row or a JSON array of objects"`
+	DataFormat    string    `json:"data_format,omitempty" jsonschema:"Format of data: 'csv' or 'json'. Default: json if data starts with [, csv otherwise." long:"data-format" description:"Format of data: 'csv' or 'json' (default: detected)"`
+	Account       string    `json:"account,omitempty" jsonschema:"Optional: name of the account to send from. Overrides the account of the template, required if the template has none." long:"account" description:"Name of the account to send from, overrides the account of the template"`
+	Attachments   []string  `json:"attachments,omitempty" jsonschema:"Absolute paths of local files to attach to every message. The files must be in a directory the server allows attachments from." long:"attachment" description:"Absolute path of a local file to attach to every message. Can be specified multiple times."`
+	Signature     string    `json:"signature,omitempty" jsonschema:"Optional: name of a Mail signature to add (see list_signatures), or 'none' for no signature. By default the Markdown signature of the account in the signature directory is appended to the content, if there is one." long:"signature" description:"Name of a Mail signature to add, or 'none' for no signature. By default the Markdown signature of the account is appended."`
+	DryRun        bool      `json:"dry_run,omitempty" jsonschema:"Render every message for review without creating any. Default: false" long:"dry-run" description:"Render every message for review without creating any"`
+}
+
+// mergeRow is the outcome of one row of a mail merge.
+type mergeRow struct {
+	Row        int      `json:"row"`
+	Status     string   `json:"status"`
+	Missing    []string `json:"missing,omitempty"`
+	Error      string   `json:"error,omitempty"`
+	OutgoingID *float64 `json:"outgoing_id,omitempty"`
+	Subject    string   `json:"subject,omitempty"`
+	To         []string `json:"to,omitempty"`
+	Cc         []string `json:"cc,omitempty"`
+	Bcc        []string `json:"bcc,omitempty"`
+	Content    string   `json:"content,omitempty"`
+
+	input CreateOutgoingMessageInput
+}
+
+// Statuses of a mergeRow.
+const (
+	mergeRowValid    = "valid"
+	mergeRowRejected = "rejected"
+	mergeRowCreated  = "created"
+	mergeRowFailed   = "failed"
+)
+
+// RegisterMailMerge registers the mail_merge tool with the MCP server
+func RegisterMailMerge(srv *mcp.Server, executor jxa.Executor, cfg Config) {
+	mcp.AddTool(srv,
+		&mcp.Tool{
+			Name:        "mail_merge",
+			Description: "Creates one personalized outgoing message per row of a CSV or JSON dataset from a template (see list_templates) or a subject and Markdown body with placeholders such as {{.name}}. Every row is checked first: if any row lacks a variable the template needs, leaves one blank that is required (without a template, every placeholder is) or renders no valid To recipient, no message is created and the rejected rows are reported. Use dry_run to review the rendered messages first. Messages are created like create_outgoing_message creates them and are NOT sent; the result reports the outgoing_id or the error of each row. Messages are created one at a time: if a row fails while being created, the windows of the rows created before stay open and the remaining rows are still created.",
+			InputSchema: GenerateSchema[MailMergeInput](),
+			Annotations: &mcp.ToolAnnotations{
+				Title:           "Mail Merge",
+				ReadOnlyHint:    false,
+				IdempotentHint:  false,
+				DestructiveHint: new(true),
+				OpenWorldHint:   new(true),
+			},
+		},
+		func(ctx context.Context, request *mcp.CallToolRequest, input MailMergeInput) (*mcp.CallToolResult, any, error) {
+			return HandleMailMerge(ctx, executor, cfg, request, input)
+		},
+	)
+}
+
+func HandleMailMerge(ctx context.Context, executor jxa.Executor, cfg Config, request *mcp.CallToolRequest, input MailMergeInput) (*mcp.CallToolResult, any, error) {
+	tmpl, err := mergeTemplate(cfg, input)
+	if err != nil {
+		return nil, nil, err
+	}
+	rows, err := parseMergeRows(input.Data, input.DataFormat)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil, fmt.Errorf("data has no rows")
+	}
+	if len(rows) > maxMergeRows {
+		return nil, nil, fmt.Errorf("data has %d rows, at most %d can be merged at once", len(rows), maxMergeRows)
+	}
+	attachments, err := resolveAttachments(input.Attachments, cfg.AttachmentAllowDirs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Check all rows before creating any message
+	results := make([]mergeRow, len(rows))
+	rejected := 0
+	for i, row := range rows {
+		results[i] = renderMergeRow(tmpl, row, i+1, input.Account)
+		if results[i].Status == mergeRowRejected {
+			rejected++
+		}
+	}
+	result := map[string]any{
+		"template":       tmpl.Name,
+		"rows":           results,
+		"row_count":      len(rows),
+		"rejected_count": rejected,
+		"dry_run":        input.DryRun,
+	}
+	if rejected > 0 {
+		result["created_count"] = 0
+		result["message"] = fmt.Sprintf("No messages were created, %d of %d rows were rejected. Fix the data and try again.", rejected, len(rows))
+		return nil, result, nil
+	}
+	if input.DryRun {
+		result["message"] = fmt.Sprintf("Dry run: %d messages rendered, none were created.", len(rows))
+		return nil, result, nil
+	}
+	if err := mac.EnsureAccessibility(); err != nil {
+		return nil, nil, err
+	}
+
+	created, failed := 0, 0
+	for i := range results {
+		r := &results[i]
+		// The content is only returned for review in a dry run
+		r.Content = ""
+		r.input.Attachments = attachments
+		r.input.Signature = input.Signature
+		_, data, err := HandleCreateOutgoingMessage(ctx, executor, cfg, request, r.input)
+		if err != nil {
+			r.Status = mergeRowFailed
+			r.Error = err.Error()
+			failed++
+			continue
+		}
+		r.Status = mergeRowCreated
+		if id, ok := data.(map[string]any)["outgoing_id"].(float64); ok {
+			r.OutgoingID = &id
+		}
+		created++
+	}
+	result["created_count"] = created
+	result["failed_count"] = failed
+	result["message"] = fmt.Sprintf("%d of %d messages created. They are NOT sent.", created, len(rows))
+	return nil, result, nil
+}
+
+// mergeTemplate returns the template of a mail merge, loaded by name or
+// given by subject and content, with the recipients of the input. All
+// variables of a template given by subject and content are required.
+func mergeTemplate(cfg Config, input MailMergeInput) (*templates.Template, error) {
+	var tmpl *templates.Template
+	switch {
+	case input.Template != "":
+		if input.Subject != "" || input.Content != "" {
+			return nil, fmt.Errorf("either template or subject and content can be given, not both")
+		}
+		dir, err := templateDir(cfg)
+		if err != nil {
+			return nil, err
+		}
+		if tmpl, err = templates.Load(dir, input.Template); err != nil {
+			return nil, err
+		}
+	case input.Subject != "" && input.Content != "":
+		tmpl = &templates.Template{Name: "inline", Subject: input.Subject, Body: input.Content, To: []string{"{{.email}}"}}
+	default:
+		return nil, fmt.Errorf("either template or subject and content are required")
+	}
+	if input.ToRecipients != nil {
+		tmpl.To = *input.ToRecipients
+	}
+	if input.CcRecipients != nil {
+		tmpl.Cc = *input.CcRecipients
+	}
+	if input.BccRecipients != nil {
+		tmpl.Bcc = *input.BccRecipients
+	}
+	if err := tmpl.Validate(); err != nil {
+		return nil, err
+	}
+	if input.Template == "" {
+		// Without a template there is no way to declare a variable optional,
+		// and a CSV row has every column, so blank values must not slip through
+		tmpl.Variables = tmpl.Fields()
+	}
+	return tmpl, nil
+}
+
+// renderMergeRow renders the message of one row, which is rejected if it
+// lacks variables or a valid recipient.
+func renderMergeRow(tmpl *templates.Template, row map[string]string, n int, account string) mergeRow {
+	r := mergeRow{Row: n, Status: mergeRowValid}
+	if missing := tmpl.Missing(row); len(missing) > 0 {
+		r.Status = mergeRowRejected
+		r.Missing = missing
+		r.Error = "missing variables: " + strings.Join(missing, ", ")
+		return r
+	}
+	createInput, err := templateMessageInput(tmpl, row, account)
+	if err != nil {
+		r.Status = mergeRowRejected
+		r.Error = err.Error()
+		return r
+	}
+	if _, err := normalizeRecipients(createInput.ToRecipients, createInput.CcRecipients, createInput.BccRecipients); err != nil {
+		r.Status = mergeRowRejected
+		r.Error = err.Error()
+		return r
+	}
+	if createInput.ToRecipients == nil {
+		r.Status = mergeRowRejected
+		r.Error = "no To recipient"
+		return r
+	}
+	r.input = createInput
+	r.Subject = createInput.Subject
+	r.Content = createInput.Content
+	r.To = *createInput.ToRecipients
+	if createInput.CcRecipients != nil {
+		r.Cc = *createInput.CcRecipients
+	}
+	if createInput.BccRecipients != nil {
+		r.Bcc = *createInput.BccRecipients
+	}
+	return r
+}
+
+// parseMergeRows parses the rows of a mail merge from CSV with a header row
+// or a JSON array of objects.
+func parseMergeRows(data, format string) ([]map[string]string, error) {
+	data = strings.TrimPrefix(data, "\ufeff")
+	if strings.TrimSpace(data) == "" {
+		return nil, fmt.Errorf("data is required")
+	}
+	if format == "" {
+		format = "csv"
+		if strings.HasPrefix(strings.TrimSpace(data), "[") {
+			format = "json"
+		}
+	}
+	switch format {
+	case "csv":
+		return parseMergeCSV(data)
+	case "json":
+		return parseMergeJSON(data)
+	default:
+		return nil, fmt.Errorf("invalid data_format %q, use 'csv' or 'json'", format)
+	}
+}
+
+func parseMergeCSV(data string) ([]map[string]string, error) {
+	records, err := csv.NewReader(strings.NewReader(data)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CSV data: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("CSV data has no header row")
+	}
+	header := records[0]
+	for i, name := range header {
+		header[i] = strings.TrimSpace(name)
+		if header[i] == "" {
+			return nil, fmt.Errorf("CSV column %d has no name", i+1)
+		}
+		if slices.Contains(header[:i], header[i]) {
+			return nil, fmt.Errorf("CSV column %q is given twice", header[i])
+		}
+	}
+	rows := make([]map[string]string, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := make(map[string]string, len(header))
+		for i, name := range header {
+			row[name] = strings.TrimSpace(record[i])
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func parseMergeJSON(data string) ([]map[string]string, error) {
+	var objects []map[string]any
+	if err := json.Unmarshal([]byte(data), &objects); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON data, expected an array of objects: %w", err)
+	}
+	rows := make([]map[string]string, 0, len(objects))
+	for i, object := range objects {
+		row := make(map[string]string, len(object))
+		for key, value := range object {
+			switch v := value.(type) {
+			case nil:
+				row[key] = ""
+			case string:
+				row[key] = strings.TrimSpace(v)
+			case float64:
+				row[key] = strconv.FormatFloat(v, 'f', -1, 64)
+			case bool:
+				row[key] = strconv.FormatBool(v)
+			default:
+				return nil, fmt.Errorf("row %d: value of %q must be a string, number or boolean", i+1, key)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
@@ -0,0 +1,60 @@
+package tools
+
+import (
+	"maps"
+	"strings"
+	"testing"
+)
+
+func TestParseMergeRows(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		format  string
+		want    []map[string]string
+		wantErr string
+	}{
+		{
+			name: "csv",
+			data: "\ufeffname, email\nBob,bob@example.com\n\"Smith, Alice\", alice@example.com \n",
+			want: []map[string]string{
+				{"name": "Bob", "email": "bob@example.com"},
+				{"name": "Smith, Alice", "email": "alice@example.com"},
+			},
+		},
+		{
+			name: "json detected",
+			data: ` [{"name": "Bob", "talk": 3, "keynote": true, "room": null}]`,
+			want: []map[string]string{{"name": "Bob", "talk": "3", "keynote": "true", "room": ""}},
+		},
+		{name: "json as csv", data: `[{"name": "Bob"}]`, format: "csv", wantErr: "CSV"},
+		{name: "ragged csv", data: "name,email\nBob\n", wantErr: "wrong number of fields"},
+		{name: "duplicate column", data: "name,name\nBob,Bob\n", wantErr: "given twice"},
+		{name: "nested json value", data: `[{"name": {"first": "Bob"}}]`, wantErr: "row 1"},
+		{name: "json object", data: `{"name": "Bob"}`, format: "json", wantErr: "array of objects"},
+		{name: "unknown format", data: "name\nBob", format: "xlsx", wantErr: "invalid data_format"},
+		{name: "empty", data: " \n", wantErr: "required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseMergeRows(tt.data, tt.format)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("parseMergeRows() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("parseMergeRows() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if !maps.Equal(got[i], tt.want[i]) {
+					t.Errorf("row %d = %v, want %v", i+1, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
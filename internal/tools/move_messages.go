@@ -0,0 +1,75 @@
+package tools
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+
+	"github.com/dastrobu/mail-mcp/internal/jxa"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+//go:embed scripts/move_messages.js
+var moveMessagesSource string
+
+var moveMessagesScript = jxa.Script{Name: "move_messages", Source: moveMessagesSource}
+
+// MoveMessagesInput defines input parameters for move_messages tool
+type MoveMessagesInput struct {
+	Account                string   `json:"account" jsonschema:"Name of the email account the messages are in" long:"account" description:"Name of the email account the messages are in"`
+	MailboxPath            []string `json:"mailboxPath" jsonschema:"Path to the source mailbox as an array (e.g. ['Inbox']). Use the mailboxPath field from get_selected_messages or find_messages. Note: Mailbox names are case-sensitive." long:"mailbox-path" description:"Path to the source mailbox. Can be specified multiple times for nested paths."`
+	MessageIDs             []int    `json:"message_ids" jsonschema:"IDs of the messages to move" long:"message-id" description:"ID of a message to move. Can be specified multiple times."`
+	DestinationAccount     string   `json:"destination_account,omitempty" jsonschema:"Optional: Name of the account of the destination mailbox. Defaults to the source account." long:"destination-account" description:"Name of the account of the destination mailbox. Defaults to the source account."`
+	DestinationMailboxPath []string `json:"destination_mailbox_path" jsonschema:"Path to the destination mailbox as an array (e.g. ['Archive'] or ['Projects', 'Alpha']). Note: Mailbox names are case-sensitive." long:"destination-mailbox-path" description:"Path to the destination mailbox. Can be specified multiple times for nested paths."`
+}
+
+// RegisterMoveMessages registers the move_messages tool with the MCP server
+func RegisterMoveMessages(srv *mcp.Server, executor jxa.Executor) {
+	mcp.AddTool(srv,
+		&mcp.Tool{
+			Name:        "move_messages",
+			Description: "Moves messages from a mailbox to another mailbox, possibly in another account. Reports for each message whether it was moved and its new ID in the destination mailbox. Message IDs change when a message is moved, so use new_id for follow-up calls. new_id is null if the moved message could not be found yet (e.g. while the account syncs).",
+			InputSchema: GenerateSchema[MoveMessagesInput](),
+			Annotations: &mcp.ToolAnnotations{
+				Title:           "Move Messages",
+				ReadOnlyHint:    false,
+				IdempotentHint:  false,
+				DestructiveHint: new(false),
+				OpenWorldHint:   new(true),
+			},
+		},
+		func(ctx context.Context, request *mcp.CallToolRequest, input MoveMessagesInput) (*mcp.CallToolResult, any, error) {
+			return HandleMoveMessages(ctx, executor, request, input)
+		},
+	)
+}
+
+func HandleMoveMessages(ctx context.Context, executor jxa.Executor, request *mcp.CallToolRequest, input MoveMessagesInput) (*mcp.CallToolResult, any, error) {
+	if len(input.MailboxPath) == 0 {
+		return nil, nil, fmt.Errorf("mailboxPath is required and must be a non-empty array")
+	}
+	if len(input.MessageIDs) == 0 {
+		return nil, nil, fmt.Errorf("message_ids is required and must be a non-empty array")
+	}
+	if len(input.DestinationMailboxPath) == 0 {
+		return nil, nil, fmt.Errorf("destination_mailbox_path is required and must be a non-empty array")
+	}
+
+	// Default to moving within the source account
+	if input.DestinationAccount == "" {
+		input.DestinationAccount = input.Account
+	}
+
+	inputJSON, err := json.Marshal(input)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal input for JXA: %w", err)
+	}
+
+	data, err := executor.Execute(ctx, moveMessagesScript, string(inputJSON))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to execute move_messages: %w", err)
+	}
+
+	return nil, data, nil
+}
@@ -0,0 +1,31 @@
+package tools
+
+import (
+	"github.com/dastrobu/mail-mcp/internal/address"
+)
+
+// normalizeRecipients parses the recipients of a compose tool and replaces
+// them with the normalized addresses, without duplicates. Nil lists are left
+// alone, e.g. those a replace tool keeps. Invalid addresses are rejected
+// before any script runs.
+func normalizeRecipients(to, cc, bcc *[]string) (address.Recipients, error) {
+	deref := func(list *[]string) []string {
+		if list == nil {
+			return nil
+		}
+		return *list
+	}
+	recipients, err := address.ParseRecipients(deref(to), deref(cc), deref(bcc))
+	if err != nil {
+		return address.Recipients{}, err
+	}
+	for _, field := range []struct {
+		list      *[]string
+		addresses []address.Address
+	}{{to, recipients.To}, {cc, recipients.Cc}, {bcc, recipients.Bcc}} {
+		if field.list != nil {
+			*field.list = address.Strings(field.addresses)
+		}
+	}
+	return recipients, nil
+}
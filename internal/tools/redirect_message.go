@@ -0,0 +1,78 @@
+package tools
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+
+	"github.com/dastrobu/mail-mcp/internal/jxa"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+//go:embed scripts/redirect_message.js
+var redirectMessageSource string
+
+var redirectMessageScript = jxa.Script{Name: "redirect_message", Source: redirectMessageSource}
+
+type RedirectMessageInput struct {
+	MessageID     int      `json:"message_id" jsonschema:"The ID of the message to redirect" long:"message-id" description:"The ID of the message to redirect"`
+	Account       string   `json:"account" jsonschema:"The name of the account the original message is in" long:"account" description:"The name of the account the original message is in"`
+	MailboxPath   []string `json:"mailbox_path" jsonschema:"The full path to the mailbox of the original message (e.g., [\"Inbox\", \"Subfolder\"])" long:"mailbox-path" description:"The full path to the mailbox of the original message (e.g., [\"Inbox\", \"Subfolder\"]). Can be specified multiple times."`
+	ToRecipients  []string `json:"to_recipients" jsonschema:"List of To recipients" long:"to-recipients" description:"List of To recipients. Can be specified multiple times."`
+	CcRecipients  []string `json:"cc_recipients,omitempty" jsonschema:"List of CC recipients" long:"cc-recipients" description:"List of CC recipients. Can be specified multiple times."`
+	BccRecipients []string `json:"bcc_recipients,omitempty" jsonschema:"List of BCC recipients" long:"bcc-recipients" description:"List of BCC recipients. Can be specified multiple times."`
+	ResolveNames  bool     `json:"resolve_names,omitempty" jsonschema:"Optional: look up recipients given by name instead of address, e.g. 'Maria from finance', like resolve_contacts does. A name that is ambiguous or unknown fails the call with the candidates to choose from." long:"resolve-names" description:"Look up recipients given by name instead of address like resolve_contacts does"`
+}
+
+func RegisterRedirectMessage(srv *mcp.Server, executor jxa.Executor, cfg Config) {
+	mcp.AddTool(srv,
+		&mcp.Tool{
+			Name:        "redirect_message",
+			Description: "Redirects (bounces) a message: opens a new window that resends the original unchanged, keeping its sender, subject, body and attachments, to new recipients. Returns the new Outgoing Message ID. Unlike create_forward, no note can be added and replies go to the original sender.",
+			InputSchema: GenerateSchema[RedirectMessageInput](),
+			Annotations: &mcp.ToolAnnotations{
+				Title:           "Redirect Message",
+				ReadOnlyHint:    false,
+				IdempotentHint:  false,
+				DestructiveHint: new(true), // Creates a new message window
+				OpenWorldHint:   new(true),
+			},
+		},
+		func(ctx context.Context, request *mcp.CallToolRequest, input RedirectMessageInput) (*mcp.CallToolResult, any, error) {
+			return HandleRedirectMessage(ctx, executor, cfg, request, input)
+		},
+	)
+}
+
+func HandleRedirectMessage(ctx context.Context, executor jxa.Executor, cfg Config, request *mcp.CallToolRequest, input RedirectMessageInput) (*mcp.CallToolResult, any, error) {
+	if input.Account == "" || input.MessageID == 0 || len(input.MailboxPath) == 0 || len(input.ToRecipients) == 0 {
+		return nil, nil, fmt.Errorf("account, message_id, mailbox_path, and to_recipients are required")
+	}
+	resolvedNames, err := resolveRecipientNames(ctx, executor, cfg, input.ResolveNames, &input.ToRecipients, &input.CcRecipients, &input.BccRecipients)
+	if err != nil {
+		return nil, nil, err
+	}
+	recipients, err := normalizeRecipients(&input.ToRecipients, &input.CcRecipients, &input.BccRecipients)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	inputJSON, err := json.Marshal(input)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal input for JXA: %w", err)
+	}
+
+	data, err := executor.Execute(ctx, redirectMessageScript, string(inputJSON))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to execute redirect_message: %w", err)
+	}
+
+	if m, ok := data.(map[string]any); ok {
+		m["recipients"] = recipients
+		if len(resolvedNames) > 0 {
+			m["resolved_names"] = resolvedNames
+		}
+	}
+	return nil, data, nil
+}
@@ -0,0 +1,77 @@
+package tools
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/dastrobu/mail-mcp/internal/jxa"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+//go:embed scripts/rename_mailbox.js
+var renameMailboxSource string
+
+var renameMailboxScript = jxa.Script{Name: "rename_mailbox", Source: renameMailboxSource}
+
+// RenameMailboxInput defines input parameters for rename_mailbox tool
+type RenameMailboxInput struct {
+	Account        string   `json:"account" jsonschema:"Name of the email account" long:"account" description:"Name of the email account"`
+	MailboxPath    []string `json:"mailboxPath" jsonschema:"Path to the mailbox to rename or move as an array (e.g. ['Projects', 'Alpha']). Note: Mailbox names are case-sensitive." long:"mailbox-path" description:"Path to the mailbox to rename or move. Can be specified multiple times for nested paths."`
+	NewMailboxPath []string `json:"new_mailbox_path" jsonschema:"New path of the mailbox as an array. Change the last element to rename the mailbox (e.g. ['Projects', 'Beta']), change the elements before it to move the mailbox under another parent (e.g. ['Archive', 'Alpha']). The new parent mailbox must exist." long:"new-mailbox-path" description:"New path of the mailbox. Can be specified multiple times for nested paths."`
+}
+
+// RegisterRenameMailbox registers the rename_mailbox tool with the MCP server
+func RegisterRenameMailbox(srv *mcp.Server, executor jxa.Executor) {
+	mcp.AddTool(srv,
+		&mcp.Tool{
+			Name:        "rename_mailbox",
+			Description: "Renames a mailbox (folder) in Apple Mail or moves it under another parent mailbox of the same account, together with its messages and sub-mailboxes. Fails if a mailbox with the new path already exists. Returns the new mailboxPath for follow-up calls. Messages keep their IDs, but their mailboxPath changes.",
+			InputSchema: GenerateSchema[RenameMailboxInput](),
+			Annotations: &mcp.ToolAnnotations{
+				Title:           "Rename Mailbox",
+				ReadOnlyHint:    false,
+				IdempotentHint:  false,
+				DestructiveHint: new(false),
+				OpenWorldHint:   new(true),
+			},
+		},
+		func(ctx context.Context, request *mcp.CallToolRequest, input RenameMailboxInput) (*mcp.CallToolResult, any, error) {
+			return HandleRenameMailbox(ctx, executor, request, input)
+		},
+	)
+}
+
+func HandleRenameMailbox(ctx context.Context, executor jxa.Executor, request *mcp.CallToolRequest, input RenameMailboxInput) (*mcp.CallToolResult, any, error) {
+	if input.Account == "" {
+		return nil, nil, fmt.Errorf("account is required")
+	}
+	if len(input.MailboxPath) == 0 {
+		return nil, nil, fmt.Errorf("mailboxPath is required and must be a non-empty array")
+	}
+	if err := validateMailboxNames("new_mailbox_path", input.NewMailboxPath); err != nil {
+		return nil, nil, err
+	}
+	if slices.Equal(input.MailboxPath, input.NewMailboxPath) {
+		return nil, nil, fmt.Errorf("new_mailbox_path must differ from mailboxPath")
+	}
+	// A mailbox cannot become its own descendant
+	if len(input.NewMailboxPath) > len(input.MailboxPath) &&
+		slices.Equal(input.NewMailboxPath[:len(input.MailboxPath)], input.MailboxPath) {
+		return nil, nil, fmt.Errorf("new_mailbox_path must not be inside the mailbox being moved")
+	}
+
+	inputJSON, err := json.Marshal(input)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal input for JXA: %w", err)
+	}
+
+	data, err := executor.Execute(ctx, renameMailboxScript, string(inputJSON))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to execute rename_mailbox: %w", err)
+	}
+
+	return nil, data, nil
+}
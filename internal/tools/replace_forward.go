@@ -0,0 +1,161 @@
+package tools
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/dastrobu/mail-mcp/internal/jxa"
+	"github.com/dastrobu/mail-mcp/internal/mac"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+//go:embed scripts/replace_forward.js
+var replaceForwardSource string
+
+var replaceForwardScript = jxa.Script{Name: "replace_forward", Source: replaceForwardSource}
+
+type ReplaceForwardInput struct {
+	OutgoingID  int      `json:"outgoing_id" jsonschema:"The ID of the outgoing forward message to replace" long:"outgoing-id" description:"The ID of the outgoing forward message to replace"`
+	MessageID   int      `json:"message_id" jsonschema:"The ID of the original message to forward" long:"message-id" description:"The ID of the original message to forward"`
+	Account     string   `json:"account" jsonschema:"The account of the original message" long:"account" description:"The account of the original message"`
+	MailboxPath []string `json:"mailbox_path" jsonschema:"The mailbox path of the original message" long:"mailbox-path" description:"The mailbox path of the original message. Can be specified multiple times."`
+
+	ToRecipients  []string `json:"to_recipients" jsonschema:"List of To recipients" long:"to-recipients" description:"List of To recipients. Can be specified multiple times."`
+	CcRecipients  []string `json:"cc_recipients,omitempty" jsonschema:"List of CC recipients" long:"cc-recipients" description:"List of CC recipients. Can be specified multiple times."`
+	BccRecipients []string `json:"bcc_recipients,omitempty" jsonschema:"List of BCC recipients" long:"bcc-recipients" description:"List of BCC recipients. Can be specified multiple times."`
+	ResolveNames  bool     `json:"resolve_names,omitempty" jsonschema:"Optional: look up recipients given by name instead of address, e.g. 'Maria from finance', like resolve_contacts does. A name that is ambiguous or unknown fails the call with the candidates to choose from." long:"resolve-names" description:"Look up recipients given by name instead of address like resolve_contacts does"`
+	Content       string   `json:"content,omitempty" jsonschema:"Optional new note above the forwarded message. Supports Markdown formatting." long:"content" description:"Optional new note above the forwarded message. Supports Markdown formatting."`
+	ContentFormat *string  `json:"content_format,omitempty" jsonschema:"Content format: 'plain' or 'markdown'. Default is 'markdown'." long:"content-format" description:"Content format: 'plain' or 'markdown'. Default is 'markdown'."`
+
+	// Optional overrides for the new forward
+	Subject     *string  `json:"subject,omitempty" jsonschema:"New subject line (optional, keeps the forward subject if null)" long:"subject" description:"New subject line (optional, keeps the forward subject if null)"`
+	Attachments []string `json:"attachments,omitempty" jsonschema:"Absolute paths of local files to attach in addition to the attachments of the original message. Files attached to the replaced message are not kept, so pass them again. The files must be in a directory the server allows attachments from." long:"attachment" description:"Absolute path of a local file to attach. Can be specified multiple times."`
+	Signature   string   `json:"signature,omitempty" jsonschema:"Optional: name of a Mail signature to add (see list_signatures), or 'none' for no signature. By default the Markdown signature of the account in the signature directory is appended to the content, if there is one." long:"signature" description:"Name of a Mail signature to add, or 'none' for no signature. By default the Markdown signature of the account is appended."`
+}
+
+func RegisterReplaceForward(srv *mcp.Server, executor jxa.Executor, cfg Config) {
+	mcp.AddTool(srv,
+		&mcp.Tool{
+			Name:        "replace_forward",
+			Description: "Replaces an existing forward with new recipients and note. Deletes the old forward window, forwards the original message again, and pastes in the new note. NOTE: Mail.app may auto-save messages as drafts. Always check for and delete the old auto-saved draft after replacing. If replacing again, use the new outgoing_id.",
+			InputSchema: GenerateSchema[ReplaceForwardInput](),
+			Annotations: &mcp.ToolAnnotations{
+				Title:           "Replace Forward",
+				ReadOnlyHint:    false,
+				IdempotentHint:  false,
+				DestructiveHint: new(true),
+				OpenWorldHint:   new(true),
+			},
+		},
+		func(ctx context.Context, request *mcp.CallToolRequest, input ReplaceForwardInput) (*mcp.CallToolResult, any, error) {
+			return HandleReplaceForward(ctx, executor, cfg, request, input)
+		},
+	)
+}
+
+func HandleReplaceForward(ctx context.Context, executor jxa.Executor, cfg Config, request *mcp.CallToolRequest, input ReplaceForwardInput) (*mcp.CallToolResult, any, error) {
+	// 1. Input Validation and Setup
+	if input.OutgoingID == 0 || input.MessageID == 0 || input.Account == "" || len(input.MailboxPath) == 0 || len(input.ToRecipients) == 0 {
+		return nil, nil, fmt.Errorf("outgoing_id, message_id, account, mailbox_path, and to_recipients are required")
+	}
+	resolvedNames, err := resolveRecipientNames(ctx, executor, cfg, input.ResolveNames, &input.ToRecipients, &input.CcRecipients, &input.BccRecipients)
+	if err != nil {
+		return nil, nil, err
+	}
+	recipients, err := normalizeRecipients(&input.ToRecipients, &input.CcRecipients, &input.BccRecipients)
+	if err != nil {
+		return nil, nil, err
+	}
+	attachments, err := resolveAttachments(input.Attachments, cfg.AttachmentAllowDirs)
+	if err != nil {
+		return nil, nil, err
+	}
+	input.Attachments = attachments
+	if input.Content != "" {
+		if err := mac.EnsureAccessibility(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	contentFormat, err := ValidateAndNormalizeContentFormat(input.ContentFormat)
+	if err != nil {
+		return nil, nil, err
+	}
+	htmlContent, plainContent, err := ToClipboardContent(input.Content, contentFormat)
+	if err != nil {
+		return nil, nil, err
+	}
+	signature, err := chooseSignature(cfg, input.Account, input.Signature)
+	if err != nil {
+		return nil, nil, err
+	}
+	// A Markdown signature is only added below a note
+	if input.Content == "" {
+		signature.File = nil
+	}
+	htmlContent, plainContent, err = signature.apply(htmlContent, plainContent)
+	if err != nil {
+		return nil, nil, err
+	}
+	input.Signature = signature.Mail
+
+	// 2. Prepare arguments for JXA
+	inputJSON, err := json.Marshal(input)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal input for JXA: %w", err)
+	}
+
+	// 3. Execute JXA to replace the forward
+	resultAny, err := executor.Execute(ctx, replaceForwardScript, string(inputJSON))
+	if err != nil {
+		return nil, nil, fmt.Errorf("JXA execution failed: %w", err)
+	}
+
+	resultMap, ok := resultAny.(map[string]any)
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid JXA result format")
+	}
+
+	// 4. Extract data for pasting
+	newOutgoingID, idOk := resultMap["outgoing_id"].(float64)
+	resultSubject, subjectOk := resultMap["subject"].(string)
+	mailPID, pidOk := resultMap["pid"].(float64)
+
+	if !idOk || !subjectOk || !pidOk {
+		return nil, nil, fmt.Errorf("JXA result is missing required fields (outgoing_id, subject, pid)")
+	}
+
+	// 5. Paste the note into the new forward window
+	if input.Content != "" {
+		if err := mac.PasteIntoWindow(ctx, int(mailPID), resultSubject, 5*time.Second, htmlContent, plainContent); err != nil {
+			return nil, nil, fmt.Errorf("accessibility paste operation failed: %w", err)
+		}
+		time.Sleep(250 * time.Millisecond) // Allow Mail.app to process the paste event.
+	}
+
+	// 6. Return success
+	finalResult := map[string]any{
+		"outgoing_id":           newOutgoingID,
+		"subject":               resultSubject,
+		"forwarded_attachments": resultMap["forwarded_attachments"],
+		"message":               "Forward replaced.",
+	}
+	if input.Content != "" {
+		finalResult["message"] = "Forward replaced and note pasted."
+	}
+	finalResult["recipients"] = recipients
+	if len(resolvedNames) > 0 {
+		finalResult["resolved_names"] = resolvedNames
+	}
+	if len(attachments) > 0 {
+		finalResult["attachments"] = attachments
+	}
+	if s := signature.result(); s != nil {
+		finalResult["signature"] = s
+	}
+
+	return nil, finalResult, nil
+}
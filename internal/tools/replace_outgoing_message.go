@@ -15,7 +15,9 @@ import (
 )
 
 //go:embed scripts/replace_outgoing_message.js
-var replaceOutgoingMessageScript string
+var replaceOutgoingMessageSource string
+
+var replaceOutgoingMessageScript = jxa.Script{Name: "replace_outgoing_message", Source: replaceOutgoingMessageSource}
 
 type ReplaceOutgoingMessageInput struct {
 	OutgoingID    int       `json:"outgoing_id" jsonschema:"The ID of the outgoing message to replace" long:"outgoing-id" description:"The ID of the outgoing message to replace"`
@@ -25,10 +27,13 @@ type ReplaceOutgoingMessageInput struct {
 	ToRecipients  *[]string `json:"to_recipients,omitempty" jsonschema:"New list of To recipients (optional, keeps existing if null, clears if empty array)" long:"to-recipients" description:"New list of To recipients (optional, keeps existing if null, clears if empty array). Can be specified multiple times."`
 	CcRecipients  *[]string `json:"cc_recipients,omitempty" jsonschema:"New list of CC recipients (optional, keeps existing if null, clears if empty array)" long:"cc-recipients" description:"New list of CC recipients (optional, keeps existing if null, clears if empty array). Can be specified multiple times."`
 	BccRecipients *[]string `json:"bcc_recipients,omitempty" jsonschema:"New list of BCC recipients (optional, keeps existing if null, clears if empty array)" long:"bcc-recipients" description:"New list of BCC recipients (optional, keeps existing if null, clears if empty array). Can be specified multiple times."`
+	ResolveNames  bool      `json:"resolve_names,omitempty" jsonschema:"Optional: look up recipients given by name instead of address, e.g. 'Maria from finance', like resolve_contacts does. A name that is ambiguous or unknown fails the call with the candidates to choose from." long:"resolve-names" description:"Look up recipients given by name instead of address like resolve_contacts does"`
 	Sender        *string   `json:"sender,omitempty" jsonschema:"New sender email address (optional, keeps existing if null)" long:"sender" description:"New sender email address (optional, keeps existing if null)"`
+	Attachments   []string  `json:"attachments,omitempty" jsonschema:"Absolute paths of local files to attach. Attachments of the replaced message are not kept, so pass them again. The files must be in a directory the server allows attachments from." long:"attachment" description:"Absolute path of a local file to attach. Can be specified multiple times."`
+	Signature     string    `json:"signature,omitempty" jsonschema:"Optional: name of a Mail signature to add (see list_signatures), or 'none' for no signature. By default the Markdown signature of the account in the signature directory is appended to the content, if there is one." long:"signature" description:"Name of a Mail signature to add, or 'none' for no signature. By default the Markdown signature of the account is appended."`
 }
 
-func RegisterReplaceOutgoingMessage(srv *mcp.Server) {
+func RegisterReplaceOutgoingMessage(srv *mcp.Server, executor jxa.Executor, cfg Config) {
 	mcp.AddTool(srv,
 		&mcp.Tool{
 			Name:        "replace_outgoing_message",
@@ -43,16 +48,29 @@ func RegisterReplaceOutgoingMessage(srv *mcp.Server) {
 			},
 		},
 		func(ctx context.Context, request *mcp.CallToolRequest, input ReplaceOutgoingMessageInput) (*mcp.CallToolResult, any, error) {
-			return HandleReplaceOutgoingMessage(ctx, request, input)
+			return HandleReplaceOutgoingMessage(ctx, executor, cfg, request, input)
 		},
 	)
 }
 
-func HandleReplaceOutgoingMessage(ctx context.Context, request *mcp.CallToolRequest, input ReplaceOutgoingMessageInput) (*mcp.CallToolResult, any, error) {
+func HandleReplaceOutgoingMessage(ctx context.Context, executor jxa.Executor, cfg Config, request *mcp.CallToolRequest, input ReplaceOutgoingMessageInput) (*mcp.CallToolResult, any, error) {
 	// 1. Input Validation and Setup
 	if input.OutgoingID == 0 {
 		return nil, nil, fmt.Errorf("outgoing_id is required")
 	}
+	resolvedNames, err := resolveRecipientNames(ctx, executor, cfg, input.ResolveNames, input.ToRecipients, input.CcRecipients, input.BccRecipients)
+	if err != nil {
+		return nil, nil, err
+	}
+	recipients, err := normalizeRecipients(input.ToRecipients, input.CcRecipients, input.BccRecipients)
+	if err != nil {
+		return nil, nil, err
+	}
+	attachments, err := resolveAttachments(input.Attachments, cfg.AttachmentAllowDirs)
+	if err != nil {
+		return nil, nil, err
+	}
+	input.Attachments = attachments
 	if err := mac.EnsureAccessibility(); err != nil {
 		return nil, nil, err
 	}
@@ -65,6 +83,14 @@ func HandleReplaceOutgoingMessage(ctx context.Context, request *mcp.CallToolRequ
 	if err != nil {
 		return nil, nil, err
 	}
+	// A Mail signature is applied by the script. The Markdown signature
+	// depends on the account of the sender, which is only known afterwards.
+	requestedSignature := input.Signature
+	signature, err := chooseSignature(cfg, "", requestedSignature)
+	if err != nil {
+		return nil, nil, err
+	}
+	input.Signature = signature.Mail
 
 	// 2. Prepare arguments for JXA
 	inputJSON, err := json.Marshal(input)
@@ -73,7 +99,7 @@ func HandleReplaceOutgoingMessage(ctx context.Context, request *mcp.CallToolRequ
 	}
 
 	// 3. Execute JXA to replace the message
-	resultAny, err := jxa.Execute(ctx, replaceOutgoingMessageScript, string(inputJSON))
+	resultAny, err := executor.Execute(ctx, replaceOutgoingMessageScript, string(inputJSON))
 	if err != nil {
 		return nil, nil, fmt.Errorf("JXA execution failed: %w", err)
 	}
@@ -92,6 +118,28 @@ func HandleReplaceOutgoingMessage(ctx context.Context, request *mcp.CallToolRequ
 		return nil, nil, fmt.Errorf("JXA result is missing required fields (outgoing_id, subject, pid)")
 	}
 
+	// Append the Markdown signature of the sender's account. The old message
+	// is already replaced, so if the signature fails, the content is pasted
+	// without it rather than lost.
+	var warning string
+	if requestedSignature == "" {
+		account, _ := resultMap["account"].(string)
+		if err := func() error {
+			fileSignature, err := chooseSignature(cfg, account, "")
+			if err != nil {
+				return err
+			}
+			signedHTML, signedPlain, err := fileSignature.apply(htmlContent, plainContent)
+			if err != nil {
+				return err
+			}
+			signature, htmlContent, plainContent = fileSignature, signedHTML, signedPlain
+			return nil
+		}(); err != nil {
+			warning = fmt.Sprintf("content pasted without the signature: %v", err)
+		}
+	}
+
 	// 5. Paste content into the new message window
 	if err := mac.PasteIntoWindow(ctx, int(mailPID), resultSubject, 5*time.Second, htmlContent, plainContent); err != nil {
 		return nil, nil, fmt.Errorf("accessibility paste operation failed: %w", err)
@@ -105,6 +153,19 @@ func HandleReplaceOutgoingMessage(ctx context.Context, request *mcp.CallToolRequ
 		"subject":     resultSubject,
 		"message":     "Outgoing message replaced and content pasted.",
 	}
+	finalResult["recipients"] = recipients
+	if len(resolvedNames) > 0 {
+		finalResult["resolved_names"] = resolvedNames
+	}
+	if len(attachments) > 0 {
+		finalResult["attachments"] = attachments
+	}
+	if s := signature.result(); s != nil {
+		finalResult["signature"] = s
+	}
+	if warning != "" {
+		finalResult["warning"] = warning
+	}
 
 	return nil, finalResult, nil
 }
@@ -15,7 +15,9 @@ import (
 )
 
 //go:embed scripts/replace_reply.js
-var replaceReplyScript string
+var replaceReplySource string
+
+var replaceReplyScript = jxa.Script{Name: "replace_reply", Source: replaceReplySource}
 
 type ReplaceReplyInput struct {
 	OutgoingID  int      `json:"outgoing_id" jsonschema:"The ID of the outgoing reply message to replace" long:"outgoing-id" description:"The ID of the outgoing reply message to replace"`
@@ -32,9 +34,12 @@ type ReplaceReplyInput struct {
 	ToRecipients  *[]string `json:"to_recipients,omitempty" jsonschema:"New list of To recipients (optional, replaces reply recipients)" long:"to-recipients" description:"New list of To recipients (optional, replaces reply recipients). Can be specified multiple times."`
 	CcRecipients  *[]string `json:"cc_recipients,omitempty" jsonschema:"New list of CC recipients (optional, replaces reply recipients)" long:"cc-recipients" description:"New list of CC recipients (optional, replaces reply recipients). Can be specified multiple times."`
 	BccRecipients *[]string `json:"bcc_recipients,omitempty" jsonschema:"New list of BCC recipients (optional, replaces reply recipients)" long:"bcc-recipients" description:"New list of BCC recipients (optional, replaces reply recipients). Can be specified multiple times."`
+	ResolveNames  bool      `json:"resolve_names,omitempty" jsonschema:"Optional: look up recipients given by name instead of address, e.g. 'Maria from finance', like resolve_contacts does. A name that is ambiguous or unknown fails the call with the candidates to choose from." long:"resolve-names" description:"Look up recipients given by name instead of address like resolve_contacts does"`
+	Attachments   []string  `json:"attachments,omitempty" jsonschema:"Absolute paths of local files to attach. Attachments of the replaced message are not kept, so pass them again. The files must be in a directory the server allows attachments from." long:"attachment" description:"Absolute path of a local file to attach. Can be specified multiple times."`
+	Signature     string    `json:"signature,omitempty" jsonschema:"Optional: name of a Mail signature to add (see list_signatures), or 'none' for no signature. By default the Markdown signature of the account in the signature directory is appended to the content, if there is one." long:"signature" description:"Name of a Mail signature to add, or 'none' for no signature. By default the Markdown signature of the account is appended."`
 }
 
-func RegisterReplaceReply(srv *mcp.Server) {
+func RegisterReplaceReply(srv *mcp.Server, executor jxa.Executor, cfg Config) {
 	mcp.AddTool(srv,
 		&mcp.Tool{
 			Name:        "replace_reply",
@@ -49,16 +54,29 @@ func RegisterReplaceReply(srv *mcp.Server) {
 			},
 		},
 		func(ctx context.Context, request *mcp.CallToolRequest, input ReplaceReplyInput) (*mcp.CallToolResult, any, error) {
-			return HandleReplaceReply(ctx, request, input)
+			return HandleReplaceReply(ctx, executor, cfg, request, input)
 		},
 	)
 }
 
-func HandleReplaceReply(ctx context.Context, request *mcp.CallToolRequest, input ReplaceReplyInput) (*mcp.CallToolResult, any, error) {
+func HandleReplaceReply(ctx context.Context, executor jxa.Executor, cfg Config, request *mcp.CallToolRequest, input ReplaceReplyInput) (*mcp.CallToolResult, any, error) {
 	// 1. Input Validation and Setup
 	if input.OutgoingID == 0 || input.MessageID == 0 || input.Account == "" || len(input.MailboxPath) == 0 {
 		return nil, nil, fmt.Errorf("outgoing_id, message_id, account, and mailbox_path are required")
 	}
+	resolvedNames, err := resolveRecipientNames(ctx, executor, cfg, input.ResolveNames, input.ToRecipients, input.CcRecipients, input.BccRecipients)
+	if err != nil {
+		return nil, nil, err
+	}
+	recipients, err := normalizeRecipients(input.ToRecipients, input.CcRecipients, input.BccRecipients)
+	if err != nil {
+		return nil, nil, err
+	}
+	attachments, err := resolveAttachments(input.Attachments, cfg.AttachmentAllowDirs)
+	if err != nil {
+		return nil, nil, err
+	}
+	input.Attachments = attachments
 	if err := mac.EnsureAccessibility(); err != nil {
 		return nil, nil, err
 	}
@@ -71,6 +89,15 @@ func HandleReplaceReply(ctx context.Context, request *mcp.CallToolRequest, input
 	if err != nil {
 		return nil, nil, err
 	}
+	signature, err := chooseSignature(cfg, input.Account, input.Signature)
+	if err != nil {
+		return nil, nil, err
+	}
+	htmlContent, plainContent, err = signature.apply(htmlContent, plainContent)
+	if err != nil {
+		return nil, nil, err
+	}
+	input.Signature = signature.Mail
 
 	// 2. Prepare arguments for JXA
 	inputJSON, err := json.Marshal(input)
@@ -79,7 +106,7 @@ func HandleReplaceReply(ctx context.Context, request *mcp.CallToolRequest, input
 	}
 
 	// 3. Execute JXA to replace the reply
-	resultAny, err := jxa.Execute(ctx, replaceReplyScript, string(inputJSON))
+	resultAny, err := executor.Execute(ctx, replaceReplyScript, string(inputJSON))
 	if err != nil {
 		return nil, nil, fmt.Errorf("JXA execution failed: %w", err)
 	}
@@ -111,6 +138,16 @@ func HandleReplaceReply(ctx context.Context, request *mcp.CallToolRequest, input
 		"subject":     resultSubject,
 		"message":     "Reply replaced and content pasted.",
 	}
+	finalResult["recipients"] = recipients
+	if len(resolvedNames) > 0 {
+		finalResult["resolved_names"] = resolvedNames
+	}
+	if len(attachments) > 0 {
+		finalResult["attachments"] = attachments
+	}
+	if s := signature.result(); s != nil {
+		finalResult["signature"] = s
+	}
 
 	return nil, finalResult, nil
 }
@@ -0,0 +1,83 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/dastrobu/mail-mcp/internal/contacts"
+	"github.com/dastrobu/mail-mcp/internal/jxa"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ResolveContactsInput defines input parameters for resolve_contacts tool
+type ResolveContactsInput struct {
+	Names []string `json:"names" jsonschema:"Names of people to find addresses for, e.g. 'Maria' or 'Maria from finance'. Words besides the name may match the organization, title, categories or domain." long:"name" description:"Name of a person to find addresses for. Can be specified multiple times."`
+	Days  int      `json:"days,omitempty" jsonschema:"Number of days of mail whose senders and recipients are searched besides the vCards (1-365, default: 90)" long:"days" description:"Number of days of mail whose senders and recipients are searched besides the vCards (1-365, default: 90)"`
+	Limit int      `json:"limit,omitempty" jsonschema:"Maximum number of candidates per name (1-50, default: 5)" long:"limit" description:"Maximum number of candidates per name (1-50, default: 5)"`
+}
+
+// RegisterResolveContacts registers the resolve_contacts tool with the MCP server
+func RegisterResolveContacts(srv *mcp.Server, executor jxa.Executor, cfg Config) {
+	mcp.AddTool(srv,
+		&mcp.Tool{
+			Name:        "resolve_contacts",
+			Description: "Finds the email addresses of people by name in the vCards of the contacts path and in the senders of the Inbox and recipients of the Sent mailboxes of recent mail. Returns the candidates per name ranked by score, with the evidence behind each. A name is resolved only if one person clearly matches; otherwise its status is ambiguous and the candidates are a choice to be made, not to be guessed from.",
+			InputSchema: GenerateSchema[ResolveContactsInput](),
+			Annotations: &mcp.ToolAnnotations{
+				Title:           "Resolve Contacts",
+				ReadOnlyHint:    true,
+				IdempotentHint:  true,
+				DestructiveHint: new(false),
+				OpenWorldHint:   new(true),
+			},
+		},
+		func(ctx context.Context, request *mcp.CallToolRequest, input ResolveContactsInput) (*mcp.CallToolResult, any, error) {
+			return HandleResolveContacts(ctx, executor, cfg, request, input)
+		},
+	)
+}
+
+func HandleResolveContacts(ctx context.Context, executor jxa.Executor, cfg Config, request *mcp.CallToolRequest, input ResolveContactsInput) (*mcp.CallToolResult, any, error) {
+	if len(input.Names) == 0 {
+		return nil, nil, fmt.Errorf("names is required")
+	}
+	for _, name := range input.Names {
+		if strings.TrimSpace(name) == "" {
+			return nil, nil, fmt.Errorf("names must not be empty")
+		}
+	}
+	if input.Days == 0 {
+		input.Days = defaultContactDays
+	}
+	if input.Days < 1 || input.Days > maxContactDays {
+		return nil, nil, fmt.Errorf("days must be between 1 and %d", maxContactDays)
+	}
+	if input.Limit == 0 {
+		input.Limit = 5
+	}
+	if input.Limit < 1 || input.Limit > 50 {
+		return nil, nil, fmt.Errorf("limit must be between 1 and 50")
+	}
+
+	index, err := loadContactIndex(ctx, executor, cfg, input.Days)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	results := make([]contacts.Resolution, len(input.Names))
+	for i, name := range input.Names {
+		results[i] = index.resolver.Resolve(strings.TrimSpace(name), input.Limit)
+	}
+
+	return nil, map[string]any{
+		"results":             results,
+		"contacts_path":       index.path,
+		"contact_count":       len(index.resolver.Contacts),
+		"correspondent_count": len(index.resolver.Correspondents),
+		"message_count":       index.messageCount,
+		"days":                input.Days,
+		"truncated":           index.truncated,
+		"errors":              index.loadErrors,
+	}, nil
+}
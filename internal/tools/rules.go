@@ -0,0 +1,354 @@
+package tools
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/dastrobu/mail-mcp/internal/jxa"
+)
+
+//go:embed scripts/list_rules.js
+var listRulesSource string
+
+var listRulesScript = jxa.Script{Name: "list_rules", Source: listRulesSource}
+
+// Rule is a Mail.app rule in the form the rule tools return it. Conditions
+// and actions that cannot be represented are listed in Unsupported instead
+// of being dropped.
+type Rule struct {
+	Index       int                   `json:"index"`
+	Name        string                `json:"name"`
+	Enabled     bool                  `json:"enabled"`
+	Match       string                `json:"match"`
+	Conditions  []RuleCondition       `json:"conditions"`
+	Actions     RuleActions           `json:"actions"`
+	Unsupported []UnsupportedRulePart `json:"unsupported"`
+}
+
+// RuleCondition is a condition of a rule, e.g. "subject contains invoice".
+type RuleCondition struct {
+	Type      string `json:"type" jsonschema:"Condition type: from, to, cc, to_or_cc, any_recipient, subject, content, account, header, every_message, junk, sender_in_contacts, sender_not_in_contacts or sender_is_vip"`
+	Header    string `json:"header,omitempty" jsonschema:"Name of the header field, required for type header (e.g. List-Id)"`
+	Qualifier string `json:"qualifier,omitempty" jsonschema:"How value is compared: contains, does_not_contain, begins_with, ends_with or equals. Default: contains. Not used by every_message, junk and the sender_ types."`
+	Value     string `json:"value,omitempty" jsonschema:"Value to compare with, e.g. a sender address or a subject fragment. For type account, the name of the account."`
+}
+
+// UnmarshalFlag parses a condition given as JSON on the command line.
+func (c *RuleCondition) UnmarshalFlag(value string) error {
+	return json.Unmarshal([]byte(value), c)
+}
+
+// RuleMailbox is the mailbox a rule moves or copies messages to.
+type RuleMailbox struct {
+	Account     string   `json:"account" jsonschema:"Name of the account of the mailbox"`
+	MailboxPath []string `json:"mailboxPath" jsonschema:"Path to the mailbox as an array (e.g. ['Archive'] or ['Projects', 'Alpha'])"`
+}
+
+// RuleActions are the actions of a rule that the rule tools can represent.
+type RuleActions struct {
+	MoveTo         *RuleMailbox `json:"move_to,omitempty" jsonschema:"Move the message to this mailbox"`
+	CopyTo         *RuleMailbox `json:"copy_to,omitempty" jsonschema:"Copy the message to this mailbox"`
+	MarkRead       bool         `json:"mark_read,omitempty" jsonschema:"Mark the message as read"`
+	FlagIndex      *int         `json:"flag_index,omitempty" jsonschema:"Flag the message with a color (0 red, 1 orange, 2 yellow, 3 green, 4 blue, 5 purple, 6 gray)"`
+	Delete         bool         `json:"delete,omitempty" jsonschema:"Delete the message"`
+	ForwardTo      []string     `json:"forward_to,omitempty" jsonschema:"Forward the message to these addresses"`
+	StopEvaluating bool         `json:"stop_evaluating,omitempty" jsonschema:"Stop evaluating further rules"`
+}
+
+// UnmarshalFlag parses actions given as JSON on the command line.
+func (a *RuleActions) UnmarshalFlag(value string) error {
+	return json.Unmarshal([]byte(value), a)
+}
+
+// empty reports whether no action is set.
+func (a RuleActions) empty() bool {
+	return a.MoveTo == nil && a.CopyTo == nil && !a.MarkRead && a.FlagIndex == nil &&
+		!a.Delete && len(a.ForwardTo) == 0 && !a.StopEvaluating
+}
+
+// UnsupportedRulePart is a condition or action of a rule the rule tools
+// cannot represent, e.g. running an AppleScript.
+type UnsupportedRulePart struct {
+	Part   string `json:"part"`
+	Type   string `json:"type"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// ruleTypes maps condition types to Mail's rule types.
+var ruleTypes = map[string]string{
+	"from":                   "from header",
+	"to":                     "to header",
+	"cc":                     "cc header",
+	"to_or_cc":               "to or cc header",
+	"any_recipient":          "any recipient",
+	"subject":                "subject header",
+	"content":                "message content",
+	"account":                "account",
+	"header":                 "header key",
+	"every_message":          "matches every message",
+	"junk":                   "message is junk mail",
+	"sender_in_contacts":     "sender is in my contacts",
+	"sender_not_in_contacts": "sender is not in my contacts",
+	"sender_is_vip":          "sender is VIP",
+}
+
+// valuelessRuleTypes are the condition types without a value to compare.
+var valuelessRuleTypes = []string{"every_message", "junk", "sender_in_contacts", "sender_not_in_contacts", "sender_is_vip"}
+
+// ruleQualifiers maps qualifiers to Mail's rule qualifiers.
+var ruleQualifiers = map[string]string{
+	"contains":         "does contain value",
+	"does_not_contain": "does not contain value",
+	"begins_with":      "begins with value",
+	"ends_with":        "ends with value",
+	"equals":           "equal to value",
+}
+
+// mailRuleCondition is a rule condition as the scripts read and write it.
+type mailRuleCondition struct {
+	RuleType   string `json:"ruleType"`
+	Qualifier  string `json:"qualifier"`
+	Expression string `json:"expression"`
+	Header     string `json:"header"`
+}
+
+// mailRuleActions are the actions of a rule as the scripts read and write
+// them. The fields after StopEvaluatingRules are only read, to report them
+// as unsupported.
+type mailRuleActions struct {
+	MoveMessage         *RuleMailbox `json:"moveMessage"`
+	CopyMessage         *RuleMailbox `json:"copyMessage"`
+	MarkRead            bool         `json:"markRead"`
+	MarkFlagged         bool         `json:"markFlagged"`
+	MarkFlagIndex       int          `json:"markFlagIndex"`
+	DeleteMessage       bool         `json:"deleteMessage"`
+	ForwardMessage      string       `json:"forwardMessage"`
+	StopEvaluatingRules bool         `json:"stopEvaluatingRules"`
+
+	ForwardText             string `json:"forwardText,omitempty"`
+	RedirectMessage         string `json:"redirectMessage,omitempty"`
+	ReplyText               string `json:"replyText,omitempty"`
+	RunScript               string `json:"runScript,omitempty"`
+	PlaySound               string `json:"playSound,omitempty"`
+	ColorMessage            string `json:"colorMessage,omitempty"`
+	HighlightTextUsingColor bool   `json:"highlightTextUsingColor,omitempty"`
+}
+
+// mailRule is a rule as the scripts read it.
+type mailRule struct {
+	Index                  int                 `json:"index"`
+	Name                   string              `json:"name"`
+	Enabled                bool                `json:"enabled"`
+	AllConditionsMustBeMet bool                `json:"allConditionsMustBeMet"`
+	Conditions             []mailRuleCondition `json:"conditions"`
+	Actions                mailRuleActions     `json:"actions"`
+}
+
+// validateRuleMatch checks whether match is all or any.
+func validateRuleMatch(match string) error {
+	if match != "all" && match != "any" {
+		return fmt.Errorf("match must be all or any, got %q", match)
+	}
+	return nil
+}
+
+// toMailConditions validates conditions and converts them for the scripts.
+// Unsupported types and qualifiers are rejected with the supported ones.
+func toMailConditions(conditions []RuleCondition) ([]mailRuleCondition, error) {
+	if len(conditions) == 0 {
+		return nil, fmt.Errorf("conditions is required and must be a non-empty array")
+	}
+	out := make([]mailRuleCondition, 0, len(conditions))
+	for i, c := range conditions {
+		ruleType, ok := ruleTypes[c.Type]
+		if !ok {
+			return nil, fmt.Errorf("conditions[%d]: unsupported type %q, supported types are %s", i, c.Type, strings.Join(slices.Sorted(maps.Keys(ruleTypes)), ", "))
+		}
+		mc := mailRuleCondition{RuleType: ruleType, Qualifier: "none"}
+		if slices.Contains(valuelessRuleTypes, c.Type) {
+			if c.Value != "" || c.Qualifier != "" || c.Header != "" {
+				return nil, fmt.Errorf("conditions[%d]: type %s takes no qualifier, header or value", i, c.Type)
+			}
+			out = append(out, mc)
+			continue
+		}
+		if c.Value == "" {
+			return nil, fmt.Errorf("conditions[%d]: value is required for type %s", i, c.Type)
+		}
+		if c.Type == "header" && c.Header == "" {
+			return nil, fmt.Errorf("conditions[%d]: header is required for type header", i)
+		}
+		if c.Type != "header" && c.Header != "" {
+			return nil, fmt.Errorf("conditions[%d]: header is only used by type header", i)
+		}
+		if c.Qualifier == "" {
+			c.Qualifier = "contains"
+		}
+		qualifier, ok := ruleQualifiers[c.Qualifier]
+		if !ok {
+			return nil, fmt.Errorf("conditions[%d]: unsupported qualifier %q, supported qualifiers are %s", i, c.Qualifier, strings.Join(slices.Sorted(maps.Keys(ruleQualifiers)), ", "))
+		}
+		mc.Qualifier = qualifier
+		mc.Expression = c.Value
+		mc.Header = c.Header
+		out = append(out, mc)
+	}
+	return out, nil
+}
+
+// toMailActions validates actions and converts them for the scripts. All
+// actions the rule tools represent are set, those not given are turned off.
+func toMailActions(actions RuleActions) (mailRuleActions, error) {
+	if actions.empty() {
+		return mailRuleActions{}, fmt.Errorf("actions must contain at least one action")
+	}
+	for name, mb := range map[string]*RuleMailbox{"move_to": actions.MoveTo, "copy_to": actions.CopyTo} {
+		if mb != nil && (mb.Account == "" || len(mb.MailboxPath) == 0) {
+			return mailRuleActions{}, fmt.Errorf("actions.%s requires account and a non-empty mailboxPath", name)
+		}
+	}
+	out := mailRuleActions{
+		MoveMessage:         actions.MoveTo,
+		CopyMessage:         actions.CopyTo,
+		MarkRead:            actions.MarkRead,
+		MarkFlagIndex:       -1,
+		DeleteMessage:       actions.Delete,
+		ForwardMessage:      strings.Join(actions.ForwardTo, ", "),
+		StopEvaluatingRules: actions.StopEvaluating,
+	}
+	if actions.FlagIndex != nil {
+		if *actions.FlagIndex < 0 || *actions.FlagIndex > 6 {
+			return mailRuleActions{}, fmt.Errorf("actions.flag_index must be between 0 and 6")
+		}
+		out.MarkFlagged = true
+		out.MarkFlagIndex = *actions.FlagIndex
+	}
+	return out, nil
+}
+
+// fromMailRule converts a rule read by a script. Parts without a
+// representation are reported in Unsupported.
+func fromMailRule(r mailRule) Rule {
+	rule := Rule{
+		Index:       r.Index,
+		Name:        r.Name,
+		Enabled:     r.Enabled,
+		Match:       "any",
+		Conditions:  []RuleCondition{},
+		Unsupported: []UnsupportedRulePart{},
+	}
+	if r.AllConditionsMustBeMet {
+		rule.Match = "all"
+	}
+
+	for _, c := range r.Conditions {
+		typ, ok := keyOf(ruleTypes, c.RuleType)
+		if !ok {
+			rule.Unsupported = append(rule.Unsupported, UnsupportedRulePart{Part: "condition", Type: c.RuleType, Detail: conditionDetail(c)})
+			continue
+		}
+		if slices.Contains(valuelessRuleTypes, typ) {
+			rule.Conditions = append(rule.Conditions, RuleCondition{Type: typ})
+			continue
+		}
+		qualifier, ok := keyOf(ruleQualifiers, c.Qualifier)
+		if !ok {
+			rule.Unsupported = append(rule.Unsupported, UnsupportedRulePart{Part: "condition", Type: c.RuleType, Detail: conditionDetail(c)})
+			continue
+		}
+		condition := RuleCondition{Type: typ, Qualifier: qualifier, Value: c.Expression}
+		if typ == "header" {
+			condition.Header = c.Header
+		}
+		rule.Conditions = append(rule.Conditions, condition)
+	}
+
+	a := r.Actions
+	rule.Actions = RuleActions{
+		MoveTo:         a.MoveMessage,
+		CopyTo:         a.CopyMessage,
+		MarkRead:       a.MarkRead,
+		Delete:         a.DeleteMessage,
+		StopEvaluating: a.StopEvaluatingRules,
+	}
+	if a.MarkFlagged {
+		rule.Actions.FlagIndex = new(max(a.MarkFlagIndex, 0))
+	}
+	if a.ForwardMessage != "" {
+		for address := range strings.SplitSeq(a.ForwardMessage, ",") {
+			if address = strings.TrimSpace(address); address != "" {
+				rule.Actions.ForwardTo = append(rule.Actions.ForwardTo, address)
+			}
+		}
+	}
+	for _, part := range []struct{ typ, detail string }{
+		{"forward_text", a.ForwardText},
+		{"redirect", a.RedirectMessage},
+		{"reply", a.ReplyText},
+		{"run_script", a.RunScript},
+		{"play_sound", a.PlaySound},
+		{"color", a.ColorMessage},
+	} {
+		if part.detail != "" {
+			rule.Unsupported = append(rule.Unsupported, UnsupportedRulePart{Part: "action", Type: part.typ, Detail: part.detail})
+		}
+	}
+	if a.HighlightTextUsingColor {
+		rule.Unsupported = append(rule.Unsupported, UnsupportedRulePart{Part: "action", Type: "highlight_text"})
+	}
+	return rule
+}
+
+func keyOf(m map[string]string, value string) (string, bool) {
+	for k, v := range m {
+		if v == value {
+			return k, true
+		}
+	}
+	return "", false
+}
+
+func conditionDetail(c mailRuleCondition) string {
+	var parts []string
+	for _, s := range []string{c.Header, c.Qualifier, c.Expression} {
+		if s != "" && s != "none" {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// fetchRules reads all rules in the order Mail evaluates them.
+func fetchRules(ctx context.Context, executor jxa.Executor) ([]Rule, error) {
+	data, err := executor.Execute(ctx, listRulesScript, "{}")
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute list_rules: %w", err)
+	}
+	var result struct {
+		Rules []mailRule `json:"rules"`
+	}
+	if err := remarshal(data, &result); err != nil {
+		return nil, err
+	}
+	rules := make([]Rule, 0, len(result.Rules))
+	for _, r := range result.Rules {
+		rules = append(rules, fromMailRule(r))
+	}
+	return rules, nil
+}
+
+// ruleResult converts the rule a script returns after changing it.
+func ruleResult(data any) (Rule, error) {
+	var result struct {
+		Rule mailRule `json:"rule"`
+	}
+	if err := remarshal(data, &result); err != nil {
+		return Rule{}, err
+	}
+	return fromMailRule(result.Rule), nil
+}
@@ -0,0 +1,108 @@
+package tools
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestToMailConditions(t *testing.T) {
+	got, err := toMailConditions([]RuleCondition{
+		{Type: "from", Value: "billing@example.com"},
+		{Type: "header", Header: "List-Id", Qualifier: "ends_with", Value: "lists.example.com>"},
+		{Type: "junk"},
+	})
+	if err != nil {
+		t.Fatalf("toMailConditions() error = %v", err)
+	}
+	want := []mailRuleCondition{
+		{RuleType: "from header", Qualifier: "does contain value", Expression: "billing@example.com"},
+		{RuleType: "header key", Qualifier: "ends with value", Expression: "lists.example.com>", Header: "List-Id"},
+		{RuleType: "message is junk mail", Qualifier: "none"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("toMailConditions() = %+v, want %+v", got, want)
+	}
+
+	errors := []struct {
+		name       string
+		conditions []RuleCondition
+		want       string
+	}{
+		{name: "none", want: "conditions is required"},
+		{name: "unknown type", conditions: []RuleCondition{{Type: "sender_in_group", Value: "x"}}, want: `unsupported type "sender_in_group", supported types are account, any_recipient`},
+		{name: "unknown qualifier", conditions: []RuleCondition{{Type: "subject", Qualifier: "matches", Value: "x"}}, want: `unsupported qualifier "matches"`},
+		{name: "missing value", conditions: []RuleCondition{{Type: "subject"}}, want: "value is required"},
+		{name: "missing header", conditions: []RuleCondition{{Type: "header", Value: "x"}}, want: "header is required"},
+		{name: "header for other type", conditions: []RuleCondition{{Type: "to", Header: "X", Value: "x"}}, want: "header is only used by type header"},
+		{name: "value for valueless type", conditions: []RuleCondition{{Type: "every_message", Value: "x"}}, want: "takes no qualifier"},
+	}
+	for _, tt := range errors {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := toMailConditions(tt.conditions); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("toMailConditions() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestToMailActions(t *testing.T) {
+	got, err := toMailActions(RuleActions{MarkRead: true, FlagIndex: new(3), ForwardTo: []string{"a@example.com", "b@example.com"}})
+	if err != nil {
+		t.Fatalf("toMailActions() error = %v", err)
+	}
+	want := mailRuleActions{MarkRead: true, MarkFlagged: true, MarkFlagIndex: 3, ForwardMessage: "a@example.com, b@example.com"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("toMailActions() = %+v, want %+v", got, want)
+	}
+	if got, _ := toMailActions(RuleActions{Delete: true}); got.MarkFlagged || got.MarkFlagIndex != -1 {
+		t.Errorf("toMailActions() without flag = %+v, want flag turned off", got)
+	}
+
+	for _, actions := range []RuleActions{{}, {FlagIndex: new(7)}, {MoveTo: &RuleMailbox{Account: "Work"}}} {
+		if _, err := toMailActions(actions); err == nil {
+			t.Errorf("toMailActions(%+v) error = nil, want error", actions)
+		}
+	}
+}
+
+func TestFromMailRule(t *testing.T) {
+	rule := fromMailRule(mailRule{
+		Index:   2,
+		Name:    "Newsletters",
+		Enabled: true,
+		Conditions: []mailRuleCondition{
+			{RuleType: "header key", Qualifier: "does contain value", Expression: "news", Header: "List-Id"},
+			{RuleType: "sender is member of group", Qualifier: "none", Expression: "Friends"},
+			{RuleType: "matches every message", Qualifier: "none"},
+		},
+		Actions: mailRuleActions{
+			MoveMessage:    &RuleMailbox{Account: "Work", MailboxPath: []string{"News"}},
+			MarkFlagged:    true,
+			MarkFlagIndex:  4,
+			ForwardMessage: "a@example.com, b@example.com",
+			RunScript:      "/Users/me/Library/Application Scripts/com.apple.mail/notify.scpt",
+		},
+	})
+
+	if rule.Match != "any" || rule.Index != 2 {
+		t.Errorf("Match, Index = %q, %d, want any, 2", rule.Match, rule.Index)
+	}
+	wantConditions := []RuleCondition{
+		{Type: "header", Header: "List-Id", Qualifier: "contains", Value: "news"},
+		{Type: "every_message"},
+	}
+	if !reflect.DeepEqual(rule.Conditions, wantConditions) {
+		t.Errorf("Conditions = %+v, want %+v", rule.Conditions, wantConditions)
+	}
+	if rule.Actions.MoveTo == nil || *rule.Actions.FlagIndex != 4 || !reflect.DeepEqual(rule.Actions.ForwardTo, []string{"a@example.com", "b@example.com"}) {
+		t.Errorf("Actions = %+v", rule.Actions)
+	}
+	wantUnsupported := []UnsupportedRulePart{
+		{Part: "condition", Type: "sender is member of group", Detail: "Friends"},
+		{Part: "action", Type: "run_script", Detail: "/Users/me/Library/Application Scripts/com.apple.mail/notify.scpt"},
+	}
+	if !reflect.DeepEqual(rule.Unsupported, wantUnsupported) {
+		t.Errorf("Unsupported = %+v, want %+v", rule.Unsupported, wantUnsupported)
+	}
+}
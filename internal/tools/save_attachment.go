@@ -0,0 +1,157 @@
+package tools
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dastrobu/mail-mcp/internal/jxa"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+//go:embed scripts/save_attachment.js
+var saveAttachmentSource string
+
+var saveAttachmentScript = jxa.Script{Name: "save_attachment", Source: saveAttachmentSource}
+
+// SaveAttachmentInput defines input parameters for save_attachment tool
+type SaveAttachmentInput struct {
+	Account      string   `json:"account" jsonschema:"Name of the email account" long:"account" description:"Name of the email account"`
+	MailboxPath  []string `json:"mailboxPath" jsonschema:"Path to the mailbox as an array (e.g. ['Inbox']). Use the mailboxPath field from get_selected_messages or find_messages. Note: Mailbox names are case-sensitive." long:"mailbox-path" description:"Path to the mailbox. Can be specified multiple times for nested paths."`
+	MessageID    int      `json:"message_id" jsonschema:"The unique ID of the message" long:"message-id" description:"The unique ID of the message"`
+	AttachmentID string   `json:"attachment_id" jsonschema:"ID of the attachment as returned by list_attachments" long:"attachment-id" description:"ID of the attachment as returned by list_attachments"`
+	Inline       bool     `json:"inline,omitempty" jsonschema:"Also return the content of text-like attachments (txt, csv, ics, eml) in the result" long:"inline" description:"Also return the content of text-like attachments (txt, csv, ics, eml)"`
+}
+
+// RegisterSaveAttachment registers the save_attachment tool with the MCP server
+func RegisterSaveAttachment(srv *mcp.Server, executor jxa.Executor, cfg Config) {
+	mcp.AddTool(srv,
+		&mcp.Tool{
+			Name:        "save_attachment",
+			Description: "Saves an attachment of a message to the attachments directory configured for the server and returns the path of the file. Existing files are not overwritten. With inline=true, the content of text-like attachments (txt, csv, ics, eml) is returned as well.",
+			InputSchema: GenerateSchema[SaveAttachmentInput](),
+			Annotations: &mcp.ToolAnnotations{
+				Title:           "Save Attachment",
+				ReadOnlyHint:    false,
+				IdempotentHint:  false,
+				DestructiveHint: new(false),
+				OpenWorldHint:   new(true),
+			},
+		},
+		func(ctx context.Context, request *mcp.CallToolRequest, input SaveAttachmentInput) (*mcp.CallToolResult, any, error) {
+			return HandleSaveAttachment(ctx, executor, cfg, request, input)
+		},
+	)
+}
+
+func HandleSaveAttachment(ctx context.Context, executor jxa.Executor, cfg Config, request *mcp.CallToolRequest, input SaveAttachmentInput) (*mcp.CallToolResult, any, error) {
+	if cfg.AttachmentsDir == "" {
+		return nil, nil, fmt.Errorf("save_attachment is disabled: no attachments directory configured (set --attachments-dir or APPLE_MAIL_MCP_ATTACHMENTS_DIR)")
+	}
+	if len(input.MailboxPath) == 0 {
+		return nil, nil, fmt.Errorf("mailboxPath is required and must be a non-empty array")
+	}
+	if input.AttachmentID == "" {
+		return nil, nil, fmt.Errorf("attachment_id is required")
+	}
+
+	dir, err := filepath.Abs(cfg.AttachmentsDir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid attachments directory: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, nil, fmt.Errorf("failed to create attachments directory: %w", err)
+	}
+
+	// Mail.app saves into a fresh directory, so whatever name it uses, the
+	// file cannot replace anything. It is moved to its final place below.
+	tmp, err := os.MkdirTemp(dir, ".save-")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	inputJSON, err := json.Marshal(struct {
+		SaveAttachmentInput
+		Directory string `json:"directory"`
+	}{input, tmp})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal input for JXA: %w", err)
+	}
+
+	data, err := executor.Execute(ctx, saveAttachmentScript, string(inputJSON))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to execute save_attachment: %w", err)
+	}
+
+	result, ok := data.(map[string]any)
+	if !ok {
+		return nil, nil, fmt.Errorf("unexpected result from save_attachment: %v", data)
+	}
+	savedPath, _ := result["path"].(string)
+	if filepath.Dir(savedPath) != tmp {
+		return nil, nil, fmt.Errorf("attachment was saved outside the attachments directory: %q", savedPath)
+	}
+	info, err := os.Lstat(savedPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("saved attachment not found: %w", err)
+	}
+	if !info.Mode().IsRegular() {
+		return nil, nil, fmt.Errorf("saved attachment is not a regular file: %q", savedPath)
+	}
+
+	path, err := moveToUniquePath(savedPath, dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	result["path"] = path
+	result["size"] = info.Size()
+
+	name, _ := result["name"].(string)
+	if t, _ := result["mimeType"].(string); t == "" {
+		result["mimeType"] = attachmentMIMEType(name)
+	}
+
+	if input.Inline && isTextAttachment(name) {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read saved attachment: %w", err)
+		}
+		truncated := len(content) > maxInlineAttachmentSize
+		if truncated {
+			content = content[:maxInlineAttachmentSize]
+		}
+		// A character cut in half by truncation becomes a replacement character
+		result["content"] = strings.ToValidUTF8(string(content), "\uFFFD")
+		result["content_truncated"] = truncated
+	}
+
+	return nil, result, nil
+}
+
+// moveToUniquePath moves the file at src into dir under its own name, adding
+// a counter to the name if a file of that name already exists.
+func moveToUniquePath(src, dir string) (string, error) {
+	name := filepath.Base(src)
+	ext := filepath.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	for i := 0; ; i++ {
+		candidate := name
+		if i > 0 {
+			candidate = fmt.Sprintf("%s (%d)%s", stem, i, ext)
+		}
+		dst := filepath.Join(dir, candidate)
+		// Link fails if dst exists, so an existing file is never replaced
+		if err := os.Link(src, dst); err != nil {
+			if os.IsExist(err) {
+				continue
+			}
+			return "", fmt.Errorf("failed to move saved attachment: %w", err)
+		}
+		return dst, nil
+	}
+}
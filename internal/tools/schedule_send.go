@@ -0,0 +1,104 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dastrobu/mail-mcp/internal/jxa"
+	"github.com/dastrobu/mail-mcp/internal/schedule"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type ScheduleSendInput struct {
+	OutgoingID int    `json:"outgoing_id" jsonschema:"The ID of the outgoing message to send" long:"outgoing-id" description:"The ID of the outgoing message to send"`
+	SendAt     string `json:"send_at,omitempty" jsonschema:"When to send the message, as RFC 3339 timestamp (e.g. 2024-02-12T09:00:00+01:00). Default: after the undo delay configured for the server." long:"send-at" description:"When to send the message, as RFC 3339 timestamp. Default: after the undo delay."`
+}
+
+// RegisterScheduleSend registers the schedule_send tool with the MCP server
+func RegisterScheduleSend(srv *mcp.Server, executor jxa.Executor, cfg Config) {
+	mcp.AddTool(srv,
+		&mcp.Tool{
+			Name:        "schedule_send",
+			Description: "Queues an outgoing message to be sent at a given time, or after the undo delay of the server if no time is given. Until then it can be canceled with cancel_scheduled. The recipients are checked against the send policy now and again right before sending, see send_outgoing_message. The queue survives server restarts, but outgoing messages only exist while Mail.app is running. Disabled unless the server was started with --enable-send.",
+			InputSchema: GenerateSchema[ScheduleSendInput](),
+			Annotations: &mcp.ToolAnnotations{
+				Title:           "Schedule Send",
+				ReadOnlyHint:    false,
+				IdempotentHint:  false,
+				DestructiveHint: new(true),
+				OpenWorldHint:   new(true),
+			},
+		},
+		func(ctx context.Context, request *mcp.CallToolRequest, input ScheduleSendInput) (*mcp.CallToolResult, any, error) {
+			return HandleScheduleSend(ctx, executor, cfg, schedule.SystemClock, request, input)
+		},
+	)
+}
+
+func HandleScheduleSend(ctx context.Context, executor jxa.Executor, cfg Config, clock schedule.Clock, request *mcp.CallToolRequest, input ScheduleSendInput) (*mcp.CallToolResult, any, error) {
+	if !cfg.EnableSend {
+		return nil, nil, fmt.Errorf("schedule_send is disabled: start the server with --enable-send (or APPLE_MAIL_MCP_ENABLE_SEND=true) to allow sending")
+	}
+	if input.OutgoingID == 0 {
+		return nil, nil, fmt.Errorf("outgoing_id is required")
+	}
+
+	now := clock.Now()
+	sendAt := now.Add(defaultUndoSendDelay)
+	if cfg.UndoSendDelay > 0 {
+		sendAt = now.Add(cfg.UndoSendDelay)
+	}
+	if input.SendAt != "" {
+		t, err := time.Parse(time.RFC3339, input.SendAt)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid send_at %q: must be an RFC 3339 timestamp like 2024-02-12T09:00:00Z", input.SendAt)
+		}
+		if t.Before(now) {
+			return nil, nil, fmt.Errorf("send_at %s is in the past", input.SendAt)
+		}
+		sendAt = t
+	}
+	store, err := scheduleStore(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Check the policy now, so a message that could not be sent is not
+	// queued
+	msg, err := fetchOutgoingMessage(ctx, executor, input.OutgoingID)
+	if err != nil {
+		return nil, nil, err
+	}
+	decision := sendPolicy(cfg).Check(msg.Sender, msg.ToRecipients, msg.CcRecipients, msg.BccRecipients)
+	if !decision.Allowed {
+		return nil, map[string]any{
+			"outgoing_id": msg.OutgoingID,
+			"subject":     msg.Subject,
+			"scheduled":   false,
+			"policy":      decision,
+			"message":     "Not scheduled, the message violates the send policy: " + strings.Join(decision.Violations(), "; "),
+		}, nil
+	}
+
+	entry, err := store.Add(schedule.Entry{
+		OutgoingID: msg.OutgoingID,
+		Subject:    msg.Subject,
+		Recipients: slices.Concat(msg.ToRecipients, msg.CcRecipients, msg.BccRecipients),
+		SendAt:     sendAt.UTC(),
+	}, now)
+	if err != nil {
+		return nil, nil, err
+	}
+	return nil, map[string]any{
+		"outgoing_id": msg.OutgoingID,
+		"subject":     msg.Subject,
+		"scheduled":   true,
+		"schedule_id": entry.ID,
+		"send_at":     entry.SendAt.Format(time.RFC3339),
+		"policy":      decision,
+		"message":     fmt.Sprintf("Message scheduled to be sent at %s. Cancel it with cancel_scheduled before then to undo.", entry.SendAt.Format(time.RFC3339)),
+	}, nil
+}
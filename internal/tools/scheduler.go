@@ -0,0 +1,76 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dastrobu/mail-mcp/internal/jxa"
+	"github.com/dastrobu/mail-mcp/internal/schedule"
+)
+
+// defaultUndoSendDelay is the delay before a message scheduled without a
+// send time is sent if none is configured.
+const defaultUndoSendDelay = 30 * time.Second
+
+// scheduleStore returns the store of the schedule file configured by cfg.
+func scheduleStore(cfg Config) (*schedule.Store, error) {
+	path := cfg.ScheduleFile
+	if path == "" {
+		var err error
+		path, err = appSupportPath("schedule.json")
+		if err != nil {
+			return nil, err
+		}
+	}
+	return schedule.NewStore(path), nil
+}
+
+// NewScheduler returns the scheduler sending the messages queued with
+// schedule_send. Like send_outgoing_message, it checks every message against
+// the send policy of cfg right before sending it. Outgoing message IDs only
+// last as long as Mail.app runs, so a message is only sent if its subject and
+// recipients are still those that were scheduled.
+func NewScheduler(executor jxa.Executor, cfg Config, clock schedule.Clock) (*schedule.Scheduler, error) {
+	store, err := scheduleStore(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &schedule.Scheduler{
+		Store: store,
+		Clock: clock,
+		Send: func(ctx context.Context, e schedule.Entry) error {
+			result, err := sendOutgoing(ctx, executor, cfg, e.OutgoingID, func(msg outgoingMessage) error {
+				return matchScheduled(msg, e)
+			})
+			if err != nil {
+				return err
+			}
+			if result["sent"] != true {
+				return fmt.Errorf("%s", result["message"])
+			}
+			return nil
+		},
+	}, nil
+}
+
+// matchScheduled checks that msg is the message scheduled as e, not another
+// one that got its ID after Mail.app restarted.
+func matchScheduled(msg outgoingMessage, e schedule.Entry) error {
+	normalize := func(recipients []string) []string {
+		out := make([]string, len(recipients))
+		for i, r := range recipients {
+			out[i] = strings.ToLower(strings.TrimSpace(r))
+		}
+		slices.Sort(out)
+		return out
+	}
+	recipients := slices.Concat(msg.ToRecipients, msg.CcRecipients, msg.BccRecipients)
+	if msg.Subject != e.Subject || !slices.Equal(normalize(recipients), normalize(e.Recipients)) {
+		return fmt.Errorf("not sent: outgoing message %d is no longer the scheduled message (subject %q to %s, scheduled %q to %s), Mail.app may have been restarted",
+			e.OutgoingID, msg.Subject, strings.Join(recipients, ", "), e.Subject, strings.Join(e.Recipients, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+#!/usr/bin/osascript -l JavaScript
+
+/**
+ * Creates a mailbox, including missing parent mailboxes
+ *
+ * Arguments:
+ *   argv[0] - JSON string containing:
+ *     - account (required)
+ *     - mailboxPath (required) - path of the new mailbox, e.g. ["Projects"]
+ *
+ * Mail creates nested mailboxes from a name with "/" separated levels. The new
+ * mailbox is looked up by its path afterwards to verify it was created.
+ */
+
+function run(argv) {
+  const Mail = Application("Mail");
+  Mail.includeStandardAdditions = true;
+
+  // Check if Mail.app is running
+  if (!Mail.running()) {
+    return JSON.stringify({
+      success: false,
+      error: "Mail.app is not running. Please start Mail.app and try again.",
+      errorCode: "MAIL_APP_NOT_RUNNING",
+    });
+  }
+
+  // Collect logs instead of using console.log
+  const logs = [];
+
+  // Helper function to log messages
+  function log(message) {
+    logs.push(message);
+  }
+
+  // Parse arguments
+  let args;
+  try {
+    args = JSON.parse(argv[0]);
+  } catch (e) {
+    return JSON.stringify({
+      success: false,
+      error: "Failed to parse input arguments JSON",
+    });
+  }
+
+  const accountName = args.account || "";
+  const mailboxPath = args.mailboxPath || [];
+
+  if (!accountName) {
+    return JSON.stringify({
+      success: false,
+      error: "Account name is required",
+    });
+  }
+
+  if (!Array.isArray(mailboxPath) || mailboxPath.length === 0) {
+    return JSON.stringify({
+      success: false,
+      error: "Mailbox path is required and must be a non-empty array",
+    });
+  }
+
+  try {
+    const targetAccount = Mail.accounts[accountName];
+    try {
+      targetAccount.name();
+    } catch (e) {
+      return JSON.stringify({
+        success: false,
+        error: `Account "${accountName}" not found. Please verify the account name is correct.`,
+      });
+    }
+
+    // Robust mailbox traversal function
+    function findMailboxByPath(account, targetPath) {
+      if (!targetPath || targetPath.length === 0) return account;
+
+      try {
+        let current = account;
+        for (let i = 0; i < targetPath.length; i++) {
+          const part = targetPath[i];
+          let next = null;
+          try {
+            next = current.mailboxes.whose({ name: part })()[0];
+          } catch (e) {}
+
+          if (!next) {
+            try {
+              next = current.mailboxes[part];
+              next.name();
+            } catch (e) {}
+          }
+          if (!next) throw new Error("not found");
+          current = next;
+        }
+        return current;
+      } catch (e) {}
+
+      try {
+        const allMailboxes = account.mailboxes();
+        for (let i = 0; i < allMailboxes.length; i++) {
+          const mbx = allMailboxes[i];
+          const path = [];
+          let current = mbx;
+          while (current) {
+            try {
+              const name = current.name();
+              if (name === account.name()) break;
+              path.unshift(name);
+              current = current.container();
+            } catch (e) {
+              break;
+            }
+          }
+          if (path.length === targetPath.length) {
+            let match = true;
+            for (let j = 0; j < path.length; j++) {
+              if (path[j] !== targetPath[j]) {
+                match = false;
+                break;
+              }
+            }
+            if (match) return mbx;
+          }
+        }
+      } catch (e) {}
+      return null;
+    }
+
+    if (findMailboxByPath(targetAccount, mailboxPath)) {
+      return JSON.stringify({
+        success: false,
+        error: `Mailbox path '${mailboxPath.join(" > ")}' already exists in account '${accountName}'.`,
+        errorCode: "MAILBOX_EXISTS",
+      });
+    }
+
+    // Remember which parents exist to report the ones created on the way
+    let existingDepth = 0;
+    while (
+      existingDepth < mailboxPath.length - 1 &&
+      findMailboxByPath(targetAccount, mailboxPath.slice(0, existingDepth + 1))
+    ) {
+      existingDepth++;
+    }
+
+    const newMailbox = Mail.Mailbox({ name: mailboxPath.join("/") });
+    targetAccount.mailboxes.push(newMailbox);
+
+    if (!findMailboxByPath(targetAccount, mailboxPath)) {
+      return JSON.stringify({
+        success: false,
+        error: `Mailbox path '${mailboxPath.join(" > ")}' could not be found after creating it in account '${accountName}'. The account may still be syncing.`,
+        errorCode: "MAILBOX_NOT_CREATED",
+        logs: logs.join("\n"),
+      });
+    }
+
+    const createdPaths = [];
+    for (let i = existingDepth + 1; i <= mailboxPath.length; i++) {
+      createdPaths.push(mailboxPath.slice(0, i));
+    }
+    log(`Created ${createdPaths.length} mailbox(es)`);
+
+    return JSON.stringify({
+      success: true,
+      data: {
+        account: accountName,
+        mailboxPath: mailboxPath,
+        name: mailboxPath[mailboxPath.length - 1],
+        createdMailboxPaths: createdPaths,
+      },
+      logs: logs.join("\n"),
+    });
+  } catch (e) {
+    return JSON.stringify({
+      success: false,
+      error: `Failed to create mailbox: ${e.toString()}`,
+      logs: logs.join("\n"),
+    });
+  }
+}
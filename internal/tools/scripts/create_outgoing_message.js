@@ -35,6 +35,7 @@ function run(argv) {
   const toList = args.to_recipients || [];
   const ccList = args.cc_recipients || [];
   const bccList = args.bcc_recipients || [];
+  const signatureName = args.signature || "";
 
   log(`Received arguments: account='${accountName}', subject='${subject}'`);
 
@@ -49,6 +50,20 @@ function run(argv) {
 
   // 4. Execution wrapped in try/catch
   try {
+    // Resolve the Mail signature before creating the message
+    let signature = null;
+    if (signatureName) {
+      if (Mail.signatures.name().indexOf(signatureName) === -1) {
+        return JSON.stringify({
+          success: false,
+          error: `Signature '${signatureName}' not found. Use list_signatures to see the available signatures.`,
+          errorCode: "SIGNATURE_NOT_FOUND",
+          logs: logs.join("\n"),
+        });
+      }
+      signature = Mail.signatures.byName(signatureName);
+    }
+
     const accounts = Mail.accounts.whose({ name: accountName })();
     if (accounts.length === 0) {
       return JSON.stringify({
@@ -89,6 +104,22 @@ function run(argv) {
       );
     }
 
+    // Add attachments. The Go layer has checked that the files exist and
+    // are in an allowed directory.
+    const attachmentPaths = args.attachments || [];
+    attachmentPaths.forEach((path) => {
+      msg.content.attachments.push(
+        Mail.Attachment({ fileName: Path(path) }),
+      );
+      log(`Attached file: ${path}`);
+    });
+
+    // Mail places the signature below the pasted content
+    if (signature) {
+      msg.messageSignature = signature;
+      log(`Applied signature: ${signatureName}`);
+    }
+
     // NOTE: We are NOT saving the message here. It exists as an open window (OutgoingMessage).
     // This allows the user to decide whether to save it later (e.g. via replace_outgoing_message or manual action).
 
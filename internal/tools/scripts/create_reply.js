@@ -34,6 +34,7 @@ function run(argv) {
   const messageId = parseInt(args.message_id, 10) || 0;
   const mailboxPath = args.mailbox_path || [];
   const replyToAll = args.reply_to_all === true;
+  const signatureName = args.signature || "";
 
   log(
     `Received arguments: account='${accountName}', messageId=${messageId}, replyToAll=${replyToAll}, path='${JSON.stringify(mailboxPath)}'`,
@@ -59,6 +60,20 @@ function run(argv) {
 
   // 4. Execution wrapped in try/catch
   try {
+    // Resolve the Mail signature before creating the message
+    let signature = null;
+    if (signatureName) {
+      if (Mail.signatures.name().indexOf(signatureName) === -1) {
+        return JSON.stringify({
+          success: false,
+          error: `Signature '${signatureName}' not found. Use list_signatures to see the available signatures.`,
+          errorCode: "SIGNATURE_NOT_FOUND",
+          logs: logs.join("\n"),
+        });
+      }
+      signature = Mail.signatures.byName(signatureName);
+    }
+
     const accounts = Mail.accounts.whose({ name: accountName })();
     if (accounts.length === 0) {
       return JSON.stringify({
@@ -158,6 +173,22 @@ function run(argv) {
       replyToAll: replyToAll,
     });
 
+    // Add attachments. The Go layer has checked that the files exist and
+    // are in an allowed directory.
+    const attachmentPaths = args.attachments || [];
+    attachmentPaths.forEach((path) => {
+      replyMessage.content.attachments.push(
+        Mail.Attachment({ fileName: Path(path) }),
+      );
+      log(`Attached file: ${path}`);
+    });
+
+    // Mail places the signature below the pasted content
+    if (signature) {
+      replyMessage.messageSignature = signature;
+      log(`Applied signature: ${signatureName}`);
+    }
+
     // NOTE: We are NOT saving the reply. It exists as an open window (OutgoingMessage).
     log("Reply message window created.");
 
@@ -0,0 +1,188 @@
+#!/usr/bin/osascript -l JavaScript
+
+/**
+ * Deletes a mailbox
+ *
+ * Arguments:
+ *   argv[0] - JSON string containing:
+ *     - account (required)
+ *     - mailboxPath (required) - path of the mailbox, e.g. ["Projects"]
+ *     - force (optional) - delete a mailbox with messages or sub-mailboxes
+ *
+ * Without force, a mailbox is only deleted if neither it nor any of its
+ * sub-mailboxes contain messages and it has no sub-mailboxes.
+ */
+
+function run(argv) {
+  const Mail = Application("Mail");
+  Mail.includeStandardAdditions = true;
+
+  // Check if Mail.app is running
+  if (!Mail.running()) {
+    return JSON.stringify({
+      success: false,
+      error: "Mail.app is not running. Please start Mail.app and try again.",
+      errorCode: "MAIL_APP_NOT_RUNNING",
+    });
+  }
+
+  // Collect logs instead of using console.log
+  const logs = [];
+
+  // Helper function to log messages
+  function log(message) {
+    logs.push(message);
+  }
+
+  // Parse arguments
+  let args;
+  try {
+    args = JSON.parse(argv[0]);
+  } catch (e) {
+    return JSON.stringify({
+      success: false,
+      error: "Failed to parse input arguments JSON",
+    });
+  }
+
+  const accountName = args.account || "";
+  const mailboxPath = args.mailboxPath || [];
+  const force = args.force === true;
+
+  if (!accountName) {
+    return JSON.stringify({
+      success: false,
+      error: "Account name is required",
+    });
+  }
+
+  if (!Array.isArray(mailboxPath) || mailboxPath.length === 0) {
+    return JSON.stringify({
+      success: false,
+      error: "Mailbox path is required and must be a non-empty array",
+    });
+  }
+
+  try {
+    const targetAccount = Mail.accounts[accountName];
+    try {
+      targetAccount.name();
+    } catch (e) {
+      return JSON.stringify({
+        success: false,
+        error: `Account "${accountName}" not found. Please verify the account name is correct.`,
+      });
+    }
+
+    // Robust mailbox traversal function
+    function findMailboxByPath(account, targetPath) {
+      if (!targetPath || targetPath.length === 0) return account;
+
+      try {
+        let current = account;
+        for (let i = 0; i < targetPath.length; i++) {
+          const part = targetPath[i];
+          let next = null;
+          try {
+            next = current.mailboxes.whose({ name: part })()[0];
+          } catch (e) {}
+
+          if (!next) {
+            try {
+              next = current.mailboxes[part];
+              next.name();
+            } catch (e) {}
+          }
+          if (!next) throw new Error("not found");
+          current = next;
+        }
+        return current;
+      } catch (e) {}
+
+      try {
+        const allMailboxes = account.mailboxes();
+        for (let i = 0; i < allMailboxes.length; i++) {
+          const mbx = allMailboxes[i];
+          const path = [];
+          let current = mbx;
+          while (current) {
+            try {
+              const name = current.name();
+              if (name === account.name()) break;
+              path.unshift(name);
+              current = current.container();
+            } catch (e) {
+              break;
+            }
+          }
+          if (path.length === targetPath.length) {
+            let match = true;
+            for (let j = 0; j < path.length; j++) {
+              if (path[j] !== targetPath[j]) {
+                match = false;
+                break;
+              }
+            }
+            if (match) return mbx;
+          }
+        }
+      } catch (e) {}
+      return null;
+    }
+
+    const targetMailbox = findMailboxByPath(targetAccount, mailboxPath);
+    if (!targetMailbox) {
+      return JSON.stringify({
+        success: false,
+        error: `Mailbox path '${mailboxPath.join(" > ")}' not found in account '${accountName}'.`,
+        errorCode: "MAILBOX_NOT_FOUND",
+      });
+    }
+
+    // Count messages and sub-mailboxes of the whole subtree
+    function countContents(mailbox) {
+      const counts = { messages: mailbox.messages.length, mailboxes: 0 };
+      const children = mailbox.mailboxes();
+      for (let i = 0; i < children.length; i++) {
+        const child = countContents(children[i]);
+        counts.messages += child.messages;
+        counts.mailboxes += child.mailboxes + 1;
+      }
+      return counts;
+    }
+
+    const counts = countContents(targetMailbox);
+    if (!force && (counts.messages > 0 || counts.mailboxes > 0)) {
+      return JSON.stringify({
+        success: false,
+        error: `Mailbox '${mailboxPath.join(" > ")}' is not empty: it contains ${counts.messages} message(s) and ${counts.mailboxes} sub-mailbox(es). Move them first or set force to delete them with the mailbox.`,
+        errorCode: "MAILBOX_NOT_EMPTY",
+      });
+    }
+
+    Mail.delete(targetMailbox);
+    log(
+      `Deleted mailbox with ${counts.messages} message(s) and ${counts.mailboxes} sub-mailbox(es)`,
+    );
+
+    return JSON.stringify({
+      success: true,
+      data: {
+        account: accountName,
+        mailboxPath: mailboxPath,
+        parentMailboxPath:
+          mailboxPath.length > 1 ? mailboxPath.slice(0, -1) : null,
+        deleted: true,
+        deletedMessageCount: counts.messages,
+        deletedSubMailboxCount: counts.mailboxes,
+      },
+      logs: logs.join("\n"),
+    });
+  } catch (e) {
+    return JSON.stringify({
+      success: false,
+      error: `Failed to delete mailbox: ${e.toString()}`,
+      logs: logs.join("\n"),
+    });
+  }
+}
@@ -0,0 +1,309 @@
+#!/usr/bin/osascript -l JavaScript
+
+/**
+ * Deletes messages by moving them to the account's Trash, or permanently
+ *
+ * Arguments:
+ *   argv[0] - JSON string containing:
+ *     - account (required)
+ *     - mailboxPath (required) - Array like ["Inbox"] or ["Inbox","GitHub"]
+ *     - message_ids (required) - array of numeric message IDs
+ *     - permanent (optional) - delete permanently instead of moving to Trash
+ *     - dry_run (optional) - only report which messages would be deleted
+ *
+ * Permanent deletion moves the messages to the Trash first and then deletes
+ * them from there. The confirmation token for permanent deletion is checked
+ * by the Go layer before this script runs.
+ */
+
+function run(argv) {
+  const Mail = Application("Mail");
+  Mail.includeStandardAdditions = true;
+
+  // Check if Mail.app is running
+  if (!Mail.running()) {
+    return JSON.stringify({
+      success: false,
+      error: "Mail.app is not running. Please start Mail.app and try again.",
+      errorCode: "MAIL_APP_NOT_RUNNING",
+    });
+  }
+
+  // Collect logs instead of using console.log
+  const logs = [];
+
+  // Helper function to log messages
+  function log(message) {
+    logs.push(message);
+  }
+
+  // Parse arguments
+  let args;
+  try {
+    args = JSON.parse(argv[0]);
+  } catch (e) {
+    return JSON.stringify({
+      success: false,
+      error: "Failed to parse input arguments JSON",
+    });
+  }
+
+  const accountName = args.account || "";
+  const mailboxPath = args.mailboxPath || [];
+  const messageIds = args.message_ids || [];
+  const permanent = args.permanent === true;
+  const dryRun = args.dry_run === true;
+
+  // Validate all required arguments explicitly
+  if (!accountName) {
+    return JSON.stringify({
+      success: false,
+      error: "Account name is required",
+    });
+  }
+
+  if (!Array.isArray(mailboxPath) || mailboxPath.length === 0) {
+    return JSON.stringify({
+      success: false,
+      error: "Mailbox path is required and must be a non-empty array",
+    });
+  }
+
+  if (!Array.isArray(messageIds) || messageIds.length === 0) {
+    return JSON.stringify({
+      success: false,
+      error: "message_ids is required and must be a non-empty array",
+    });
+  }
+
+  try {
+    const targetAccount = Mail.accounts[accountName];
+    try {
+      targetAccount.name();
+    } catch (e) {
+      return JSON.stringify({
+        success: false,
+        error: `Account "${accountName}" not found. Please verify the account name is correct.`,
+      });
+    }
+
+    // Robust mailbox traversal function
+    function findMailboxByPath(account, targetPath) {
+      if (!targetPath || targetPath.length === 0) return account;
+
+      try {
+        let current = account;
+        for (let i = 0; i < targetPath.length; i++) {
+          const part = targetPath[i];
+          let next = null;
+          try {
+            next = current.mailboxes.whose({ name: part })()[0];
+          } catch (e) {}
+
+          if (!next) {
+            try {
+              next = current.mailboxes[part];
+              next.name();
+            } catch (e) {}
+          }
+          if (!next) throw new Error("not found");
+          current = next;
+        }
+        return current;
+      } catch (e) {}
+
+      try {
+        const allMailboxes = account.mailboxes();
+        for (let i = 0; i < allMailboxes.length; i++) {
+          const mbx = allMailboxes[i];
+          const path = [];
+          let current = mbx;
+          while (current) {
+            try {
+              const name = current.name();
+              if (name === account.name()) break;
+              path.unshift(name);
+              current = current.container();
+            } catch (e) {
+              break;
+            }
+          }
+          if (path.length === targetPath.length) {
+            let match = true;
+            for (let j = 0; j < path.length; j++) {
+              if (path[j] !== targetPath[j]) {
+                match = false;
+                break;
+              }
+            }
+            if (match) return mbx;
+          }
+        }
+      } catch (e) {}
+      return null;
+    }
+
+    const targetMailbox = findMailboxByPath(targetAccount, mailboxPath);
+    if (!targetMailbox) {
+      return JSON.stringify({
+        success: false,
+        error: `Mailbox path '${mailboxPath.join(" > ")}' not found in account '${accountName}'.`,
+      });
+    }
+
+    // Finds the Trash of the account. The unified Trash mailbox of Mail.app
+    // contains one mailbox per account; fall back to common names.
+    function findTrashMailbox(account) {
+      try {
+        const trashes = Mail.trashMailbox.mailboxes();
+        for (let i = 0; i < trashes.length; i++) {
+          try {
+            if (trashes[i].account().name() === account.name()) {
+              return trashes[i];
+            }
+          } catch (e) {}
+        }
+      } catch (e) {
+        log(`Could not read unified Trash mailbox: ${e.toString()}`);
+      }
+      const names = ["Trash", "Deleted Messages", "Deleted Items", "Bin"];
+      for (let i = 0; i < names.length; i++) {
+        try {
+          const mbx = targetAccount.mailboxes[names[i]];
+          mbx.name();
+          return mbx;
+        } catch (e) {}
+      }
+      return null;
+    }
+
+    const trashMailbox = findTrashMailbox(targetAccount);
+    if (!trashMailbox) {
+      return JSON.stringify({
+        success: false,
+        error: `Trash mailbox not found in account '${accountName}'.`,
+        logs: logs.join("\n"),
+      });
+    }
+    const trashPath = [trashMailbox.name()];
+    const inTrash =
+      mailboxPath.length === trashPath.length &&
+      mailboxPath.every((name, i) => name === trashPath[i]);
+
+    const results = [];
+    let deletedCount = 0;
+
+    for (let i = 0; i < messageIds.length; i++) {
+      const messageId = parseInt(messageIds[i], 10);
+      const result = { message_id: messageId, deleted: false };
+
+      try {
+        const matches = targetMailbox.messages.whose({ id: messageId })();
+        if (!matches || matches.length === 0) {
+          result.error = `Message with ID ${messageId} not found in mailbox "${mailboxPath.join(" > ")}".`;
+          results.push(result);
+          continue;
+        }
+        const msg = matches[0];
+
+        try {
+          result.subject = msg.subject();
+          result.sender = msg.sender();
+        } catch (e) {}
+
+        if (dryRun) {
+          result.would_delete = true;
+          deletedCount++;
+          results.push(result);
+          continue;
+        }
+
+        if (inTrash) {
+          // Deleting a message from the Trash removes it permanently
+          if (permanent) {
+            Mail.delete(msg);
+            result.deleted = true;
+            deletedCount++;
+          } else {
+            result.error = "Message is already in the Trash";
+          }
+          results.push(result);
+          continue;
+        }
+
+        // Capture the RFC Message-ID to find the message in the Trash
+        let rfcMessageId = "";
+        try {
+          rfcMessageId = msg.messageId();
+        } catch (e) {
+          log(`Could not read Message-ID of ${messageId}: ${e.toString()}`);
+        }
+
+        Mail.move(msg, { to: trashMailbox });
+        log(`Moved message ${messageId} to the Trash.`);
+
+        let trashed = null;
+        if (rfcMessageId) {
+          try {
+            const moved = trashMailbox.messages.whose({
+              messageId: rfcMessageId,
+            })();
+            if (moved.length > 0) {
+              trashed = moved[0];
+            }
+          } catch (e) {
+            log(
+              `Could not look up message ${messageId} in the Trash: ${e.toString()}`,
+            );
+          }
+        }
+
+        if (permanent) {
+          if (!trashed) {
+            result.error =
+              "Message was moved to the Trash but could not be found there to delete it permanently";
+            results.push(result);
+            continue;
+          }
+          Mail.delete(trashed);
+        } else {
+          result.trash_id = trashed ? trashed.id() : null;
+        }
+        result.deleted = true;
+        deletedCount++;
+      } catch (e) {
+        result.error = `Failed to delete message: ${e.toString()}`;
+      }
+
+      results.push(result);
+    }
+
+    const data = {
+      results: results,
+      dry_run: dryRun,
+      permanent: permanent,
+      failed_count: results.length - deletedCount,
+      trash: {
+        account: accountName,
+        mailboxPath: trashPath,
+      },
+    };
+    if (dryRun) {
+      data.would_delete_count = deletedCount;
+    } else {
+      data.deleted_count = deletedCount;
+    }
+
+    return JSON.stringify({
+      success: true,
+      data: data,
+      logs: logs.join("\n"),
+    });
+  } catch (e) {
+    return JSON.stringify({
+      success: false,
+      error: `Failed to delete messages: ${e.toString()}`,
+      logs: logs.join("\n"),
+    });
+  }
+}
@@ -0,0 +1,183 @@
+#!/usr/bin/osascript -l JavaScript
+
+/**
+ * Deletes a rule identified by its name
+ *
+ * Arguments:
+ *   argv[0] - JSON string containing:
+ *     - name (required) - name of the rule
+ *
+ * The rule is returned as it was before deleting it.
+ */
+
+function run(argv) {
+  const Mail = Application("Mail");
+  Mail.includeStandardAdditions = true;
+
+  // Check if Mail.app is running
+  if (!Mail.running()) {
+    return JSON.stringify({
+      success: false,
+      error: "Mail.app is not running. Please start Mail.app and try again.",
+      errorCode: "MAIL_APP_NOT_RUNNING",
+    });
+  }
+
+  // Collect logs instead of using console.log
+  const logs = [];
+
+  // Helper function to log messages
+  function log(message) {
+    logs.push(message);
+  }
+
+  // Parse arguments
+  let args;
+  try {
+    args = JSON.parse(argv[0]);
+  } catch (e) {
+    return JSON.stringify({
+      success: false,
+      error: "Failed to parse input arguments JSON",
+    });
+  }
+
+  const name = args.name || "";
+
+  if (!name) {
+    return JSON.stringify({
+      success: false,
+      error: "Rule name is required",
+    });
+  }
+
+  try {
+    // Read a property, falling back if it is missing or unset
+    function read(getter, fallback) {
+      try {
+        const value = getter();
+        return value === null || value === undefined ? fallback : value;
+      } catch (e) {
+        return fallback;
+      }
+    }
+
+    // Account and path of the mailbox a rule moves or copies to
+    function mailboxInfo(mailbox) {
+      if (!mailbox) return null;
+      try {
+        const accountName = mailbox.account().name();
+        const path = [];
+        let current = mailbox;
+        while (current) {
+          const name = current.name();
+          if (name === accountName) break;
+          path.unshift(name);
+          try {
+            current = current.container();
+          } catch (e) {
+            break;
+          }
+        }
+        return { account: accountName, mailboxPath: path };
+      } catch (e) {
+        log("Error reading rule mailbox: " + e.toString());
+        return null;
+      }
+    }
+
+    // Serialize a rule with all conditions and actions. Mapping them to the
+    // types of the tools, and finding unsupported ones, is left to the server.
+    function readRule(rule, index) {
+      const conditions = read(() => rule.ruleConditions(), []).map((c) => ({
+        ruleType: read(() => c.ruleType(), ""),
+        qualifier: read(() => c.qualifier(), ""),
+        expression: read(() => c.expression(), ""),
+        header: read(() => c.header(), ""),
+      }));
+      const forwardMessage = read(() => rule.forwardMessage(), "");
+      const runScript = read(() => rule.runScript(), null);
+      const colorMessage = read(() => rule.colorMessage(), "none");
+      return {
+        index: index,
+        name: read(() => rule.name(), ""),
+        enabled: read(() => rule.enabled(), false),
+        allConditionsMustBeMet: read(() => rule.allConditionsMustBeMet(), true),
+        conditions: conditions,
+        actions: {
+          moveMessage: read(() => rule.shouldMoveMessage(), false)
+            ? mailboxInfo(read(() => rule.moveMessage(), null))
+            : null,
+          copyMessage: read(() => rule.shouldCopyMessage(), false)
+            ? mailboxInfo(read(() => rule.copyMessage(), null))
+            : null,
+          markRead: read(() => rule.markRead(), false),
+          markFlagged: read(() => rule.markFlagged(), false),
+          markFlagIndex: read(() => rule.markFlagIndex(), -1),
+          deleteMessage: read(() => rule.deleteMessage(), false),
+          forwardMessage: forwardMessage,
+          stopEvaluatingRules: read(() => rule.stopEvaluatingRules(), false),
+          forwardText: forwardMessage ? read(() => rule.forwardText(), "") : "",
+          redirectMessage: read(() => rule.redirectMessage(), ""),
+          replyText: read(() => rule.replyText(), ""),
+          runScript: runScript ? runScript.toString() : "",
+          playSound: read(() => rule.playSound(), ""),
+          colorMessage: colorMessage === "none" ? "" : colorMessage,
+          highlightTextUsingColor: read(
+            () => rule.highlightTextUsingColor(),
+            false,
+          ),
+        },
+      };
+    }
+
+    // Rules are identified by name, Mail allows duplicates which cannot be
+    // told apart
+    function findRule(name) {
+      const names = Mail.rules.name();
+      const indexes = [];
+      for (let i = 0; i < names.length; i++) {
+        if (names[i] === name) indexes.push(i);
+      }
+      if (indexes.length === 0) {
+        return {
+          error: `Rule "${name}" not found.`,
+          errorCode: "RULE_NOT_FOUND",
+        };
+      }
+      if (indexes.length > 1) {
+        return {
+          error: `There are ${indexes.length} rules named "${name}". Rename them in Mail so they can be told apart.`,
+          errorCode: "AMBIGUOUS_RULE",
+        };
+      }
+      return { rule: Mail.rules[indexes[0]], index: indexes[0] + 1 };
+    }
+
+    const found = findRule(name);
+    if (found.error) {
+      return JSON.stringify({
+        success: false,
+        error: found.error,
+        errorCode: found.errorCode,
+      });
+    }
+    const deleted = readRule(found.rule, found.index);
+    Mail.delete(found.rule);
+    log(`Deleted rule "${name}"`);
+
+    return JSON.stringify({
+      success: true,
+      data: {
+        rule: deleted,
+      },
+      logs: logs.join("\n"),
+    });
+  } catch (e) {
+    return JSON.stringify({
+      success: false,
+      error: `Failed to delete rule: ${e.toString()}`,
+      logs: logs.join("\n"),
+    });
+  }
+}
@@ -29,6 +29,9 @@ function run(argv) {
   const {
     account: accountName,
     mailboxPath = [],
+    accounts = [],
+    mailboxPaths = [],
+    recursive = false,
     limit = 50,
     subject,
     sender,
@@ -36,16 +39,53 @@ function run(argv) {
     flaggedOnly,
     dateAfter,
     dateBefore,
+    to,
+    cc,
+    body,
+    hasAttachment,
+    minSize,
+    maxSize,
+    flagIndex,
+    wasRepliedTo,
+    wasForwarded,
+    junkMailStatus,
+    query,
+    filter,
+    offset = 0,
+    body_format: bodyFormat,
+    strip_quotes: stripQuotes,
   } = args;
 
-  if (!accountName) {
+  // The search scope: the accounts and mailbox paths lists take precedence
+  // over the single account and mailboxPath. "*" matches all enabled accounts
+  // or, as the only path element, all mailboxes of an account.
+  const scopeAccounts =
+    Array.isArray(accounts) && accounts.length > 0
+      ? accounts
+      : accountName
+        ? [accountName]
+        : [];
+  const scopePaths =
+    Array.isArray(mailboxPaths) && mailboxPaths.length > 0
+      ? mailboxPaths
+      : [mailboxPath];
+
+  if (scopeAccounts.length === 0) {
     return JSON.stringify({
       success: false,
       error: "Account name is required",
     });
   }
-  if (!Array.isArray(mailboxPath) || mailboxPath.length === 0) {
-    return JSON.stringify({ success: false, error: "Mailbox path required" });
+  for (let i = 0; i < scopePaths.length; i++) {
+    if (!Array.isArray(scopePaths[i]) || scopePaths[i].length === 0) {
+      return JSON.stringify({ success: false, error: "Mailbox path required" });
+    }
+  }
+
+  // The filter is a tree of predicates built and validated by the server
+  // from all filter criteria, see internal/query.
+  if (!filter || typeof filter !== "object") {
+    return JSON.stringify({ success: false, error: "Filter is required" });
   }
 
   if (limit < 1 || limit > 1000) {
@@ -56,16 +96,6 @@ function run(argv) {
   }
 
   try {
-    const targetAccount = Mail.accounts[accountName];
-    try {
-      targetAccount.name();
-    } catch (e) {
-      return JSON.stringify({
-        success: false,
-        error: `Account "${accountName}" not found.`,
-      });
-    }
-
     // Robust mailbox traversal function
     function findMailboxByPath(account, targetPath) {
       if (!targetPath || targetPath.length === 0) return account;
@@ -122,113 +152,285 @@ function run(argv) {
       return null;
     }
 
-    const targetMailbox = findMailboxByPath(targetAccount, mailboxPath);
-    if (!targetMailbox) {
+    // Returns the path of a mailbox by walking up its containers.
+    function mailboxPathOf(account, mbx) {
+      const path = [];
+      let current = mbx;
+      while (current) {
+        try {
+          const name = current.name();
+          if (name === account.name()) break;
+          path.unshift(name);
+          current = current.container();
+        } catch (e) {
+          break;
+        }
+      }
+      return path;
+    }
+
+    // Collects a mailbox and, if recursive, all its sub-mailboxes. Mailboxes
+    // already collected (by path) are skipped.
+    function collectMailboxes(account, mbx, path, withChildren, into, seen) {
+      const key = account.name() + "\u0000" + path.join("\u0000");
+      if (seen[key]) return;
+      seen[key] = true;
+      into.push({ account: account.name(), mailbox: mbx, path: path });
+      if (!withChildren) return;
+      let children = [];
+      try {
+        children = mbx.mailboxes();
+      } catch (e) {
+        log(`Error reading sub-mailboxes of ${path.join(" > ")}: ${e}`);
+      }
+      for (let i = 0; i < children.length; i++) {
+        collectMailboxes(
+          account,
+          children[i],
+          [...path, children[i].name()],
+          withChildren,
+          into,
+          seen,
+        );
+      }
+    }
+
+    // Resolve the accounts to search
+    const targetAccounts = [];
+    if (scopeAccounts.indexOf("*") !== -1) {
+      const all = Mail.accounts();
+      for (let i = 0; i < all.length; i++) {
+        try {
+          if (all[i].enabled()) targetAccounts.push(all[i]);
+        } catch (e) {
+          log(`Error reading account ${i}: ${e.toString()}`);
+        }
+      }
+    } else {
+      for (let i = 0; i < scopeAccounts.length; i++) {
+        const acc = Mail.accounts[scopeAccounts[i]];
+        try {
+          acc.name();
+        } catch (e) {
+          return JSON.stringify({
+            success: false,
+            error: `Account "${scopeAccounts[i]}" not found.`,
+          });
+        }
+        targetAccounts.push(acc);
+      }
+    }
+
+    // Resolve the mailboxes to search
+    const targetMailboxes = [];
+    const notFound = [];
+    const seen = {};
+    for (let a = 0; a < targetAccounts.length; a++) {
+      const account = targetAccounts[a];
+      for (let p = 0; p < scopePaths.length; p++) {
+        const path = scopePaths[p];
+        if (path.length === 1 && path[0] === "*") {
+          const all = account.mailboxes();
+          for (let i = 0; i < all.length; i++) {
+            collectMailboxes(
+              account,
+              all[i],
+              mailboxPathOf(account, all[i]),
+              true,
+              targetMailboxes,
+              seen,
+            );
+          }
+          continue;
+        }
+        const mbx = findMailboxByPath(account, path);
+        if (!mbx) {
+          notFound.push({ account: account.name(), mailboxPath: path });
+          continue;
+        }
+        collectMailboxes(account, mbx, path, recursive, targetMailboxes, seen);
+      }
+    }
+
+    if (targetMailboxes.length === 0) {
+      const first = notFound[0] || {
+        account: scopeAccounts[0],
+        mailboxPath: scopePaths[0],
+      };
       return JSON.stringify({
         success: false,
-        error: `Mailbox "${mailboxPath.join(" > ")}" not found in account "${accountName}".`,
+        error: `Mailbox "${first.mailboxPath.join(" > ")}" not found in account "${first.account}".`,
+        logs: logs.join("\n"),
       });
     }
 
-    const msgs = targetMailbox.messages;
-    const count = msgs.length;
-    log(
-      `Mailbox contains ${count} messages. Performing bulk property fetch...`,
-    );
-
     // PERFORMANCE OPTIMIZATION:
     // whose({ subject: { _contains: "..." } }) is extremely slow and causes timeouts on large mailboxes.
     // Instead, we fetch property arrays once and filter in JavaScript.
     // This is significantly faster for 10k+ messages as it reduces AppleEvent overhead to a O(1) bulk fetch.
 
-    let filterSubject = subject ? subject.toLowerCase() : null;
-    let filterSender = sender ? sender.toLowerCase() : null;
-    let filterDateAfter = dateAfter ? new Date(dateAfter) : null;
-    let filterDateBefore = dateBefore ? new Date(dateBefore) : null;
-
-    // Fetch only the columns needed for filtering to minimize data transfer
-    const subjects = filterSubject ? msgs.subject() : null;
-    const senders = filterSender ? msgs.sender() : null;
-    const readStatuses =
-      readStatus !== undefined && readStatus !== null
-        ? msgs.readStatus()
-        : null;
-    const flaggedStatuses = flaggedOnly ? msgs.flaggedStatus() : null;
-    const datesReceived =
-      filterDateAfter || filterDateBefore ? msgs.dateReceived() : null;
-
-    const matchingIndices = [];
-    for (let i = 0; i < count; i++) {
-      if (
-        subjects &&
-        (!subjects[i] ||
-          subjects[i].toLowerCase().indexOf(filterSubject) === -1)
-      )
-        continue;
-      if (
-        senders &&
-        (!senders[i] || senders[i].toLowerCase().indexOf(filterSender) === -1)
-      )
+    // Returns a function that fetches a column of message properties on
+    // first use, so only the properties the filter refers to are fetched.
+    function columns(msgs) {
+      const cache = {};
+      const fetchers = {
+        to: () => recipientColumn(msgs.toRecipients),
+        cc: () => recipientColumn(msgs.ccRecipients),
+        hasAttachment: () =>
+          msgs.mailAttachments.name().map((names) => names.length > 0),
+        dateReceived: () => msgs.dateReceived(),
+      };
+      return (field) => {
+        if (!(field in cache)) {
+          cache[field] = fetchers[field] ? fetchers[field]() : msgs[field]();
+        }
+        return cache[field];
+      };
+    }
+
+    // Joins names and addresses of the recipients of each message.
+    function recipientColumn(recipients) {
+      const names = recipients.name();
+      const addresses = recipients.address();
+      return names.map((n, i) => n.concat(addresses[i]).join("\n"));
+    }
+
+    // Evaluates a filter node against message i of a mailbox.
+    function evaluate(node, column, i) {
+      switch (node.op) {
+        case "and":
+          return node.args.every((a) => evaluate(a, column, i));
+        case "or":
+          return node.args.some((a) => evaluate(a, column, i));
+        case "not":
+          return !evaluate(node.args[0], column, i);
+        case "contains": {
+          const text = column(node.field)[i];
+          return (
+            !!text &&
+            text.toLowerCase().indexOf(node.value.toLowerCase()) !== -1
+          );
+        }
+        case "is":
+        case "eq":
+          return column(node.field)[i] === node.value;
+        case "gt":
+          return column(node.field)[i] > node.value;
+        case "ge":
+          return column(node.field)[i] >= node.value;
+        case "lt":
+          return column(node.field)[i] < node.value;
+        case "le":
+          return column(node.field)[i] <= node.value;
+        case "after": {
+          const d = column(node.field)[i];
+          return !!d && d > new Date(node.value);
+        }
+        case "before": {
+          const d = column(node.field)[i];
+          return !!d && d < new Date(node.value);
+        }
+      }
+      throw new Error(`Unknown filter operator "${node.op}"`);
+    }
+
+    // Matches of all mailboxes, sorted by date received before the limit is
+    // applied, so that total_matches and has_more cover the whole scope.
+    const matches = [];
+
+    for (let m = 0; m < targetMailboxes.length; m++) {
+      const target = targetMailboxes[m];
+      const msgs = target.mailbox.messages;
+      let count = 0;
+      try {
+        count = msgs.length;
+      } catch (e) {
+        log(`Error reading ${target.path.join(" > ")}: ${e.toString()}`);
         continue;
-      if (readStatuses && readStatuses[i] !== readStatus) continue;
-      if (flaggedStatuses && flaggedStatuses[i] !== true) continue;
-      if (datesReceived) {
+      }
+      if (count === 0) continue;
+      log(
+        `Mailbox ${target.account} > ${target.path.join(" > ")} contains ${count} messages. Performing bulk property fetch...`,
+      );
+
+      // Fetch only the columns needed for filtering to minimize data transfer
+      const column = columns(msgs);
+      // Dates are always needed to sort the merged results
+      const datesReceived = column("dateReceived");
+
+      for (let i = 0; i < count; i++) {
+        if (!evaluate(filter, column, i)) continue;
         const d = datesReceived[i];
-        if (filterDateAfter && d <= filterDateAfter) continue;
-        if (filterDateBefore && d >= filterDateBefore) continue;
+        matches.push({
+          target: target,
+          msgs: msgs,
+          index: i,
+          time: d ? d.getTime() : 0,
+        });
       }
-      matchingIndices.push(i);
     }
 
-    const totalMatches = matchingIndices.length;
+    // Newest first
+    matches.sort((x, y) => y.time - x.time);
+
+    const totalMatches = matches.length;
     log(`Found ${totalMatches} matching messages.`);
 
-    const maxProcess = Math.min(totalMatches, limit);
+    // The page starts at the offset of the cursor
+    const end = Math.min(totalMatches, offset + limit);
+    const hasMore = totalMatches > end;
     const resultMessages = [];
 
-    if (maxProcess > 0) {
-      const subsetIndices = matchingIndices.slice(0, maxProcess);
-      const subsetMsgs = subsetIndices.map((idx) => msgs[idx]);
+    for (let i = offset; i < end; i++) {
+      const match = matches[i];
+      const msg = match.msgs[match.index];
 
-      for (let i = 0; i < maxProcess; i++) {
-        const msg = subsetMsgs[i];
+      // 1. Get content safely (often fails on weird/syncing messages)
+      let content = "";
+      try {
+        content = msg.content() || "";
+      } catch (e) {
+        log("Error reading content for message " + i + ": " + e.toString());
+      }
 
-        // 1. Get content safely (often fails on weird/syncing messages)
-        let content = "";
-        try {
-          content = msg.content() || "";
-        } catch (e) {
-          log("Error reading content for message " + i + ": " + e.toString());
-        }
+      // 2. Get the rest of the properties
+      try {
+        // Cache dateSent to avoid double AppleEvents
+        const ds = msg.dateSent();
 
-        // 2. Get the rest of the properties
-        try {
-          // Cache dateSent to avoid double AppleEvents
-          const ds = msg.dateSent();
-
-          resultMessages.push({
-            id: msg.id(),
-            subject: msg.subject(),
-            sender: msg.sender(),
-            date_received: msg.dateReceived().toISOString(),
-            date_sent: ds ? ds.toISOString() : null,
-            read_status: msg.readStatus(),
-            flagged_status: msg.flaggedStatus(),
-            message_size: msg.messageSize(),
-            content_preview:
-              content.length > 100
-                ? content.substring(0, 100) + "..."
-                : content,
-            content_length: content.length,
-            mailbox_path: mailboxPath,
-            account: accountName,
-          });
-        } catch (e) {
-          log(
-            "Error reading properties for message " + i + ": " + e.toString(),
-          );
-          // Skip this message and continue
+        const result = {
+          id: msg.id(),
+          subject: msg.subject(),
+          sender: msg.sender(),
+          date_received: msg.dateReceived().toISOString(),
+          date_sent: ds ? ds.toISOString() : null,
+          read_status: msg.readStatus(),
+          flagged_status: msg.flaggedStatus(),
+          message_size: msg.messageSize(),
+          content_preview:
+            content.length > 100 ? content.substring(0, 100) + "..." : content,
+          content_length: content.length,
+          mailbox_path: match.target.path,
+          account: match.target.account,
+        };
+
+        // The full body, html and markdown are extracted from the source
+        // and quotes are stripped by the Go handler
+        if (bodyFormat || stripQuotes) {
+          result.content = content;
         }
+        if (bodyFormat && bodyFormat !== "plain") {
+          try {
+            result.source = msg.source();
+          } catch (e) {
+            log("Error reading source for message " + i + ": " + e.toString());
+          }
+        }
+
+        resultMessages.push(result);
+      } catch (e) {
+        log("Error reading properties for message " + i + ": " + e.toString());
+        // Skip this message and continue
       }
     }
 
@@ -239,7 +441,11 @@ function run(argv) {
         count: resultMessages.length,
         total_matches: totalMatches,
         limit: limit,
-        has_more: totalMatches > limit,
+        offset: offset,
+        has_more: hasMore,
+        next_offset: hasMore ? end : null,
+        mailboxes_searched: targetMailboxes.length,
+        mailboxes_not_found: notFound,
         filters_applied: {
           subject: subject || null,
           sender: sender || null,
@@ -247,6 +453,18 @@ function run(argv) {
           flagged_only: flaggedOnly || false,
           date_after: dateAfter || null,
           date_before: dateBefore || null,
+          to: to || null,
+          cc: cc || null,
+          body: body || null,
+          has_attachment: hasAttachment !== undefined ? hasAttachment : null,
+          min_size: minSize !== undefined ? minSize : null,
+          max_size: maxSize !== undefined ? maxSize : null,
+          flag_index: flagIndex !== undefined ? flagIndex : null,
+          was_replied_to: wasRepliedTo !== undefined ? wasRepliedTo : null,
+          was_forwarded: wasForwarded !== undefined ? wasForwarded : null,
+          junk_mail_status:
+            junkMailStatus !== undefined ? junkMailStatus : null,
+          query: query || null,
         },
       },
       logs: logs.join("\n"),
@@ -8,6 +8,9 @@
  *     - account (required)
  *     - mailboxPath (required) - Array like ["Inbox"] or ["Inbox","GitHub"]
  *     - message_id (required) - numeric ID
+ *     - body_format (optional) - "plain" (default), "html" or "markdown".
+ *       For html and markdown the message source is returned as well, the
+ *       Go handler extracts and converts the HTML part from it.
  *
  * Improvements:
  *   - Supports nested mailboxes via mailboxPath array
@@ -52,6 +55,7 @@ function run(argv) {
   const accountName = args.account || "";
   const mailboxPath = args.mailboxPath || [];
   const messageId = args.message_id ? parseInt(args.message_id) : 0;
+  const bodyFormat = args.body_format || "plain";
 
   // Validate all required arguments explicitly
   if (!accountName) {
@@ -228,6 +232,15 @@ function run(argv) {
       result.content = "";
     }
 
+    if (bodyFormat !== "plain") {
+      try {
+        result.source = targetMessage.source();
+      } catch (e) {
+        log("Error reading message source: " + e.toString());
+        result.source = "";
+      }
+    }
+
     try {
       result.readStatus = targetMessage.readStatus();
     } catch (e) {
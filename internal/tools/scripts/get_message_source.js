@@ -0,0 +1,194 @@
+#!/usr/bin/osascript -l JavaScript
+
+/**
+ * Reads the raw RFC 822 source of one or more messages
+ *
+ * Arguments:
+ *   argv[0] - JSON string containing:
+ *     - account (required)
+ *     - mailboxPath (required) - Array like ["Inbox"] or ["Inbox","GitHub"]
+ *     - message_ids (required) - array of numeric message IDs
+ *
+ * Messages that cannot be found or read are reported with an error instead
+ * of failing the whole call.
+ */
+
+function run(argv) {
+  const Mail = Application("Mail");
+  Mail.includeStandardAdditions = true;
+
+  // Check if Mail.app is running
+  if (!Mail.running()) {
+    return JSON.stringify({
+      success: false,
+      error: "Mail.app is not running. Please start Mail.app and try again.",
+      errorCode: "MAIL_APP_NOT_RUNNING",
+    });
+  }
+
+  // Collect logs instead of using console.log
+  const logs = [];
+
+  // Helper function to log messages
+  function log(message) {
+    logs.push(message);
+  }
+
+  // Parse arguments
+  let args;
+  try {
+    args = JSON.parse(argv[0]);
+  } catch (e) {
+    return JSON.stringify({
+      success: false,
+      error: "Failed to parse input arguments JSON",
+    });
+  }
+
+  const accountName = args.account || "";
+  const mailboxPath = args.mailboxPath || [];
+  const messageIds = args.message_ids || [];
+
+  if (!accountName) {
+    return JSON.stringify({
+      success: false,
+      error: "Account name is required",
+    });
+  }
+
+  if (!Array.isArray(mailboxPath) || mailboxPath.length === 0) {
+    return JSON.stringify({
+      success: false,
+      error: "Mailbox path is required and must be a non-empty array",
+    });
+  }
+
+  if (!Array.isArray(messageIds) || messageIds.length === 0) {
+    return JSON.stringify({
+      success: false,
+      error: "message_ids is required and must be a non-empty array",
+    });
+  }
+
+  try {
+    const targetAccount = Mail.accounts[accountName];
+    try {
+      targetAccount.name();
+    } catch (e) {
+      return JSON.stringify({
+        success: false,
+        error: `Account "${accountName}" not found. Please verify the account name is correct.`,
+      });
+    }
+
+    // Robust mailbox traversal function
+    function findMailboxByPath(account, targetPath) {
+      if (!targetPath || targetPath.length === 0) return account;
+
+      try {
+        let current = account;
+        for (let i = 0; i < targetPath.length; i++) {
+          const part = targetPath[i];
+          let next = null;
+          try {
+            next = current.mailboxes.whose({ name: part })()[0];
+          } catch (e) {}
+
+          if (!next) {
+            try {
+              next = current.mailboxes[part];
+              next.name();
+            } catch (e) {}
+          }
+          if (!next) throw new Error("not found");
+          current = next;
+        }
+        return current;
+      } catch (e) {}
+
+      try {
+        const allMailboxes = account.mailboxes();
+        for (let i = 0; i < allMailboxes.length; i++) {
+          const mbx = allMailboxes[i];
+          const path = [];
+          let current = mbx;
+          while (current) {
+            try {
+              const name = current.name();
+              if (name === account.name()) break;
+              path.unshift(name);
+              current = current.container();
+            } catch (e) {
+              break;
+            }
+          }
+          if (path.length === targetPath.length) {
+            let match = true;
+            for (let j = 0; j < path.length; j++) {
+              if (path[j] !== targetPath[j]) {
+                match = false;
+                break;
+              }
+            }
+            if (match) return mbx;
+          }
+        }
+      } catch (e) {}
+      return null;
+    }
+
+    const targetMailbox = findMailboxByPath(targetAccount, mailboxPath);
+    if (!targetMailbox) {
+      return JSON.stringify({
+        success: false,
+        error: `Mailbox path '${mailboxPath.join(" > ")}' not found in account '${accountName}'.`,
+      });
+    }
+
+    const messages = [];
+
+    for (let i = 0; i < messageIds.length; i++) {
+      const messageId = parseInt(messageIds[i], 10);
+      const result = { id: messageId };
+
+      try {
+        const matches = targetMailbox.messages.whose({ id: messageId })();
+        if (!matches || matches.length === 0) {
+          result.error = `Message with ID ${messageId} not found in mailbox "${mailboxPath.join(" > ")}".`;
+          messages.push(result);
+          continue;
+        }
+        const msg = matches[0];
+
+        result.source = msg.source();
+        try {
+          result.subject = msg.subject();
+          result.sender = msg.sender();
+          result.dateReceived = msg.dateReceived().toISOString();
+        } catch (e) {
+          log(
+            `Could not read properties of message ${messageId}: ${e.toString()}`,
+          );
+        }
+      } catch (e) {
+        result.error = `Failed to read message source: ${e.toString()}`;
+      }
+
+      messages.push(result);
+    }
+
+    return JSON.stringify({
+      success: true,
+      data: {
+        messages: messages,
+      },
+      logs: logs.join("\n"),
+    });
+  } catch (e) {
+    return JSON.stringify({
+      success: false,
+      error: `Failed to read message sources: ${e.toString()}`,
+      logs: logs.join("\n"),
+    });
+  }
+}
@@ -34,6 +34,7 @@ function run(argv) {
 
   const limit = args.limit || 5;
   const startAt = 0;
+  const bodyFormat = args.body_format || "";
 
   if (limit < 1) {
     return JSON.stringify({
@@ -133,7 +134,7 @@ function run(argv) {
       // Build mailbox path for nested mailbox support
       const mailboxPath = getMailboxPath(mailbox, account.name());
 
-      result.push({
+      const message = {
         id: msg.id(),
         subject: msg.subject(),
         sender: msg.sender(),
@@ -145,7 +146,27 @@ function run(argv) {
         mailbox: mailbox.name(),
         mailboxPath: mailboxPath,
         account: account.name(),
-      });
+      };
+
+      // The full body, html and markdown are extracted from the source by
+      // the Go handler
+      if (bodyFormat) {
+        try {
+          message.content = msg.content() || "";
+        } catch (e) {
+          log("Error reading content for message " + i + ": " + e.toString());
+          message.content = "";
+        }
+        if (bodyFormat !== "plain") {
+          try {
+            message.source = msg.source();
+          } catch (e) {
+            log("Error reading source for message " + i + ": " + e.toString());
+          }
+        }
+      }
+
+      result.push(message);
     }
 
     return JSON.stringify({
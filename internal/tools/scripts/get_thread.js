@@ -0,0 +1,270 @@
+#!/usr/bin/osascript -l JavaScript
+
+/**
+ * Collects the candidate messages of the thread of a message
+ *
+ * The messages are selected by message ID or by subject. Linking them into a
+ * thread is done by the server, which parses their headers.
+ *
+ * Arguments:
+ *   argv[0] - JSON string containing:
+ *     - account (required)
+ *     - mailboxPath (required) - mailbox of the message, always searched
+ *     - message_ids (required) - message IDs of the thread known so far
+ *     - subject (optional) - normalized, lower-case subject of the thread
+ *     - mailboxPaths (optional) - mailboxes to search instead of the Inbox,
+ *       Sent and archive mailboxes
+ *     - limit (optional) - maximum number of candidates (default: 100)
+ */
+
+function run(argv) {
+  const Mail = Application("Mail");
+  Mail.includeStandardAdditions = true;
+
+  // Check if Mail.app is running
+  if (!Mail.running()) {
+    return JSON.stringify({
+      success: false,
+      error: "Mail.app is not running. Please start Mail.app and try again.",
+      errorCode: "MAIL_APP_NOT_RUNNING",
+    });
+  }
+
+  // Collect logs instead of using console.log
+  const logs = [];
+
+  // Helper function to log messages
+  function log(message) {
+    logs.push(message);
+  }
+
+  // Parse arguments
+  let args;
+  try {
+    args = JSON.parse(argv[0]);
+  } catch (e) {
+    return JSON.stringify({
+      success: false,
+      error: "Failed to parse input arguments JSON",
+    });
+  }
+
+  const accountName = args.account || "";
+  const mailboxPath = args.mailboxPath || [];
+  const messageIds = args.message_ids || [];
+  const subject = args.subject || "";
+  const mailboxPaths = args.mailboxPaths || [];
+  const limit = args.limit || 100;
+
+  if (!accountName) {
+    return JSON.stringify({
+      success: false,
+      error: "Account name is required",
+    });
+  }
+
+  if (!Array.isArray(mailboxPath) || mailboxPath.length === 0) {
+    return JSON.stringify({
+      success: false,
+      error: "Mailbox path is required and must be a non-empty array",
+    });
+  }
+
+  if (!Array.isArray(messageIds)) {
+    return JSON.stringify({
+      success: false,
+      error: "message_ids must be an array",
+    });
+  }
+
+  try {
+    const targetAccount = Mail.accounts[accountName];
+    try {
+      targetAccount.name();
+    } catch (e) {
+      return JSON.stringify({
+        success: false,
+        error: `Account "${accountName}" not found. Please verify the account name is correct.`,
+      });
+    }
+
+    // Robust mailbox traversal function
+    function findMailboxByPath(account, targetPath) {
+      if (!targetPath || targetPath.length === 0) return account;
+
+      try {
+        let current = account;
+        for (let i = 0; i < targetPath.length; i++) {
+          const part = targetPath[i];
+          let next = null;
+          try {
+            next = current.mailboxes.whose({ name: part })()[0];
+          } catch (e) {}
+
+          if (!next) {
+            try {
+              next = current.mailboxes[part];
+              next.name();
+            } catch (e) {}
+          }
+          if (!next) throw new Error("not found");
+          current = next;
+        }
+        return current;
+      } catch (e) {}
+
+      try {
+        const allMailboxes = account.mailboxes();
+        for (let i = 0; i < allMailboxes.length; i++) {
+          const mbx = allMailboxes[i];
+          const path = [];
+          let current = mbx;
+          while (current) {
+            try {
+              const name = current.name();
+              if (name === account.name()) break;
+              path.unshift(name);
+              current = current.container();
+            } catch (e) {
+              break;
+            }
+          }
+          if (path.length === targetPath.length) {
+            let match = true;
+            for (let j = 0; j < path.length; j++) {
+              if (path[j] !== targetPath[j]) {
+                match = false;
+                break;
+              }
+            }
+            if (match) return mbx;
+          }
+        }
+      } catch (e) {}
+      return null;
+    }
+
+    const targetMailbox = findMailboxByPath(targetAccount, mailboxPath);
+    if (!targetMailbox) {
+      return JSON.stringify({
+        success: false,
+        error: `Mailbox path '${mailboxPath.join(" > ")}' not found in account '${accountName}'.`,
+      });
+    }
+
+    // Returns the path of a mailbox by walking up its containers.
+    function mailboxPathOf(account, mbx) {
+      const path = [];
+      let current = mbx;
+      while (current) {
+        try {
+          const name = current.name();
+          if (name === account.name()) break;
+          path.unshift(name);
+          current = current.container();
+        } catch (e) {
+          break;
+        }
+      }
+      return path;
+    }
+
+    // Mailboxes conversations usually span: the Inbox and its sub-mailboxes,
+    // the Sent mailbox and archives.
+    function isThreadMailbox(path) {
+      if (/^inbox$/i.test(path[0])) return true;
+      if (/^sent( messages| items| mail)?$/i.test(path[0])) return true;
+      return path.some((name) => /archive/i.test(name));
+    }
+
+    // Resolve the mailboxes to search, the mailbox of the message first
+    const searchMailboxes = [{ mailbox: targetMailbox, path: mailboxPath }];
+    const seen = { [mailboxPath.join("\u0000")]: true };
+    if (mailboxPaths.length > 0) {
+      for (let i = 0; i < mailboxPaths.length; i++) {
+        const path = mailboxPaths[i];
+        if (seen[path.join("\u0000")]) continue;
+        const mbx = findMailboxByPath(targetAccount, path);
+        if (!mbx) {
+          log(`Mailbox ${path.join(" > ")} not found, skipping`);
+          continue;
+        }
+        seen[path.join("\u0000")] = true;
+        searchMailboxes.push({ mailbox: mbx, path: path });
+      }
+    } else {
+      const all = targetAccount.mailboxes();
+      for (let i = 0; i < all.length; i++) {
+        const path = mailboxPathOf(targetAccount, all[i]);
+        if (seen[path.join("\u0000")] || !isThreadMailbox(path)) continue;
+        seen[path.join("\u0000")] = true;
+        searchMailboxes.push({ mailbox: all[i], path: path });
+      }
+    }
+
+    const wanted = {};
+    for (let i = 0; i < messageIds.length; i++) {
+      wanted[messageIds[i]] = true;
+    }
+
+    // Select candidates with bulk property fetches, the server links them
+    const candidates = [];
+    let truncated = false;
+    for (let m = 0; m < searchMailboxes.length && !truncated; m++) {
+      const target = searchMailboxes[m];
+      const msgs = target.mailbox.messages;
+      let ids;
+      let subjects;
+      try {
+        ids = msgs.messageId();
+        subjects = msgs.subject();
+      } catch (e) {
+        log(`Error reading ${target.path.join(" > ")}: ${e.toString()}`);
+        continue;
+      }
+      for (let i = 0; i < ids.length; i++) {
+        const normalized = (subjects[i] || "")
+          .toLowerCase()
+          .replace(/\s+/g, " ");
+        const bySubject = subject && normalized.indexOf(subject) !== -1;
+        if (!wanted[ids[i]] && !bySubject) continue;
+        if (candidates.length >= limit) {
+          truncated = true;
+          break;
+        }
+        const msg = msgs[i];
+        try {
+          const dateReceived = msg.dateReceived();
+          candidates.push({
+            id: msg.id(),
+            account: accountName,
+            mailbox_path: target.path,
+            subject: subjects[i],
+            sender: msg.sender(),
+            date_received: dateReceived ? dateReceived.toISOString() : null,
+            message_id: ids[i],
+            all_headers: msg.allHeaders(),
+          });
+        } catch (e) {
+          log(`Error reading message ${i}: ${e.toString()}`);
+        }
+      }
+    }
+
+    return JSON.stringify({
+      success: true,
+      data: {
+        candidates: candidates,
+        mailboxes_searched: searchMailboxes.map((t) => t.path),
+        truncated: truncated,
+      },
+      logs: logs.join("\n"),
+    });
+  } catch (e) {
+    return JSON.stringify({
+      success: false,
+      error: `Failed to collect thread: ${e.toString()}`,
+      logs: logs.join("\n"),
+    });
+  }
+}
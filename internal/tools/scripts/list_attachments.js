@@ -0,0 +1,212 @@
+#!/usr/bin/osascript -l JavaScript
+
+/**
+ * Lists the attachments of a message
+ *
+ * Arguments:
+ *   argv[0] - JSON string containing:
+ *     - account (required)
+ *     - mailboxPath (required) - Array like ["Inbox"] or ["Inbox","GitHub"]
+ *     - message_id (required) - numeric ID
+ */
+
+function run(argv) {
+  const Mail = Application("Mail");
+  Mail.includeStandardAdditions = true;
+
+  // Check if Mail.app is running
+  if (!Mail.running()) {
+    return JSON.stringify({
+      success: false,
+      error: "Mail.app is not running. Please start Mail.app and try again.",
+      errorCode: "MAIL_APP_NOT_RUNNING",
+    });
+  }
+
+  // Collect logs instead of using console.log
+  const logs = [];
+
+  // Helper function to log messages
+  function log(message) {
+    logs.push(message);
+  }
+
+  // Parse arguments
+  let args;
+  try {
+    args = JSON.parse(argv[0]);
+  } catch (e) {
+    return JSON.stringify({
+      success: false,
+      error: "Failed to parse input arguments JSON",
+    });
+  }
+
+  const accountName = args.account || "";
+  const mailboxPath = args.mailboxPath || [];
+  const messageId = args.message_id ? parseInt(args.message_id) : 0;
+
+  if (!accountName) {
+    return JSON.stringify({
+      success: false,
+      error: "Account name is required",
+    });
+  }
+
+  if (!Array.isArray(mailboxPath) || mailboxPath.length === 0) {
+    return JSON.stringify({
+      success: false,
+      error: "Mailbox path is required and must be a non-empty array",
+    });
+  }
+
+  if (!messageId || messageId < 1) {
+    return JSON.stringify({
+      success: false,
+      error: "Message ID is required and must be a positive integer",
+    });
+  }
+
+  try {
+    const targetAccount = Mail.accounts[accountName];
+    try {
+      targetAccount.name();
+    } catch (e) {
+      return JSON.stringify({
+        success: false,
+        error: `Account "${accountName}" not found. Please verify the account name is correct.`,
+      });
+    }
+
+    // Robust mailbox traversal function
+    function findMailboxByPath(account, targetPath) {
+      if (!targetPath || targetPath.length === 0) return account;
+
+      try {
+        let current = account;
+        for (let i = 0; i < targetPath.length; i++) {
+          const part = targetPath[i];
+          let next = null;
+          try {
+            next = current.mailboxes.whose({ name: part })()[0];
+          } catch (e) {}
+
+          if (!next) {
+            try {
+              next = current.mailboxes[part];
+              next.name();
+            } catch (e) {}
+          }
+          if (!next) throw new Error("not found");
+          current = next;
+        }
+        return current;
+      } catch (e) {}
+
+      try {
+        const allMailboxes = account.mailboxes();
+        for (let i = 0; i < allMailboxes.length; i++) {
+          const mbx = allMailboxes[i];
+          const path = [];
+          let current = mbx;
+          while (current) {
+            try {
+              const name = current.name();
+              if (name === account.name()) break;
+              path.unshift(name);
+              current = current.container();
+            } catch (e) {
+              break;
+            }
+          }
+          if (path.length === targetPath.length) {
+            let match = true;
+            for (let j = 0; j < path.length; j++) {
+              if (path[j] !== targetPath[j]) {
+                match = false;
+                break;
+              }
+            }
+            if (match) return mbx;
+          }
+        }
+      } catch (e) {}
+      return null;
+    }
+
+    const targetMailbox = findMailboxByPath(targetAccount, mailboxPath);
+    if (!targetMailbox) {
+      return JSON.stringify({
+        success: false,
+        error: `Mailbox path '${mailboxPath.join(" > ")}' not found in account '${accountName}'.`,
+      });
+    }
+
+    const matches = targetMailbox.messages.whose({ id: messageId })();
+    if (!matches || matches.length === 0) {
+      return JSON.stringify({
+        success: false,
+        error: `Message with ID ${messageId} not found in mailbox "${mailboxPath.join(" > ")}". The message may have been deleted or moved.`,
+      });
+    }
+    const targetMessage = matches[0];
+
+    function attachmentInfo(att) {
+      const info = {};
+      try {
+        info.id = String(att.id());
+      } catch (e) {
+        info.id = null;
+      }
+      try {
+        info.name = att.name();
+      } catch (e) {
+        info.name = "unknown";
+      }
+      // mimeType() is unreliable in Mail.app; the Go layer guesses missing
+      // types from the name.
+      try {
+        info.mimeType = att.mimeType();
+      } catch (e) {
+        info.mimeType = null;
+      }
+      try {
+        info.size = att.fileSize();
+      } catch (e) {
+        info.size = 0;
+      }
+      try {
+        info.downloaded = att.downloaded();
+      } catch (e) {
+        info.downloaded = false;
+      }
+      return info;
+    }
+
+    const attachments = [];
+    try {
+      const mailAttachments = targetMessage.mailAttachments();
+      for (let i = 0; i < mailAttachments.length; i++) {
+        attachments.push(attachmentInfo(mailAttachments[i]));
+      }
+    } catch (e) {
+      log("Error getting attachments: " + e.toString());
+    }
+
+    return JSON.stringify({
+      success: true,
+      data: {
+        message_id: messageId,
+        attachments: attachments,
+        count: attachments.length,
+      },
+      logs: logs.join("\n"),
+    });
+  } catch (e) {
+    return JSON.stringify({
+      success: false,
+      error: `Failed to list attachments: ${e.toString()}`,
+      logs: logs.join("\n"),
+    });
+  }
+}
@@ -0,0 +1,183 @@
+#!/usr/bin/osascript -l JavaScript
+
+/**
+ * Lists the correspondents of recent mail
+ *
+ * The senders of the messages in the Inbox mailboxes and the recipients of the
+ * messages in the Sent mailboxes of all accounts are counted. The server builds
+ * its index of people from them.
+ *
+ * Arguments:
+ *   argv[0] - JSON string containing:
+ *     - since (required) - ISO 8601 date, older messages are skipped
+ *     - limit (optional) - maximum number of messages read per mailbox
+ *       (default: 1000), the newest are read
+ */
+
+function run(argv) {
+  const Mail = Application("Mail");
+  Mail.includeStandardAdditions = true;
+
+  // Check if Mail.app is running
+  if (!Mail.running()) {
+    return JSON.stringify({
+      success: false,
+      error: "Mail.app is not running. Please start Mail.app and try again.",
+      errorCode: "MAIL_APP_NOT_RUNNING",
+    });
+  }
+
+  // Collect logs instead of using console.log
+  const logs = [];
+
+  // Helper function to log messages
+  function log(message) {
+    logs.push(message);
+  }
+
+  // Parse arguments
+  let args;
+  try {
+    args = JSON.parse(argv[0]);
+  } catch (e) {
+    return JSON.stringify({
+      success: false,
+      error: "Failed to parse input arguments JSON",
+    });
+  }
+
+  const since = new Date(args.since || "");
+  const limit = args.limit || 1000;
+
+  if (isNaN(since.getTime())) {
+    return JSON.stringify({
+      success: false,
+      error: "since is required and must be an ISO 8601 date",
+    });
+  }
+
+  try {
+    const senders = {};
+    const recipients = {};
+    const searched = [];
+    let messageCount = 0;
+    let truncated = false;
+
+    // Returns the path of a mailbox by walking up its containers.
+    function mailboxPathOf(account, mbx) {
+      const path = [];
+      let current = mbx;
+      while (current) {
+        try {
+          const name = current.name();
+          if (name === account.name()) break;
+          path.unshift(name);
+          current = current.container();
+        } catch (e) {
+          break;
+        }
+      }
+      return path;
+    }
+
+    // Indices of the newest messages at or after since, at most limit
+    function recent(dates) {
+      const indices = [];
+      for (let i = 0; i < dates.length; i++) {
+        if (dates[i] && dates[i] >= since) indices.push(i);
+      }
+      indices.sort((a, b) => dates[b] - dates[a]);
+      if (indices.length > limit) truncated = true;
+      return indices.slice(0, limit);
+    }
+
+    function count(index, key, entry, date) {
+      if (!index[key]) {
+        index[key] = entry;
+        entry.count = 0;
+        entry.last = date;
+      }
+      index[key].count++;
+      if (date > index[key].last) index[key].last = date;
+    }
+
+    const accounts = Mail.accounts();
+    for (let a = 0; a < accounts.length; a++) {
+      const account = accounts[a];
+      const mailboxes = account.mailboxes();
+      for (let m = 0; m < mailboxes.length; m++) {
+        const path = mailboxPathOf(account, mailboxes[m]);
+        const received = /^inbox$/i.test(path[0]);
+        const sent = /^sent( messages| items| mail)?$/i.test(path[0]);
+        if (!received && !sent) continue;
+
+        const msgs = mailboxes[m].messages;
+        try {
+          if (received) {
+            const dates = msgs.dateReceived();
+            const indices = recent(dates);
+            if (indices.length === 0) continue;
+            const froms = msgs.sender();
+            for (const i of indices) {
+              if (!froms[i]) continue;
+              count(senders, froms[i], { sender: froms[i] }, dates[i]);
+            }
+            messageCount += indices.length;
+          } else {
+            const dates = msgs.dateSent();
+            const indices = recent(dates);
+            if (indices.length === 0) continue;
+            const addresses = msgs.toRecipients.address();
+            const names = msgs.toRecipients.name();
+            const ccAddresses = msgs.ccRecipients.address();
+            const ccNames = msgs.ccRecipients.name();
+            for (const i of indices) {
+              const to = (addresses[i] || []).concat(ccAddresses[i] || []);
+              const toNames = (names[i] || []).concat(ccNames[i] || []);
+              for (let r = 0; r < to.length; r++) {
+                if (!to[r]) continue;
+                count(
+                  recipients,
+                  to[r].toLowerCase(),
+                  { name: toNames[r] || "", address: to[r] },
+                  dates[i],
+                );
+              }
+            }
+            messageCount += indices.length;
+          }
+          searched.push({ account: account.name(), mailboxPath: path });
+        } catch (e) {
+          log(
+            `Error reading ${account.name()} > ${path.join(" > ")}: ${e.toString()}`,
+          );
+        }
+      }
+    }
+
+    const toList = (index) =>
+      Object.keys(index).map((key) => {
+        const entry = index[key];
+        entry.last = entry.last.toISOString();
+        return entry;
+      });
+
+    return JSON.stringify({
+      success: true,
+      data: {
+        senders: toList(senders),
+        recipients: toList(recipients),
+        messageCount: messageCount,
+        searched: searched,
+        truncated: truncated,
+      },
+      logs: logs.join("\n"),
+    });
+  } catch (e) {
+    return JSON.stringify({
+      success: false,
+      error: `Failed to list correspondents: ${e.toString()}`,
+      logs: logs.join("\n"),
+    });
+  }
+}
@@ -32,6 +32,7 @@ function run(argv) {
 
   const targetAccountName = args.account || "";
   const limit = args.limit || 50;
+  const offset = args.offset || 0;
 
   // Validate limit
   if (limit < 1 || limit > 1000) {
@@ -68,13 +69,11 @@ function run(argv) {
 
     const drafts = [];
     let hasMore = false;
+    // Drafts of the account seen so far, the page starts at the offset of
+    // the cursor
+    let matched = 0;
 
     for (let i = 0; i < totalDrafts; i++) {
-      if (drafts.length >= limit) {
-        hasMore = true;
-        break;
-      }
-
       const msg = allDrafts[i];
       let msgAccountName = "";
 
@@ -89,6 +88,13 @@ function run(argv) {
         continue;
       }
 
+      matched++;
+      if (matched <= offset) continue;
+      if (matched > offset + limit) {
+        hasMore = true;
+        break;
+      }
+
       try {
         // Get basic properties
         const id = msg.id();
@@ -193,7 +199,9 @@ function run(argv) {
         count: drafts.length,
         total_drafts: totalDrafts,
         limit: limit,
+        offset: offset,
         has_more: hasMore,
+        next_offset: hasMore ? offset + limit : null,
       },
       logs: logs.join("\n"),
     });
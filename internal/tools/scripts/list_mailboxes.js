@@ -50,6 +50,8 @@ function run(argv) {
 
   const accountName = args.account || "";
   const mailboxPath = args.mailboxPath || [];
+  const limit = args.limit || 0;
+  const offset = args.offset || 0;
 
   // Validate account name
   if (!accountName) {
@@ -173,8 +175,14 @@ function run(argv) {
       sourceMailboxes = targetAccount.mailboxes();
     }
 
+    // The page starts at the offset of the cursor, without a limit all
+    // remaining mailboxes are listed
+    const total = sourceMailboxes.length;
+    const end = limit > 0 ? Math.min(total, offset + limit) : total;
+    const hasMore = total > end;
+
     // Process each mailbox
-    for (let i = 0; i < sourceMailboxes.length; i++) {
+    for (let i = offset; i < end; i++) {
       const mailbox = sourceMailboxes[i];
 
       // Build the full mailbox path for this mailbox
@@ -225,6 +233,10 @@ function run(argv) {
         mailboxes: mailboxes,
         count: mailboxes.length,
         parentMailboxPath: mailboxPath.length > 0 ? mailboxPath : null,
+        totalMailboxes: total,
+        offset: offset,
+        hasMore: hasMore,
+        next_offset: hasMore ? end : null,
       },
       logs: logs.join("\n"),
     });
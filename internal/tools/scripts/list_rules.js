@@ -0,0 +1,146 @@
+#!/usr/bin/osascript -l JavaScript
+
+/**
+ * Lists all rules of Mail in the order they are evaluated
+ *
+ * Arguments:
+ *   argv[0] - JSON string, no arguments are used
+ *
+ * Each rule is returned with all conditions and actions as Mail reports them.
+ * The server maps them to the condition and action types of the rule tools.
+ */
+
+function run(argv) {
+  const Mail = Application("Mail");
+  Mail.includeStandardAdditions = true;
+
+  // Check if Mail.app is running
+  if (!Mail.running()) {
+    return JSON.stringify({
+      success: false,
+      error: "Mail.app is not running. Please start Mail.app and try again.",
+      errorCode: "MAIL_APP_NOT_RUNNING",
+    });
+  }
+
+  // Collect logs instead of using console.log
+  const logs = [];
+
+  // Helper function to log messages
+  function log(message) {
+    logs.push(message);
+  }
+
+  // Parse arguments
+  let args;
+  try {
+    args = JSON.parse(argv[0]);
+  } catch (e) {
+    return JSON.stringify({
+      success: false,
+      error: "Failed to parse input arguments JSON",
+    });
+  }
+
+  try {
+    // Read a property, falling back if it is missing or unset
+    function read(getter, fallback) {
+      try {
+        const value = getter();
+        return value === null || value === undefined ? fallback : value;
+      } catch (e) {
+        return fallback;
+      }
+    }
+
+    // Account and path of the mailbox a rule moves or copies to
+    function mailboxInfo(mailbox) {
+      if (!mailbox) return null;
+      try {
+        const accountName = mailbox.account().name();
+        const path = [];
+        let current = mailbox;
+        while (current) {
+          const name = current.name();
+          if (name === accountName) break;
+          path.unshift(name);
+          try {
+            current = current.container();
+          } catch (e) {
+            break;
+          }
+        }
+        return { account: accountName, mailboxPath: path };
+      } catch (e) {
+        log("Error reading rule mailbox: " + e.toString());
+        return null;
+      }
+    }
+
+    // Serialize a rule with all conditions and actions. Mapping them to the
+    // types of the tools, and finding unsupported ones, is left to the server.
+    function readRule(rule, index) {
+      const conditions = read(() => rule.ruleConditions(), []).map((c) => ({
+        ruleType: read(() => c.ruleType(), ""),
+        qualifier: read(() => c.qualifier(), ""),
+        expression: read(() => c.expression(), ""),
+        header: read(() => c.header(), ""),
+      }));
+      const forwardMessage = read(() => rule.forwardMessage(), "");
+      const runScript = read(() => rule.runScript(), null);
+      const colorMessage = read(() => rule.colorMessage(), "none");
+      return {
+        index: index,
+        name: read(() => rule.name(), ""),
+        enabled: read(() => rule.enabled(), false),
+        allConditionsMustBeMet: read(() => rule.allConditionsMustBeMet(), true),
+        conditions: conditions,
+        actions: {
+          moveMessage: read(() => rule.shouldMoveMessage(), false)
+            ? mailboxInfo(read(() => rule.moveMessage(), null))
+            : null,
+          copyMessage: read(() => rule.shouldCopyMessage(), false)
+            ? mailboxInfo(read(() => rule.copyMessage(), null))
+            : null,
+          markRead: read(() => rule.markRead(), false),
+          markFlagged: read(() => rule.markFlagged(), false),
+          markFlagIndex: read(() => rule.markFlagIndex(), -1),
+          deleteMessage: read(() => rule.deleteMessage(), false),
+          forwardMessage: forwardMessage,
+          stopEvaluatingRules: read(() => rule.stopEvaluatingRules(), false),
+          forwardText: forwardMessage ? read(() => rule.forwardText(), "") : "",
+          redirectMessage: read(() => rule.redirectMessage(), ""),
+          replyText: read(() => rule.replyText(), ""),
+          runScript: runScript ? runScript.toString() : "",
+          playSound: read(() => rule.playSound(), ""),
+          colorMessage: colorMessage === "none" ? "" : colorMessage,
+          highlightTextUsingColor: read(
+            () => rule.highlightTextUsingColor(),
+            false,
+          ),
+        },
+      };
+    }
+
+    const rules = Mail.rules();
+    const result = [];
+    for (let i = 0; i < rules.length; i++) {
+      result.push(readRule(rules[i], i + 1));
+    }
+
+    return JSON.stringify({
+      success: true,
+      data: {
+        rules: result,
+        count: result.length,
+      },
+      logs: logs.join("\n"),
+    });
+  } catch (e) {
+    return JSON.stringify({
+      success: false,
+      error: `Failed to list rules: ${e.toString()}`,
+      logs: logs.join("\n"),
+    });
+  }
+}
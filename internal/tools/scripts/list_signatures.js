@@ -0,0 +1,76 @@
+#!/usr/bin/osascript -l JavaScript
+
+/**
+ * Lists the signatures configured in Mail
+ *
+ * Arguments:
+ *   argv[0] - JSON string, no arguments are used
+ *
+ * Signatures are global in Mail, each is returned with its name and the plain
+ * text of its content.
+ */
+
+function run(argv) {
+  const Mail = Application("Mail");
+  Mail.includeStandardAdditions = true;
+
+  // Check if Mail.app is running
+  if (!Mail.running()) {
+    return JSON.stringify({
+      success: false,
+      error: "Mail.app is not running. Please start Mail.app and try again.",
+      errorCode: "MAIL_APP_NOT_RUNNING",
+    });
+  }
+
+  // Collect logs instead of using console.log
+  const logs = [];
+
+  // Helper function to log messages
+  function log(message) {
+    logs.push(message);
+  }
+
+  // Parse arguments
+  let args;
+  try {
+    args = JSON.parse(argv[0]);
+  } catch (e) {
+    return JSON.stringify({
+      success: false,
+      error: "Failed to parse input arguments JSON",
+    });
+  }
+
+  try {
+    const signatures = Mail.signatures();
+    const result = [];
+    for (let i = 0; i < signatures.length; i++) {
+      let content = "";
+      try {
+        content = signatures[i].content() || "";
+      } catch (e) {
+        log(`Could not read content of signature ${i + 1}: ${e.toString()}`);
+      }
+      result.push({
+        name: signatures[i].name(),
+        content: content,
+      });
+    }
+
+    return JSON.stringify({
+      success: true,
+      data: {
+        signatures: result,
+        count: result.length,
+      },
+      logs: logs.join("\n"),
+    });
+  } catch (e) {
+    return JSON.stringify({
+      success: false,
+      error: `Failed to list signatures: ${e.toString()}`,
+      logs: logs.join("\n"),
+    });
+  }
+}
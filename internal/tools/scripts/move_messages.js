@@ -0,0 +1,277 @@
+#!/usr/bin/osascript -l JavaScript
+
+/**
+ * Moves messages from one mailbox to another, possibly in another account
+ *
+ * Arguments:
+ *   argv[0] - JSON string containing:
+ *     - account (required) - account of the source mailbox
+ *     - mailboxPath (required) - source mailbox path, e.g. ["Inbox"]
+ *     - message_ids (required) - array of numeric message IDs
+ *     - destination_account (required) - account of the destination mailbox
+ *     - destination_mailbox_path (required) - destination mailbox path
+ *
+ * Message IDs change when a message is moved. The new ID is looked up in the
+ * destination mailbox by the RFC Message-ID header and reported as new_id, or
+ * null if the moved message cannot be found yet (e.g. while IMAP syncs).
+ */
+
+function run(argv) {
+  const Mail = Application("Mail");
+  Mail.includeStandardAdditions = true;
+
+  // Check if Mail.app is running
+  if (!Mail.running()) {
+    return JSON.stringify({
+      success: false,
+      error: "Mail.app is not running. Please start Mail.app and try again.",
+      errorCode: "MAIL_APP_NOT_RUNNING",
+    });
+  }
+
+  // Collect logs instead of using console.log
+  const logs = [];
+
+  // Helper function to log messages
+  function log(message) {
+    logs.push(message);
+  }
+
+  // Parse arguments
+  let args;
+  try {
+    args = JSON.parse(argv[0]);
+  } catch (e) {
+    return JSON.stringify({
+      success: false,
+      error: "Failed to parse input arguments JSON",
+    });
+  }
+
+  const accountName = args.account || "";
+  const mailboxPath = args.mailboxPath || [];
+  const messageIds = args.message_ids || [];
+  const destinationAccountName = args.destination_account || "";
+  const destinationMailboxPath = args.destination_mailbox_path || [];
+
+  // Validate all required arguments explicitly
+  if (!accountName) {
+    return JSON.stringify({
+      success: false,
+      error: "Account name is required",
+    });
+  }
+
+  if (!Array.isArray(mailboxPath) || mailboxPath.length === 0) {
+    return JSON.stringify({
+      success: false,
+      error: "Mailbox path is required and must be a non-empty array",
+    });
+  }
+
+  if (!Array.isArray(messageIds) || messageIds.length === 0) {
+    return JSON.stringify({
+      success: false,
+      error: "message_ids is required and must be a non-empty array",
+    });
+  }
+
+  if (!destinationAccountName) {
+    return JSON.stringify({
+      success: false,
+      error: "Destination account name is required",
+    });
+  }
+
+  if (
+    !Array.isArray(destinationMailboxPath) ||
+    destinationMailboxPath.length === 0
+  ) {
+    return JSON.stringify({
+      success: false,
+      error:
+        "Destination mailbox path is required and must be a non-empty array",
+    });
+  }
+
+  try {
+    function findAccount(name) {
+      const account = Mail.accounts[name];
+      try {
+        account.name();
+      } catch (e) {
+        return null;
+      }
+      return account;
+    }
+
+    // Robust mailbox traversal function
+    function findMailboxByPath(account, targetPath) {
+      if (!targetPath || targetPath.length === 0) return account;
+
+      try {
+        let current = account;
+        for (let i = 0; i < targetPath.length; i++) {
+          const part = targetPath[i];
+          let next = null;
+          try {
+            next = current.mailboxes.whose({ name: part })()[0];
+          } catch (e) {}
+
+          if (!next) {
+            try {
+              next = current.mailboxes[part];
+              next.name();
+            } catch (e) {}
+          }
+          if (!next) throw new Error("not found");
+          current = next;
+        }
+        return current;
+      } catch (e) {}
+
+      try {
+        const allMailboxes = account.mailboxes();
+        for (let i = 0; i < allMailboxes.length; i++) {
+          const mbx = allMailboxes[i];
+          const path = [];
+          let current = mbx;
+          while (current) {
+            try {
+              const name = current.name();
+              if (name === account.name()) break;
+              path.unshift(name);
+              current = current.container();
+            } catch (e) {
+              break;
+            }
+          }
+          if (path.length === targetPath.length) {
+            let match = true;
+            for (let j = 0; j < path.length; j++) {
+              if (path[j] !== targetPath[j]) {
+                match = false;
+                break;
+              }
+            }
+            if (match) return mbx;
+          }
+        }
+      } catch (e) {}
+      return null;
+    }
+
+    const sourceAccount = findAccount(accountName);
+    if (!sourceAccount) {
+      return JSON.stringify({
+        success: false,
+        error: `Account "${accountName}" not found. Please verify the account name is correct.`,
+      });
+    }
+
+    const destinationAccount = findAccount(destinationAccountName);
+    if (!destinationAccount) {
+      return JSON.stringify({
+        success: false,
+        error: `Destination account "${destinationAccountName}" not found. Please verify the account name is correct.`,
+      });
+    }
+
+    const sourceMailbox = findMailboxByPath(sourceAccount, mailboxPath);
+    if (!sourceMailbox) {
+      return JSON.stringify({
+        success: false,
+        error: `Mailbox path '${mailboxPath.join(" > ")}' not found in account '${accountName}'.`,
+      });
+    }
+
+    const destinationMailbox = findMailboxByPath(
+      destinationAccount,
+      destinationMailboxPath,
+    );
+    if (!destinationMailbox) {
+      return JSON.stringify({
+        success: false,
+        error: `Destination mailbox path '${destinationMailboxPath.join(" > ")}' not found in account '${destinationAccountName}'.`,
+      });
+    }
+
+    const results = [];
+    let movedCount = 0;
+
+    for (let i = 0; i < messageIds.length; i++) {
+      const messageId = parseInt(messageIds[i], 10);
+      const result = {
+        message_id: messageId,
+        moved: false,
+        new_id: null,
+      };
+
+      try {
+        const matches = sourceMailbox.messages.whose({ id: messageId })();
+        if (!matches || matches.length === 0) {
+          result.error = `Message with ID ${messageId} not found in mailbox "${mailboxPath.join(" > ")}".`;
+          results.push(result);
+          continue;
+        }
+        const msg = matches[0];
+
+        // Capture the RFC Message-ID to find the message after the move
+        let rfcMessageId = "";
+        try {
+          rfcMessageId = msg.messageId();
+        } catch (e) {
+          log(`Could not read Message-ID of ${messageId}: ${e.toString()}`);
+        }
+
+        try {
+          result.subject = msg.subject();
+        } catch (e) {}
+
+        Mail.move(msg, { to: destinationMailbox });
+        result.moved = true;
+        movedCount++;
+        log(`Moved message ${messageId}.`);
+
+        if (rfcMessageId) {
+          try {
+            const moved = destinationMailbox.messages.whose({
+              messageId: rfcMessageId,
+            })();
+            if (moved.length > 0) {
+              result.new_id = moved[0].id();
+            }
+          } catch (e) {
+            log(
+              `Could not look up new ID of ${messageId}: ${e.toString()}`,
+            );
+          }
+        }
+      } catch (e) {
+        result.error = `Failed to move message: ${e.toString()}`;
+      }
+
+      results.push(result);
+    }
+
+    return JSON.stringify({
+      success: true,
+      data: {
+        results: results,
+        moved_count: movedCount,
+        failed_count: results.length - movedCount,
+        destination: {
+          account: destinationAccountName,
+          mailboxPath: destinationMailboxPath,
+        },
+      },
+      logs: logs.join("\n"),
+    });
+  } catch (e) {
+    return JSON.stringify({
+      success: false,
+      error: `Failed to move messages: ${e.toString()}`,
+      logs: logs.join("\n"),
+    });
+  }
+}
@@ -0,0 +1,211 @@
+function run(argv) {
+  const Mail = Application("Mail");
+  Mail.includeStandardAdditions = true;
+
+  // 1. CRITICAL: Check if running FIRST
+  if (!Mail.running()) {
+    return JSON.stringify({
+      success: false,
+      error: "Mail.app is not running. Please start Mail.app and try again.",
+      errorCode: "MAIL_APP_NOT_RUNNING",
+    });
+  }
+
+  // 2. Logging setup
+  const logs = [];
+  function log(message) {
+    logs.push(message);
+  }
+
+  // 3. Argument parsing & validation
+  let args;
+  try {
+    args = JSON.parse(argv[0]);
+  } catch (e) {
+    return JSON.stringify({
+      success: false,
+      error: "Failed to parse input arguments JSON",
+      logs: logs.join("\n"),
+    });
+  }
+
+  const accountName = args.account || "";
+  const messageId = parseInt(args.message_id, 10) || 0;
+  const mailboxPath = args.mailbox_path || [];
+  const toRecipients = args.to_recipients || [];
+  const ccRecipients = args.cc_recipients || [];
+  const bccRecipients = args.bcc_recipients || [];
+
+  log(
+    `Received arguments: account='${accountName}', messageId=${messageId}, path='${JSON.stringify(mailboxPath)}'`,
+  );
+
+  if (!accountName || !messageId) {
+    return JSON.stringify({
+      success: false,
+      error: "Account name and message ID are required.",
+      errorCode: "MISSING_PARAMETERS",
+      logs: logs.join("\n"),
+    });
+  }
+
+  if (!Array.isArray(mailboxPath) || mailboxPath.length === 0) {
+    return JSON.stringify({
+      success: false,
+      error: "Mailbox path must be a non-empty array.",
+      errorCode: "INVALID_MAILBOX_PATH",
+      logs: logs.join("\n"),
+    });
+  }
+
+  if (!Array.isArray(toRecipients) || toRecipients.length === 0) {
+    return JSON.stringify({
+      success: false,
+      error: "At least one To recipient is required.",
+      errorCode: "MISSING_PARAMETERS",
+      logs: logs.join("\n"),
+    });
+  }
+
+  // 4. Execution wrapped in try/catch
+  try {
+    const accounts = Mail.accounts.whose({ name: accountName })();
+    if (accounts.length === 0) {
+      return JSON.stringify({
+        success: false,
+        error: `Account '${accountName}' not found.`,
+        errorCode: "ACCOUNT_NOT_FOUND",
+        logs: logs.join("\n"),
+      });
+    }
+    log(`Successfully found account '${accountName}'.`);
+
+    // Robust mailbox traversal function
+    function findMailboxByPath(account, targetPath) {
+      if (!targetPath || targetPath.length === 0) return account;
+
+      try {
+        let current = account;
+        for (let i = 0; i < targetPath.length; i++) {
+          const part = targetPath[i];
+          let next = null;
+          try {
+            next = current.mailboxes.whose({ name: part })()[0];
+          } catch (e) {}
+
+          if (!next) {
+            try {
+              next = current.mailboxes[part];
+              next.name();
+            } catch (e) {}
+          }
+          if (!next) throw new Error("not found");
+          current = next;
+        }
+        return current;
+      } catch (e) {}
+
+      try {
+        const allMailboxes = account.mailboxes();
+        for (let i = 0; i < allMailboxes.length; i++) {
+          const mbx = allMailboxes[i];
+          const path = [];
+          let current = mbx;
+          while (current) {
+            try {
+              const name = current.name();
+              if (name === account.name()) break;
+              path.unshift(name);
+              current = current.container();
+            } catch (e) {
+              break;
+            }
+          }
+          if (path.length === targetPath.length) {
+            let match = true;
+            for (let j = 0; j < path.length; j++) {
+              if (path[j] !== targetPath[j]) {
+                match = false;
+                break;
+              }
+            }
+            if (match) return mbx;
+          }
+        }
+      } catch (e) {}
+      return null;
+    }
+
+    let targetMailbox = findMailboxByPath(accounts[0], mailboxPath);
+    if (!targetMailbox) {
+      return JSON.stringify({
+        success: false,
+        error:
+          "Mailbox path '" +
+          mailboxPath.join(" > ") +
+          "' not found in account '" +
+          accountName +
+          "'.",
+      });
+    }
+
+    // --- End of Traversal Logic ---
+
+    const messages = targetMailbox.messages.whose({ id: messageId })();
+    if (messages.length === 0) {
+      return JSON.stringify({
+        success: false,
+        error: `Message with ID ${messageId} not found in mailbox '${mailboxPath.join(" > ")}'.`,
+        errorCode: "MESSAGE_NOT_FOUND",
+        logs: logs.join("\n"),
+      });
+    }
+    const originalMessage = messages[0];
+    log(`Found original message with ID ${messageId}.`);
+
+    // A redirect resends the original unchanged, with the original sender,
+    // subject, body and attachments. Only the recipients are set.
+    const redirectMessage = originalMessage.redirect({ openingWindow: true });
+
+    toRecipients.forEach((addr) =>
+      redirectMessage.toRecipients.push(Mail.Recipient({ address: addr })),
+    );
+    ccRecipients.forEach((addr) =>
+      redirectMessage.ccRecipients.push(Mail.Recipient({ address: addr })),
+    );
+    bccRecipients.forEach((addr) =>
+      redirectMessage.bccRecipients.push(Mail.Recipient({ address: addr })),
+    );
+    log(
+      `Added ${toRecipients.length + ccRecipients.length + bccRecipients.length} recipients.`,
+    );
+
+    // NOTE: We are NOT sending the redirect. It exists as an open window (OutgoingMessage).
+    log("Redirect message window created.");
+
+    Mail.activate();
+
+    // 5. CRITICAL: Return 'outgoing_id' for the new message window.
+    return JSON.stringify({
+      success: true,
+      data: {
+        outgoing_id: redirectMessage.id(), // This is now an OutgoingMessage ID
+        subject: redirectMessage.subject(),
+        message: "Redirect message created successfully.",
+      },
+      logs: logs.join("\n"),
+    });
+  } catch (e) {
+    let errorCode = "UNKNOWN_ERROR";
+    if (e.toString().includes("Automation is not allowed")) {
+      errorCode = "MAIL_APP_NO_PERMISSIONS";
+    }
+    log(`Caught error: ${e.toString()}`);
+    return JSON.stringify({
+      success: false,
+      error: `Failed to create redirect: ${e.toString()}`,
+      errorCode: errorCode,
+      logs: logs.join("\n"),
+    });
+  }
+}
@@ -0,0 +1,202 @@
+#!/usr/bin/osascript -l JavaScript
+
+/**
+ * Renames a mailbox or moves it under another parent mailbox
+ *
+ * Arguments:
+ *   argv[0] - JSON string containing:
+ *     - account (required)
+ *     - mailboxPath (required) - current path, e.g. ["Projects", "Alpha"]
+ *     - new_mailbox_path (required) - new path, e.g. ["Archive", "Alpha"]
+ *
+ * A mailbox is renamed by setting its name. Mail interprets a name with "/"
+ * separated levels as a path from the account root, which moves the mailbox
+ * with its messages and sub-mailboxes. The mailbox is looked up by its new
+ * path afterwards to verify the change.
+ */
+
+function run(argv) {
+  const Mail = Application("Mail");
+  Mail.includeStandardAdditions = true;
+
+  // Check if Mail.app is running
+  if (!Mail.running()) {
+    return JSON.stringify({
+      success: false,
+      error: "Mail.app is not running. Please start Mail.app and try again.",
+      errorCode: "MAIL_APP_NOT_RUNNING",
+    });
+  }
+
+  // Collect logs instead of using console.log
+  const logs = [];
+
+  // Helper function to log messages
+  function log(message) {
+    logs.push(message);
+  }
+
+  // Parse arguments
+  let args;
+  try {
+    args = JSON.parse(argv[0]);
+  } catch (e) {
+    return JSON.stringify({
+      success: false,
+      error: "Failed to parse input arguments JSON",
+    });
+  }
+
+  const accountName = args.account || "";
+  const mailboxPath = args.mailboxPath || [];
+  const newMailboxPath = args.new_mailbox_path || [];
+
+  if (!accountName) {
+    return JSON.stringify({
+      success: false,
+      error: "Account name is required",
+    });
+  }
+
+  if (!Array.isArray(mailboxPath) || mailboxPath.length === 0) {
+    return JSON.stringify({
+      success: false,
+      error: "Mailbox path is required and must be a non-empty array",
+    });
+  }
+
+  if (!Array.isArray(newMailboxPath) || newMailboxPath.length === 0) {
+    return JSON.stringify({
+      success: false,
+      error: "new_mailbox_path is required and must be a non-empty array",
+    });
+  }
+
+  try {
+    const targetAccount = Mail.accounts[accountName];
+    try {
+      targetAccount.name();
+    } catch (e) {
+      return JSON.stringify({
+        success: false,
+        error: `Account "${accountName}" not found. Please verify the account name is correct.`,
+      });
+    }
+
+    // Robust mailbox traversal function
+    function findMailboxByPath(account, targetPath) {
+      if (!targetPath || targetPath.length === 0) return account;
+
+      try {
+        let current = account;
+        for (let i = 0; i < targetPath.length; i++) {
+          const part = targetPath[i];
+          let next = null;
+          try {
+            next = current.mailboxes.whose({ name: part })()[0];
+          } catch (e) {}
+
+          if (!next) {
+            try {
+              next = current.mailboxes[part];
+              next.name();
+            } catch (e) {}
+          }
+          if (!next) throw new Error("not found");
+          current = next;
+        }
+        return current;
+      } catch (e) {}
+
+      try {
+        const allMailboxes = account.mailboxes();
+        for (let i = 0; i < allMailboxes.length; i++) {
+          const mbx = allMailboxes[i];
+          const path = [];
+          let current = mbx;
+          while (current) {
+            try {
+              const name = current.name();
+              if (name === account.name()) break;
+              path.unshift(name);
+              current = current.container();
+            } catch (e) {
+              break;
+            }
+          }
+          if (path.length === targetPath.length) {
+            let match = true;
+            for (let j = 0; j < path.length; j++) {
+              if (path[j] !== targetPath[j]) {
+                match = false;
+                break;
+              }
+            }
+            if (match) return mbx;
+          }
+        }
+      } catch (e) {}
+      return null;
+    }
+
+    const targetMailbox = findMailboxByPath(targetAccount, mailboxPath);
+    if (!targetMailbox) {
+      return JSON.stringify({
+        success: false,
+        error: `Mailbox path '${mailboxPath.join(" > ")}' not found in account '${accountName}'.`,
+        errorCode: "MAILBOX_NOT_FOUND",
+      });
+    }
+
+    if (findMailboxByPath(targetAccount, newMailboxPath)) {
+      return JSON.stringify({
+        success: false,
+        error: `Mailbox path '${newMailboxPath.join(" > ")}' already exists in account '${accountName}'.`,
+        errorCode: "MAILBOX_EXISTS",
+      });
+    }
+
+    const newParentPath = newMailboxPath.slice(0, -1);
+    if (!findMailboxByPath(targetAccount, newParentPath)) {
+      return JSON.stringify({
+        success: false,
+        error: `Parent mailbox path '${newParentPath.join(" > ")}' not found in account '${accountName}'. Create it with create_mailbox first.`,
+        errorCode: "MAILBOX_NOT_FOUND",
+      });
+    }
+
+    const sameParent =
+      mailboxPath.length === newMailboxPath.length &&
+      mailboxPath.slice(0, -1).every((name, i) => name === newParentPath[i]);
+    const newName = newMailboxPath[newMailboxPath.length - 1];
+    targetMailbox.name = sameParent ? newName : newMailboxPath.join("/");
+    log(sameParent ? "Renamed mailbox" : "Moved mailbox");
+
+    if (!findMailboxByPath(targetAccount, newMailboxPath)) {
+      return JSON.stringify({
+        success: false,
+        error: `Mailbox path '${newMailboxPath.join(" > ")}' could not be found after renaming '${mailboxPath.join(" > ")}' in account '${accountName}'. The account may still be syncing.`,
+        errorCode: "MAILBOX_NOT_RENAMED",
+        logs: logs.join("\n"),
+      });
+    }
+
+    return JSON.stringify({
+      success: true,
+      data: {
+        account: accountName,
+        previousMailboxPath: mailboxPath,
+        mailboxPath: newMailboxPath,
+        name: newName,
+        moved: !sameParent,
+      },
+      logs: logs.join("\n"),
+    });
+  } catch (e) {
+    return JSON.stringify({
+      success: false,
+      error: `Failed to rename mailbox: ${e.toString()}`,
+      logs: logs.join("\n"),
+    });
+  }
+}
@@ -0,0 +1,263 @@
+function run(argv) {
+  const Mail = Application("Mail");
+  Mail.includeStandardAdditions = true;
+  const SystemEvents = Application("System Events");
+
+  // 1. CRITICAL: Check if running FIRST
+  if (!Mail.running()) {
+    return JSON.stringify({
+      success: false,
+      error: "Mail.app is not running. Please start Mail.app and try again.",
+      errorCode: "MAIL_APP_NOT_RUNNING",
+    });
+  }
+
+  // 2. Logging setup
+  const logs = [];
+  function log(message) {
+    logs.push(message);
+  }
+
+  // 3. Argument parsing & validation
+  let args;
+  try {
+    args = JSON.parse(argv[0]);
+  } catch (e) {
+    return JSON.stringify({
+      success: false,
+      error: "Failed to parse input arguments JSON",
+      logs: logs.join("\n"),
+    });
+  }
+
+  const outgoingIdToReplace = parseInt(args.outgoing_id, 10) || 0;
+  const messageId = parseInt(args.message_id, 10) || 0;
+  const accountName = args.account || "";
+  const mailboxPath = args.mailbox_path || [];
+  const toRecipients = args.to_recipients || [];
+  const ccRecipients = args.cc_recipients || [];
+  const bccRecipients = args.bcc_recipients || [];
+  const signatureName = args.signature || "";
+
+  log(
+    `Replacing forward. Old outgoing_id: ${outgoingIdToReplace}, Original message_id: ${messageId}`,
+  );
+
+  if (
+    !outgoingIdToReplace ||
+    !messageId ||
+    !accountName ||
+    mailboxPath.length === 0
+  ) {
+    return JSON.stringify({
+      success: false,
+      error: "outgoing_id, message_id, account, and mailbox_path are required.",
+      errorCode: "MISSING_PARAMETERS",
+      logs: logs.join("\n"),
+    });
+  }
+
+  if (!Array.isArray(toRecipients) || toRecipients.length === 0) {
+    return JSON.stringify({
+      success: false,
+      error: "At least one To recipient is required.",
+      errorCode: "MISSING_PARAMETERS",
+      logs: logs.join("\n"),
+    });
+  }
+
+  // 4. Execution wrapped in try/catch
+  try {
+    // Resolve the Mail signature before creating the message
+    let signature = null;
+    if (signatureName) {
+      if (Mail.signatures.name().indexOf(signatureName) === -1) {
+        return JSON.stringify({
+          success: false,
+          error: `Signature '${signatureName}' not found. Use list_signatures to see the available signatures.`,
+          errorCode: "SIGNATURE_NOT_FOUND",
+          logs: logs.join("\n"),
+        });
+      }
+      signature = Mail.signatures.byName(signatureName);
+    }
+
+    // --- Step 1: Find and delete the old forward message window ---
+    const oldForwards = Mail.outgoingMessages.whose({
+      id: outgoingIdToReplace,
+    })();
+    if (oldForwards.length > 0) {
+      const oldForward = oldForwards[0];
+      log(
+        `Found old forward window to replace (Subject: "${oldForward.subject()}"). Deleting it.`,
+      );
+      Mail.delete(oldForward);
+    } else {
+      log(
+        `Warning: Outgoing message with ID ${outgoingIdToReplace} not found. It might have been closed or sent. Proceeding to create a new forward.`,
+      );
+    }
+
+    // --- Step 2: Find the original message to forward ---
+    const account = Mail.accounts[accountName];
+    try {
+      account.name();
+    } catch (e) {
+      return JSON.stringify({
+        success: false,
+        error: `Account '${accountName}' not found.`,
+      });
+    }
+
+    // Robust mailbox traversal function
+    function findMailboxByPath(account, targetPath) {
+      if (!targetPath || targetPath.length === 0) return account;
+
+      try {
+        let current = account;
+        for (let i = 0; i < targetPath.length; i++) {
+          const part = targetPath[i];
+          let next = null;
+          try {
+            next = current.mailboxes.whose({ name: part })()[0];
+          } catch (e) {}
+
+          if (!next) {
+            try {
+              next = current.mailboxes[part];
+              next.name();
+            } catch (e) {}
+          }
+          if (!next) throw new Error("not found");
+          current = next;
+        }
+        return current;
+      } catch (e) {}
+
+      try {
+        const allMailboxes = account.mailboxes();
+        for (let i = 0; i < allMailboxes.length; i++) {
+          const mbx = allMailboxes[i];
+          const path = [];
+          let current = mbx;
+          while (current) {
+            try {
+              const name = current.name();
+              if (name === account.name()) break;
+              path.unshift(name);
+              current = current.container();
+            } catch (e) {
+              break;
+            }
+          }
+          if (path.length === targetPath.length) {
+            let match = true;
+            for (let j = 0; j < path.length; j++) {
+              if (path[j] !== targetPath[j]) {
+                match = false;
+                break;
+              }
+            }
+            if (match) return mbx;
+          }
+        }
+      } catch (e) {}
+      return null;
+    }
+
+    let targetMailbox = findMailboxByPath(account, mailboxPath);
+    if (!targetMailbox) {
+      return JSON.stringify({
+        success: false,
+        error:
+          "Mailbox path '" +
+          mailboxPath.join(" > ") +
+          "' not found in account '" +
+          accountName +
+          "'.",
+      });
+    }
+
+    const messages = targetMailbox.messages.whose({ id: messageId })();
+    if (messages.length === 0) {
+      return JSON.stringify({
+        success: false,
+        error: `Original message with ID ${messageId} not found in mailbox '${mailboxPath.join(" > ")}'.`,
+        errorCode: "MESSAGE_NOT_FOUND",
+        logs: logs.join("\n"),
+      });
+    }
+    const originalMessage = messages[0];
+    log(`Found original message with ID ${messageId}.`);
+
+    // --- Step 3: Create a new forward of the original message ---
+    const newForwardMessage = originalMessage.forward({ openingWindow: true });
+    const forwardedAttachments = [];
+    try {
+      originalMessage.mailAttachments().forEach((att) => {
+        forwardedAttachments.push(att.name());
+      });
+    } catch (e) {
+      log(`Could not list attachments of the original: ${e.toString()}`);
+    }
+    log("New forward message window created.");
+
+    // --- Step 4: Set subject and recipients ---
+    if (args.subject !== undefined) {
+      newForwardMessage.subject = args.subject;
+      log(`Set new subject: "${args.subject}"`);
+    }
+    toRecipients.forEach((addr) =>
+      newForwardMessage.toRecipients.push(Mail.Recipient({ address: addr })),
+    );
+    ccRecipients.forEach((addr) =>
+      newForwardMessage.ccRecipients.push(Mail.Recipient({ address: addr })),
+    );
+    bccRecipients.forEach((addr) =>
+      newForwardMessage.bccRecipients.push(Mail.Recipient({ address: addr })),
+    );
+
+    // Add attachments. The Go layer has checked that the files exist and
+    // are in an allowed directory.
+    const attachmentPaths = args.attachments || [];
+    attachmentPaths.forEach((path) => {
+      newForwardMessage.content.attachments.push(
+        Mail.Attachment({ fileName: Path(path) }),
+      );
+      log(`Attached file: ${path}`);
+    });
+
+    // Mail places the signature below the pasted content
+    if (signature) {
+      newForwardMessage.messageSignature = signature;
+      log(`Applied signature: ${signatureName}`);
+    }
+
+    // NOTE: We do NOT save the forward. It remains an open OutgoingMessage.
+    Mail.activate();
+
+    const mailProcess = SystemEvents.processes.byName("Mail");
+    const pid = mailProcess.unixId();
+
+    // 5. CRITICAL: Return 'outgoing_id' for the new message.
+    return JSON.stringify({
+      success: true,
+      data: {
+        outgoing_id: newForwardMessage.id(),
+        subject: newForwardMessage.subject(),
+        pid: pid,
+        forwarded_attachments: forwardedAttachments,
+        message: "Forward was successfully replaced.",
+      },
+      logs: logs.join("\n"),
+    });
+  } catch (e) {
+    log(`Error during forward replacement: ${e.toString()}`);
+    return JSON.stringify({
+      success: false,
+      error: `Failed to replace forward: ${e.toString()}`,
+      errorCode: "UNKNOWN_ERROR",
+      logs: logs.join("\n"),
+    });
+  }
+}
@@ -31,6 +31,7 @@ function run(argv) {
   }
 
   const outgoingIdToReplace = args.outgoing_id;
+  const signatureName = args.signature || "";
 
   if (outgoingIdToReplace === undefined || outgoingIdToReplace === null) {
     return JSON.stringify({
@@ -44,6 +45,20 @@ function run(argv) {
 
   // 4. Execution wrapped in try/catch
   try {
+    // Resolve the Mail signature before creating the message
+    let signature = null;
+    if (signatureName) {
+      if (Mail.signatures.name().indexOf(signatureName) === -1) {
+        return JSON.stringify({
+          success: false,
+          error: `Signature '${signatureName}' not found. Use list_signatures to see the available signatures.`,
+          errorCode: "SIGNATURE_NOT_FOUND",
+          logs: logs.join("\n"),
+        });
+      }
+      signature = Mail.signatures.byName(signatureName);
+    }
+
     // --- Find the Old Message ---
     // We search directly in Mail.outgoingMessages (open windows/drafts)
     const messages = Mail.outgoingMessages.whose({ id: outgoingIdToReplace })();
@@ -101,10 +116,42 @@ function run(argv) {
     updateRecipients(newMsg.bccRecipients, args.bcc_recipients, oldBcc);
     log("Applied properties to new message.");
 
+    // Add attachments. The Go layer has checked that the files exist and
+    // are in an allowed directory.
+    const attachmentPaths = args.attachments || [];
+    attachmentPaths.forEach((path) => {
+      newMsg.content.attachments.push(
+        Mail.Attachment({ fileName: Path(path) }),
+      );
+      log(`Attached file: ${path}`);
+    });
+
     // --- Delete the Old Message ---
     Mail.delete(oldMsg);
     log(`Deleted old outgoing message with ID ${outgoingIdToReplace}.`);
 
+    // The account of the sender selects the Markdown signature the server
+    // appends to the content
+    let senderAccount = null;
+    const sender = newMsg.sender() || "";
+    const senderMatch = /<([^>]+)>/.exec(sender);
+    const senderAddress = (senderMatch ? senderMatch[1] : sender)
+      .trim()
+      .toLowerCase();
+    const accounts = Mail.accounts();
+    for (let i = 0; i < accounts.length && !senderAccount; i++) {
+      const addresses = accounts[i].emailAddresses();
+      if (addresses.some((a) => a.toLowerCase() === senderAddress)) {
+        senderAccount = accounts[i].name();
+      }
+    }
+
+    // Mail places the signature below the pasted content
+    if (signature) {
+      newMsg.messageSignature = signature;
+      log(`Applied signature: ${signatureName}`);
+    }
+
     // NOTE: We are NOT saving the message here. It exists as an open window.
 
     Mail.activate();
@@ -116,6 +163,7 @@ function run(argv) {
       data: {
         outgoing_id: newMsg.id(),
         subject: newMsg.subject(),
+        account: senderAccount,
         pid: pid,
         message: "Outgoing message was successfully replaced.",
       },
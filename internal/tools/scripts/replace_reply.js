@@ -35,6 +35,7 @@ function run(argv) {
   const accountName = args.account || "";
   const mailboxPath = args.mailbox_path || [];
   const replyToAll = args.reply_to_all === true;
+  const signatureName = args.signature || "";
 
   log(
     `Replacing reply. Old outgoing_id: ${outgoingIdToReplace}, Original message_id: ${messageId}`,
@@ -56,6 +57,20 @@ function run(argv) {
 
   // 4. Execution wrapped in try/catch
   try {
+    // Resolve the Mail signature before creating the message
+    let signature = null;
+    if (signatureName) {
+      if (Mail.signatures.name().indexOf(signatureName) === -1) {
+        return JSON.stringify({
+          success: false,
+          error: `Signature '${signatureName}' not found. Use list_signatures to see the available signatures.`,
+          errorCode: "SIGNATURE_NOT_FOUND",
+          logs: logs.join("\n"),
+        });
+      }
+      signature = Mail.signatures.byName(signatureName);
+    }
+
     // --- Step 1: Find and delete the old reply message window ---
     const oldReplies = Mail.outgoingMessages.whose({
       id: outgoingIdToReplace,
@@ -200,6 +215,22 @@ function run(argv) {
     if (updateRecipients(newReplyMessage.bccRecipients, args.bcc_recipients))
       log("Replaced Bcc: recipients.");
 
+    // Add attachments. The Go layer has checked that the files exist and
+    // are in an allowed directory.
+    const attachmentPaths = args.attachments || [];
+    attachmentPaths.forEach((path) => {
+      newReplyMessage.content.attachments.push(
+        Mail.Attachment({ fileName: Path(path) }),
+      );
+      log(`Attached file: ${path}`);
+    });
+
+    // Mail places the signature below the pasted content
+    if (signature) {
+      newReplyMessage.messageSignature = signature;
+      log(`Applied signature: ${signatureName}`);
+    }
+
     // NOTE: We do NOT save the reply. It remains an open OutgoingMessage.
     Mail.activate();
 
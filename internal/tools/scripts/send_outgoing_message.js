@@ -0,0 +1,129 @@
+function run(argv) {
+  const Mail = Application("Mail");
+  Mail.includeStandardAdditions = true;
+
+  // 1. CRITICAL: Check if running FIRST
+  if (!Mail.running()) {
+    return JSON.stringify({
+      success: false,
+      error: "Mail.app is not running. Please start Mail.app and try again.",
+      errorCode: "MAIL_APP_NOT_RUNNING",
+    });
+  }
+
+  // 2. Logging setup
+  const logs = [];
+  function log(message) {
+    logs.push(message);
+  }
+
+  // 3. Argument Parsing & Validation
+  let args;
+  try {
+    args = JSON.parse(argv[0]);
+  } catch (e) {
+    return JSON.stringify({
+      success: false,
+      error: "Failed to parse input arguments JSON",
+      logs: logs.join("\n"),
+    });
+  }
+
+  const outgoingId = args.outgoing_id;
+  const expected = {
+    to: args.to_recipients || [],
+    cc: args.cc_recipients || [],
+    bcc: args.bcc_recipients || [],
+  };
+
+  if (outgoingId === undefined || outgoingId === null) {
+    return JSON.stringify({
+      success: false,
+      error: "outgoing_id is required.",
+      errorCode: "MISSING_PARAMETERS",
+      logs: logs.join("\n"),
+    });
+  }
+
+  function addresses(recipients) {
+    const result = [];
+    const list = recipients();
+    for (let i = 0; i < list.length; i++) {
+      result.push(list[i].address());
+    }
+    return result;
+  }
+
+  function same(a, b) {
+    return a.length === b.length && a.every((value, i) => value === b[i]);
+  }
+
+  // 4. Execution wrapped in try/catch
+  try {
+    // Find the outgoing message using whose clause
+    // Mail.outgoingMessages contains open composition windows
+    const messages = Mail.outgoingMessages.whose({ id: outgoingId })();
+
+    if (messages.length === 0) {
+      return JSON.stringify({
+        success: false,
+        error: `Outgoing message with ID ${outgoingId} not found.`,
+        logs: logs.join("\n"),
+      });
+    }
+
+    const msg = messages[0];
+
+    // The Go layer checked the recipients against the send policy. Refuse to
+    // send if they were changed in the meantime.
+    const actual = {
+      to: addresses(msg.toRecipients),
+      cc: addresses(msg.ccRecipients),
+      bcc: addresses(msg.bccRecipients),
+    };
+    if (
+      !same(actual.to, expected.to) ||
+      !same(actual.cc, expected.cc) ||
+      !same(actual.bcc, expected.bcc)
+    ) {
+      return JSON.stringify({
+        success: false,
+        error:
+          "The recipients of the outgoing message changed after the policy check. The message was not sent.",
+        errorCode: "RECIPIENTS_CHANGED",
+        logs: logs.join("\n"),
+      });
+    }
+
+    const subject = msg.subject();
+
+    // Send the message. Mail.app moves it to the Sent mailbox and closes
+    // the window.
+    const sent = msg.send();
+    if (!sent) {
+      return JSON.stringify({
+        success: false,
+        error: `Mail.app could not send outgoing message with ID ${outgoingId}.`,
+        logs: logs.join("\n"),
+      });
+    }
+    log(`Sent outgoing message with ID ${outgoingId}.`);
+
+    return JSON.stringify({
+      success: true,
+      data: {
+        outgoing_id: outgoingId,
+        subject: subject,
+        message: "Outgoing message sent successfully.",
+      },
+      logs: logs.join("\n"),
+    });
+  } catch (e) {
+    log(`Error sending outgoing message: ${e.toString()}`);
+    return JSON.stringify({
+      success: false,
+      error: `Failed to send outgoing message: ${e.toString()}`,
+      logs: logs.join("\n"),
+    });
+  }
+}
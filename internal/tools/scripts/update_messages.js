@@ -0,0 +1,249 @@
+#!/usr/bin/osascript -l JavaScript
+
+/**
+ * Updates status properties of many messages in one call
+ *
+ * Arguments:
+ *   argv[0] - JSON string containing:
+ *     - account (required)
+ *     - mailboxPath (required) - Array like ["Inbox"] or ["Inbox","GitHub"]
+ *     - message_ids (required) - array of numeric message IDs
+ *     - readStatus (optional) - true to mark read, false to mark unread
+ *     - flaggedStatus (optional) - true to flag, false to unflag
+ *     - flagIndex (optional) - flag color 0-6, or -1 to remove the flag
+ *     - junkMailStatus (optional) - true to mark as junk, false for not junk
+ *
+ * Only the given properties are changed. Each message reports its resulting
+ * status, so repeating a call with the same arguments is safe.
+ */
+
+function run(argv) {
+  const Mail = Application("Mail");
+  Mail.includeStandardAdditions = true;
+
+  // Check if Mail.app is running
+  if (!Mail.running()) {
+    return JSON.stringify({
+      success: false,
+      error: "Mail.app is not running. Please start Mail.app and try again.",
+      errorCode: "MAIL_APP_NOT_RUNNING",
+    });
+  }
+
+  // Collect logs instead of using console.log
+  const logs = [];
+
+  // Helper function to log messages
+  function log(message) {
+    logs.push(message);
+  }
+
+  // Parse arguments
+  let args;
+  try {
+    args = JSON.parse(argv[0]);
+  } catch (e) {
+    return JSON.stringify({
+      success: false,
+      error: "Failed to parse input arguments JSON",
+    });
+  }
+
+  const accountName = args.account || "";
+  const mailboxPath = args.mailboxPath || [];
+  const messageIds = args.message_ids || [];
+  const readStatus = args.readStatus;
+  const flaggedStatus = args.flaggedStatus;
+  const flagIndex = args.flagIndex;
+  const junkMailStatus = args.junkMailStatus;
+
+  // Validate all required arguments explicitly
+  if (!accountName) {
+    return JSON.stringify({
+      success: false,
+      error: "Account name is required",
+    });
+  }
+
+  if (!Array.isArray(mailboxPath) || mailboxPath.length === 0) {
+    return JSON.stringify({
+      success: false,
+      error: "Mailbox path is required and must be a non-empty array",
+    });
+  }
+
+  if (!Array.isArray(messageIds) || messageIds.length === 0) {
+    return JSON.stringify({
+      success: false,
+      error: "message_ids is required and must be a non-empty array",
+    });
+  }
+
+  if (
+    readStatus === undefined &&
+    flaggedStatus === undefined &&
+    flagIndex === undefined &&
+    junkMailStatus === undefined
+  ) {
+    return JSON.stringify({
+      success: false,
+      error:
+        "At least one of readStatus, flaggedStatus, flagIndex or junkMailStatus is required",
+    });
+  }
+
+  if (flagIndex !== undefined && (flagIndex < -1 || flagIndex > 6)) {
+    return JSON.stringify({
+      success: false,
+      error: "flagIndex must be between -1 and 6",
+    });
+  }
+
+  try {
+    const targetAccount = Mail.accounts[accountName];
+    try {
+      targetAccount.name();
+    } catch (e) {
+      return JSON.stringify({
+        success: false,
+        error: `Account "${accountName}" not found. Please verify the account name is correct.`,
+      });
+    }
+
+    // Robust mailbox traversal function
+    function findMailboxByPath(account, targetPath) {
+      if (!targetPath || targetPath.length === 0) return account;
+
+      try {
+        let current = account;
+        for (let i = 0; i < targetPath.length; i++) {
+          const part = targetPath[i];
+          let next = null;
+          try {
+            next = current.mailboxes.whose({ name: part })()[0];
+          } catch (e) {}
+
+          if (!next) {
+            try {
+              next = current.mailboxes[part];
+              next.name();
+            } catch (e) {}
+          }
+          if (!next) throw new Error("not found");
+          current = next;
+        }
+        return current;
+      } catch (e) {}
+
+      try {
+        const allMailboxes = account.mailboxes();
+        for (let i = 0; i < allMailboxes.length; i++) {
+          const mbx = allMailboxes[i];
+          const path = [];
+          let current = mbx;
+          while (current) {
+            try {
+              const name = current.name();
+              if (name === account.name()) break;
+              path.unshift(name);
+              current = current.container();
+            } catch (e) {
+              break;
+            }
+          }
+          if (path.length === targetPath.length) {
+            let match = true;
+            for (let j = 0; j < path.length; j++) {
+              if (path[j] !== targetPath[j]) {
+                match = false;
+                break;
+              }
+            }
+            if (match) return mbx;
+          }
+        }
+      } catch (e) {}
+      return null;
+    }
+
+    const targetMailbox = findMailboxByPath(targetAccount, mailboxPath);
+    if (!targetMailbox) {
+      return JSON.stringify({
+        success: false,
+        error: `Mailbox path '${mailboxPath.join(" > ")}' not found in account '${accountName}'.`,
+      });
+    }
+
+    const results = [];
+    let updatedCount = 0;
+
+    for (let i = 0; i < messageIds.length; i++) {
+      const messageId = parseInt(messageIds[i], 10);
+      const result = { message_id: messageId, updated: false };
+
+      try {
+        const matches = targetMailbox.messages.whose({ id: messageId })();
+        if (!matches || matches.length === 0) {
+          result.error = `Message with ID ${messageId} not found in mailbox "${mailboxPath.join(" > ")}".`;
+          results.push(result);
+          continue;
+        }
+        const msg = matches[0];
+
+        if (readStatus !== undefined) {
+          msg.readStatus = readStatus;
+        }
+        // The flag color implies the flagged status, so set it first and let
+        // an explicit flaggedStatus override it.
+        if (flagIndex !== undefined) {
+          if (flagIndex === -1) {
+            msg.flaggedStatus = false;
+          } else {
+            msg.flagIndex = flagIndex;
+            msg.flaggedStatus = true;
+          }
+        }
+        if (flaggedStatus !== undefined) {
+          msg.flaggedStatus = flaggedStatus;
+        }
+        if (junkMailStatus !== undefined) {
+          msg.junkMailStatus = junkMailStatus;
+        }
+
+        result.updated = true;
+        updatedCount++;
+
+        try {
+          result.readStatus = msg.readStatus();
+          result.flaggedStatus = msg.flaggedStatus();
+          result.flagIndex = msg.flagIndex();
+          result.junkMailStatus = msg.junkMailStatus();
+        } catch (e) {
+          log(
+            `Could not read back status of message ${messageId}: ${e.toString()}`,
+          );
+        }
+      } catch (e) {
+        result.error = `Failed to update message: ${e.toString()}`;
+      }
+
+      results.push(result);
+    }
+
+    return JSON.stringify({
+      success: true,
+      data: {
+        results: results,
+        updated_count: updatedCount,
+        failed_count: results.length - updatedCount,
+      },
+      logs: logs.join("\n"),
+    });
+  } catch (e) {
+    return JSON.stringify({
+      success: false,
+      error: `Failed to update messages: ${e.toString()}`,
+      logs: logs.join("\n"),
+    });
+  }
+}
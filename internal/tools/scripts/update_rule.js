@@ -0,0 +1,330 @@
+#!/usr/bin/osascript -l JavaScript
+
+/**
+ * Updates a rule identified by its name
+ *
+ * Arguments:
+ *   argv[0] - JSON string containing:
+ *     - name (required) - name of the rule
+ *     - new_name (optional) - new name of the rule
+ *     - enabled (optional) - whether the rule is enabled
+ *     - allConditionsMustBeMet (optional) - all or any conditions must match
+ *     - conditions (optional) - conditions replacing all conditions, see
+ *       create_rule.js
+ *     - actions (optional) - supported actions replacing the current ones, see
+ *       create_rule.js. Unsupported actions such as run script are kept.
+ */
+
+function run(argv) {
+  const Mail = Application("Mail");
+  Mail.includeStandardAdditions = true;
+
+  // Check if Mail.app is running
+  if (!Mail.running()) {
+    return JSON.stringify({
+      success: false,
+      error: "Mail.app is not running. Please start Mail.app and try again.",
+      errorCode: "MAIL_APP_NOT_RUNNING",
+    });
+  }
+
+  // Collect logs instead of using console.log
+  const logs = [];
+
+  // Helper function to log messages
+  function log(message) {
+    logs.push(message);
+  }
+
+  // Parse arguments
+  let args;
+  try {
+    args = JSON.parse(argv[0]);
+  } catch (e) {
+    return JSON.stringify({
+      success: false,
+      error: "Failed to parse input arguments JSON",
+    });
+  }
+
+  const name = args.name || "";
+  const conditions = args.conditions || null;
+  const actions = args.actions || null;
+
+  if (!name) {
+    return JSON.stringify({
+      success: false,
+      error: "Rule name is required",
+    });
+  }
+
+  try {
+    // Read a property, falling back if it is missing or unset
+    function read(getter, fallback) {
+      try {
+        const value = getter();
+        return value === null || value === undefined ? fallback : value;
+      } catch (e) {
+        return fallback;
+      }
+    }
+
+    // Account and path of the mailbox a rule moves or copies to
+    function mailboxInfo(mailbox) {
+      if (!mailbox) return null;
+      try {
+        const accountName = mailbox.account().name();
+        const path = [];
+        let current = mailbox;
+        while (current) {
+          const name = current.name();
+          if (name === accountName) break;
+          path.unshift(name);
+          try {
+            current = current.container();
+          } catch (e) {
+            break;
+          }
+        }
+        return { account: accountName, mailboxPath: path };
+      } catch (e) {
+        log("Error reading rule mailbox: " + e.toString());
+        return null;
+      }
+    }
+
+    // Serialize a rule with all conditions and actions. Mapping them to the
+    // types of the tools, and finding unsupported ones, is left to the server.
+    function readRule(rule, index) {
+      const conditions = read(() => rule.ruleConditions(), []).map((c) => ({
+        ruleType: read(() => c.ruleType(), ""),
+        qualifier: read(() => c.qualifier(), ""),
+        expression: read(() => c.expression(), ""),
+        header: read(() => c.header(), ""),
+      }));
+      const forwardMessage = read(() => rule.forwardMessage(), "");
+      const runScript = read(() => rule.runScript(), null);
+      const colorMessage = read(() => rule.colorMessage(), "none");
+      return {
+        index: index,
+        name: read(() => rule.name(), ""),
+        enabled: read(() => rule.enabled(), false),
+        allConditionsMustBeMet: read(() => rule.allConditionsMustBeMet(), true),
+        conditions: conditions,
+        actions: {
+          moveMessage: read(() => rule.shouldMoveMessage(), false)
+            ? mailboxInfo(read(() => rule.moveMessage(), null))
+            : null,
+          copyMessage: read(() => rule.shouldCopyMessage(), false)
+            ? mailboxInfo(read(() => rule.copyMessage(), null))
+            : null,
+          markRead: read(() => rule.markRead(), false),
+          markFlagged: read(() => rule.markFlagged(), false),
+          markFlagIndex: read(() => rule.markFlagIndex(), -1),
+          deleteMessage: read(() => rule.deleteMessage(), false),
+          forwardMessage: forwardMessage,
+          stopEvaluatingRules: read(() => rule.stopEvaluatingRules(), false),
+          forwardText: forwardMessage ? read(() => rule.forwardText(), "") : "",
+          redirectMessage: read(() => rule.redirectMessage(), ""),
+          replyText: read(() => rule.replyText(), ""),
+          runScript: runScript ? runScript.toString() : "",
+          playSound: read(() => rule.playSound(), ""),
+          colorMessage: colorMessage === "none" ? "" : colorMessage,
+          highlightTextUsingColor: read(
+            () => rule.highlightTextUsingColor(),
+            false,
+          ),
+        },
+      };
+    }
+
+    // Rules are identified by name, Mail allows duplicates which cannot be
+    // told apart
+    function findRule(name) {
+      const names = Mail.rules.name();
+      const indexes = [];
+      for (let i = 0; i < names.length; i++) {
+        if (names[i] === name) indexes.push(i);
+      }
+      if (indexes.length === 0) {
+        return {
+          error: `Rule "${name}" not found.`,
+          errorCode: "RULE_NOT_FOUND",
+        };
+      }
+      if (indexes.length > 1) {
+        return {
+          error: `There are ${indexes.length} rules named "${name}". Rename them in Mail so they can be told apart.`,
+          errorCode: "AMBIGUOUS_RULE",
+        };
+      }
+      return { rule: Mail.rules[indexes[0]], index: indexes[0] + 1 };
+    }
+
+    const found = findRule(name);
+    if (found.error) {
+      return JSON.stringify({
+        success: false,
+        error: found.error,
+        errorCode: found.errorCode,
+      });
+    }
+    const rule = found.rule;
+
+    if (args.new_name && args.new_name !== name) {
+      if (Mail.rules.name().indexOf(args.new_name) !== -1) {
+        return JSON.stringify({
+          success: false,
+          error: `A rule named "${args.new_name}" already exists.`,
+          errorCode: "RULE_EXISTS",
+        });
+      }
+    }
+
+    // Robust mailbox traversal function
+    function findMailboxByPath(account, targetPath) {
+      if (!targetPath || targetPath.length === 0) return account;
+
+      try {
+        let current = account;
+        for (let i = 0; i < targetPath.length; i++) {
+          const part = targetPath[i];
+          let next = null;
+          try {
+            next = current.mailboxes.whose({ name: part })()[0];
+          } catch (e) {}
+
+          if (!next) {
+            try {
+              next = current.mailboxes[part];
+              next.name();
+            } catch (e) {}
+          }
+          if (!next) throw new Error("not found");
+          current = next;
+        }
+        return current;
+      } catch (e) {}
+
+      try {
+        const allMailboxes = account.mailboxes();
+        for (let i = 0; i < allMailboxes.length; i++) {
+          const mbx = allMailboxes[i];
+          const path = [];
+          let current = mbx;
+          while (current) {
+            try {
+              const name = current.name();
+              if (name === account.name()) break;
+              path.unshift(name);
+              current = current.container();
+            } catch (e) {
+              break;
+            }
+          }
+          if (path.length === targetPath.length) {
+            let match = true;
+            for (let j = 0; j < path.length; j++) {
+              if (path[j] !== targetPath[j]) {
+                match = false;
+                break;
+              }
+            }
+            if (match) return mbx;
+          }
+        }
+      } catch (e) {}
+      return null;
+    }
+
+    // Resolve the mailbox of a move or copy action
+    function resolveMailbox(target) {
+      if (!target) return null;
+      let account;
+      try {
+        account = Mail.accounts[target.account];
+        account.name();
+      } catch (e) {
+        throw new Error(`Account "${target.account}" not found.`);
+      }
+      const mailbox = findMailboxByPath(account, target.mailboxPath);
+      if (!mailbox) {
+        throw new Error(
+          `Mailbox path '${target.mailboxPath.join(" > ")}' not found in account '${target.account}'.`,
+        );
+      }
+      return mailbox;
+    }
+
+    // Replace all conditions of a rule
+    function writeConditions(rule, conditions) {
+      const existing = rule.ruleConditions();
+      for (let i = existing.length - 1; i >= 0; i--) {
+        Mail.delete(existing[i]);
+      }
+      for (const condition of conditions) {
+        const properties = {
+          ruleType: condition.ruleType,
+          qualifier: condition.qualifier,
+        };
+        if (condition.expression) properties.expression = condition.expression;
+        if (condition.header) properties.header = condition.header;
+        rule.ruleConditions.push(Mail.RuleCondition(properties));
+      }
+    }
+
+    // Set all supported actions of a rule, leaving unsupported ones such as
+    // run script untouched. Mailboxes are resolved before.
+    function writeActions(rule, actions, moveMailbox, copyMailbox) {
+      rule.shouldMoveMessage = moveMailbox !== null;
+      if (moveMailbox) rule.moveMessage = moveMailbox;
+      rule.shouldCopyMessage = copyMailbox !== null;
+      if (copyMailbox) rule.copyMessage = copyMailbox;
+      rule.markRead = actions.markRead;
+      rule.markFlagged = actions.markFlagged;
+      if (actions.markFlagged) rule.markFlagIndex = actions.markFlagIndex;
+      rule.deleteMessage = actions.deleteMessage;
+      rule.forwardMessage = actions.forwardMessage;
+      rule.stopEvaluatingRules = actions.stopEvaluatingRules;
+    }
+
+    // Resolve the mailboxes of the actions before changing anything
+    let moveMailbox = null;
+    let copyMailbox = null;
+    if (actions) {
+      try {
+        moveMailbox = resolveMailbox(actions.moveMessage);
+        copyMailbox = resolveMailbox(actions.copyMessage);
+      } catch (e) {
+        return JSON.stringify({
+          success: false,
+          error: e.message,
+          errorCode: "MAILBOX_NOT_FOUND",
+        });
+      }
+    }
+
+    if (args.new_name) rule.name = args.new_name;
+    if (args.enabled !== undefined) rule.enabled = args.enabled;
+    if (args.allConditionsMustBeMet !== undefined) {
+      rule.allConditionsMustBeMet = args.allConditionsMustBeMet;
+    }
+    if (conditions) writeConditions(rule, conditions);
+    if (actions) writeActions(rule, actions, moveMailbox, copyMailbox);
+    log(`Updated rule "${name}"`);
+
+    return JSON.stringify({
+      success: true,
+      data: {
+        rule: readRule(rule, found.index),
+      },
+      logs: logs.join("\n"),
+    });
+  } catch (e) {
+    return JSON.stringify({
+      success: false,
+      error: `Failed to update rule: ${e.toString()}`,
+      logs: logs.join("\n"),
+    });
+  }
+}
@@ -0,0 +1,132 @@
+package tools
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/dastrobu/mail-mcp/internal/jxa"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+//go:embed scripts/send_outgoing_message.js
+var sendOutgoingMessageSource string
+
+var sendOutgoingMessageScript = jxa.Script{Name: "send_outgoing_message", Source: sendOutgoingMessageSource}
+
+type SendOutgoingMessageInput struct {
+	OutgoingID int `json:"outgoing_id" jsonschema:"The ID of the outgoing message to send" long:"outgoing-id" description:"The ID of the outgoing message to send"`
+}
+
+// RegisterSendOutgoingMessage registers the send_outgoing_message tool with the MCP server
+func RegisterSendOutgoingMessage(srv *mcp.Server, executor jxa.Executor, cfg Config) {
+	mcp.AddTool(srv,
+		&mcp.Tool{
+			Name:        "send_outgoing_message",
+			Description: "Sends an outgoing message (open composition window) by its ID. The recipients are checked against the send policy of the server first: allowed domains, a maximum number of recipients and no external Bcc recipients. Returns the policy decision; the message is only sent if all checks pass. Disabled unless the server was started with --enable-send. This action is irreversible.",
+			InputSchema: GenerateSchema[SendOutgoingMessageInput](),
+			Annotations: &mcp.ToolAnnotations{
+				Title:           "Send Outgoing Message",
+				ReadOnlyHint:    false,
+				IdempotentHint:  false,
+				DestructiveHint: new(true),
+				OpenWorldHint:   new(true),
+			},
+		},
+		func(ctx context.Context, request *mcp.CallToolRequest, input SendOutgoingMessageInput) (*mcp.CallToolResult, any, error) {
+			return HandleSendOutgoingMessage(ctx, executor, cfg, request, input)
+		},
+	)
+}
+
+// outgoingMessage is an outgoing message as reported by the
+// list_outgoing_messages script.
+type outgoingMessage struct {
+	OutgoingID    int      `json:"outgoing_id"`
+	Subject       string   `json:"subject"`
+	Sender        string   `json:"sender"`
+	ToRecipients  []string `json:"to_recipients"`
+	CcRecipients  []string `json:"cc_recipients"`
+	BccRecipients []string `json:"bcc_recipients"`
+}
+
+func HandleSendOutgoingMessage(ctx context.Context, executor jxa.Executor, cfg Config, request *mcp.CallToolRequest, input SendOutgoingMessageInput) (*mcp.CallToolResult, any, error) {
+	if !cfg.EnableSend {
+		return nil, nil, fmt.Errorf("send_outgoing_message is disabled: start the server with --enable-send (or APPLE_MAIL_MCP_ENABLE_SEND=true) to allow sending")
+	}
+	if input.OutgoingID == 0 {
+		return nil, nil, fmt.Errorf("outgoing_id is required")
+	}
+
+	result, err := sendOutgoing(ctx, executor, cfg, input.OutgoingID, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return nil, result, nil
+}
+
+// sendOutgoing checks an outgoing message against the send policy of cfg and
+// sends it if the policy allows it. The result holds the decision. If verify
+// is given, it must accept the message before anything is sent.
+func sendOutgoing(ctx context.Context, executor jxa.Executor, cfg Config, outgoingID int, verify func(outgoingMessage) error) (map[string]any, error) {
+	msg, err := fetchOutgoingMessage(ctx, executor, outgoingID)
+	if err != nil {
+		return nil, err
+	}
+	if verify != nil {
+		if err := verify(msg); err != nil {
+			return nil, err
+		}
+	}
+	decision := sendPolicy(cfg).Check(msg.Sender, msg.ToRecipients, msg.CcRecipients, msg.BccRecipients)
+	result := map[string]any{
+		"outgoing_id": msg.OutgoingID,
+		"subject":     msg.Subject,
+		"sent":        false,
+		"policy":      decision,
+	}
+	if !decision.Allowed {
+		result["message"] = "Not sent, the message violates the send policy: " + strings.Join(decision.Violations(), "; ")
+		return result, nil
+	}
+
+	// The script refuses to send if the recipients changed since the check
+	inputJSON, err := json.Marshal(map[string]any{
+		"outgoing_id":    msg.OutgoingID,
+		"to_recipients":  msg.ToRecipients,
+		"cc_recipients":  msg.CcRecipients,
+		"bcc_recipients": msg.BccRecipients,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal input for JXA: %w", err)
+	}
+	if _, err := executor.Execute(ctx, sendOutgoingMessageScript, string(inputJSON)); err != nil {
+		return nil, fmt.Errorf("failed to execute send_outgoing_message: %w", err)
+	}
+	result["sent"] = true
+	result["message"] = "Outgoing message sent."
+	return result, nil
+}
+
+// fetchOutgoingMessage reads the subject, sender and recipients of an
+// outgoing message.
+func fetchOutgoingMessage(ctx context.Context, executor jxa.Executor, outgoingID int) (outgoingMessage, error) {
+	data, err := executor.Execute(ctx, listOutgoingMessagesScript)
+	if err != nil {
+		return outgoingMessage{}, fmt.Errorf("failed to execute list_outgoing_messages: %w", err)
+	}
+	var result struct {
+		Messages []outgoingMessage `json:"messages"`
+	}
+	if err := remarshal(data, &result); err != nil {
+		return outgoingMessage{}, fmt.Errorf("failed to parse list_outgoing_messages result: %w", err)
+	}
+	for _, msg := range result.Messages {
+		if msg.OutgoingID == outgoingID {
+			return msg, nil
+		}
+	}
+	return outgoingMessage{}, fmt.Errorf("outgoing message with ID %d not found", outgoingID)
+}
@@ -0,0 +1,172 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dastrobu/mail-mcp/internal/address"
+	"github.com/dastrobu/mail-mcp/internal/headers"
+)
+
+// defaultSendMaxRecipients is the maximum number of recipients of a message
+// send_outgoing_message sends if none is configured.
+const defaultSendMaxRecipients = 10
+
+// Rules of a SendPolicy
+const (
+	RuleValidAddresses  = "valid_addresses"
+	RuleMaxRecipients   = "max_recipients"
+	RuleDomainAllowlist = "domain_allowlist"
+	RuleNoExternalBcc   = "no_external_bcc"
+)
+
+// SendPolicy is checked by send_outgoing_message before a message is sent.
+// Recipients must be in an allowed domain. The domain of the sender is not
+// allowed by itself, since for providers such as gmail.com it would allow
+// every other user of the provider. If it is allowed, it is internal; other
+// domains are external.
+type SendPolicy struct {
+	AllowDomains      []string // domains recipients may be in, including subdomains
+	AllowSenderDomain bool     // recipients may also be in the domain of the sender
+	MaxRecipients     int      // maximum number of To, Cc and Bcc recipients
+}
+
+// sendPolicy returns the policy configured by cfg.
+func sendPolicy(cfg Config) SendPolicy {
+	policy := SendPolicy{AllowDomains: cfg.SendAllowDomains, AllowSenderDomain: cfg.SendAllowSenderDomain, MaxRecipients: cfg.SendMaxRecipients}
+	if policy.MaxRecipients <= 0 {
+		policy.MaxRecipients = defaultSendMaxRecipients
+	}
+	return policy
+}
+
+// PolicyCheck is the outcome of one rule of a SendPolicy.
+type PolicyCheck struct {
+	Rule   string `json:"rule"`
+	Passed bool   `json:"passed"`
+	Detail string `json:"detail"`
+}
+
+// PolicyDecision is the outcome of checking a message against a SendPolicy.
+// The message may be sent if all checks passed.
+type PolicyDecision struct {
+	Allowed        bool          `json:"allowed"`
+	SenderDomain   string        `json:"sender_domain"`
+	AllowedDomains []string      `json:"allowed_domains"`
+	MaxRecipients  int           `json:"max_recipients"`
+	Checks         []PolicyCheck `json:"checks"`
+}
+
+// Violations returns the details of the failed checks.
+func (d PolicyDecision) Violations() []string {
+	var out []string
+	for _, c := range d.Checks {
+		if !c.Passed {
+			out = append(out, c.Detail)
+		}
+	}
+	return out
+}
+
+// Check checks a message from sender to the given recipients against the
+// policy. All rules are checked, so the decision lists every violation.
+func (p SendPolicy) Check(sender string, to, cc, bcc []string) PolicyDecision {
+	senderDomain := ""
+	if addresses := headers.ParseAddressList(sender); len(addresses) > 0 {
+		senderDomain = domainOf(addresses[0].Email)
+	}
+	allowed := []string{}
+	if senderDomain != "" && p.AllowSenderDomain {
+		allowed = append(allowed, senderDomain)
+	}
+	for _, d := range p.AllowDomains {
+		if d = strings.TrimPrefix(strings.TrimSpace(d), "@"); d != "" {
+			allowed = append(allowed, normalizeDomain(d))
+		}
+	}
+	decision := PolicyDecision{SenderDomain: senderDomain, AllowedDomains: allowed, MaxRecipients: p.MaxRecipients}
+	// Bcc recipients may only be in the domain of the sender, and only if
+	// mail may be sent there at all
+	internal := []string{}
+	if inDomains(senderDomain, allowed) {
+		internal = append(internal, senderDomain)
+	}
+
+	var invalid, disallowed, externalBcc []string
+	count := 0
+	for i, recipients := range [][]string{to, cc, bcc} {
+		for _, r := range recipients {
+			count++
+			domain := recipientDomain(r)
+			switch {
+			case domain == "":
+				invalid = append(invalid, r)
+			case !inDomains(domain, allowed):
+				disallowed = append(disallowed, r)
+			}
+			if i == 2 && domain != "" && !inDomains(domain, internal) {
+				externalBcc = append(externalBcc, r)
+			}
+		}
+	}
+
+	decision.Checks = []PolicyCheck{
+		check(RuleValidAddresses, invalid, "all recipients have a valid address", "invalid recipient addresses: %s"),
+		{
+			Rule:   RuleMaxRecipients,
+			Passed: count > 0 && count <= p.MaxRecipients,
+			Detail: fmt.Sprintf("%d recipients, at most %d allowed", count, p.MaxRecipients),
+		},
+		check(RuleDomainAllowlist, disallowed, "all recipients are in allowed domains", "recipients outside the allowed domains: %s"),
+		check(RuleNoExternalBcc, externalBcc, "no external Bcc recipients", "external Bcc recipients are not allowed: %s"),
+	}
+	if count == 0 {
+		decision.Checks[1].Detail = "the message has no recipients"
+	}
+	decision.Allowed = len(decision.Violations()) == 0
+	return decision
+}
+
+func check(rule string, offenders []string, passed, failed string) PolicyCheck {
+	if len(offenders) == 0 {
+		return PolicyCheck{Rule: rule, Passed: true, Detail: passed}
+	}
+	return PolicyCheck{Rule: rule, Detail: fmt.Sprintf(failed, strings.Join(offenders, ", "))}
+}
+
+// recipientDomain returns the domain of a recipient such as "bob@example.com"
+// or "Bob <bob@example.com>", or "" if it has no valid address.
+func recipientDomain(recipient string) string {
+	addresses := headers.ParseAddressList(recipient)
+	if len(addresses) != 1 {
+		return ""
+	}
+	return domainOf(addresses[0].Email)
+}
+
+func domainOf(email string) string {
+	i := strings.LastIndexByte(email, '@')
+	if i <= 0 || i == len(email)-1 {
+		return ""
+	}
+	return normalizeDomain(email[i+1:])
+}
+
+// normalizeDomain returns the ASCII form of a domain, so internationalized
+// domains match however they are written.
+func normalizeDomain(domain string) string {
+	if ascii, err := address.NormalizeDomain(domain); err == nil {
+		return ascii
+	}
+	return strings.ToLower(domain)
+}
+
+// inDomains reports whether domain is one of domains or a subdomain of one.
+func inDomains(domain string, domains []string) bool {
+	for _, d := range domains {
+		if d != "" && (domain == d || strings.HasSuffix(domain, "."+d)) {
+			return true
+		}
+	}
+	return false
+}
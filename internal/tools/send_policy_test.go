@@ -0,0 +1,98 @@
+package tools
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSendPolicy_Check(t *testing.T) {
+	policy := SendPolicy{AllowDomains: []string{"partner.example", "@Client.example", "Bücher.example"}, AllowSenderDomain: true, MaxRecipients: 3}
+	sender := "Me <me@corp.example>"
+
+	tests := []struct {
+		name          string
+		to, cc, bcc   []string
+		wantViolation []string // rules that fail
+	}{
+		{name: "internal", to: []string{"team@corp.example"}},
+		{name: "subdomain of sender", to: []string{"ops@eu.corp.example"}},
+		{name: "allowed domains", to: []string{"Anna <anna@partner.example>"}, cc: []string{"bob@sales.client.example"}},
+		{name: "internationalized domain", to: []string{"a@xn--bcher-kva.example", "b@BÜCHER.example"}},
+		{name: "domain not allowed", to: []string{"x@other.example", "team@corp.example"}, wantViolation: []string{RuleDomainAllowlist}},
+		{name: "suffix is not a subdomain", to: []string{"x@notpartner.example"}, wantViolation: []string{RuleDomainAllowlist}},
+		{name: "too many recipients", to: []string{"a@corp.example", "b@corp.example"}, cc: []string{"c@corp.example", "d@corp.example"}, wantViolation: []string{RuleMaxRecipients}},
+		{name: "no recipients", wantViolation: []string{RuleMaxRecipients}},
+		{name: "internal bcc", to: []string{"a@partner.example"}, bcc: []string{"audit@corp.example"}},
+		{name: "external bcc", to: []string{"a@corp.example"}, bcc: []string{"a@partner.example"}, wantViolation: []string{RuleNoExternalBcc}},
+		{name: "invalid address", to: []string{"not an address"}, wantViolation: []string{RuleValidAddresses}},
+		{
+			name:          "all violations",
+			to:            []string{"x@other.example", "broken", "a@corp.example"},
+			bcc:           []string{"y@partner.example"},
+			wantViolation: []string{RuleValidAddresses, RuleMaxRecipients, RuleDomainAllowlist, RuleNoExternalBcc},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := policy.Check(sender, tt.to, tt.cc, tt.bcc)
+			var failed []string
+			for _, c := range decision.Checks {
+				if !c.Passed {
+					failed = append(failed, c.Rule)
+				}
+			}
+			if !slices.Equal(failed, tt.wantViolation) {
+				t.Errorf("failed rules = %v, want %v (checks %+v)", failed, tt.wantViolation, decision.Checks)
+			}
+			if decision.Allowed != (len(tt.wantViolation) == 0) {
+				t.Errorf("Allowed = %v with failed rules %v", decision.Allowed, failed)
+			}
+		})
+	}
+
+	decision := policy.Check(sender, []string{"a@corp.example"}, nil, nil)
+	if decision.SenderDomain != "corp.example" || !slices.Equal(decision.AllowedDomains, []string{"corp.example", "partner.example", "client.example", "xn--bcher-kva.example"}) {
+		t.Errorf("decision = %+v, want the sender domain and the normalized allowlist", decision)
+	}
+}
+
+func TestSendPolicy_ConsumerDomain(t *testing.T) {
+	// The sender's domain is shared with every other user of the provider
+	policy := SendPolicy{AllowDomains: []string{"partner.example"}, MaxRecipients: 3}
+	decision := policy.Check("Me <me@gmail.com>", []string{"stranger@gmail.com"}, nil, []string{"other@gmail.com"})
+	var failed []string
+	for _, c := range decision.Checks {
+		if !c.Passed {
+			failed = append(failed, c.Rule)
+		}
+	}
+	if want := []string{RuleDomainAllowlist, RuleNoExternalBcc}; !slices.Equal(failed, want) {
+		t.Errorf("failed rules = %v, want %v (checks %+v)", failed, want, decision.Checks)
+	}
+	if !slices.Equal(decision.AllowedDomains, []string{"partner.example"}) {
+		t.Errorf("AllowedDomains = %v, want only the configured domains", decision.AllowedDomains)
+	}
+
+	// Listing the sender's domain allows it like the opt-in
+	policy.AllowDomains = append(policy.AllowDomains, "gmail.com")
+	if decision := policy.Check("me@gmail.com", []string{"friend@gmail.com"}, nil, []string{"me@gmail.com"}); !decision.Allowed {
+		t.Errorf("decision = %+v, want allowed with the sender's domain listed", decision)
+	}
+}
+
+func TestSendPolicy_Defaults(t *testing.T) {
+	if got := sendPolicy(Config{}).MaxRecipients; got != defaultSendMaxRecipients {
+		t.Errorf("MaxRecipients = %d, want %d", got, defaultSendMaxRecipients)
+	}
+	// Without an allowlist, not even the sender's domain may be sent to
+	for _, to := range []string{"x@other.example", "team@corp.example"} {
+		decision := sendPolicy(Config{}).Check("me@corp.example", []string{to}, nil, nil)
+		if decision.Allowed {
+			t.Errorf("decision for %s = %+v, want rejected without an allowlist", to, decision)
+		}
+	}
+	if decision := sendPolicy(Config{SendAllowSenderDomain: true}).Check("me@corp.example", []string{"team@corp.example"}, nil, nil); !decision.Allowed {
+		t.Errorf("decision = %+v, want the sender's domain allowed with --send-allow-sender-domain", decision)
+	}
+}
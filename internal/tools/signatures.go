@@ -0,0 +1,140 @@
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dastrobu/mail-mcp/internal/md"
+)
+
+// signatureNone disables the signature of a message.
+const signatureNone = "none"
+
+// fileSignature is the Markdown signature of an account in the signature
+// directory.
+type fileSignature struct {
+	Account  string `json:"account"`
+	Path     string `json:"path"`
+	Markdown string `json:"content"`
+}
+
+func signatureDir(cfg Config) (string, error) {
+	if cfg.SignatureDir != "" {
+		return cfg.SignatureDir, nil
+	}
+	return appSupportPath("signatures")
+}
+
+// loadFileSignature returns the Markdown signature of account, or nil if the
+// account has none.
+func loadFileSignature(cfg Config, account string) (*fileSignature, error) {
+	// The file name is the account name, which must not leave the directory
+	if account == "" || account != filepath.Base(account) || strings.HasPrefix(account, ".") {
+		return nil, nil
+	}
+	dir, err := signatureDir(cfg)
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, account+".md")
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signature: %w", err)
+	}
+	markdown := strings.TrimSpace(string(data))
+	if markdown == "" {
+		return nil, nil
+	}
+	return &fileSignature{Account: account, Path: path, Markdown: markdown}, nil
+}
+
+// listFileSignatures returns the Markdown signatures in the signature
+// directory, which may not exist.
+func listFileSignatures(cfg Config) (string, []fileSignature, error) {
+	dir, err := signatureDir(cfg)
+	if err != nil {
+		return "", nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return dir, []fileSignature{}, nil
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read signature directory: %w", err)
+	}
+	signatures := []fileSignature{}
+	for _, entry := range entries {
+		account, ok := strings.CutSuffix(entry.Name(), ".md")
+		if !ok || entry.IsDir() {
+			continue
+		}
+		signature, err := loadFileSignature(cfg, account)
+		if err != nil {
+			return "", nil, err
+		}
+		if signature != nil {
+			signatures = append(signatures, *signature)
+		}
+	}
+	return dir, signatures, nil
+}
+
+// signatureChoice is how a composed message is signed: with a Mail signature
+// the scripts apply, or a Markdown signature appended to the content.
+type signatureChoice struct {
+	Mail string
+	File *fileSignature
+}
+
+// chooseSignature resolves the signature parameter of the compose tools. A
+// name selects a Mail signature and "none" disables signatures. Without a
+// name, the Markdown signature of account is used if there is one.
+func chooseSignature(cfg Config, account, signature string) (signatureChoice, error) {
+	switch signature {
+	case "":
+		file, err := loadFileSignature(cfg, account)
+		return signatureChoice{File: file}, err
+	case signatureNone:
+		return signatureChoice{}, nil
+	default:
+		return signatureChoice{Mail: signature}, nil
+	}
+}
+
+// apply appends the Markdown signature to the clipboard content. The HTML
+// gets the rendered signature, the plain text the Markdown.
+func (c signatureChoice) apply(htmlContent *string, plainContent string) (*string, string, error) {
+	if c.File == nil {
+		return htmlContent, plainContent, nil
+	}
+	plain := strings.TrimRight(plainContent, "\n") + "\n\n" + c.File.Markdown
+	if htmlContent == nil {
+		return nil, plain, nil
+	}
+	rendered, err := md.Render(c.File.Markdown)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to render signature %s: %w", c.File.Path, err)
+	}
+	html := *htmlContent + rendered
+	return &html, plain, nil
+}
+
+// result describes the signature in the result of a compose tool, nil if the
+// message is not signed.
+func (c signatureChoice) result() any {
+	switch {
+	case c.Mail != "":
+		return map[string]any{"source": "mail", "name": c.Mail}
+	case c.File != nil:
+		return map[string]any{"source": "file", "account": c.File.Account, "path": c.File.Path}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,104 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadFileSignature(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "Work.md"), []byte("**Bob**\n\nACME Inc.\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "Empty.md"), []byte("\n \n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(filepath.Dir(dir), "Outside.md"), []byte("outside"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := Config{SignatureDir: dir}
+
+	signature, err := loadFileSignature(cfg, "Work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signature == nil || signature.Markdown != "**Bob**\n\nACME Inc." || signature.Path != filepath.Join(dir, "Work.md") {
+		t.Errorf("loadFileSignature(Work) = %+v, want the trimmed Markdown of Work.md", signature)
+	}
+	for _, account := range []string{"Home", "Empty", "", "../Outside", ".hidden"} {
+		if signature, err := loadFileSignature(cfg, account); err != nil || signature != nil {
+			t.Errorf("loadFileSignature(%q) = %+v, %v, want none", account, signature, err)
+		}
+	}
+
+	gotDir, signatures, err := listFileSignatures(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotDir != dir || len(signatures) != 1 || signatures[0].Account != "Work" {
+		t.Errorf("listFileSignatures() = %q, %+v, want the Work signature only", gotDir, signatures)
+	}
+	if _, signatures, err := listFileSignatures(Config{SignatureDir: filepath.Join(dir, "missing")}); err != nil || len(signatures) != 0 {
+		t.Errorf("listFileSignatures(missing) = %+v, %v, want no signatures", signatures, err)
+	}
+}
+
+func TestChooseSignature(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "Work.md"), []byte("**Bob**"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := Config{SignatureDir: dir}
+
+	tests := []struct {
+		name      string
+		account   string
+		signature string
+		wantMail  string
+		wantFile  bool
+	}{
+		{name: "file signature of the account", account: "Work", wantFile: true},
+		{name: "account without file signature", account: "Home"},
+		{name: "mail signature wins", account: "Work", signature: "Formal", wantMail: "Formal"},
+		{name: "none", account: "Work", signature: signatureNone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			choice, err := chooseSignature(cfg, tt.account, tt.signature)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if choice.Mail != tt.wantMail || (choice.File != nil) != tt.wantFile {
+				t.Errorf("chooseSignature() = %+v, want Mail %q and file %v", choice, tt.wantMail, tt.wantFile)
+			}
+		})
+	}
+}
+
+func TestSignatureChoice_Apply(t *testing.T) {
+	choice := signatureChoice{File: &fileSignature{Account: "Work", Markdown: "**Bob**"}}
+
+	html := "<p>Hi</p>"
+	gotHTML, gotPlain, err := choice.apply(&html, "Hi\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotPlain != "Hi\n\n**Bob**" {
+		t.Errorf("plain = %q, want %q", gotPlain, "Hi\n\n**Bob**")
+	}
+	if gotHTML == nil || !strings.HasPrefix(*gotHTML, html) || !strings.Contains(*gotHTML, "<strong>Bob</strong>") {
+		t.Errorf("html = %v, want the content followed by the rendered signature", gotHTML)
+	}
+
+	gotHTML, gotPlain, err = choice.apply(nil, "Hi")
+	if err != nil || gotHTML != nil || gotPlain != "Hi\n\n**Bob**" {
+		t.Errorf("apply(nil) = %v, %q, %v, want plain text only", gotHTML, gotPlain, err)
+	}
+
+	gotHTML, gotPlain, err = signatureChoice{Mail: "Formal"}.apply(&html, "Hi")
+	if err != nil || gotHTML != &html || gotPlain != "Hi" {
+		t.Errorf("apply() with a Mail signature = %v, %q, %v, want the content unchanged", gotHTML, gotPlain, err)
+	}
+}
@@ -0,0 +1,38 @@
+package tools
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/dastrobu/mail-mcp/internal/quote"
+)
+
+// validateStripQuotes checks that quotes can be stripped from the body
+// format. Quotes are detected in text, not in HTML.
+func validateStripQuotes(stripQuotes bool, bodyFormat string) error {
+	if stripQuotes && bodyFormat == BodyFormatHTML {
+		return fmt.Errorf("strip_quotes is not supported with body_format html, use plain or markdown")
+	}
+	return nil
+}
+
+// stripContent removes quoted history and the trailing signature from the
+// content of a message returned by a script. It returns the number of
+// characters removed.
+func stripContent(message map[string]any, contentKey string) int {
+	content, ok := message[contentKey].(string)
+	if !ok {
+		return 0
+	}
+	result := quote.Strip(content)
+	message[contentKey] = result.Text
+	return result.Removed()
+}
+
+// contentPreview shortens content to 100 characters like the scripts do.
+func contentPreview(content string) string {
+	if utf8.RuneCountInString(content) <= 100 {
+		return content
+	}
+	return string([]rune(content)[:100]) + "..."
+}
@@ -1,25 +1,62 @@
 package tools
 
 import (
+	"github.com/dastrobu/mail-mcp/internal/jxa"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
-// RegisterAll registers all available tools with the MCP server.
-func RegisterAll(srv *mcp.Server) {
+// RegisterAll registers all available tools with the MCP server. All tools run
+// their scripts through the given executor and are configured by cfg.
+func RegisterAll(srv *mcp.Server, executor jxa.Executor, cfg Config) {
 	// Informational tools
-	RegisterListAccounts(srv)
-	RegisterListMailboxes(srv)
-	RegisterGetMessageContent(srv)
-	RegisterFindMessages(srv)
-	RegisterGetSelectedMessages(srv)
-	RegisterListOutgoingMessages(srv)
-	RegisterListDrafts(srv)
+	RegisterListAccounts(srv, executor)
+	RegisterListMailboxes(srv, executor)
+	RegisterListRules(srv, executor)
+	RegisterListSignatures(srv, executor, cfg)
+	RegisterResolveContacts(srv, executor, cfg)
+	RegisterGetMessageContent(srv, executor)
+	RegisterGetMessageSource(srv, executor)
+	RegisterFindMessages(srv, executor)
+	RegisterGetThread(srv, executor)
+	RegisterGetSelectedMessages(srv, executor)
+	RegisterListOutgoingMessages(srv, executor)
+	RegisterListDrafts(srv, executor)
 
 	// Message creation and manipulation tools
-	RegisterCreateReply(srv)
-	RegisterReplaceReply(srv)
-	RegisterCreateOutgoingMessage(srv)
-	RegisterReplaceOutgoingMessage(srv)
-	RegisterDeleteOutgoingMessage(srv)
-	RegisterDeleteDraft(srv)
+	RegisterCreateReply(srv, executor, cfg)
+	RegisterReplaceReply(srv, executor, cfg)
+	RegisterCreateForward(srv, executor, cfg)
+	RegisterReplaceForward(srv, executor, cfg)
+	RegisterRedirectMessage(srv, executor, cfg)
+	RegisterCreateOutgoingMessage(srv, executor, cfg)
+	RegisterListTemplates(srv, executor, cfg)
+	RegisterCreateFromTemplate(srv, executor, cfg)
+	RegisterMailMerge(srv, executor, cfg)
+	RegisterReplaceOutgoingMessage(srv, executor, cfg)
+	RegisterSendOutgoingMessage(srv, executor, cfg)
+	RegisterScheduleSend(srv, executor, cfg)
+	RegisterListScheduled(srv, executor, cfg)
+	RegisterCancelScheduled(srv, executor, cfg)
+	RegisterDeleteOutgoingMessage(srv, executor)
+	RegisterDeleteDraft(srv, executor)
+
+	// Message organization tools
+	RegisterMoveMessages(srv, executor)
+	RegisterUpdateMessages(srv, executor)
+	RegisterDeleteMessages(srv, executor)
+
+	// Mailbox management tools
+	RegisterCreateMailbox(srv, executor)
+	RegisterRenameMailbox(srv, executor)
+	RegisterDeleteMailbox(srv, executor)
+
+	// Rule management tools
+	RegisterCreateRule(srv, executor)
+	RegisterUpdateRule(srv, executor)
+	RegisterDeleteRule(srv, executor)
+
+	// Attachment tools
+	RegisterListAttachments(srv, executor)
+	RegisterSaveAttachment(srv, executor, cfg)
+	RegisterExportMessages(srv, executor, cfg)
 }
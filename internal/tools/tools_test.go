@@ -0,0 +1,1752 @@
+package tools
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dastrobu/mail-mcp/internal/fakemail"
+	"github.com/dastrobu/mail-mcp/internal/schedule"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// newTestMail returns a fake Mail.app with one account, a nested Inbox,
+// Drafts and a Trash mailbox.
+func newTestMail() *fakemail.Mail {
+	mail := fakemail.New()
+	work := mail.AddAccount("Work", "me@example.com")
+	inbox := work.AddMailbox("Inbox")
+	inbox.AddMessage(&fakemail.Message{
+		Subject:      "Meeting Tomorrow",
+		Sender:       "Boss <boss@example.com>",
+		DateReceived: time.Date(2024, 2, 11, 10, 30, 0, 0, time.UTC),
+		DateSent:     time.Date(2024, 2, 11, 10, 25, 0, 0, time.UTC),
+		Content:      "Please be on time.",
+		To:           []fakemail.Recipient{{Name: "Me", Address: "me@example.com"}},
+		Cc:           []fakemail.Recipient{{Name: "Colleague", Address: "colleague@example.com"}},
+		Attachments: []fakemail.Attachment{
+			{Name: "invite.ics", Downloaded: true, Content: []byte("BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n")},
+			{Name: "agenda.pdf", MIMEType: "application/pdf", FileSize: 2048},
+		},
+	})
+	inbox.AddMessage(&fakemail.Message{
+		Subject:      "Lunch?",
+		Sender:       "colleague@example.com",
+		DateReceived: time.Date(2024, 2, 12, 12, 0, 0, 0, time.UTC),
+		Content:      "Pizza or sushi?",
+		ReadStatus:   true,
+	})
+	work.AddMailbox("Inbox", "GitHub").AddMessage(&fakemail.Message{
+		Subject:      "[repo] Pull Request #1",
+		Sender:       "notifications@github.com",
+		DateReceived: time.Date(2024, 2, 13, 8, 0, 0, 0, time.UTC),
+	})
+	work.AddMailbox("Drafts").AddMessage(&fakemail.Message{
+		Subject: "Draft",
+		Sender:  "me@example.com",
+		To:      []fakemail.Recipient{{Address: "someone@example.com"}},
+	})
+	work.AddMailbox("Trash")
+	return mail
+}
+
+// callTool calls a tool through an in-memory MCP session backed by the fake
+// and returns its structured content.
+func callTool(t *testing.T, mail *fakemail.Mail, name string, args any) (map[string]any, bool) {
+	t.Helper()
+	return callToolWithConfig(t, mail, Config{}, name, args)
+}
+
+// callToolWithConfig is like callTool with the tools configured by cfg.
+func callToolWithConfig(t *testing.T, mail *fakemail.Mail, cfg Config, name string, args any) (map[string]any, bool) {
+	t.Helper()
+	ctx := context.Background()
+
+	srv := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	RegisterAll(srv, mail, cfg)
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+	if _, err := srv.Connect(ctx, serverTransport, nil); err != nil {
+		t.Fatalf("server connect: %v", err)
+	}
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client connect: %v", err)
+	}
+	defer session.Close()
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: name, Arguments: args})
+	if err != nil {
+		t.Fatalf("CallTool(%s) error = %v", name, err)
+	}
+	if result.IsError {
+		var text string
+		for _, c := range result.Content {
+			if tc, ok := c.(*mcp.TextContent); ok {
+				text += tc.Text
+			}
+		}
+		return map[string]any{"error": text}, false
+	}
+	data, err := json.Marshal(result.StructuredContent)
+	if err != nil {
+		t.Fatalf("marshal structured content: %v", err)
+	}
+	var out map[string]any
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("unmarshal structured content: %v", err)
+	}
+	return out, true
+}
+
+func TestTools_EndToEnd(t *testing.T) {
+	mail := newTestMail()
+
+	t.Run("list_accounts", func(t *testing.T) {
+		out, ok := callTool(t, mail, "list_accounts", map[string]any{"enabled": false})
+		if !ok {
+			t.Fatalf("list_accounts failed: %v", out)
+		}
+		if out["count"] != float64(1) {
+			t.Errorf("count = %v, want 1", out["count"])
+		}
+	})
+
+	t.Run("list_mailboxes nested", func(t *testing.T) {
+		out, ok := callTool(t, mail, "list_mailboxes", map[string]any{"account": "Work", "mailboxPath": []string{"Inbox"}})
+		if !ok {
+			t.Fatalf("list_mailboxes failed: %v", out)
+		}
+		mailboxes := out["mailboxes"].([]any)
+		if len(mailboxes) != 1 || mailboxes[0].(map[string]any)["name"] != "GitHub" {
+			t.Errorf("mailboxes = %v, want [GitHub]", mailboxes)
+		}
+	})
+
+	t.Run("find_messages", func(t *testing.T) {
+		out, ok := callTool(t, mail, "find_messages", map[string]any{"account": "Work", "mailboxPath": []string{"Inbox"}, "readStatus": false})
+		if !ok {
+			t.Fatalf("find_messages failed: %v", out)
+		}
+		if out["total_matches"] != float64(1) {
+			t.Errorf("total_matches = %v, want 1", out["total_matches"])
+		}
+	})
+
+	t.Run("find_messages requires a filter", func(t *testing.T) {
+		out, ok := callTool(t, mail, "find_messages", map[string]any{"account": "Work", "mailboxPath": []string{"Inbox"}})
+		if ok || !strings.Contains(out["error"].(string), "at least one filter criterion") {
+			t.Errorf("expected filter error, got %v", out)
+		}
+	})
+
+	t.Run("get_message_content", func(t *testing.T) {
+		out, ok := callTool(t, mail, "get_message_content", map[string]any{"account": "Work", "mailboxPath": []string{"Inbox"}, "message_id": 1})
+		if !ok {
+			t.Fatalf("get_message_content failed: %v", out)
+		}
+		msg := out["message"].(map[string]any)
+		if msg["subject"] != "Meeting Tomorrow" || msg["dateReceived"] != "2024-02-11T10:30:00.000Z" {
+			t.Errorf("message = %v", msg)
+		}
+	})
+
+	t.Run("get_message_content unknown mailbox", func(t *testing.T) {
+		out, ok := callTool(t, mail, "get_message_content", map[string]any{"account": "Work", "mailboxPath": []string{"Nope"}, "message_id": 1})
+		if ok || !strings.Contains(out["error"].(string), "Mailbox path 'Nope' not found") {
+			t.Errorf("expected mailbox error, got %v", out)
+		}
+	})
+
+	t.Run("list_drafts and delete_draft", func(t *testing.T) {
+		out, ok := callTool(t, mail, "list_drafts", map[string]any{"account": "Work"})
+		if !ok || out["count"] != float64(1) {
+			t.Fatalf("list_drafts = %v", out)
+		}
+		draftID := out["drafts"].([]any)[0].(map[string]any)["draft_id"]
+		if out, ok := callTool(t, mail, "delete_draft", map[string]any{"draft_id": draftID}); !ok {
+			t.Fatalf("delete_draft failed: %v", out)
+		}
+		out, _ = callTool(t, mail, "list_drafts", map[string]any{})
+		if out["count"] != float64(0) {
+			t.Errorf("drafts after delete = %v, want 0", out["count"])
+		}
+	})
+
+	t.Run("outgoing message lifecycle", func(t *testing.T) {
+		out, ok := callTool(t, mail, "create_outgoing_message", map[string]any{
+			"account":       "Work",
+			"subject":       "Report",
+			"content":       "# Weekly",
+			"to_recipients": []string{"team@example.com"},
+		})
+		if !ok {
+			t.Fatalf("create_outgoing_message failed: %v", out)
+		}
+		subject := "Report v2"
+		out, ok = callTool(t, mail, "replace_outgoing_message", map[string]any{
+			"outgoing_id": out["outgoing_id"],
+			"subject":     subject,
+			"content":     "# Weekly v2",
+		})
+		if !ok {
+			t.Fatalf("replace_outgoing_message failed: %v", out)
+		}
+		outgoing := mail.OutgoingMessages()
+		if len(outgoing) != 1 || outgoing[0].Subject != subject || outgoing[0].To[0] != "team@example.com" {
+			t.Errorf("outgoing messages = %+v", outgoing)
+		}
+		if out, ok := callTool(t, mail, "delete_outgoing_message", map[string]any{"outgoing_id": out["outgoing_id"]}); !ok {
+			t.Fatalf("delete_outgoing_message failed: %v", out)
+		}
+		out, _ = callTool(t, mail, "list_outgoing_messages", map[string]any{})
+		if out["count"] != float64(0) {
+			t.Errorf("outgoing after delete = %v, want 0", out["count"])
+		}
+	})
+
+	t.Run("reply lifecycle", func(t *testing.T) {
+		out, ok := callTool(t, mail, "create_reply", map[string]any{
+			"account":      "Work",
+			"mailbox_path": []string{"Inbox"},
+			"message_id":   1,
+			"content":      "Will do.",
+			"reply_to_all": true,
+		})
+		if !ok {
+			t.Fatalf("create_reply failed: %v", out)
+		}
+		if out["subject"] != "Re: Meeting Tomorrow" {
+			t.Errorf("subject = %v", out["subject"])
+		}
+		reply := mail.OutgoingMessages()[0]
+		if len(reply.To) != 1 || len(reply.Cc) != 1 {
+			t.Errorf("reply-all recipients = %+v, want sender in To and colleague in Cc", reply)
+		}
+		out, ok = callTool(t, mail, "replace_reply", map[string]any{
+			"outgoing_id":  out["outgoing_id"],
+			"account":      "Work",
+			"mailbox_path": []string{"Inbox"},
+			"message_id":   1,
+			"content":      "Will do, see you.",
+		})
+		if !ok {
+			t.Fatalf("replace_reply failed: %v", out)
+		}
+		if n := len(mail.OutgoingMessages()); n != 1 {
+			t.Errorf("outgoing messages after replace = %d, want 1", n)
+		}
+	})
+
+	t.Run("get_selected_messages", func(t *testing.T) {
+		out, ok := callTool(t, mail, "get_selected_messages", map[string]any{})
+		if !ok || len(out["messages"].([]any)) != 0 {
+			t.Fatalf("get_selected_messages = %v, want empty selection", out)
+		}
+	})
+
+	t.Run("mail not running", func(t *testing.T) {
+		mail.SetRunning(false)
+		defer mail.SetRunning(true)
+		out, ok := callTool(t, mail, "list_accounts", map[string]any{"enabled": false})
+		if ok || !strings.Contains(out["error"].(string), "Mail.app is not running") {
+			t.Errorf("expected not running error, got %v", out)
+		}
+	})
+}
+
+func TestMoveMessages(t *testing.T) {
+	mail := newTestMail()
+	mail.AddAccount("Personal", "me@example.org").AddMailbox("Archive")
+
+	out, ok := callTool(t, mail, "move_messages", map[string]any{
+		"account":                  "Work",
+		"mailboxPath":              []string{"Inbox"},
+		"message_ids":              []int{1, 999},
+		"destination_account":      "Personal",
+		"destination_mailbox_path": []string{"Archive"},
+	})
+	if !ok {
+		t.Fatalf("move_messages failed: %v", out)
+	}
+	if out["moved_count"] != float64(1) || out["failed_count"] != float64(1) {
+		t.Errorf("moved_count/failed_count = %v/%v, want 1/1", out["moved_count"], out["failed_count"])
+	}
+	results := out["results"].([]any)
+	moved := results[0].(map[string]any)
+	if moved["moved"] != true || moved["new_id"] == nil || moved["new_id"] == float64(1) {
+		t.Errorf("moved result = %v, want moved with a new ID", moved)
+	}
+	if missing := results[1].(map[string]any); missing["moved"] != false || missing["error"] == nil {
+		t.Errorf("missing result = %v, want not moved with error", missing)
+	}
+
+	out, ok = callTool(t, mail, "get_message_content", map[string]any{"account": "Personal", "mailboxPath": []string{"Archive"}, "message_id": moved["new_id"]})
+	if !ok || out["message"].(map[string]any)["subject"] != "Meeting Tomorrow" {
+		t.Errorf("moved message not found in destination: %v", out)
+	}
+
+	// The destination account defaults to the source account
+	out, ok = callTool(t, mail, "move_messages", map[string]any{
+		"account":                  "Work",
+		"mailboxPath":              []string{"Inbox"},
+		"message_ids":              []int{2},
+		"destination_mailbox_path": []string{"Inbox", "GitHub"},
+	})
+	if !ok || out["moved_count"] != float64(1) {
+		t.Errorf("move within account = %v", out)
+	}
+}
+
+func TestMailboxManagement(t *testing.T) {
+	mail := newTestMail()
+
+	out, ok := callTool(t, mail, "create_mailbox", map[string]any{"account": "Work", "mailboxPath": []string{"Projects", "Alpha"}})
+	if !ok {
+		t.Fatalf("create_mailbox failed: %v", out)
+	}
+	if got := fmt.Sprint(out["mailboxPath"], out["createdMailboxPaths"]); got != "[Projects Alpha] [[Projects] [Projects Alpha]]" {
+		t.Errorf("mailboxPath, createdMailboxPaths = %s", got)
+	}
+	if out, ok := callTool(t, mail, "create_mailbox", map[string]any{"account": "Work", "mailboxPath": []string{"Projects", "Alpha"}}); ok || !strings.Contains(out["error"].(string), "already exists") {
+		t.Errorf("create_mailbox for an existing mailbox = %v, want error", out)
+	}
+	if out, ok := callTool(t, mail, "create_mailbox", map[string]any{"account": "Work", "mailboxPath": []string{"Projects/Beta"}}); ok || !strings.Contains(out["error"].(string), "must not contain '/'") {
+		t.Errorf("create_mailbox with a slash = %v, want error", out)
+	}
+
+	// Rename in place, then move under another parent
+	out, ok = callTool(t, mail, "rename_mailbox", map[string]any{"account": "Work", "mailboxPath": []string{"Projects", "Alpha"}, "new_mailbox_path": []string{"Projects", "Beta"}})
+	if !ok || fmt.Sprint(out["mailboxPath"]) != "[Projects Beta]" || out["moved"] != false {
+		t.Errorf("rename_mailbox = %v, want renamed to Projects > Beta", out)
+	}
+	out, ok = callTool(t, mail, "rename_mailbox", map[string]any{"account": "Work", "mailboxPath": []string{"Projects", "Beta"}, "new_mailbox_path": []string{"Inbox", "Beta"}})
+	if !ok || fmt.Sprint(out["mailboxPath"]) != "[Inbox Beta]" || out["moved"] != true {
+		t.Errorf("rename_mailbox to another parent = %v, want moved to Inbox > Beta", out)
+	}
+	if out, ok := callTool(t, mail, "list_mailboxes", map[string]any{"account": "Work", "mailboxPath": []string{"Projects"}}); !ok || out["count"] != float64(0) {
+		t.Errorf("Projects after the move = %v, want no sub-mailboxes", out)
+	}
+	if out, ok := callTool(t, mail, "rename_mailbox", map[string]any{"account": "Work", "mailboxPath": []string{"Inbox"}, "new_mailbox_path": []string{"Inbox", "Old"}}); ok || !strings.Contains(out["error"].(string), "inside the mailbox") {
+		t.Errorf("rename_mailbox into itself = %v, want error", out)
+	}
+	if out, ok := callTool(t, mail, "rename_mailbox", map[string]any{"account": "Work", "mailboxPath": []string{"Inbox", "Beta"}, "new_mailbox_path": []string{"Nope", "Beta"}}); ok || !strings.Contains(out["error"].(string), "Parent mailbox path 'Nope' not found") {
+		t.Errorf("rename_mailbox to a missing parent = %v, want error", out)
+	}
+
+	// Deleting a mailbox with messages or sub-mailboxes needs force
+	if out, ok := callTool(t, mail, "delete_mailbox", map[string]any{"account": "Work", "mailboxPath": []string{"Inbox"}}); ok || !strings.Contains(out["error"].(string), "3 message(s) and 2 sub-mailbox(es)") {
+		t.Errorf("delete_mailbox of a non-empty mailbox = %v, want error", out)
+	}
+	out, ok = callTool(t, mail, "delete_mailbox", map[string]any{"account": "Work", "mailboxPath": []string{"Inbox", "Beta"}})
+	if !ok || out["deleted"] != true || fmt.Sprint(out["parentMailboxPath"]) != "[Inbox]" {
+		t.Errorf("delete_mailbox of an empty mailbox = %v", out)
+	}
+	out, ok = callTool(t, mail, "delete_mailbox", map[string]any{"account": "Work", "mailboxPath": []string{"Inbox"}, "force": true})
+	if !ok || out["deletedMessageCount"] != float64(3) || out["deletedSubMailboxCount"] != float64(1) || out["parentMailboxPath"] != nil {
+		t.Errorf("delete_mailbox with force = %v", out)
+	}
+	if out, ok := callTool(t, mail, "list_mailboxes", map[string]any{"account": "Work"}); !ok || strings.Contains(fmt.Sprint(out["mailboxes"]), "Inbox") {
+		t.Errorf("mailboxes after delete = %v, want no Inbox", out)
+	}
+}
+
+func TestRules(t *testing.T) {
+	mail := newTestMail()
+	mail.AddRule(&fakemail.Rule{
+		Name:       "Notify",
+		Enabled:    true,
+		Conditions: []fakemail.RuleCondition{{RuleType: "sender is member of group", Qualifier: "none", Expression: "Family"}},
+		Actions:    fakemail.RuleActions{MarkFlagIndex: -1, RunScript: "/Users/me/notify.scpt"},
+	})
+
+	out, ok := callTool(t, mail, "create_rule", map[string]any{
+		"name": "Invoices",
+		"conditions": []map[string]any{
+			{"type": "from", "value": "billing@example.com"},
+			{"type": "subject", "qualifier": "begins_with", "value": "Invoice"},
+		},
+		"actions": map[string]any{"move_to": map[string]any{"account": "Work", "mailboxPath": []string{"Inbox", "GitHub"}}, "flag_index": 2},
+	})
+	if !ok {
+		t.Fatalf("create_rule failed: %v", out)
+	}
+	rule := out["rule"].(map[string]any)
+	if rule["index"] != float64(2) || rule["match"] != "all" || rule["enabled"] != true || len(rule["conditions"].([]any)) != 2 || len(rule["unsupported"].([]any)) != 0 {
+		t.Errorf("created rule = %v", rule)
+	}
+	if got := mail.Rules()[1].Conditions[1]; got.RuleType != "subject header" || got.Qualifier != "begins with value" {
+		t.Errorf("stored condition = %+v, want Mail's rule type and qualifier", got)
+	}
+	if out, ok := callTool(t, mail, "create_rule", map[string]any{"name": "Invoices", "conditions": []map[string]any{{"type": "junk"}}, "actions": map[string]any{"delete": true}}); ok || !strings.Contains(out["error"].(string), "already exists") {
+		t.Errorf("create_rule with a used name = %v, want error", out)
+	}
+	if out, ok := callTool(t, mail, "create_rule", map[string]any{"name": "Archive", "conditions": []map[string]any{{"type": "junk"}}, "actions": map[string]any{"move_to": map[string]any{"account": "Work", "mailboxPath": []string{"Nope"}}}}); ok || !strings.Contains(out["error"].(string), "Mailbox path 'Nope' not found") {
+		t.Errorf("create_rule with a missing mailbox = %v, want error", out)
+	}
+
+	out, ok = callTool(t, mail, "list_rules", map[string]any{})
+	if !ok || out["count"] != float64(2) || out["with_unsupported_count"] != float64(1) {
+		t.Fatalf("list_rules = %v", out)
+	}
+	notify := out["rules"].([]any)[0].(map[string]any)
+	if got := fmt.Sprint(notify["unsupported"]); !strings.Contains(got, "sender is member of group") || !strings.Contains(got, "run_script") {
+		t.Errorf("unsupported parts = %s, want the group condition and the script", got)
+	}
+
+	// Actions can be replaced, unsupported actions are kept, but unsupported
+	// conditions cannot be replaced
+	out, ok = callTool(t, mail, "update_rule", map[string]any{"name": "Notify", "new_name": "Family", "enabled": false, "actions": map[string]any{"mark_read": true}})
+	if !ok {
+		t.Fatalf("update_rule failed: %v", out)
+	}
+	rule = out["rule"].(map[string]any)
+	if rule["name"] != "Family" || rule["enabled"] != false || rule["actions"].(map[string]any)["mark_read"] != true || !strings.Contains(fmt.Sprint(rule["unsupported"]), "run_script") {
+		t.Errorf("updated rule = %v", rule)
+	}
+	if out, ok := callTool(t, mail, "update_rule", map[string]any{"name": "Family", "conditions": []map[string]any{{"type": "every_message"}}}); ok || !strings.Contains(out["error"].(string), "unsupported conditions") {
+		t.Errorf("update_rule replacing unsupported conditions = %v, want error", out)
+	}
+	out, ok = callTool(t, mail, "update_rule", map[string]any{"name": "Invoices", "match": "any", "conditions": []map[string]any{{"type": "to", "qualifier": "equals", "value": "ap@example.com"}}})
+	if !ok || out["rule"].(map[string]any)["match"] != "any" || fmt.Sprint(out["rule"].(map[string]any)["conditions"]) != "[map[qualifier:equals type:to value:ap@example.com]]" {
+		t.Errorf("update_rule conditions = %v", out)
+	}
+	if out, ok := callTool(t, mail, "update_rule", map[string]any{"name": "Nope", "enabled": true}); ok || !strings.Contains(out["error"].(string), `Rule "Nope" not found`) {
+		t.Errorf("update_rule of a missing rule = %v, want error", out)
+	}
+
+	out, ok = callTool(t, mail, "delete_rule", map[string]any{"name": "Invoices"})
+	if !ok || out["deleted"] != true || out["rule"].(map[string]any)["name"] != "Invoices" {
+		t.Errorf("delete_rule = %v", out)
+	}
+	if rules := mail.Rules(); len(rules) != 1 || rules[0].Name != "Family" {
+		t.Errorf("rules after delete = %+v", rules)
+	}
+}
+
+func TestUpdateMessages(t *testing.T) {
+	mail := newTestMail()
+
+	args := map[string]any{
+		"account":        "Work",
+		"mailboxPath":    []string{"Inbox"},
+		"message_ids":    []int{1, 2, 999},
+		"readStatus":     true,
+		"flagIndex":      4,
+		"junkMailStatus": false,
+	}
+	for range 2 { // idempotent: a repeated call yields the same result
+		out, ok := callTool(t, mail, "update_messages", args)
+		if !ok {
+			t.Fatalf("update_messages failed: %v", out)
+		}
+		if out["updated_count"] != float64(2) || out["failed_count"] != float64(1) {
+			t.Errorf("updated_count/failed_count = %v/%v, want 2/1", out["updated_count"], out["failed_count"])
+		}
+		results := out["results"].([]any)
+		for _, r := range results[:2] {
+			result := r.(map[string]any)
+			if result["readStatus"] != true || result["flaggedStatus"] != true || result["flagIndex"] != float64(4) {
+				t.Errorf("result = %v, want read and flagged blue", result)
+			}
+		}
+		if missing := results[2].(map[string]any); missing["updated"] != false || missing["error"] == nil {
+			t.Errorf("missing result = %v, want not updated with error", missing)
+		}
+	}
+
+	out, ok := callTool(t, mail, "update_messages", map[string]any{
+		"account":     "Work",
+		"mailboxPath": []string{"Inbox"},
+		"message_ids": []int{1},
+		"flagIndex":   -1,
+	})
+	if !ok {
+		t.Fatalf("update_messages failed: %v", out)
+	}
+	result := out["results"].([]any)[0].(map[string]any)
+	if result["flaggedStatus"] != false || result["flagIndex"] != float64(-1) || result["readStatus"] != true {
+		t.Errorf("result = %v, want unflagged and still read", result)
+	}
+
+	_, ok = callTool(t, mail, "update_messages", map[string]any{
+		"account":     "Work",
+		"mailboxPath": []string{"Inbox"},
+		"message_ids": []int{1},
+	})
+	if ok {
+		t.Error("update_messages without updates succeeded, want error")
+	}
+}
+
+func TestDeleteMessages(t *testing.T) {
+	mail := newTestMail()
+
+	out, ok := callTool(t, mail, "delete_messages", map[string]any{
+		"account":     "Work",
+		"mailboxPath": []string{"Inbox"},
+		"message_ids": []int{1},
+	})
+	if !ok || out["deleted_count"] != float64(1) {
+		t.Fatalf("delete_messages = %v, want one message moved to the Trash", out)
+	}
+	trashID := out["results"].([]any)[0].(map[string]any)["trash_id"]
+	out, ok = callTool(t, mail, "get_message_content", map[string]any{"account": "Work", "mailboxPath": []string{"Trash"}, "message_id": trashID})
+	if !ok || out["message"].(map[string]any)["subject"] != "Meeting Tomorrow" {
+		t.Errorf("deleted message not found in the Trash: %v", out)
+	}
+
+	permanent := map[string]any{
+		"account":     "Work",
+		"mailboxPath": []string{"Inbox"},
+		"message_ids": []int{2},
+		"permanent":   true,
+	}
+	if out, ok := callTool(t, mail, "delete_messages", permanent); ok {
+		t.Fatalf("permanent delete without token = %v, want error", out)
+	}
+
+	dryRun := maps.Clone(permanent)
+	dryRun["dry_run"] = true
+	out, ok = callTool(t, mail, "delete_messages", dryRun)
+	if !ok || out["would_delete_count"] != float64(1) || out["confirmation_token"] == nil {
+		t.Fatalf("dry run = %v, want one message and a confirmation token", out)
+	}
+	permanent["confirmation_token"] = out["confirmation_token"]
+
+	other := maps.Clone(permanent)
+	other["message_ids"] = []int{2, 3}
+	if out, ok := callTool(t, mail, "delete_messages", other); ok {
+		t.Errorf("permanent delete of other messages = %v, want error", out)
+	}
+
+	out, ok = callTool(t, mail, "delete_messages", permanent)
+	if !ok || out["deleted_count"] != float64(1) {
+		t.Fatalf("permanent delete = %v, want one message deleted", out)
+	}
+	if out, ok := callTool(t, mail, "get_message_content", map[string]any{"account": "Work", "mailboxPath": []string{"Inbox"}, "message_id": 2}); ok {
+		t.Errorf("permanently deleted message still found: %v", out)
+	}
+	if out, ok := callTool(t, mail, "delete_messages", permanent); ok {
+		t.Errorf("reused confirmation token = %v, want error", out)
+	}
+}
+
+func TestAttachments(t *testing.T) {
+	mail := newTestMail()
+	message := map[string]any{"account": "Work", "mailboxPath": []string{"Inbox"}, "message_id": 1}
+
+	out, ok := callTool(t, mail, "list_attachments", message)
+	if !ok {
+		t.Fatalf("list_attachments failed: %v", out)
+	}
+	attachments := out["attachments"].([]any)
+	if len(attachments) != 2 {
+		t.Fatalf("attachments = %v, want 2", attachments)
+	}
+	invite := attachments[0].(map[string]any)
+	if invite["name"] != "invite.ics" || invite["mimeType"] != "text/calendar" || invite["downloaded"] != true {
+		t.Errorf("invite = %v, want downloaded text/calendar guessed from the name", invite)
+	}
+	if agenda := attachments[1].(map[string]any); agenda["mimeType"] != "application/pdf" || agenda["size"] != float64(2048) {
+		t.Errorf("agenda = %v, want application/pdf of 2048 bytes", agenda)
+	}
+
+	save := maps.Clone(message)
+	save["attachment_id"] = invite["id"]
+	save["inline"] = true
+	if out, ok := callTool(t, mail, "save_attachment", save); ok {
+		t.Errorf("save_attachment without attachments directory = %v, want error", out)
+	}
+
+	cfg := Config{AttachmentsDir: t.TempDir()}
+	for _, want := range []string{"invite.ics", "invite (1).ics"} {
+		out, ok = callToolWithConfig(t, mail, cfg, "save_attachment", save)
+		if !ok {
+			t.Fatalf("save_attachment failed: %v", out)
+		}
+		if out["path"] != filepath.Join(cfg.AttachmentsDir, want) {
+			t.Errorf("path = %v, want %s", out["path"], want)
+		}
+		if out["content"] != "BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n" || out["content_truncated"] != false {
+			t.Errorf("content = %q, truncated = %v", out["content"], out["content_truncated"])
+		}
+	}
+	entries, err := os.ReadDir(cfg.AttachmentsDir)
+	if err != nil || len(entries) != 2 {
+		t.Errorf("attachments directory = %v, %v, want two saved files and no leftovers", entries, err)
+	}
+
+	save["attachment_id"] = attachments[1].(map[string]any)["id"]
+	if out, ok := callToolWithConfig(t, mail, cfg, "save_attachment", save); ok {
+		t.Errorf("save_attachment of a missing download = %v, want error", out)
+	}
+}
+
+func TestComposeAttachments(t *testing.T) {
+	mail := newTestMail()
+	dir := t.TempDir()
+	report := filepath.Join(dir, "report.pdf")
+	if err := os.WriteFile(report, []byte("%PDF"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	args := map[string]any{
+		"account":       "Work",
+		"subject":       "Report",
+		"content":       "Attached.",
+		"to_recipients": []string{"boss@example.com"},
+		"attachments":   []string{report},
+	}
+
+	if out, ok := callTool(t, mail, "create_outgoing_message", args); ok {
+		t.Errorf("create_outgoing_message without allowed directories = %v, want error", out)
+	}
+	cfg := Config{AttachmentAllowDirs: []string{t.TempDir()}}
+	if out, ok := callToolWithConfig(t, mail, cfg, "create_outgoing_message", args); ok {
+		t.Errorf("create_outgoing_message with disallowed file = %v, want error", out)
+	}
+	if n := len(mail.OutgoingMessages()); n != 0 {
+		t.Fatalf("%d outgoing messages opened for rejected attachments, want 0", n)
+	}
+
+	cfg.AttachmentAllowDirs = append(cfg.AttachmentAllowDirs, dir)
+	out, ok := callToolWithConfig(t, mail, cfg, "create_outgoing_message", args)
+	if !ok {
+		t.Fatalf("create_outgoing_message failed: %v", out)
+	}
+	outgoing := mail.OutgoingMessages()
+	if len(outgoing) != 1 || len(outgoing[0].Attachments) != 1 {
+		t.Fatalf("outgoing messages = %+v, want one with one attachment", outgoing)
+	}
+	if want, _ := filepath.EvalSymlinks(report); outgoing[0].Attachments[0] != want {
+		t.Errorf("attachment = %q, want %q", outgoing[0].Attachments[0], want)
+	}
+
+	out, ok = callToolWithConfig(t, mail, cfg, "create_reply", map[string]any{
+		"account":      "Work",
+		"mailbox_path": []string{"Inbox"},
+		"message_id":   1,
+		"content":      "See attached.",
+		"attachments":  []string{report},
+	})
+	if !ok || len(out["attachments"].([]any)) != 1 {
+		t.Errorf("create_reply = %v, want one attachment", out)
+	}
+}
+
+func TestRecipientNormalization(t *testing.T) {
+	mail := newTestMail()
+	args := map[string]any{
+		"account":        "Work",
+		"subject":        "Hello",
+		"content":        "Hi.",
+		"to_recipients":  []string{"bob@example", "Smith, Alice <alice@example.com>"},
+		"bcc_recipients": []string{"carol@example.com"},
+	}
+	out, ok := callTool(t, mail, "create_outgoing_message", args)
+	if ok || !strings.Contains(out["error"].(string), "bob@example") || !strings.Contains(out["error"].(string), "alice@example.com") {
+		t.Errorf("create_outgoing_message with invalid recipients = %v, want both reported", out)
+	}
+	if n := len(mail.OutgoingMessages()); n != 0 {
+		t.Fatalf("%d outgoing messages opened for invalid recipients, want 0", n)
+	}
+
+	args["to_recipients"] = []string{"  Bob   Smith <Bob@Example.COM>", `"Smith, Alice" <alice@münchen.de>, carol@example.com`}
+	args["cc_recipients"] = []string{"bob@example.com"}
+	out, ok = callTool(t, mail, "create_outgoing_message", args)
+	if !ok {
+		t.Fatalf("create_outgoing_message failed: %v", out)
+	}
+	wantTo := []string{"Bob Smith <Bob@example.com>", `"Smith, Alice" <alice@xn--mnchen-3ya.de>`, "carol@example.com"}
+	outgoing := mail.OutgoingMessages()
+	if len(outgoing) != 1 || !slices.Equal(outgoing[0].To, wantTo) || len(outgoing[0].Cc) != 0 || len(outgoing[0].Bcc) != 0 {
+		t.Errorf("outgoing messages = %+v, want To %v without duplicates", outgoing, wantTo)
+	}
+	recipients := out["recipients"].(map[string]any)
+	if len(recipients["to"].([]any)) != 3 || len(recipients["duplicates"].([]any)) != 2 {
+		t.Errorf("recipients = %v, want 3 To recipients and 2 duplicates", recipients)
+	}
+}
+
+func TestResolveContacts(t *testing.T) {
+	mail := newTestMail()
+	now := time.Now()
+	home := mail.AddAccount("Home", "me@home.example")
+	home.AddMailbox("Inbox").AddMessage(&fakemail.Message{
+		Subject:      "Budget",
+		Sender:       "Maria Garcia <maria.garcia@acme.example>",
+		DateReceived: now.AddDate(0, 0, -1),
+	})
+	home.AddMailbox("Sent Messages").AddMessage(&fakemail.Message{
+		Subject:  "Offer",
+		Sender:   "me@home.example",
+		DateSent: now.AddDate(0, 0, -3),
+		To:       []fakemail.Recipient{{Name: "Maria Rossi", Address: "m.rossi@acme.example"}},
+	})
+	dir := t.TempDir()
+	vcard := "BEGIN:VCARD\nVERSION:3.0\nFN:Maria Garcia\nORG:ACME;Finance\nEMAIL;TYPE=WORK:maria.garcia@acme.example\nEND:VCARD\n"
+	if err := os.WriteFile(filepath.Join(dir, "maria.vcf"), []byte(vcard), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := Config{ContactsPath: dir}
+
+	out, ok := callToolWithConfig(t, mail, cfg, "resolve_contacts", map[string]any{
+		"names": []string{"Maria", "Maria from finance", "Boss"},
+	})
+	if !ok {
+		t.Fatalf("resolve_contacts failed: %v", out)
+	}
+	if out["contact_count"] != float64(1) || out["correspondent_count"] != float64(2) {
+		t.Errorf("resolve_contacts = %v, want 1 contact and 2 correspondents of recent mail", out)
+	}
+	results := out["results"].([]any)
+	maria := results[0].(map[string]any)
+	if maria["status"] != "ambiguous" || len(maria["candidates"].([]any)) != 2 {
+		t.Errorf("Maria = %v, want ambiguous with 2 candidates", maria)
+	}
+	best := maria["candidates"].([]any)[0].(map[string]any)
+	if best["email"] != "maria.garcia@acme.example" || len(best["evidence"].([]any)) == 0 {
+		t.Errorf("best candidate = %v, want Maria Garcia with evidence", best)
+	}
+	if finance := results[1].(map[string]any); finance["status"] != "resolved" || finance["address"] != "Maria Garcia <maria.garcia@acme.example>" {
+		t.Errorf("Maria from finance = %v, want resolved to Maria Garcia", finance)
+	}
+	if boss := results[2].(map[string]any); boss["status"] != "not_found" {
+		t.Errorf("Boss = %v, want not_found, the mail from Boss is not recent", boss)
+	}
+
+	args := map[string]any{
+		"account":       "Work",
+		"subject":       "Budget",
+		"content":       "Hi Maria.",
+		"to_recipients": []string{"Maria from finance"},
+		"cc_recipients": []string{"Maria"},
+		"resolve_names": true,
+	}
+	out, ok = callToolWithConfig(t, mail, cfg, "create_outgoing_message", args)
+	if ok || !strings.Contains(out["error"].(string), `"Maria" is ambiguous`) || !strings.Contains(out["error"].(string), "m.rossi@acme.example") {
+		t.Errorf("create_outgoing_message with an ambiguous name = %v, want the candidates", out)
+	}
+	if n := len(mail.OutgoingMessages()); n != 0 {
+		t.Fatalf("%d outgoing messages opened for an ambiguous name, want 0", n)
+	}
+
+	delete(args, "cc_recipients")
+	out, ok = callToolWithConfig(t, mail, cfg, "create_outgoing_message", args)
+	if !ok {
+		t.Fatalf("create_outgoing_message failed: %v", out)
+	}
+	if outgoing := mail.OutgoingMessages(); len(outgoing) != 1 || !slices.Equal(outgoing[0].To, []string{"Maria Garcia <maria.garcia@acme.example>"}) {
+		t.Errorf("outgoing messages = %+v, want To Maria Garcia", outgoing)
+	}
+	if resolved := out["resolved_names"].([]any); len(resolved) != 1 {
+		t.Errorf("resolved_names = %v, want 1", resolved)
+	}
+
+	args["resolve_names"] = false
+	if out, ok := callToolWithConfig(t, mail, cfg, "create_outgoing_message", args); ok || !strings.Contains(out["error"].(string), "no @") {
+		t.Errorf("create_outgoing_message with a name = %v, want an invalid address without resolve_names", out)
+	}
+}
+
+func TestSignatures(t *testing.T) {
+	mail := newTestMail()
+	mail.AddSignature("Formal", "Best regards, Bob")
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "Work.md"), []byte("**Bob**"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := Config{SignatureDir: dir}
+
+	out, ok := callToolWithConfig(t, mail, cfg, "list_signatures", map[string]any{})
+	if !ok || len(out["mail_signatures"].([]any)) != 1 || len(out["file_signatures"].([]any)) != 1 || out["signature_dir"] != dir {
+		t.Fatalf("list_signatures = %v, want one Mail and one file signature", out)
+	}
+
+	create := func(signature string) (map[string]any, bool) {
+		t.Helper()
+		args := map[string]any{"account": "Work", "subject": "Hello", "content": "Hi.", "to_recipients": []string{"bob@example.com"}}
+		if signature != "" {
+			args["signature"] = signature
+		}
+		return callToolWithConfig(t, mail, cfg, "create_outgoing_message", args)
+	}
+
+	out, ok = create("")
+	if !ok {
+		t.Fatalf("create_outgoing_message failed: %v", out)
+	}
+	if s, _ := out["signature"].(map[string]any); s["source"] != "file" || s["account"] != "Work" {
+		t.Errorf("signature = %v, want the file signature of Work", out["signature"])
+	}
+
+	out, ok = create("Formal")
+	if s, _ := out["signature"].(map[string]any); !ok || s["source"] != "mail" || s["name"] != "Formal" {
+		t.Errorf("create_outgoing_message with Mail signature = %v, want it applied", out)
+	}
+	outgoing := mail.OutgoingMessages()
+	if got := outgoing[len(outgoing)-1].Signature; got != "Formal" {
+		t.Errorf("outgoing signature = %q, want Formal", got)
+	}
+
+	if out, ok = create(signatureNone); !ok || out["signature"] != nil {
+		t.Errorf("create_outgoing_message with none = %v, want no signature", out)
+	}
+
+	if out, ok = create("Casual"); ok || !strings.Contains(out["error"].(string), "not found") {
+		t.Errorf("create_outgoing_message with unknown signature = %v, want error", out)
+	}
+
+	out, ok = callToolWithConfig(t, mail, cfg, "replace_outgoing_message", map[string]any{
+		"outgoing_id": outgoing[0].ID,
+		"content":     "Hi again.",
+	})
+	if s, _ := out["signature"].(map[string]any); !ok || s["source"] != "file" || s["account"] != "Work" {
+		t.Errorf("replace_outgoing_message = %v, want the file signature of the sender's account", out)
+	}
+
+	// The old message is gone once the signature is loaded, so a broken
+	// signature must not lose the content
+	if err := os.Remove(filepath.Join(dir, "Work.md")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "Work.md"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	out, ok = callToolWithConfig(t, mail, cfg, "replace_outgoing_message", map[string]any{
+		"outgoing_id": out["outgoing_id"],
+		"content":     "Hi once more.",
+	})
+	if !ok || out["signature"] != nil || !strings.Contains(fmt.Sprint(out["warning"]), "without the signature") {
+		t.Errorf("replace_outgoing_message with a broken signature = %v, want pasted with a warning", out)
+	}
+}
+
+func TestTemplates(t *testing.T) {
+	mail := newTestMail()
+	dir := t.TempDir()
+	template := "---\naccount: Work\nsubject: \"Ticket {{.ticket}}\"\nto: [\"{{.email}}\"]\nvariables: [name, email, ticket]\n---\nHi {{.name}}!\n"
+	if err := os.WriteFile(filepath.Join(dir, "ticket.md"), []byte(template), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := Config{TemplateDir: dir, SignatureDir: t.TempDir()}
+
+	out, ok := callToolWithConfig(t, mail, cfg, "list_templates", map[string]any{})
+	if !ok || out["count"] != float64(1) || out["templates"].([]any)[0].(map[string]any)["name"] != "ticket" {
+		t.Fatalf("list_templates = %v, want the ticket template", out)
+	}
+
+	out, ok = callToolWithConfig(t, mail, cfg, "create_from_template", map[string]any{
+		"template":  "ticket",
+		"variables": map[string]string{"name": "Bob"},
+	})
+	if ok || !strings.Contains(out["error"].(string), "email, ticket") {
+		t.Errorf("create_from_template without variables = %v, want missing variables", out)
+	}
+	if n := len(mail.OutgoingMessages()); n != 0 {
+		t.Fatalf("%d outgoing messages opened for missing variables, want 0", n)
+	}
+
+	out, ok = callToolWithConfig(t, mail, cfg, "create_from_template", map[string]any{
+		"template":  "ticket",
+		"variables": map[string]string{"name": "Bob", "email": "bob@example.com", "ticket": "42"},
+	})
+	if !ok || out["template"] != "ticket" || out["subject"] != "Ticket 42" {
+		t.Fatalf("create_from_template = %v, want the rendered message", out)
+	}
+	outgoing := mail.OutgoingMessages()
+	if len(outgoing) != 1 || !slices.Equal(outgoing[0].To, []string{"bob@example.com"}) {
+		t.Errorf("outgoing messages = %+v, want one to bob@example.com", outgoing)
+	}
+
+	if out, ok := callToolWithConfig(t, mail, cfg, "create_from_template", map[string]any{"template": "missing"}); ok {
+		t.Errorf("create_from_template with unknown template = %v, want error", out)
+	}
+}
+
+func TestMailMerge(t *testing.T) {
+	mail := newTestMail()
+	cfg := Config{SignatureDir: t.TempDir()}
+	args := map[string]any{
+		"account": "Work",
+		"subject": "Your talk {{.talk}}",
+		"content": "Hi {{.name}}, see you in room {{.room}}.",
+		"data":    "name,email,talk,room\nBob,bob@example.com,Go,A\nAlice,alice@example.com,Rust,B\n",
+	}
+
+	out, ok := callToolWithConfig(t, mail, cfg, "mail_merge", args)
+	if !ok || out["created_count"] != float64(2) || len(mail.OutgoingMessages()) != 2 {
+		t.Fatalf("mail_merge = %v, want both rows created", out)
+	}
+
+	// A missing column rejects every row before anything is created
+	args["content"] = "Hi {{.name}}, your slot is {{.slot}}."
+	mail = newTestMail()
+	out, ok = callToolWithConfig(t, mail, cfg, "mail_merge", args)
+	if !ok || out["rejected_count"] != float64(2) || out["created_count"] != float64(0) {
+		t.Fatalf("mail_merge with missing column = %v, want all rows rejected", out)
+	}
+	if row := out["rows"].([]any)[0].(map[string]any); row["status"] != "rejected" || row["missing"].([]any)[0] != "slot" {
+		t.Errorf("row = %v, want rejected for slot", row)
+	}
+	if n := len(mail.OutgoingMessages()); n != 0 {
+		t.Fatalf("%d outgoing messages opened for rejected rows, want 0", n)
+	}
+
+	// So does an empty cell, every placeholder is required without a template
+	args["content"] = "Hi {{.name}}, see you in room {{.room}}."
+	args["data"] = "name,email,talk,room\nBob,bob@example.com,Go,A\nAlice,alice@example.com,Rust,\n"
+	out, ok = callToolWithConfig(t, mail, cfg, "mail_merge", args)
+	if !ok || out["rejected_count"] != float64(1) || out["created_count"] != float64(0) {
+		t.Fatalf("mail_merge with empty cell = %v, want the row rejected", out)
+	}
+	if row := out["rows"].([]any)[1].(map[string]any); row["status"] != "rejected" || row["missing"].([]any)[0] != "room" {
+		t.Errorf("row = %v, want rejected for room", row)
+	}
+	if n := len(mail.OutgoingMessages()); n != 0 {
+		t.Fatalf("%d outgoing messages opened for a rejected row, want 0", n)
+	}
+
+	args["data"] = "name,email,talk,room\nBob,bob@example.com,Go,A\nAlice,alice@example.com,Rust,B\n"
+	args["dry_run"] = true
+	out, ok = callToolWithConfig(t, mail, cfg, "mail_merge", args)
+	if !ok || out["rejected_count"] != float64(0) {
+		t.Fatalf("mail_merge dry run = %v, want all rows rendered", out)
+	}
+	row := out["rows"].([]any)[1].(map[string]any)
+	if row["subject"] != "Your talk Rust" || row["content"] != "Hi Alice, see you in room B." || row["to"].([]any)[0] != "alice@example.com" {
+		t.Errorf("rendered row = %v, want the message to Alice", row)
+	}
+	if n := len(mail.OutgoingMessages()); n != 0 {
+		t.Fatalf("%d outgoing messages opened in a dry run, want 0", n)
+	}
+
+	args["dry_run"] = false
+	args["data"] = `[{"name": "Bob", "email": "bob@example.com", "talk": "Go", "room": "A"}, {"name": "Alice", "email": "alice@example.com", "talk": "Rust", "room": "B"}]`
+	out, ok = callToolWithConfig(t, mail, cfg, "mail_merge", args)
+	if !ok || out["created_count"] != float64(2) || out["failed_count"] != float64(0) {
+		t.Fatalf("mail_merge = %v, want two messages created", out)
+	}
+	outgoing := mail.OutgoingMessages()
+	if len(outgoing) != 2 || outgoing[1].Subject != "Your talk Rust" || !slices.Equal(outgoing[1].To, []string{"alice@example.com"}) {
+		t.Errorf("outgoing messages = %+v, want one per row", outgoing)
+	}
+	if row := out["rows"].([]any)[0].(map[string]any); row["status"] != "created" || row["outgoing_id"] != float64(outgoing[0].ID) {
+		t.Errorf("row = %v, want created with the outgoing ID", row)
+	}
+}
+
+func TestFindMessages_AcrossMailboxes(t *testing.T) {
+	mail := newTestMail()
+	personal := mail.AddAccount("Personal", "me@example.org")
+	personal.AddMailbox("Inbox").AddMessage(&fakemail.Message{
+		Subject:      "Holiday plans",
+		Sender:       "friend@example.org",
+		DateReceived: time.Date(2024, 2, 14, 9, 0, 0, 0, time.UTC),
+	})
+	personal.AddMailbox("Archive")
+
+	out, ok := callTool(t, mail, "find_messages", map[string]any{
+		"accounts":     []string{"*"},
+		"mailboxPaths": [][]string{{"Inbox"}},
+		"recursive":    true,
+		"dateAfter":    "2024-01-01T00:00:00Z",
+		"limit":        3,
+	})
+	if !ok {
+		t.Fatalf("find_messages failed: %v", out)
+	}
+	if out["total_matches"] != float64(4) || out["has_more"] != true || out["mailboxes_searched"] != float64(3) {
+		t.Errorf("total_matches/has_more/mailboxes_searched = %v/%v/%v, want 4/true/3", out["total_matches"], out["has_more"], out["mailboxes_searched"])
+	}
+	var got []string
+	for _, m := range out["messages"].([]any) {
+		msg := m.(map[string]any)
+		got = append(got, fmt.Sprintf("%s:%v:%s", msg["account"], msg["mailbox_path"], msg["subject"]))
+	}
+	want := []string{"Personal:[Inbox]:Holiday plans", "Work:[Inbox GitHub]:[repo] Pull Request #1", "Work:[Inbox]:Lunch?"}
+	if !slices.Equal(got, want) {
+		t.Errorf("messages = %v, want %v", got, want)
+	}
+
+	out, ok = callTool(t, mail, "find_messages", map[string]any{"account": "Work", "mailboxPath": []string{"*"}, "subject": "pull"})
+	if !ok || out["total_matches"] != float64(1) {
+		t.Errorf("wildcard mailbox search = %v, want one match", out)
+	}
+
+	out, ok = callTool(t, mail, "find_messages", map[string]any{
+		"accounts":     []string{"Work", "Personal"},
+		"mailboxPaths": [][]string{{"Archive"}},
+		"subject":      "x",
+	})
+	if !ok {
+		t.Fatalf("find_messages failed: %v", out)
+	}
+	if notFound := out["mailboxes_not_found"].([]any); len(notFound) != 1 || notFound[0].(map[string]any)["account"] != "Work" {
+		t.Errorf("mailboxes_not_found = %v, want Archive in Work", notFound)
+	}
+
+	if out, ok := callTool(t, mail, "find_messages", map[string]any{"accounts": []string{"Nope"}, "mailboxPath": []string{"Inbox"}, "subject": "x"}); ok {
+		t.Errorf("unknown account = %v, want error", out)
+	}
+}
+
+func TestFindMessages_Filters(t *testing.T) {
+	mail := newTestMail()
+	mail.AddAccount("Finance", "finance@example.com").AddMailbox("Inbox").AddMessage(&fakemail.Message{
+		Subject:       "Re: Budget",
+		Sender:        "cfo@example.com",
+		DateReceived:  time.Date(2024, 2, 14, 9, 0, 0, 0, time.UTC),
+		Content:       "The numbers are final.",
+		FlaggedStatus: true,
+		FlagIndex:     3,
+		WasRepliedTo:  true,
+		MessageSize:   4096,
+		To:            []fakemail.Recipient{{Name: "Finance Team", Address: "finance@example.com"}},
+	})
+
+	find := func(args map[string]any) []string {
+		t.Helper()
+		args["accounts"] = []string{"Work", "Finance"}
+		args["mailboxPath"] = []string{"Inbox"}
+		out, ok := callTool(t, mail, "find_messages", args)
+		if !ok {
+			t.Fatalf("find_messages(%v) failed: %v", args, out)
+		}
+		var subjects []string
+		for _, m := range out["messages"].([]any) {
+			subjects = append(subjects, m.(map[string]any)["subject"].(string))
+		}
+		return subjects
+	}
+
+	tests := []struct {
+		name string
+		args map[string]any
+		want []string
+	}{
+		{"to", map[string]any{"to": "FINANCE"}, []string{"Re: Budget"}},
+		{"cc", map[string]any{"cc": "colleague@"}, []string{"Meeting Tomorrow"}},
+		{"body", map[string]any{"body": "sushi"}, []string{"Lunch?"}},
+		{"has attachment", map[string]any{"hasAttachment": true}, []string{"Meeting Tomorrow"}},
+		{"no attachment", map[string]any{"hasAttachment": false}, []string{"Re: Budget", "Lunch?"}},
+		{"size range", map[string]any{"minSize": 1000, "maxSize": 5000}, []string{"Re: Budget"}},
+		{"flag color", map[string]any{"flagIndex": 3}, []string{"Re: Budget"}},
+		{"replied", map[string]any{"wasRepliedTo": true}, []string{"Re: Budget"}},
+		{"not junk", map[string]any{"junkMailStatus": false, "subject": "lunch"}, []string{"Lunch?"}},
+		{"query", map[string]any{"query": "(flag:green OR has:attachment) NOT is:replied"}, []string{"Meeting Tomorrow"}},
+		{"query and filter", map[string]any{"query": "is:unread OR is:read", "sender": "colleague"}, []string{"Lunch?"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := find(tt.args); !slices.Equal(got, tt.want) {
+				t.Errorf("subjects = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	errors := []struct {
+		name string
+		args map[string]any
+		want string
+	}{
+		{"malformed query", map[string]any{"query": "subject:a OR"}, "invalid query at position 12: unexpected end of query"},
+		{"unknown field", map[string]any{"query": "color:red"}, `unknown field "color"`},
+		{"flag index range", map[string]any{"flagIndex": 7}, "flagIndex must be between 0 and 6"},
+		{"size range", map[string]any{"minSize": 10, "maxSize": 5}, "minSize must not be greater than maxSize"},
+		{"invalid date", map[string]any{"dateAfter": "last week"}, "dateAfter: invalid date"},
+	}
+	for _, tt := range errors {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.args["account"] = "Work"
+			tt.args["mailboxPath"] = []string{"Inbox"}
+			out, ok := callTool(t, mail, "find_messages", tt.args)
+			if ok || !strings.Contains(out["error"].(string), tt.want) {
+				t.Errorf("find_messages(%v) = %v, want error containing %q", tt.args, out, tt.want)
+			}
+		})
+	}
+}
+
+func TestPagination(t *testing.T) {
+	mail := newTestMail()
+
+	// Pages through all messages of the Work account, newest first
+	args := map[string]any{"account": "Work", "mailboxPath": []string{"*"}, "query": "before:2030-01-01", "limit": 2}
+	var subjects []string
+	for page := 0; ; page++ {
+		out, ok := callTool(t, mail, "find_messages", args)
+		if !ok {
+			t.Fatalf("find_messages page %d failed: %v", page, out)
+		}
+		for _, m := range out["messages"].([]any) {
+			subjects = append(subjects, m.(map[string]any)["subject"].(string))
+		}
+		next, _ := out["next_cursor"].(string)
+		if next == "" {
+			if out["has_more"] != false {
+				t.Errorf("has_more = %v on the last page", out["has_more"])
+			}
+			break
+		}
+		if page > 2 {
+			t.Fatal("too many pages")
+		}
+		args["cursor"] = next
+	}
+	want := []string{"[repo] Pull Request #1", "Lunch?", "Meeting Tomorrow", "Draft"}
+	if !slices.Equal(subjects, want) {
+		t.Errorf("subjects = %v, want %v", subjects, want)
+	}
+
+	// A cursor is bound to the filters it was issued for
+	out, ok := callTool(t, mail, "find_messages", map[string]any{"account": "Work", "mailboxPath": []string{"*"}, "query": "before:2030-01-01", "limit": 1})
+	if !ok {
+		t.Fatalf("find_messages failed: %v", out)
+	}
+	cursor := out["next_cursor"].(string)
+	out, ok = callTool(t, mail, "find_messages", map[string]any{"account": "Work", "mailboxPath": []string{"*"}, "query": "is:unread", "cursor": cursor})
+	if ok || !strings.Contains(out["error"].(string), "cursor does not match the current filters") {
+		t.Errorf("find_messages with mismatched cursor = %v, want error", out)
+	}
+	if out, ok := callTool(t, mail, "list_drafts", map[string]any{"cursor": cursor}); ok {
+		t.Errorf("list_drafts with a find_messages cursor = %v, want error", out)
+	}
+
+	out, ok = callTool(t, mail, "list_mailboxes", map[string]any{"account": "Work", "limit": 2})
+	if !ok || out["count"] != float64(2) || out["hasMore"] != true {
+		t.Fatalf("list_mailboxes page 1 = %v", out)
+	}
+	out, ok = callTool(t, mail, "list_mailboxes", map[string]any{"account": "Work", "limit": 2, "cursor": out["next_cursor"]})
+	if !ok {
+		t.Fatalf("list_mailboxes page 2 failed: %v", out)
+	}
+	if mailboxes := out["mailboxes"].([]any); len(mailboxes) != 1 || mailboxes[0].(map[string]any)["name"] != "Trash" || out["next_cursor"] != nil {
+		t.Errorf("list_mailboxes page 2 = %v, want Trash only", out)
+	}
+
+	mail.AddAccount("Personal", "me@example.org").AddMailbox("Drafts").AddMessage(&fakemail.Message{Subject: "Other Draft"})
+	out, ok = callTool(t, mail, "list_drafts", map[string]any{"limit": 1})
+	if !ok || out["count"] != float64(1) || out["next_cursor"] == nil {
+		t.Fatalf("list_drafts page 1 = %v", out)
+	}
+	out, ok = callTool(t, mail, "list_drafts", map[string]any{"limit": 1, "cursor": out["next_cursor"]})
+	if !ok || out["drafts"].([]any)[0].(map[string]any)["subject"] != "Other Draft" || out["next_cursor"] != nil {
+		t.Errorf("list_drafts page 2 = %v, want the Personal draft only", out)
+	}
+}
+
+func TestGetThread(t *testing.T) {
+	mail := newTestMail()
+	work := mail.AddAccount("Team", "me@example.com")
+	inbox := work.AddMailbox("Inbox")
+	sent := work.AddMailbox("Sent Messages")
+	archive := work.AddMailbox("Archive", "2024")
+	other := work.AddMailbox("Newsletters")
+	day := func(h int) time.Time { return time.Date(2024, 2, 12, h, 0, 0, 0, time.UTC) }
+
+	archive.AddMessage(&fakemail.Message{
+		Subject: "Quarterly budget", Sender: "alice@example.com", DateReceived: day(9), MessageID: "1@example.com",
+		AllHeaders: "Message-ID: <1@example.com>\nSubject: Quarterly budget\n",
+	})
+	sent.AddMessage(&fakemail.Message{
+		Subject: "Re: Quarterly budget", Sender: "me@example.com", DateReceived: day(10), MessageID: "2@example.com",
+		AllHeaders: "Message-ID: <2@example.com>\nIn-Reply-To: <1@example.com>\nReferences: <1@example.com>\n",
+	})
+	target := inbox.AddMessage(&fakemail.Message{
+		Subject: "Re: Re: Quarterly budget", Sender: "alice@example.com", DateReceived: day(11), MessageID: "3@example.com",
+		AllHeaders: "Message-ID: <3@example.com>\nIn-Reply-To: <2@example.com>\nReferences: <1@example.com>\n <2@example.com>\n",
+	})
+	inbox.AddMessage(&fakemail.Message{
+		Subject: "RE: quarterly budget", Sender: "bob@example.com", DateReceived: day(12), MessageID: "4@example.com",
+		AllHeaders: "Message-ID: <4@example.com>\n",
+	})
+	// Not searched by default
+	other.AddMessage(&fakemail.Message{
+		Subject: "Re: Quarterly budget", Sender: "news@example.com", DateReceived: day(13), MessageID: "5@example.com",
+		AllHeaders: "Message-ID: <5@example.com>\nIn-Reply-To: <1@example.com>\n",
+	})
+	// Similar subject, but another thread
+	inbox.AddMessage(&fakemail.Message{
+		Subject: "Quarterly budget review", Sender: "carol@example.com", DateReceived: day(8), MessageID: "6@example.com",
+		AllHeaders: "Message-ID: <6@example.com>\n",
+	})
+
+	out, ok := callTool(t, mail, "get_thread", map[string]any{
+		"account":     "Team",
+		"mailboxPath": []string{"Inbox"},
+		"message_id":  target.ID,
+	})
+	if !ok {
+		t.Fatalf("get_thread failed: %v", out)
+	}
+
+	var lines []string
+	var walk func(nodes []any, depth int)
+	walk = func(nodes []any, depth int) {
+		for _, n := range nodes {
+			node := n.(map[string]any)
+			line := fmt.Sprintf("%s%s %v %v", strings.Repeat("  ", depth), node["message_id"], node["mailbox_path"], node["linked_by"])
+			if node["is_target"] == true {
+				line += " *"
+			}
+			lines = append(lines, line)
+			walk(node["replies"].([]any), depth+1)
+		}
+	}
+	walk(out["thread"].([]any), 0)
+	want := []string{
+		"1@example.com [Archive 2024] <nil>",
+		"  2@example.com [Sent Messages] in-reply-to",
+		"    3@example.com [Inbox] in-reply-to *",
+		"  4@example.com [Inbox] subject",
+	}
+	if !slices.Equal(lines, want) {
+		t.Errorf("thread =\n%s\nwant\n%s", strings.Join(lines, "\n"), strings.Join(want, "\n"))
+	}
+	if out["count"] != float64(4) {
+		t.Errorf("count = %v, want 4", out["count"])
+	}
+
+	out, ok = callTool(t, mail, "get_thread", map[string]any{
+		"account":      "Team",
+		"mailboxPath":  []string{"Inbox"},
+		"message_id":   target.ID,
+		"mailboxPaths": [][]string{{"Newsletters"}},
+	})
+	if !ok || out["count"] != float64(3) {
+		t.Errorf("get_thread with mailboxPaths = %v, want 3 messages", out)
+	}
+
+	if out, ok := callTool(t, mail, "get_thread", map[string]any{"account": "Team", "mailboxPath": []string{"Inbox"}, "message_id": 9999}); ok {
+		t.Errorf("get_thread of a missing message = %v, want error", out)
+	}
+}
+
+func TestGetMessageContent_Headers(t *testing.T) {
+	mail := newTestMail()
+	inbox := mail.AddAccount("Lists", "me@example.com").AddMailbox("Inbox")
+	msg := inbox.AddMessage(&fakemail.Message{
+		Subject:      "Release",
+		Sender:       "dev@lists.example.org",
+		DateReceived: time.Date(2024, 2, 12, 9, 0, 0, 0, time.UTC),
+		MessageID:    "3@example.com",
+		AllHeaders: "From: =?UTF-8?Q?J=C3=BCrgen_M=C3=BCller?= <juergen@example.com>\r\n" +
+			"To: \"Smith, Bob\" <bob@example.com>,\r\n dev@lists.example.org\r\n" +
+			"Cc: =?ISO-8859-1?Q?Ren=E9?= <rene@example.com>\r\n" +
+			"Subject: =?UTF-8?B?UmVsZWFzZSDinJM=?=\r\n" +
+			"Message-ID: <3@example.com>\r\n" +
+			"In-Reply-To: <2@example.com>\r\n" +
+			"References: <1@example.com>\r\n\t<2@example.com>\r\n" +
+			"List-Id: Developers <dev.lists.example.org>\r\n" +
+			"List-Unsubscribe: <mailto:dev-leave@lists.example.org>,\r\n <https://lists.example.org/leave>\r\n",
+	})
+	args := map[string]any{"account": "Lists", "mailboxPath": []string{"Inbox"}, "message_id": msg.ID}
+
+	out, ok := callTool(t, mail, "get_message_content", args)
+	if !ok {
+		t.Fatalf("get_message_content failed: %v", out)
+	}
+	message := out["message"].(map[string]any)
+	if _, ok := message["allHeaders"]; ok {
+		t.Errorf("allHeaders returned without raw_headers")
+	}
+	got, _ := json.Marshal(map[string]any{
+		"from":            message["from"],
+		"to":              message["to"],
+		"cc":              message["cc"],
+		"inReplyTo":       message["inReplyTo"],
+		"references":      message["references"],
+		"listId":          message["listId"],
+		"listUnsubscribe": message["listUnsubscribe"],
+		"subject":         message["headers"].(map[string]any)["Subject"],
+	})
+	want := `{"cc":[{"email":"rene@example.com","name":"René"}],` +
+		`"from":[{"email":"juergen@example.com","name":"Jürgen Müller"}],` +
+		`"inReplyTo":"2@example.com",` +
+		`"listId":"dev.lists.example.org",` +
+		`"listUnsubscribe":["mailto:dev-leave@lists.example.org","https://lists.example.org/leave"],` +
+		`"references":["1@example.com","2@example.com"],` +
+		`"subject":["Release ✓"],` +
+		`"to":[{"email":"bob@example.com","name":"Smith, Bob"},{"email":"dev@lists.example.org","name":""}]}`
+	if string(got) != want {
+		t.Errorf("headers =\n%s\nwant\n%s", got, want)
+	}
+
+	args["raw_headers"] = true
+	out, ok = callTool(t, mail, "get_message_content", args)
+	if !ok {
+		t.Fatalf("get_message_content with raw_headers failed: %v", out)
+	}
+	if raw, _ := out["message"].(map[string]any)["allHeaders"].(string); !strings.HasPrefix(raw, "From: =?UTF-8?Q?") {
+		t.Errorf("allHeaders = %q, want the raw header text", raw)
+	}
+}
+
+func TestBodyFormat(t *testing.T) {
+	mail := newTestMail()
+	inbox := mail.AddAccount("News", "me@example.com").AddMailbox("Inbox")
+	newsletter := inbox.AddMessage(&fakemail.Message{
+		Subject:      "Weekly",
+		Sender:       "news@example.com",
+		DateReceived: time.Date(2024, 2, 12, 9, 0, 0, 0, time.UTC),
+		Content:      "Weekly news Read more",
+		Source: strings.Join([]string{
+			"From: news@example.com",
+			"Subject: Weekly",
+			`Content-Type: multipart/alternative; boundary="b"`,
+			"",
+			"--b",
+			"Content-Type: text/plain; charset=utf-8",
+			"",
+			"Weekly news Read more",
+			"--b",
+			"Content-Type: text/html; charset=utf-8",
+			"Content-Transfer-Encoding: quoted-printable",
+			"",
+			`<html><head><style>p{margin:0}</style></head><body>`,
+			`<div style=3D"display:none">Preheader</div>`,
+			`<h1>Weekly news</h1><p><a href=3D"https://example.com/post">Read more</a></p>`,
+			`<img src=3D"https://t.example.com/p.gif" width=3D"1" height=3D"1">`,
+			`</body></html>`,
+			"--b--",
+		}, "\r\n"),
+	})
+	plain := inbox.AddMessage(&fakemail.Message{
+		Subject:      "Plain",
+		Sender:       "friend@example.com",
+		DateReceived: time.Date(2024, 2, 11, 9, 0, 0, 0, time.UTC),
+		Content:      "Just text",
+		AllHeaders:   "From: friend@example.com\nSubject: Plain\n",
+	})
+	get := func(id int, format string) map[string]any {
+		t.Helper()
+		args := map[string]any{"account": "News", "mailboxPath": []string{"Inbox"}, "message_id": id}
+		if format != "" {
+			args["body_format"] = format
+		}
+		out, ok := callTool(t, mail, "get_message_content", args)
+		if !ok {
+			t.Fatalf("get_message_content(%s) failed: %v", format, out)
+		}
+		return out["message"].(map[string]any)
+	}
+
+	wantMarkdown := "# Weekly news\n\n[Read more](https://example.com/post)"
+	for _, tt := range []struct {
+		id         int
+		format     string
+		wantFormat string
+		want       string
+	}{
+		{newsletter.ID, "", "plain", "Weekly news Read more"},
+		{newsletter.ID, "markdown", "markdown", wantMarkdown},
+		{newsletter.ID, "HTML", "html", ""},
+		{plain.ID, "markdown", "plain", "Just text"},
+	} {
+		message := get(tt.id, tt.format)
+		if message["bodyFormat"] != tt.wantFormat {
+			t.Errorf("body_format %q: bodyFormat = %v, want %s", tt.format, message["bodyFormat"], tt.wantFormat)
+		}
+		content, _ := message["content"].(string)
+		if tt.wantFormat == "html" {
+			if !strings.Contains(content, `<a href="https://example.com/post">`) {
+				t.Errorf("body_format html: content = %q, want the decoded HTML part", content)
+			}
+		} else if content != tt.want {
+			t.Errorf("body_format %q: content = %q, want %q", tt.format, content, tt.want)
+		}
+		if _, ok := message["source"]; ok {
+			t.Errorf("body_format %q: source returned", tt.format)
+		}
+	}
+
+	out, ok := callTool(t, mail, "find_messages", map[string]any{"account": "News", "mailboxPath": []string{"Inbox"}, "subject": "Weekly", "body_format": "markdown"})
+	if !ok {
+		t.Fatalf("find_messages failed: %v", out)
+	}
+	found := out["messages"].([]any)[0].(map[string]any)
+	if found["content"] != wantMarkdown || found["body_format"] != "markdown" {
+		t.Errorf("find_messages message = %v, want markdown content", found)
+	}
+
+	out, ok = callTool(t, mail, "find_messages", map[string]any{"account": "News", "mailboxPath": []string{"Inbox"}, "subject": "Weekly"})
+	if !ok {
+		t.Fatalf("find_messages failed: %v", out)
+	}
+	if _, ok := out["messages"].([]any)[0].(map[string]any)["content"]; ok {
+		t.Errorf("find_messages without body_format returned content")
+	}
+
+	mail.Select(newsletter, plain)
+	out, ok = callTool(t, mail, "get_selected_messages", map[string]any{"body_format": "markdown"})
+	if !ok {
+		t.Fatalf("get_selected_messages failed: %v", out)
+	}
+	var formats []any
+	for _, m := range out["messages"].([]any) {
+		formats = append(formats, m.(map[string]any)["bodyFormat"])
+	}
+	if !slices.Equal(formats, []any{"markdown", "plain"}) {
+		t.Errorf("get_selected_messages formats = %v, want [markdown plain]", formats)
+	}
+
+	if out, ok := callTool(t, mail, "get_message_content", map[string]any{"account": "News", "mailboxPath": []string{"Inbox"}, "message_id": plain.ID, "body_format": "rtf"}); ok {
+		t.Errorf("get_message_content with an invalid body_format = %v, want error", out)
+	}
+}
+
+func TestStripQuotes(t *testing.T) {
+	mail := newTestMail()
+	inbox := mail.AddAccount("Threads", "me@example.com").AddMailbox("Inbox")
+	reply := "Works for me.\n\nOn Mon, 12 Feb 2024 at 10:00, Alice <alice@example.com> wrote:\n> Shall we meet at " +
+		strings.Repeat("ten, ", 30) + "\n> or later?\n\n-- \nBob\n"
+	msg := inbox.AddMessage(&fakemail.Message{
+		Subject:      "Re: Meeting",
+		Sender:       "bob@example.com",
+		DateReceived: time.Date(2024, 2, 12, 11, 0, 0, 0, time.UTC),
+		Content:      reply,
+	})
+	removed := float64(utf8.RuneCountInString(reply) - len("Works for me."))
+
+	out, ok := callTool(t, mail, "get_message_content", map[string]any{"account": "Threads", "mailboxPath": []string{"Inbox"}, "message_id": msg.ID, "strip_quotes": true})
+	if !ok {
+		t.Fatalf("get_message_content failed: %v", out)
+	}
+	message := out["message"].(map[string]any)
+	if message["content"] != "Works for me." || message["strippedChars"] != removed {
+		t.Errorf("content, strippedChars = %q, %v, want %q, %v", message["content"], message["strippedChars"], "Works for me.", removed)
+	}
+
+	out, ok = callTool(t, mail, "find_messages", map[string]any{"account": "Threads", "mailboxPath": []string{"Inbox"}, "subject": "Meeting", "strip_quotes": true})
+	if !ok {
+		t.Fatalf("find_messages failed: %v", out)
+	}
+	found := out["messages"].([]any)[0].(map[string]any)
+	if found["content_preview"] != "Works for me." || found["content_length"] != float64(13) || found["stripped_chars"] != removed {
+		t.Errorf("find_messages message = %v, want the stripped preview", found)
+	}
+	if _, ok := found["content"]; ok {
+		t.Errorf("find_messages returned content without body_format")
+	}
+
+	out, ok = callTool(t, mail, "find_messages", map[string]any{"account": "Threads", "mailboxPath": []string{"Inbox"}, "subject": "Meeting", "strip_quotes": true, "body_format": "plain"})
+	if !ok {
+		t.Fatalf("find_messages failed: %v", out)
+	}
+	if found := out["messages"].([]any)[0].(map[string]any); found["content"] != "Works for me." {
+		t.Errorf("find_messages content = %q, want the stripped content", found["content"])
+	}
+
+	if out, ok := callTool(t, mail, "get_message_content", map[string]any{"account": "Threads", "mailboxPath": []string{"Inbox"}, "message_id": msg.ID, "strip_quotes": true, "body_format": "html"}); ok {
+		t.Errorf("get_message_content with strip_quotes and body_format html = %v, want error", out)
+	}
+}
+
+func TestMessageSourceAndExport(t *testing.T) {
+	mail := newTestMail()
+	inbox := mail.AddAccount("Archive", "me@example.com").AddMailbox("Inbox")
+	first := inbox.AddMessage(&fakemail.Message{
+		Subject:      "Minutes: 12/02",
+		Sender:       "Alice <alice@example.com>",
+		DateReceived: time.Date(2024, 2, 12, 10, 0, 0, 0, time.UTC),
+		Source:       "From: Alice <alice@example.com>\r\nSubject: Minutes: 12/02\r\n\r\nAgenda\r\nFrom the top.\r\n>From quoted.\r\n",
+	})
+	second := inbox.AddMessage(&fakemail.Message{
+		Subject:      "Second",
+		Sender:       "bob@example.com",
+		DateReceived: time.Date(2024, 2, 13, 10, 0, 0, 0, time.UTC),
+		AllHeaders:   "From: bob@example.com\nSubject: Second\n",
+		Content:      "Hi",
+	})
+	message := map[string]any{"account": "Archive", "mailboxPath": []string{"Inbox"}, "message_id": first.ID}
+
+	out, ok := callTool(t, mail, "get_message_source", message)
+	if !ok {
+		t.Fatalf("get_message_source failed: %v", out)
+	}
+	if out["source"] != first.Source || out["encoding"] != "text" || out["size"] != float64(len(first.Source)) {
+		t.Errorf("get_message_source = %v, want the raw source", out)
+	}
+	message["base64"] = true
+	if out, ok = callTool(t, mail, "get_message_source", message); !ok || out["encoding"] != "base64" {
+		t.Fatalf("get_message_source base64 = %v", out)
+	}
+	if decoded, err := base64.StdEncoding.DecodeString(out["source"].(string)); err != nil || string(decoded) != first.Source {
+		t.Errorf("decoded source = %q, %v, want %q", decoded, err, first.Source)
+	}
+	message["message_id"] = 999
+	if out, ok := callTool(t, mail, "get_message_source", message); ok {
+		t.Errorf("get_message_source of a missing message = %v, want error", out)
+	}
+
+	export := map[string]any{"account": "Archive", "mailboxPath": []string{"Inbox"}, "message_ids": []int{first.ID, second.ID, 999}}
+	if out, ok := callTool(t, mail, "export_messages", export); ok {
+		t.Errorf("export_messages without export directory = %v, want error", out)
+	}
+
+	cfg := Config{ExportDir: t.TempDir()}
+	out, ok = callToolWithConfig(t, mail, cfg, "export_messages", export)
+	if !ok {
+		t.Fatalf("export_messages failed: %v", out)
+	}
+	if out["exported_count"] != float64(2) || len(out["failed"].([]any)) != 1 {
+		t.Errorf("export_messages = %v, want 2 exported and 1 failed", out)
+	}
+	files := out["files"].([]any)
+	eml := files[0].(map[string]any)["path"].(string)
+	if filepath.Base(eml) != fmt.Sprintf("%d Minutes_ 12_02.eml", first.ID) {
+		t.Errorf("eml file = %s, want a name derived from ID and subject", eml)
+	}
+	if data, err := os.ReadFile(eml); err != nil || string(data) != first.Source {
+		t.Errorf("eml content = %q, %v, want the raw source", data, err)
+	}
+
+	export["format"] = "mbox"
+	out, ok = callToolWithConfig(t, mail, cfg, "export_messages", export)
+	if !ok {
+		t.Fatalf("export_messages mbox failed: %v", out)
+	}
+	if out["path"] != filepath.Join(cfg.ExportDir, "messages.mbox") || out["validated"] != true {
+		t.Errorf("export_messages mbox = %v", out)
+	}
+	data, err := os.ReadFile(out["path"].(string))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "From alice@example.com Mon Feb 12 10:00:00 2024\n" +
+		"From: Alice <alice@example.com>\nSubject: Minutes: 12/02\n\nAgenda\n>From the top.\n>>From quoted.\n\n" +
+		"From bob@example.com Tue Feb 13 10:00:00 2024\n"
+	if !strings.HasPrefix(string(data), want) {
+		t.Errorf("mbox =\n%s\nwant prefix\n%s", data, want)
+	}
+}
+
+func TestForwardAndRedirect(t *testing.T) {
+	mail := newTestMail()
+	inbox := mail.AddAccount("Triage", "me@example.com").AddMailbox("Inbox")
+	invoice := inbox.AddMessage(&fakemail.Message{
+		Subject:      "Invoice 42",
+		Sender:       "Billing <billing@vendor.example>",
+		DateReceived: time.Date(2024, 2, 12, 10, 0, 0, 0, time.UTC),
+		Attachments:  []fakemail.Attachment{{Name: "invoice.pdf", Downloaded: true, Content: []byte("%PDF")}},
+	})
+	forward := map[string]any{
+		"account":       "Triage",
+		"mailbox_path":  []string{"Inbox"},
+		"message_id":    invoice.ID,
+		"to_recipients": []string{"accounting@example.com"},
+		"content":       "Please **pay** this one.",
+	}
+
+	out, ok := callTool(t, mail, "create_forward", forward)
+	if !ok {
+		t.Fatalf("create_forward failed: %v", out)
+	}
+	if out["subject"] != "Fwd: Invoice 42" || !slices.Equal(out["forwarded_attachments"].([]any), []any{"invoice.pdf"}) {
+		t.Errorf("create_forward = %v, want Fwd: subject and the forwarded attachment", out)
+	}
+	outgoing := mail.OutgoingMessages()
+	if len(outgoing) != 1 || outgoing[0].Forwarded != invoice || !slices.Equal(outgoing[0].To, []string{"accounting@example.com"}) {
+		t.Fatalf("outgoing messages = %+v, want one forward to accounting", outgoing)
+	}
+
+	replace := maps.Clone(forward)
+	replace["outgoing_id"] = out["outgoing_id"]
+	replace["to_recipients"] = []string{"ap@example.com"}
+	replace["cc_recipients"] = []string{"boss@example.com"}
+	delete(replace, "content")
+	out, ok = callTool(t, mail, "replace_forward", replace)
+	if !ok {
+		t.Fatalf("replace_forward failed: %v", out)
+	}
+	outgoing = mail.OutgoingMessages()
+	if len(outgoing) != 1 || outgoing[0].To[0] != "ap@example.com" || outgoing[0].Cc[0] != "boss@example.com" {
+		t.Errorf("outgoing messages after replace = %+v, want the new forward only", outgoing)
+	}
+
+	forward["to_recipients"] = []string{}
+	if out, ok := callTool(t, mail, "create_forward", forward); ok {
+		t.Errorf("create_forward without recipients = %v, want error", out)
+	}
+
+	out, ok = callTool(t, mail, "redirect_message", map[string]any{
+		"account":       "Triage",
+		"mailbox_path":  []string{"Inbox"},
+		"message_id":    invoice.ID,
+		"to_recipients": []string{"accounting@example.com"},
+	})
+	if !ok {
+		t.Fatalf("redirect_message failed: %v", out)
+	}
+	if out["subject"] != "Invoice 42" {
+		t.Errorf("redirect subject = %v, want the original subject", out["subject"])
+	}
+	redirect := mail.OutgoingMessages()[1]
+	if redirect.Redirected != invoice || redirect.Sender != invoice.Sender {
+		t.Errorf("redirect = %+v, want the original resent unchanged", redirect)
+	}
+}
+
+func TestSendOutgoingMessage(t *testing.T) {
+	mail := newTestMail()
+	create := func(to, bcc []string) float64 {
+		t.Helper()
+		args := map[string]any{"account": "Work", "subject": "Weekly report", "content": "Numbers.", "to_recipients": to}
+		if bcc != nil {
+			args["bcc_recipients"] = bcc
+		}
+		out, ok := callTool(t, mail, "create_outgoing_message", args)
+		if !ok {
+			t.Fatalf("create_outgoing_message failed: %v", out)
+		}
+		return out["outgoing_id"].(float64)
+	}
+
+	id := create([]string{"team@example.com"}, nil)
+	if out, ok := callTool(t, mail, "send_outgoing_message", map[string]any{"outgoing_id": id}); ok || !strings.Contains(out["error"].(string), "--enable-send") {
+		t.Errorf("send_outgoing_message without --enable-send = %v, want disabled error", out)
+	}
+
+	cfg := Config{EnableSend: true, SendAllowDomains: []string{"partner.example"}, SendAllowSenderDomain: true}
+	out, ok := callToolWithConfig(t, mail, cfg, "send_outgoing_message", map[string]any{"outgoing_id": id})
+	if !ok || out["sent"] != true {
+		t.Fatalf("send_outgoing_message = %v, want sent", out)
+	}
+	if policy := out["policy"].(map[string]any); policy["allowed"] != true || len(policy["checks"].([]any)) != 4 {
+		t.Errorf("policy = %v, want an allowed decision with all checks", policy)
+	}
+	if sent := mail.SentMessages(); len(sent) != 1 || sent[0].Subject != "Weekly report" {
+		t.Errorf("sent messages = %+v, want the report", sent)
+	}
+
+	id = create([]string{"team@example.com"}, []string{"someone@partner.example"})
+	out, ok = callToolWithConfig(t, mail, cfg, "send_outgoing_message", map[string]any{"outgoing_id": id})
+	if !ok || out["sent"] != false || out["policy"].(map[string]any)["allowed"] != false {
+		t.Fatalf("send_outgoing_message with external Bcc = %v, want blocked", out)
+	}
+	if !strings.Contains(out["message"].(string), "someone@partner.example") {
+		t.Errorf("message = %v, want the violating recipient", out["message"])
+	}
+	if n := len(mail.SentMessages()); n != 1 {
+		t.Errorf("%d sent messages, want the blocked message kept unsent", n)
+	}
+}
+
+// fixedClock is a schedule.Clock standing still at a given time.
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time                         { return time.Time(c) }
+func (c fixedClock) After(d time.Duration) <-chan time.Time { return nil }
+
+func TestScheduleSend(t *testing.T) {
+	mail := newTestMail()
+	cfg := Config{EnableSend: true, ScheduleFile: filepath.Join(t.TempDir(), "schedule.json"), SendAllowSenderDomain: true, UndoSendDelay: time.Minute}
+	create := func(subject string) float64 {
+		t.Helper()
+		out, ok := callTool(t, mail, "create_outgoing_message", map[string]any{"account": "Work", "subject": subject, "content": "Numbers.", "to_recipients": []string{"team@example.com"}})
+		if !ok {
+			t.Fatalf("create_outgoing_message failed: %v", out)
+		}
+		return out["outgoing_id"].(float64)
+	}
+
+	report := create("Weekly report")
+	if out, ok := callTool(t, mail, "schedule_send", map[string]any{"outgoing_id": report}); ok {
+		t.Errorf("schedule_send without --enable-send = %v, want error", out)
+	}
+	now := time.Date(2024, 2, 12, 9, 0, 0, 0, time.UTC)
+	_, data, err := HandleScheduleSend(context.Background(), mail, cfg, fixedClock(now), nil, ScheduleSendInput{OutgoingID: int(report)})
+	if err != nil {
+		t.Fatalf("schedule_send error = %v", err)
+	}
+	if out := data.(map[string]any); out["scheduled"] != true || out["send_at"] != "2024-02-12T09:01:00Z" {
+		t.Fatalf("schedule_send = %v, want scheduled after the undo delay", out)
+	}
+
+	later := create("Monthly report")
+	out, ok := callToolWithConfig(t, mail, cfg, "schedule_send", map[string]any{"outgoing_id": later, "send_at": "2999-01-01T09:00:00+01:00"})
+	if !ok || out["send_at"] != "2999-01-01T08:00:00Z" {
+		t.Fatalf("schedule_send with send_at = %v", out)
+	}
+	laterID := out["schedule_id"]
+	if _, _, err := HandleScheduleSend(context.Background(), mail, cfg, fixedClock(now), nil, ScheduleSendInput{OutgoingID: int(later), SendAt: "2024-02-12T08:59:00Z"}); err == nil || !strings.Contains(err.Error(), "in the past") {
+		t.Errorf("schedule_send in the past error = %v, want in the past", err)
+	}
+
+	out, ok = callToolWithConfig(t, mail, cfg, "list_scheduled", map[string]any{})
+	if !ok || out["count"] != float64(2) {
+		t.Fatalf("list_scheduled = %v, want 2", out)
+	}
+	if first := out["scheduled"].([]any)[0].(map[string]any); first["subject"] != "Weekly report" || first["status"] != "pending" {
+		t.Errorf("first scheduled = %v, want the weekly report pending", first)
+	}
+
+	_, data, err = HandleCancelScheduled(context.Background(), mail, cfg, fixedClock(now), nil, CancelScheduledInput{ScheduleID: laterID.(string)})
+	if err != nil {
+		t.Fatalf("cancel_scheduled error = %v", err)
+	}
+	if canceled := data.(map[string]any)["scheduled"].(schedule.Entry); canceled.Status != schedule.StatusCanceled || !canceled.FinishedAt.Equal(now) {
+		t.Fatalf("cancel_scheduled = %+v, want canceled now", canceled)
+	}
+	if out, ok := callToolWithConfig(t, mail, cfg, "cancel_scheduled", map[string]any{"schedule_id": laterID}); ok {
+		t.Errorf("cancel_scheduled twice = %v, want error", out)
+	}
+
+	// A scheduler started later, e.g. after a restart, sends the due message
+	scheduler, err := NewScheduler(mail, cfg, fixedClock(now.Add(time.Hour)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := scheduler.RunDue(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if sent := mail.SentMessages(); len(sent) != 1 || sent[0].Subject != "Weekly report" {
+		t.Errorf("sent messages = %+v, want the weekly report only", sent)
+	}
+	out, _ = callToolWithConfig(t, mail, cfg, "list_scheduled", map[string]any{"include_finished": true})
+	statuses := []string{}
+	for _, e := range out["scheduled"].([]any) {
+		statuses = append(statuses, e.(map[string]any)["status"].(string))
+	}
+	if !slices.Equal(statuses, []string{"sent", "canceled"}) {
+		t.Errorf("statuses = %v, want sent and canceled", statuses)
+	}
+
+	// After a restart of Mail.app, the ID may belong to another message
+	invoice := create("Invoice")
+	store, err := scheduleStore(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reused, err := store.Add(schedule.Entry{
+		OutgoingID: int(invoice),
+		Subject:    "Weekly report",
+		Recipients: []string{"team@example.com"},
+		SendAt:     now,
+	}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := scheduler.RunDue(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if sent := mail.SentMessages(); len(sent) != 1 {
+		t.Errorf("sent messages = %+v, want the invoice not sent", sent)
+	}
+	entries, _ := store.List()
+	for _, e := range entries {
+		if e.ID == reused.ID && (e.Status != schedule.StatusFailed || !strings.Contains(e.Error, "no longer the scheduled message")) {
+			t.Errorf("entry = %+v, want failed as another message", e)
+		}
+	}
+}
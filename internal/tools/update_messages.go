@@ -0,0 +1,80 @@
+package tools
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+
+	"github.com/dastrobu/mail-mcp/internal/jxa"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+//go:embed scripts/update_messages.js
+var updateMessagesSource string
+
+var updateMessagesScript = jxa.Script{Name: "update_messages", Source: updateMessagesSource}
+
+// UpdateMessagesInput defines input parameters for update_messages tool
+type UpdateMessagesInput struct {
+	Account        string   `json:"account" jsonschema:"Name of the email account the messages are in" long:"account" description:"Name of the email account the messages are in"`
+	MailboxPath    []string `json:"mailboxPath" jsonschema:"Path to the mailbox as an array (e.g. ['Inbox']). Use the mailboxPath field from get_selected_messages or find_messages. Note: Mailbox names are case-sensitive." long:"mailbox-path" description:"Path to the mailbox. Can be specified multiple times for nested paths."`
+	MessageIDs     []int    `json:"message_ids" jsonschema:"IDs of the messages to update" long:"message-id" description:"ID of a message to update. Can be specified multiple times."`
+	ReadStatus     *bool    `json:"readStatus,omitempty" jsonschema:"Optional: true to mark as read, false to mark as unread" long:"read-status" description:"true to mark as read, false to mark as unread"`
+	FlaggedStatus  *bool    `json:"flaggedStatus,omitempty" jsonschema:"Optional: true to flag, false to remove the flag" long:"flagged-status" description:"true to flag, false to remove the flag"`
+	FlagIndex      *int     `json:"flagIndex,omitempty" jsonschema:"Optional: flag color (0 red, 1 orange, 2 yellow, 3 green, 4 blue, 5 purple, 6 gray) or -1 to remove the flag. Setting a color also flags the message." long:"flag-index" description:"Flag color (0 red, 1 orange, 2 yellow, 3 green, 4 blue, 5 purple, 6 gray) or -1 to remove the flag"`
+	JunkMailStatus *bool    `json:"junkMailStatus,omitempty" jsonschema:"Optional: true to mark as junk, false to mark as not junk" long:"junk-mail-status" description:"true to mark as junk, false to mark as not junk"`
+}
+
+// RegisterUpdateMessages registers the update_messages tool with the MCP server
+func RegisterUpdateMessages(srv *mcp.Server, executor jxa.Executor) {
+	mcp.AddTool(srv,
+		&mcp.Tool{
+			Name:        "update_messages",
+			Description: "Updates the read status, flagged status, flag color and junk status of many messages in one call. Only the given properties are changed. Returns the resulting status of each message.",
+			InputSchema: GenerateSchema[UpdateMessagesInput](),
+			Annotations: &mcp.ToolAnnotations{
+				Title:           "Update Messages",
+				ReadOnlyHint:    false,
+				IdempotentHint:  true,
+				DestructiveHint: new(false),
+				OpenWorldHint:   new(true),
+			},
+		},
+		func(ctx context.Context, request *mcp.CallToolRequest, input UpdateMessagesInput) (*mcp.CallToolResult, any, error) {
+			return HandleUpdateMessages(ctx, executor, request, input)
+		},
+	)
+}
+
+func HandleUpdateMessages(ctx context.Context, executor jxa.Executor, request *mcp.CallToolRequest, input UpdateMessagesInput) (*mcp.CallToolResult, any, error) {
+	if len(input.MailboxPath) == 0 {
+		return nil, nil, fmt.Errorf("mailboxPath is required and must be a non-empty array")
+	}
+	if len(input.MessageIDs) == 0 {
+		return nil, nil, fmt.Errorf("message_ids is required and must be a non-empty array")
+	}
+
+	hasUpdate := input.ReadStatus != nil ||
+		input.FlaggedStatus != nil ||
+		input.FlagIndex != nil ||
+		input.JunkMailStatus != nil
+	if !hasUpdate {
+		return nil, nil, fmt.Errorf("at least one update is required (readStatus, flaggedStatus, flagIndex, or junkMailStatus)")
+	}
+	if input.FlagIndex != nil && (*input.FlagIndex < -1 || *input.FlagIndex > 6) {
+		return nil, nil, fmt.Errorf("flagIndex must be between -1 and 6")
+	}
+
+	inputJSON, err := json.Marshal(input)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal input for JXA: %w", err)
+	}
+
+	data, err := executor.Execute(ctx, updateMessagesScript, string(inputJSON))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to execute update_messages: %w", err)
+	}
+
+	return nil, data, nil
+}
@@ -0,0 +1,118 @@
+package tools
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+
+	"github.com/dastrobu/mail-mcp/internal/jxa"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+//go:embed scripts/update_rule.js
+var updateRuleSource string
+
+var updateRuleScript = jxa.Script{Name: "update_rule", Source: updateRuleSource}
+
+// UpdateRuleInput defines input parameters for update_rule tool
+type UpdateRuleInput struct {
+	Name       string          `json:"name" jsonschema:"Name of the rule to update" long:"name" description:"Name of the rule to update"`
+	NewName    string          `json:"new_name,omitempty" jsonschema:"Optional: new name of the rule" long:"new-name" description:"New name of the rule"`
+	Enabled    *bool           `json:"enabled,omitempty" jsonschema:"Optional: true to enable, false to disable the rule" long:"enabled" description:"true to enable, false to disable the rule"`
+	Match      string          `json:"match,omitempty" jsonschema:"Optional: whether all or any of the conditions must be met: all or any" long:"match" description:"Whether all or any of the conditions must be met: all or any"`
+	Conditions []RuleCondition `json:"conditions,omitempty" jsonschema:"Optional: conditions replacing all conditions of the rule. Rejected if the rule has conditions listed as unsupported, since they would be lost." long:"condition" description:"Condition as JSON replacing the conditions of the rule. Can be specified multiple times."`
+	Actions    *RuleActions    `json:"actions,omitempty" jsonschema:"Optional: actions replacing the actions of the rule. Actions not given are turned off, except unsupported ones, which are kept." long:"actions" description:"Actions as JSON replacing the actions of the rule"`
+}
+
+// RegisterUpdateRule registers the update_rule tool with the MCP server
+func RegisterUpdateRule(srv *mcp.Server, executor jxa.Executor) {
+	mcp.AddTool(srv,
+		&mcp.Tool{
+			Name:        "update_rule",
+			Description: "Updates a rule of Apple Mail, identified by its name. Only the given properties are changed; conditions and actions are replaced as a whole. Conditions are only replaced if the rule has no unsupported conditions, unsupported actions are kept. Returns the updated rule as list_rules does.",
+			InputSchema: GenerateSchema[UpdateRuleInput](),
+			Annotations: &mcp.ToolAnnotations{
+				Title:           "Update Rule",
+				ReadOnlyHint:    false,
+				IdempotentHint:  true,
+				DestructiveHint: new(false),
+				OpenWorldHint:   new(true),
+			},
+		},
+		func(ctx context.Context, request *mcp.CallToolRequest, input UpdateRuleInput) (*mcp.CallToolResult, any, error) {
+			return HandleUpdateRule(ctx, executor, request, input)
+		},
+	)
+}
+
+func HandleUpdateRule(ctx context.Context, executor jxa.Executor, request *mcp.CallToolRequest, input UpdateRuleInput) (*mcp.CallToolResult, any, error) {
+	if input.Name == "" {
+		return nil, nil, fmt.Errorf("name is required")
+	}
+	if input.NewName == "" && input.Enabled == nil && input.Match == "" && len(input.Conditions) == 0 && input.Actions == nil {
+		return nil, nil, fmt.Errorf("at least one update is required (new_name, enabled, match, conditions or actions)")
+	}
+
+	args := map[string]any{"name": input.Name}
+	if input.NewName != "" {
+		args["new_name"] = input.NewName
+	}
+	if input.Enabled != nil {
+		args["enabled"] = *input.Enabled
+	}
+	if input.Match != "" {
+		if err := validateRuleMatch(input.Match); err != nil {
+			return nil, nil, err
+		}
+		args["allConditionsMustBeMet"] = input.Match == "all"
+	}
+	if len(input.Conditions) > 0 {
+		conditions, err := toMailConditions(input.Conditions)
+		if err != nil {
+			return nil, nil, err
+		}
+		args["conditions"] = conditions
+	}
+	if input.Actions != nil {
+		actions, err := toMailActions(*input.Actions)
+		if err != nil {
+			return nil, nil, err
+		}
+		args["actions"] = actions
+	}
+
+	// Replacing conditions would silently drop those without a
+	// representation, so the rule is checked first
+	if len(input.Conditions) > 0 {
+		rules, err := fetchRules(ctx, executor)
+		if err != nil {
+			return nil, nil, err
+		}
+		rule, err := findRule(rules, input.Name)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, part := range rule.Unsupported {
+			if part.Part == "condition" {
+				return nil, nil, fmt.Errorf("rule %q has unsupported conditions (%s %s) that would be lost by replacing its conditions, edit them in Mail instead", input.Name, part.Type, part.Detail)
+			}
+		}
+	}
+
+	inputJSON, err := json.Marshal(args)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal input for JXA: %w", err)
+	}
+
+	data, err := executor.Execute(ctx, updateRuleScript, string(inputJSON))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to execute update_rule: %w", err)
+	}
+	rule, err := ruleResult(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return nil, map[string]any{"rule": rule}, nil
+}
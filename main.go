@@ -10,9 +10,11 @@ import (
 	"os"
 
 	"github.com/dastrobu/mail-mcp/internal/completion"
+	"github.com/dastrobu/mail-mcp/internal/jxa"
 	"github.com/dastrobu/mail-mcp/internal/launchd"
 	applog "github.com/dastrobu/mail-mcp/internal/log"
 	"github.com/dastrobu/mail-mcp/internal/opts"
+	"github.com/dastrobu/mail-mcp/internal/schedule"
 
 	"github.com/dastrobu/mail-mcp/internal/tools"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -117,7 +119,7 @@ func debugMiddleware(debug bool) func(mcp.MethodHandler) mcp.MethodHandler {
 }
 
 // createServer creates and configures a new MCP server instance
-func createServer(debug bool) *mcp.Server {
+func createServer(debug bool, executor jxa.Executor, cfg tools.Config) *mcp.Server {
 	srv := mcp.NewServer(&mcp.Implementation{
 		Name:    serverName,
 		Version: version,
@@ -129,7 +131,7 @@ func createServer(debug bool) *mcp.Server {
 	}
 
 	// Register all tools
-	tools.RegisterAll(srv)
+	tools.RegisterAll(srv, executor, cfg)
 
 	return srv
 }
@@ -151,7 +153,26 @@ func run(options *opts.RunCmd) error {
 	// Log to stderr (stdout is used for MCP communication in stdio mode)
 	log.Printf("Apple Mail MCP Server v%s (commit: %s, built: %s) initialized\n", version, commit, date)
 
-	srv := createServer(options.Debug)
+	executor, err := newExecutor(options)
+	if err != nil {
+		return err
+	}
+
+	srv := createServer(options.Debug, executor, options.Config)
+
+	// Messages queued with schedule_send are sent while the server runs
+	if options.EnableSend {
+		scheduler, err := tools.NewScheduler(executor, options.Config, schedule.SystemClock)
+		if err != nil {
+			return err
+		}
+		log.Printf("Sending scheduled messages from %s\n", scheduler.Store.Path())
+		go func() {
+			if err := scheduler.Run(ctx, log.Printf); err != nil && err != context.Canceled {
+				log.Printf("Scheduler stopped: %v\n", err)
+			}
+		}()
+	}
 
 	// Run the server with the selected transport
 	switch transport {
@@ -195,6 +216,23 @@ func run(options *opts.RunCmd) error {
 	return nil
 }
 
+// newExecutor returns the executor for the run command: osascript by default,
+// wrapped in a recorder with --record, or a replayer with --replay.
+func newExecutor(options *opts.RunCmd) (jxa.Executor, error) {
+	switch {
+	case options.Record != "" && options.Replay != "":
+		return nil, fmt.Errorf("--record and --replay cannot be used together")
+	case options.Record != "":
+		log.Printf("Recording JXA script calls to %s\n", options.Record)
+		return jxa.NewRecorder(options.Record)
+	case options.Replay != "":
+		log.Printf("Replaying JXA script calls from %s (Mail.app is not used)\n", options.Replay)
+		return jxa.NewReplayer(options.Replay)
+	default:
+		return jxa.OsascriptExecutor{}, nil
+	}
+}
+
 // createLaunchd creates the launchd service
 func createLaunchd(options *opts.LaunchdCreateCmd) error {
 	cfg, err := launchd.DefaultConfig()
@@ -212,6 +250,9 @@ func createLaunchd(options *opts.LaunchdCreateCmd) error {
 	if options.Debug {
 		cfg.Debug = options.Debug
 	}
+	if options.EnableSend {
+		cfg.EnableSend = true
+	}
 	if options.DisableRunAtLoad {
 		cfg.RunAtLoad = false
 	}
@@ -229,6 +270,8 @@ func restartLaunchd() error {
 }
 
 func registerToolHandlers() {
+	executor := jxa.OsascriptExecutor{}
+
 	// Helper to handle tool execution result
 	handleResult := func(result any, err error) error {
 		if err != nil {
@@ -240,67 +283,202 @@ func registerToolHandlers() {
 	}
 
 	opts.GlobalOpts.Tool.ListAccounts.Handler = func(input tools.ListAccountsInput) error {
-		_, data, err := tools.HandleListAccounts(context.Background(), nil, input)
+		_, data, err := tools.HandleListAccounts(context.Background(), executor, nil, input)
 		return handleResult(data, err)
 	}
 
 	opts.GlobalOpts.Tool.ListMailboxes.Handler = func(input tools.ListMailboxesInput) error {
-		_, data, err := tools.HandleListMailboxes(context.Background(), nil, input)
+		_, data, err := tools.HandleListMailboxes(context.Background(), executor, nil, input)
 		return handleResult(data, err)
 	}
 
 	opts.GlobalOpts.Tool.GetMessageContent.Handler = func(input tools.GetMessageContentInput) error {
-		_, data, err := tools.HandleGetMessageContent(context.Background(), nil, input)
+		_, data, err := tools.HandleGetMessageContent(context.Background(), executor, nil, input)
 		return handleResult(data, err)
 	}
 
 	opts.GlobalOpts.Tool.GetSelectedMessages.Handler = func(input tools.GetSelectedMessagesInput) error {
-		_, data, err := tools.HandleGetSelectedMessages(context.Background(), nil, input)
+		_, data, err := tools.HandleGetSelectedMessages(context.Background(), executor, nil, input)
 		return handleResult(data, err)
 	}
 
 	opts.GlobalOpts.Tool.CreateReply.Handler = func(input tools.CreateReplyInput) error {
-		_, data, err := tools.HandleCreateReply(context.Background(), nil, input)
+		_, data, err := tools.HandleCreateReply(context.Background(), executor, opts.GlobalOpts.Tool.Config, nil, input)
 		return handleResult(data, err)
 	}
 
 	opts.GlobalOpts.Tool.ReplaceReply.Handler = func(input tools.ReplaceReplyInput) error {
-		_, data, err := tools.HandleReplaceReply(context.Background(), nil, input)
+		_, data, err := tools.HandleReplaceReply(context.Background(), executor, opts.GlobalOpts.Tool.Config, nil, input)
 		return handleResult(data, err)
 	}
 
 	opts.GlobalOpts.Tool.ListDrafts.Handler = func(input tools.ListDraftsInput) error {
-		_, data, err := tools.HandleListDrafts(context.Background(), nil, input)
+		_, data, err := tools.HandleListDrafts(context.Background(), executor, nil, input)
 		return handleResult(data, err)
 	}
 
 	opts.GlobalOpts.Tool.DeleteDraft.Handler = func(input tools.DeleteDraftInput) error {
-		_, data, err := tools.HandleDeleteDraft(context.Background(), nil, input)
+		_, data, err := tools.HandleDeleteDraft(context.Background(), executor, nil, input)
 		return handleResult(data, err)
 	}
 
 	opts.GlobalOpts.Tool.CreateOutgoingMessage.Handler = func(input tools.CreateOutgoingMessageInput) error {
-		_, data, err := tools.HandleCreateOutgoingMessage(context.Background(), nil, input)
+		_, data, err := tools.HandleCreateOutgoingMessage(context.Background(), executor, opts.GlobalOpts.Tool.Config, nil, input)
 		return handleResult(data, err)
 	}
 
 	opts.GlobalOpts.Tool.ListOutgoingMessages.Handler = func() error {
-		_, data, err := tools.HandleListOutgoingMessages(context.Background(), nil, struct{}{})
+		_, data, err := tools.HandleListOutgoingMessages(context.Background(), executor, nil, struct{}{})
 		return handleResult(data, err)
 	}
 
 	opts.GlobalOpts.Tool.ReplaceOutgoingMessage.Handler = func(input tools.ReplaceOutgoingMessageInput) error {
-		_, data, err := tools.HandleReplaceOutgoingMessage(context.Background(), nil, input)
+		_, data, err := tools.HandleReplaceOutgoingMessage(context.Background(), executor, opts.GlobalOpts.Tool.Config, nil, input)
 		return handleResult(data, err)
 	}
 
 	opts.GlobalOpts.Tool.DeleteOutgoingMessage.Handler = func(input tools.DeleteOutgoingMessageInput) error {
-		_, data, err := tools.HandleDeleteOutgoingMessage(context.Background(), nil, input)
+		_, data, err := tools.HandleDeleteOutgoingMessage(context.Background(), executor, nil, input)
 		return handleResult(data, err)
 	}
 
 	opts.GlobalOpts.Tool.FindMessages.Handler = func(input tools.FindMessagesInput) error {
-		_, data, err := tools.HandleFindMessages(context.Background(), nil, input)
+		_, data, err := tools.HandleFindMessages(context.Background(), executor, nil, input)
+		return handleResult(data, err)
+	}
+
+	opts.GlobalOpts.Tool.MoveMessages.Handler = func(input tools.MoveMessagesInput) error {
+		_, data, err := tools.HandleMoveMessages(context.Background(), executor, nil, input)
+		return handleResult(data, err)
+	}
+
+	opts.GlobalOpts.Tool.UpdateMessages.Handler = func(input tools.UpdateMessagesInput) error {
+		_, data, err := tools.HandleUpdateMessages(context.Background(), executor, nil, input)
+		return handleResult(data, err)
+	}
+
+	opts.GlobalOpts.Tool.DeleteMessages.Handler = func(input tools.DeleteMessagesInput) error {
+		_, data, err := tools.HandleDeleteMessages(context.Background(), executor, nil, input)
+		return handleResult(data, err)
+	}
+
+	opts.GlobalOpts.Tool.ListAttachments.Handler = func(input tools.ListAttachmentsInput) error {
+		_, data, err := tools.HandleListAttachments(context.Background(), executor, nil, input)
+		return handleResult(data, err)
+	}
+
+	opts.GlobalOpts.Tool.SaveAttachment.Handler = func(input tools.SaveAttachmentInput) error {
+		_, data, err := tools.HandleSaveAttachment(context.Background(), executor, opts.GlobalOpts.Tool.Config, nil, input)
+		return handleResult(data, err)
+	}
+
+	opts.GlobalOpts.Tool.GetThread.Handler = func(input tools.GetThreadInput) error {
+		_, data, err := tools.HandleGetThread(context.Background(), executor, nil, input)
+		return handleResult(data, err)
+	}
+
+	opts.GlobalOpts.Tool.GetMessageSource.Handler = func(input tools.GetMessageSourceInput) error {
+		_, data, err := tools.HandleGetMessageSource(context.Background(), executor, nil, input)
+		return handleResult(data, err)
+	}
+
+	opts.GlobalOpts.Tool.ExportMessages.Handler = func(input tools.ExportMessagesInput) error {
+		_, data, err := tools.HandleExportMessages(context.Background(), executor, opts.GlobalOpts.Tool.Config, nil, input)
+		return handleResult(data, err)
+	}
+
+	opts.GlobalOpts.Tool.CreateForward.Handler = func(input tools.CreateForwardInput) error {
+		_, data, err := tools.HandleCreateForward(context.Background(), executor, opts.GlobalOpts.Tool.Config, nil, input)
+		return handleResult(data, err)
+	}
+
+	opts.GlobalOpts.Tool.ReplaceForward.Handler = func(input tools.ReplaceForwardInput) error {
+		_, data, err := tools.HandleReplaceForward(context.Background(), executor, opts.GlobalOpts.Tool.Config, nil, input)
+		return handleResult(data, err)
+	}
+
+	opts.GlobalOpts.Tool.RedirectMessage.Handler = func(input tools.RedirectMessageInput) error {
+		_, data, err := tools.HandleRedirectMessage(context.Background(), executor, opts.GlobalOpts.Tool.Config, nil, input)
+		return handleResult(data, err)
+	}
+
+	opts.GlobalOpts.Tool.SendOutgoingMessage.Handler = func(input tools.SendOutgoingMessageInput) error {
+		_, data, err := tools.HandleSendOutgoingMessage(context.Background(), executor, opts.GlobalOpts.Tool.Config, nil, input)
+		return handleResult(data, err)
+	}
+
+	opts.GlobalOpts.Tool.ScheduleSend.Handler = func(input tools.ScheduleSendInput) error {
+		_, data, err := tools.HandleScheduleSend(context.Background(), executor, opts.GlobalOpts.Tool.Config, schedule.SystemClock, nil, input)
+		return handleResult(data, err)
+	}
+
+	opts.GlobalOpts.Tool.ListScheduled.Handler = func(input tools.ListScheduledInput) error {
+		_, data, err := tools.HandleListScheduled(context.Background(), executor, opts.GlobalOpts.Tool.Config, nil, input)
+		return handleResult(data, err)
+	}
+
+	opts.GlobalOpts.Tool.CancelScheduled.Handler = func(input tools.CancelScheduledInput) error {
+		_, data, err := tools.HandleCancelScheduled(context.Background(), executor, opts.GlobalOpts.Tool.Config, schedule.SystemClock, nil, input)
+		return handleResult(data, err)
+	}
+
+	opts.GlobalOpts.Tool.CreateMailbox.Handler = func(input tools.CreateMailboxInput) error {
+		_, data, err := tools.HandleCreateMailbox(context.Background(), executor, nil, input)
+		return handleResult(data, err)
+	}
+
+	opts.GlobalOpts.Tool.RenameMailbox.Handler = func(input tools.RenameMailboxInput) error {
+		_, data, err := tools.HandleRenameMailbox(context.Background(), executor, nil, input)
+		return handleResult(data, err)
+	}
+
+	opts.GlobalOpts.Tool.DeleteMailbox.Handler = func(input tools.DeleteMailboxInput) error {
+		_, data, err := tools.HandleDeleteMailbox(context.Background(), executor, nil, input)
+		return handleResult(data, err)
+	}
+
+	opts.GlobalOpts.Tool.ListRules.Handler = func(input tools.ListRulesInput) error {
+		_, data, err := tools.HandleListRules(context.Background(), executor, nil, input)
+		return handleResult(data, err)
+	}
+
+	opts.GlobalOpts.Tool.CreateRule.Handler = func(input tools.CreateRuleInput) error {
+		_, data, err := tools.HandleCreateRule(context.Background(), executor, nil, input)
+		return handleResult(data, err)
+	}
+
+	opts.GlobalOpts.Tool.UpdateRule.Handler = func(input tools.UpdateRuleInput) error {
+		_, data, err := tools.HandleUpdateRule(context.Background(), executor, nil, input)
+		return handleResult(data, err)
+	}
+
+	opts.GlobalOpts.Tool.DeleteRule.Handler = func(input tools.DeleteRuleInput) error {
+		_, data, err := tools.HandleDeleteRule(context.Background(), executor, nil, input)
+		return handleResult(data, err)
+	}
+
+	opts.GlobalOpts.Tool.ListSignatures.Handler = func(input tools.ListSignaturesInput) error {
+		_, data, err := tools.HandleListSignatures(context.Background(), executor, opts.GlobalOpts.Tool.Config, nil, input)
+		return handleResult(data, err)
+	}
+
+	opts.GlobalOpts.Tool.ListTemplates.Handler = func(input tools.ListTemplatesInput) error {
+		_, data, err := tools.HandleListTemplates(context.Background(), executor, opts.GlobalOpts.Tool.Config, nil, input)
+		return handleResult(data, err)
+	}
+
+	opts.GlobalOpts.Tool.CreateFromTemplate.Handler = func(input tools.CreateFromTemplateInput) error {
+		_, data, err := tools.HandleCreateFromTemplate(context.Background(), executor, opts.GlobalOpts.Tool.Config, nil, input)
+		return handleResult(data, err)
+	}
+
+	opts.GlobalOpts.Tool.MailMerge.Handler = func(input tools.MailMergeInput) error {
+		_, data, err := tools.HandleMailMerge(context.Background(), executor, opts.GlobalOpts.Tool.Config, nil, input)
+		return handleResult(data, err)
+	}
+
+	opts.GlobalOpts.Tool.ResolveContacts.Handler = func(input tools.ResolveContactsInput) error {
+		_, data, err := tools.HandleResolveContacts(context.Background(), executor, opts.GlobalOpts.Tool.Config, nil, input)
 		return handleResult(data, err)
 	}
 }